@@ -54,6 +54,92 @@ type EnvironmentSpec struct {
 
 	// RemoteCluster flag indicates if the Environment is deployed in a separate cluster to the Development Environment
 	RemoteCluster bool `json:"remoteCluster,omitempty" protobuf:"bytes,12,opt,name=remoteCluster"`
+
+	// PromotionPolicy the gates which must pass before a version is automatically promoted to this Environment
+	PromotionPolicy *PromotionPolicy `json:"promotionPolicy,omitempty" protobuf:"bytes,13,opt,name=promotionPolicy"`
+
+	// Freezes the periods during which releases are not promoted to this Environment
+	Freezes []PromotionFreeze `json:"freezes,omitempty" protobuf:"bytes,14,rep,name=freezes"`
+
+	// PreviewLifecycle the time to live and hibernation state of a Preview Environment
+	PreviewLifecycle *PreviewLifecycle `json:"previewLifecycle,omitempty" protobuf:"bytes,15,opt,name=previewLifecycle"`
+
+	// TrustPolicy the keys which must have signed the charts and images promoted to this Environment
+	TrustPolicy *TrustPolicy `json:"trustPolicy,omitempty" protobuf:"bytes,16,opt,name=trustPolicy"`
+}
+
+// PromotionPolicy the gates which must pass in the previous Environment before a version is automatically promoted
+type PromotionPolicy struct {
+	// SoakTime the minimum duration the version must have been running in the previous Environment such as 30m
+	SoakTime string `json:"soakTime,omitempty" protobuf:"bytes,1,opt,name=soakTime"`
+	// Tests the names of the pipelines which must have succeeded after the version was promoted to the previous Environment,
+	// or after the version was released for the first Environment
+	Tests []string `json:"tests,omitempty" protobuf:"bytes,2,rep,name=tests"`
+	// PrometheusURL the URL of the Prometheus server used to evaluate the metric gates
+	PrometheusURL string `json:"prometheusURL,omitempty" protobuf:"bytes,3,opt,name=prometheusURL"`
+	// Metrics the Prometheus queries whose values must be within their thresholds
+	Metrics []PromotionMetricGate `json:"metrics,omitempty" protobuf:"bytes,4,rep,name=metrics"`
+	// Timeout how long to wait for the gates to pass before failing the promotion such as 1h. Defaults to 30m after the soak time
+	Timeout string `json:"timeout,omitempty" protobuf:"bytes,5,opt,name=timeout"`
+}
+
+// PromotionMetricGate a Prometheus query whose value must compare to a threshold for the gate to pass.
+// The query may refer to $APP, $VERSION and $NAMESPACE which are replaced with the app, its version and the
+// namespace of the previous Environment
+type PromotionMetricGate struct {
+	Name  string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	Query string `json:"query" protobuf:"bytes,2,opt,name=query"`
+	// Comparison is one of <, <=, >, >= or == and defaults to <=
+	Comparison string  `json:"comparison,omitempty" protobuf:"bytes,3,opt,name=comparison"`
+	Threshold  float64 `json:"threshold" protobuf:"fixed64,4,opt,name=threshold"`
+}
+
+// PreviewLifecycle the time to live and idle hibernation of a Preview Environment along with whether it is hibernated
+type PreviewLifecycle struct {
+	// TTL how long the Preview Environment lives after it was last deployed before it is deleted such as 72h
+	TTL string `json:"ttl,omitempty" protobuf:"bytes,1,opt,name=ttl"`
+	// IdleTimeout how long the Preview Environment can go without traffic before it is hibernated such as 8h
+	IdleTimeout string `json:"idleTimeout,omitempty" protobuf:"bytes,2,opt,name=idleTimeout"`
+	// LastDeployedTimestamp when the Preview Environment was last deployed
+	LastDeployedTimestamp *metav1.Time `json:"lastDeployedTimestamp,omitempty" protobuf:"bytes,3,opt,name=lastDeployedTimestamp"`
+	// LastActiveTimestamp when the Preview Environment was last deployed, woken up or seen to receive traffic
+	LastActiveTimestamp *metav1.Time `json:"lastActiveTimestamp,omitempty" protobuf:"bytes,4,opt,name=lastActiveTimestamp"`
+	// Hibernated whether the Deployments of the Preview Environment are scaled to zero
+	Hibernated          bool         `json:"hibernated,omitempty" protobuf:"bytes,5,opt,name=hibernated"`
+	HibernatedTimestamp *metav1.Time `json:"hibernatedTimestamp,omitempty" protobuf:"bytes,6,opt,name=hibernatedTimestamp"`
+	// Replicas the replicas of each Deployment before hibernation which are restored when woken up
+	Replicas map[string]int32 `json:"replicas,omitempty" protobuf:"bytes,7,rep,name=replicas"`
+	// WokenBy what last woke up the Preview Environment such as a pull request comment or an ingress request
+	WokenBy        string       `json:"wokenBy,omitempty" protobuf:"bytes,8,opt,name=wokenBy"`
+	WokenTimestamp *metav1.Time `json:"wokenTimestamp,omitempty" protobuf:"bytes,9,opt,name=wokenTimestamp"`
+}
+
+// TrustPolicy the public keys, in the PEM format of 'cosign generate-key-pair', of which one must have signed the
+// chart of a release and its images before the release is promoted to an Environment
+type TrustPolicy struct {
+	PublicKeys []string `json:"publicKeys,omitempty" protobuf:"bytes,1,rep,name=publicKeys"`
+	// Exclude the names of charts, such as third party charts, which do not need to be signed. Supports wildcards
+	Exclude []string `json:"exclude,omitempty" protobuf:"bytes,2,rep,name=exclude"`
+	// AllowUnsignedImages allows releases without signed images such as charts which only use third party images
+	AllowUnsignedImages bool `json:"allowUnsignedImages,omitempty" protobuf:"bytes,3,opt,name=allowUnsignedImages"`
+}
+
+// PromotionFreeze a period during which releases are not promoted to an Environment. A freeze either recurs using
+// a cron schedule and duration or is ad-hoc with an end time
+type PromotionFreeze struct {
+	Name   string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	Reason string `json:"reason,omitempty" protobuf:"bytes,2,opt,name=reason"`
+	// Schedule the cron expression for the start of each recurring freeze such as '0 17 * * FRI'
+	Schedule string `json:"schedule,omitempty" protobuf:"bytes,3,opt,name=schedule"`
+	// Duration how long each recurring freeze lasts such as 64h
+	Duration string `json:"duration,omitempty" protobuf:"bytes,4,opt,name=duration"`
+	// TimeZone the location the schedule is evaluated in such as Europe/London. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,5,opt,name=timeZone"`
+	// Start the start of an ad-hoc freeze. Defaults to when the freeze was created
+	Start *metav1.Time `json:"start,omitempty" protobuf:"bytes,6,opt,name=start"`
+	// End the end of an ad-hoc freeze
+	End       *metav1.Time `json:"end,omitempty" protobuf:"bytes,7,opt,name=end"`
+	CreatedBy string       `json:"createdBy,omitempty" protobuf:"bytes,8,opt,name=createdBy"`
 }
 
 // EnvironmentStatus is the status for an Environment resource
@@ -239,6 +325,34 @@ type TeamSettings struct {
 
 	// BootRequirements is a marshaled string of the jx-requirements.yaml used in the most recent run for this cluster
 	BootRequirements string `json:"bootRequirements,omitempty" protobuf:"bytes,31,opt,name=bootRequirements"`
+
+	// RequiredChecks the policies declaring which commit status contexts must pass on branches matching a pattern.
+	// They are aggregated into a single commit status context so they can be enforced on any git provider
+	RequiredChecks []RequiredChecksPolicy `json:"requiredChecks,omitempty" protobuf:"bytes,32,rep,name=requiredChecks"`
+
+	// LogMaskPatterns the regular expressions matching secrets which are masked in the build logs in addition to the
+	// values of the secrets in the team namespace and the built in patterns for tokens and private keys
+	LogMaskPatterns []LogMaskPattern `json:"logMaskPatterns,omitempty" protobuf:"bytes,33,rep,name=logMaskPatterns"`
+}
+
+// LogMaskPattern a regular expression matching secrets to mask in the build logs
+type LogMaskPattern struct {
+	// Name the name of the kind of secret matched such as my-api-key
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Pattern the regular expression matching the secret. If it contains a capture group only the text matched by the
+	// first group is masked
+	Pattern string `json:"pattern" protobuf:"bytes,2,opt,name=pattern"`
+}
+
+// RequiredChecksPolicy the commit status contexts which must pass for commits targeting the matching branches
+type RequiredChecksPolicy struct {
+	// Branches a regular expression matching the whole name of the target branches such as master|release-.*
+	Branches string `json:"branches" protobuf:"bytes,1,opt,name=branches"`
+	// Contexts the names of the commit status contexts which must pass. Contexts reported to the git provider by something
+	// other than Jenkins X are polled while the aggregate is pending, so they may take a minute to be reflected in it
+	Contexts []string `json:"contexts,omitempty" protobuf:"bytes,2,rep,name=contexts"`
+	// Context the name of the aggregate commit status context. Defaults to jx/required
+	Context string `json:"context,omitempty" protobuf:"bytes,3,opt,name=context"`
 }
 
 // StorageLocation
@@ -406,6 +406,7 @@ type CommitStatusCommitReference struct {
 	GitURL      string `json:"gitUrl,omitempty"  protobuf:"bytes,1,opt,name=gitUrl"`
 	PullRequest string `json:"pullRequest,omitempty"  protobuf:"bytes,2,opt,name=pullRequest"`
 	SHA         string `json:"sha,omitempty"  protobuf:"bytes,3,opt,name=sha"`
+	BaseRef     string `json:"baseRef,omitempty"  protobuf:"bytes,4,opt,name=baseRef"`
 }
 
 type CommitStatusItem struct {
@@ -525,6 +526,24 @@ type SourceRepositorySpec struct {
 	HTTPCloneURL string `json:"httpCloneURL,omitempty" protobuf:"bytes,9,opt,name=httpCloneURL"`
 	// Scheduler a reference to a custom scheduler otherwise we default to the Team's Scededuler
 	Scheduler ResourceReference `json:"scheduler,omitempty" protobuf:"bytes,10,opt,name=scheduler"`
+	// DependencyUpdates the policy for the pull requests which update the outdated dependencies of this repository
+	DependencyUpdates *DependencyUpdatePolicy `json:"dependencyUpdates,omitempty" protobuf:"bytes,11,opt,name=dependencyUpdates"`
+}
+
+// DependencyUpdatePolicy how the dependencies of a repository which are older than the version stream are updated
+type DependencyUpdatePolicy struct {
+	// Disabled disables dependency updates for the repository
+	Disabled bool `json:"disabled,omitempty" protobuf:"bytes,1,opt,name=disabled"`
+	// Grouping how updates are batched into pull requests: 'all' for a single pull request, 'kind' for one per kind
+	// of dependency (go, maven, npm or helm) or 'none' for one per dependency. Defaults to 'all'
+	Grouping string `json:"grouping,omitempty" protobuf:"bytes,2,opt,name=grouping"`
+	// Schedule the cron expression of when pull requests are opened such as '0 6 * * MON'. Defaults to every run
+	Schedule string `json:"schedule,omitempty" protobuf:"bytes,3,opt,name=schedule"`
+	// TimeZone the location the schedule is evaluated in such as Europe/London. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,4,opt,name=timeZone"`
+	// Ignore the dependencies which are not updated as a name such as 'github.com/pkg/errors' or a name and version
+	// such as 'lodash@5*'. Names and versions may end with a * wildcard
+	Ignore []string `json:"ignore,omitempty" protobuf:"bytes,5,rep,name=ignore"`
 }
 
 // AppSpec provides details of the metadata for an App
@@ -119,7 +119,14 @@ const (
 	MeasurementCount   = "count"
 )
 
+// Recommended measurements and statements for image scans in addition to a count for each severity
+const (
+	ImageScanMeasurementIgnored = "Ignored"
+	ImageScanStatementPolicy    = "PolicyPassed"
+)
+
 const (
 	FactTypeCoverage              = "jx.coverage"
 	FactTypeStaticProgramAnalysis = "jx.staticProgramAnalysis"
+	FactTypeImageScan             = "jx.imageScan"
 )
@@ -62,6 +62,28 @@ type PipelineActivitySpec struct {
 	BatchPipelineActivity BatchPipelineActivity  `json:"batchPipelineActivity,omitempty" protobuf:"bytes,25,opt,name=batchPipelineActivity"`
 	Context               string                 `json:"context,omitempty" protobuf:"bytes,26,opt,name=context"`
 	BaseSHA               string                 `json:"baseSHA,omitempty" protobuf:"bytes,27,opt,name=baseSHA"`
+	// FreezeOverrides the releases to environments which this pipeline made during a freeze
+	FreezeOverrides []PromotionFreezeOverride `json:"freezeOverrides,omitempty" protobuf:"bytes,28,rep,name=freezeOverrides"`
+	// Rollbacks the rollbacks of the version released by this pipeline in environments
+	Rollbacks []PipelineActivityRollback `json:"rollbacks,omitempty" protobuf:"bytes,29,rep,name=rollbacks"`
+}
+
+// PipelineActivityRollback records the rollback of the version released by a pipeline in an environment
+type PipelineActivityRollback struct {
+	Environment    string       `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	ToVersion      string       `json:"toVersion,omitempty" protobuf:"bytes,2,opt,name=toVersion"`
+	PullRequestURL string       `json:"pullRequestURL,omitempty" protobuf:"bytes,3,opt,name=pullRequestURL"`
+	Reason         string       `json:"reason,omitempty" protobuf:"bytes,4,opt,name=reason"`
+	RolledBackBy   string       `json:"rolledBackBy,omitempty" protobuf:"bytes,5,opt,name=rolledBackBy"`
+	Timestamp      *metav1.Time `json:"timestamp,omitempty" protobuf:"bytes,6,opt,name=timestamp"`
+}
+
+// PromotionFreezeOverride records a release to an environment during one of its freezes
+type PromotionFreezeOverride struct {
+	Environment string       `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	Freeze      string       `json:"freeze,omitempty" protobuf:"bytes,2,opt,name=freeze"`
+	Reason      string       `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+	Timestamp   *metav1.Time `json:"timestamp,omitempty" protobuf:"bytes,4,opt,name=timestamp"`
 }
 
 // BatchPipelineActivity contains information about a batch build, used by both the batch build and its comprising PRs for linking them together
@@ -118,6 +140,28 @@ type PromoteActivityStep struct {
 	PullRequest    *PromotePullRequestStep `json:"pullRequest,omitempty" protobuf:"bytes,2,opt,name=pullRequest"`
 	Update         *PromoteUpdateStep      `json:"update,omitempty" protobuf:"bytes,3,opt,name=update"`
 	ApplicationURL string                  `json:"applicationURL,omitempty" protobuf:"bytes,4,opt,name=environment"`
+	Gates          []PromotionGateResult   `json:"gates,omitempty" protobuf:"bytes,5,rep,name=gates"`
+}
+
+// PromotionGateKind is the kind of a gate of a PromotionPolicy
+type PromotionGateKind string
+
+const (
+	// PromotionGateKindSoak the version must have been running in the previous environment for the soak time
+	PromotionGateKindSoak PromotionGateKind = "Soak"
+	// PromotionGateKindTest a pipeline must have succeeded after the promotion to the previous environment
+	PromotionGateKindTest PromotionGateKind = "Test"
+	// PromotionGateKindMetric a Prometheus query must be within its threshold
+	PromotionGateKindMetric PromotionGateKind = "Metric"
+)
+
+// PromotionGateResult is the result of the last evaluation of a gate of the PromotionPolicy of the environment
+type PromotionGateResult struct {
+	Name               string             `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	Kind               PromotionGateKind  `json:"kind,omitempty" protobuf:"bytes,2,opt,name=kind"`
+	Status             ActivityStatusType `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+	Message            string             `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+	EvaluatedTimestamp *metav1.Time       `json:"evaluatedTimestamp,omitempty" protobuf:"bytes,5,opt,name=evaluatedTimestamp"`
 }
 
 // GitStatus the status of a git commit in terms of CI/CD
@@ -193,6 +237,8 @@ const (
 	ActivityStatusTypeAborted ActivityStatusType = "Aborted"
 	// ActivityStatusTypeNotExecuted if the workflow was not executed
 	ActivityStatusTypeNotExecuted ActivityStatusType = "NotExecuted"
+	// ActivityStatusTypeSkipped if the stage was skipped because its when conditions were not met
+	ActivityStatusTypeSkipped ActivityStatusType = "Skipped"
 )
 
 type Attachment struct {
@@ -51,6 +51,9 @@ type PipelineStructureStage struct {
 	Previous *string `json:"previous,omitempty" protobuf:"bytes,8,opt,name=previous"`
 	// +optional
 	Next *string `json:"next,omitempty" protobuf:"bytes,9,opt,name=next"`
+	// Skipped is true if the stage will not be run because its when conditions were not met
+	// +optional
+	Skipped bool `json:"skipped,omitempty" protobuf:"bytes,10,opt,name=skipped"`
 }
 
 // GetStage will get the PipelineStructureStage with the given name, if it exists.
@@ -119,7 +122,7 @@ func (ps *PipelineStructure) GetAllStagesWithSteps() []PipelineStructureStage {
 	var stages []PipelineStructureStage
 
 	for _, s := range ps.Stages {
-		if len(s.Stages) == 0 && len(s.Parallel) == 0 {
+		if !s.Skipped && len(s.Stages) == 0 && len(s.Parallel) == 0 {
 			stages = append(stages, s)
 		}
 	}
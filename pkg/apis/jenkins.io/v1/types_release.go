@@ -36,19 +36,57 @@ type ReleaseList struct {
 
 // ReleaseSpec is the specification of the Release
 type ReleaseSpec struct {
-	Name              string             `json:"name,omitempty"  protobuf:"bytes,1,opt,name=name"`
-	Version           string             `json:"version,omitempty"  protobuf:"bytes,2,opt,name=version"`
-	GitHTTPURL        string             `json:"gitHttpUrl,omitempty"  protobuf:"bytes,3,opt,name=gitHttpUrl"`
-	GitCloneURL       string             `json:"gitCloneUrl,omitempty"  protobuf:"bytes,4,opt,name=gitCloneUrl"`
-	Commits           []CommitSummary    `json:"commits,omitempty" protobuf:"bytes,5,opt,name=commits"`
-	Issues            []IssueSummary     `json:"issues,omitempty" protobuf:"bytes,6,opt,name=issues"`
-	PullRequests      []IssueSummary     `json:"pullRequests,omitempty" protobuf:"bytes,7,opt,name=pullRequests"`
-	DependencyUpdates []DependencyUpdate `json:"dependencyUpdates,omitempty" protobuf:"bytes,11,opt,name=dependencyUpdates"`
-	ReleaseNotesURL   string             `json:"releaseNotesURL,omitempty" protobuf:"bytes,8,opt,name=releaseNotesURL"`
-	GitRepository     string             `json:"gitRepository,omitempty" protobuf:"bytes,9,opt,name=gitRepository"`
-	GitOwner          string             `json:"gitOwner,omitempty" protobuf:"bytes,10,opt,name=gitOwner"`
+	Name              string              `json:"name,omitempty"  protobuf:"bytes,1,opt,name=name"`
+	Version           string              `json:"version,omitempty"  protobuf:"bytes,2,opt,name=version"`
+	GitHTTPURL        string              `json:"gitHttpUrl,omitempty"  protobuf:"bytes,3,opt,name=gitHttpUrl"`
+	GitCloneURL       string              `json:"gitCloneUrl,omitempty"  protobuf:"bytes,4,opt,name=gitCloneUrl"`
+	Commits           []CommitSummary     `json:"commits,omitempty" protobuf:"bytes,5,opt,name=commits"`
+	Issues            []IssueSummary      `json:"issues,omitempty" protobuf:"bytes,6,opt,name=issues"`
+	PullRequests      []IssueSummary      `json:"pullRequests,omitempty" protobuf:"bytes,7,opt,name=pullRequests"`
+	DependencyUpdates []DependencyUpdate  `json:"dependencyUpdates,omitempty" protobuf:"bytes,11,opt,name=dependencyUpdates"`
+	ReleaseNotesURL   string              `json:"releaseNotesURL,omitempty" protobuf:"bytes,8,opt,name=releaseNotesURL"`
+	GitRepository     string              `json:"gitRepository,omitempty" protobuf:"bytes,9,opt,name=gitRepository"`
+	GitOwner          string              `json:"gitOwner,omitempty" protobuf:"bytes,10,opt,name=gitOwner"`
+	SBOMs             []SBOMReference     `json:"sboms,omitempty" protobuf:"bytes,12,opt,name=sboms"`
+	Signatures        []ArtifactSignature `json:"signatures,omitempty" protobuf:"bytes,13,opt,name=signatures"`
 }
 
+// SBOMReference is a software bill of materials of the release which has been stored or attached to the git release
+type SBOMReference struct {
+	// Format the format of the document such as cyclonedx or spdx
+	Format string `json:"format,omitempty" protobuf:"bytes,1,opt,name=format"`
+	Name   string `json:"name,omitempty" protobuf:"bytes,2,opt,name=name"`
+	URL    string `json:"url,omitempty" protobuf:"bytes,3,opt,name=url"`
+}
+
+// ArtifactSignature is a signature of an image or chart of the release using the signing payload of cosign
+type ArtifactSignature struct {
+	// Kind the kind of the artifact which is either image or chart
+	Kind ArtifactKind `json:"kind,omitempty" protobuf:"bytes,1,opt,name=kind"`
+	// Name the image repository or chart name
+	Name string `json:"name,omitempty" protobuf:"bytes,2,opt,name=name"`
+	// Version the image tag or chart version
+	Version string `json:"version,omitempty" protobuf:"bytes,3,opt,name=version"`
+	// Digest the digest of the image manifest or chart archive such as sha256:abc123
+	Digest string `json:"digest,omitempty" protobuf:"bytes,4,opt,name=digest"`
+	// Payload the base64 encoded payload which was signed
+	Payload string `json:"payload,omitempty" protobuf:"bytes,5,opt,name=payload"`
+	// Signature the base64 encoded signature of the payload
+	Signature string `json:"signature,omitempty" protobuf:"bytes,6,opt,name=signature"`
+	// KeyID the fingerprint of the public key which verifies the signature
+	KeyID string `json:"keyID,omitempty" protobuf:"bytes,7,opt,name=keyID"`
+}
+
+// ArtifactKind the kind of a signed artifact
+type ArtifactKind string
+
+const (
+	// ArtifactKindImage a container image
+	ArtifactKindImage ArtifactKind = "image"
+	// ArtifactKindChart a helm chart
+	ArtifactKindChart ArtifactKind = "chart"
+)
+
 // ReleaseStatus is the status of a release
 type ReleaseStatus struct {
 	Status ReleaseStatusType `json:"status,omitempty"  protobuf:"bytes,1,opt,name=status"`
@@ -84,6 +122,7 @@ type CommitSummary struct {
 	Committer *UserDetails `json:"committer,omitempty"  protobuf:"bytes,5,opt,name=committer"`
 	Branch    string       `json:"branch,omitempty"  protobuf:"bytes,6,opt,name=branch"`
 	IssueIDs  []string     `json:"issueIds,omitempty"  protobuf:"bytes,7,opt,name=issueIds"`
+	Timestamp *metav1.Time `json:"timestamp,omitempty"  protobuf:"bytes,8,opt,name=timestamp"`
 }
 
 // ReleaseStatusType is the status of a release; usually deployed or failed at completion
@@ -184,6 +184,22 @@ func (in *Approve) DeepCopy() *Approve {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactSignature) DeepCopyInto(out *ArtifactSignature) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactSignature.
+func (in *ArtifactSignature) DeepCopy() *ArtifactSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Attachment) DeepCopyInto(out *Attachment) {
 	*out = *in
@@ -573,6 +589,14 @@ func (in *CommitSummary) DeepCopyInto(out *CommitSummary) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
 	return
 }
 
@@ -795,6 +819,27 @@ func (in DependencyUpdatePath) DeepCopy() DependencyUpdatePath {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyUpdatePolicy) DeepCopyInto(out *DependencyUpdatePolicy) {
+	*out = *in
+	if in.Ignore != nil {
+		in, out := &in.Ignore, &out.Ignore
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyUpdatePolicy.
+func (in *DependencyUpdatePolicy) DeepCopy() *DependencyUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Environment) DeepCopyInto(out *Environment) {
 	*out = *in
@@ -1010,6 +1055,28 @@ func (in *EnvironmentSpec) DeepCopyInto(out *EnvironmentSpec) {
 	out.Source = in.Source
 	in.TeamSettings.DeepCopyInto(&out.TeamSettings)
 	out.PreviewGitSpec = in.PreviewGitSpec
+	if in.PromotionPolicy != nil {
+		in, out := &in.PromotionPolicy, &out.PromotionPolicy
+		*out = new(PromotionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Freezes != nil {
+		in, out := &in.Freezes, &out.Freezes
+		*out = make([]PromotionFreeze, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreviewLifecycle != nil {
+		in, out := &in.PreviewLifecycle, &out.PreviewLifecycle
+		*out = new(PreviewLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustPolicy != nil {
+		in, out := &in.TrustPolicy, &out.TrustPolicy
+		*out = new(TrustPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1886,6 +1953,22 @@ func (in *Lgtm) DeepCopy() *Lgtm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogMaskPattern) DeepCopyInto(out *LogMaskPattern) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogMaskPattern.
+func (in *LogMaskPattern) DeepCopy() *LogMaskPattern {
+	if in == nil {
+		return nil
+	}
+	out := new(LogMaskPattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Measurement) DeepCopyInto(out *Measurement) {
 	*out = *in
@@ -2166,6 +2249,26 @@ func (in *PipelineActivityList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineActivityRollback) DeepCopyInto(out *PipelineActivityRollback) {
+	*out = *in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineActivityRollback.
+func (in *PipelineActivityRollback) DeepCopy() *PipelineActivityRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineActivityRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineActivitySpec) DeepCopyInto(out *PipelineActivitySpec) {
 	*out = *in
@@ -2207,6 +2310,20 @@ func (in *PipelineActivitySpec) DeepCopyInto(out *PipelineActivitySpec) {
 		}
 	}
 	in.BatchPipelineActivity.DeepCopyInto(&out.BatchPipelineActivity)
+	if in.FreezeOverrides != nil {
+		in, out := &in.FreezeOverrides, &out.FreezeOverrides
+		*out = make([]PromotionFreezeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollbacks != nil {
+		in, out := &in.Rollbacks, &out.Rollbacks
+		*out = make([]PipelineActivityRollback, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2854,6 +2971,45 @@ func (in *PreviewGitSpec) DeepCopy() *PreviewGitSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewLifecycle) DeepCopyInto(out *PreviewLifecycle) {
+	*out = *in
+	if in.LastDeployedTimestamp != nil {
+		in, out := &in.LastDeployedTimestamp, &out.LastDeployedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastActiveTimestamp != nil {
+		in, out := &in.LastActiveTimestamp, &out.LastActiveTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.HibernatedTimestamp != nil {
+		in, out := &in.HibernatedTimestamp, &out.HibernatedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WokenTimestamp != nil {
+		in, out := &in.WokenTimestamp, &out.WokenTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewLifecycle.
+func (in *PreviewLifecycle) DeepCopy() *PreviewLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PromoteActivityStep) DeepCopyInto(out *PromoteActivityStep) {
 	*out = *in
@@ -2876,6 +3032,13 @@ func (in *PromoteActivityStep) DeepCopyInto(out *PromoteActivityStep) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Gates != nil {
+		in, out := &in.Gates, &out.Gates
+		*out = make([]PromotionGateResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2928,6 +3091,112 @@ func (in *PromoteUpdateStep) DeepCopy() *PromoteUpdateStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionFreeze) DeepCopyInto(out *PromotionFreeze) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionFreeze.
+func (in *PromotionFreeze) DeepCopy() *PromotionFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionFreezeOverride) DeepCopyInto(out *PromotionFreezeOverride) {
+	*out = *in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionFreezeOverride.
+func (in *PromotionFreezeOverride) DeepCopy() *PromotionFreezeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionFreezeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionGateResult) DeepCopyInto(out *PromotionGateResult) {
+	*out = *in
+	if in.EvaluatedTimestamp != nil {
+		in, out := &in.EvaluatedTimestamp, &out.EvaluatedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionGateResult.
+func (in *PromotionGateResult) DeepCopy() *PromotionGateResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionGateResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionMetricGate) DeepCopyInto(out *PromotionMetricGate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionMetricGate.
+func (in *PromotionMetricGate) DeepCopy() *PromotionMetricGate {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionMetricGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionPolicy) DeepCopyInto(out *PromotionPolicy) {
+	*out = *in
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]PromotionMetricGate, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionPolicy.
+func (in *PromotionPolicy) DeepCopy() *PromotionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PromoteWorkflowStep) DeepCopyInto(out *PromoteWorkflowStep) {
 	*out = *in
@@ -3271,6 +3540,16 @@ func (in *ReleaseSpec) DeepCopyInto(out *ReleaseSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SBOMs != nil {
+		in, out := &in.SBOMs, &out.SBOMs
+		*out = make([]SBOMReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Signatures != nil {
+		in, out := &in.Signatures, &out.Signatures
+		*out = make([]ArtifactSignature, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3434,6 +3713,27 @@ func (in *RepoContextPolicy) DeepCopy() *RepoContextPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredChecksPolicy) DeepCopyInto(out *RequiredChecksPolicy) {
+	*out = *in
+	if in.Contexts != nil {
+		in, out := &in.Contexts, &out.Contexts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredChecksPolicy.
+func (in *RequiredChecksPolicy) DeepCopy() *RequiredChecksPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RequiredChecksPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
 	*out = *in
@@ -3536,6 +3836,22 @@ func (in *ReviewPolicy) DeepCopy() *ReviewPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SBOMReference) DeepCopyInto(out *SBOMReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SBOMReference.
+func (in *SBOMReference) DeepCopy() *SBOMReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SBOMReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Scheduler) DeepCopyInto(out *Scheduler) {
 	*out = *in
@@ -3774,7 +4090,7 @@ func (in *SourceRepository) DeepCopyInto(out *SourceRepository) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
@@ -3917,6 +4233,11 @@ func (in *SourceRepositoryList) DeepCopyObject() runtime.Object {
 func (in *SourceRepositorySpec) DeepCopyInto(out *SourceRepositorySpec) {
 	*out = *in
 	out.Scheduler = in.Scheduler
+	if in.DependencyUpdates != nil {
+		in, out := &in.DependencyUpdates, &out.DependencyUpdates
+		*out = new(DependencyUpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4080,6 +4401,18 @@ func (in *TeamSettings) DeepCopyInto(out *TeamSettings) {
 		copy(*out, *in)
 	}
 	out.DefaultScheduler = in.DefaultScheduler
+	if in.RequiredChecks != nil {
+		in, out := &in.RequiredChecks, &out.RequiredChecks
+		*out = make([]RequiredChecksPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogMaskPatterns != nil {
+		in, out := &in.LogMaskPatterns, &out.LogMaskPatterns
+		*out = make([]LogMaskPattern, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -4182,6 +4515,32 @@ func (in *Trigger) DeepCopy() *Trigger {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustPolicy) DeepCopyInto(out *TrustPolicy) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustPolicy.
+func (in *TrustPolicy) DeepCopy() *TrustPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
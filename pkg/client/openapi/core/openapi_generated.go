@@ -18,6 +18,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppList":                             schema_pkg_apis_jenkinsio_v1_AppList(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.AppSpec":                             schema_pkg_apis_jenkinsio_v1_AppSpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Approve":                             schema_pkg_apis_jenkinsio_v1_Approve(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ArtifactSignature":                   schema_pkg_apis_jenkinsio_v1_ArtifactSignature(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment":                          schema_pkg_apis_jenkinsio_v1_Attachment(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity":               schema_pkg_apis_jenkinsio_v1_BatchPipelineActivity(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Binary":                              schema_pkg_apis_jenkinsio_v1_Binary(ref),
@@ -40,6 +41,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CoreActivityStep":                    schema_pkg_apis_jenkinsio_v1_CoreActivityStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdate":                    schema_pkg_apis_jenkinsio_v1_DependencyUpdate(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdateDetails":             schema_pkg_apis_jenkinsio_v1_DependencyUpdateDetails(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdatePolicy":              schema_pkg_apis_jenkinsio_v1_DependencyUpdatePolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Environment":                         schema_pkg_apis_jenkinsio_v1_Environment(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentFilter":                   schema_pkg_apis_jenkinsio_v1_EnvironmentFilter(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentList":                     schema_pkg_apis_jenkinsio_v1_EnvironmentList(ref),
@@ -80,6 +82,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.IssueSummary":                        schema_pkg_apis_jenkinsio_v1_IssueSummary(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.JobBase":                             schema_pkg_apis_jenkinsio_v1_JobBase(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Lgtm":                                schema_pkg_apis_jenkinsio_v1_Lgtm(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.LogMaskPattern":                      schema_pkg_apis_jenkinsio_v1_LogMaskPattern(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Measurement":                         schema_pkg_apis_jenkinsio_v1_Measurement(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Merger":                              schema_pkg_apis_jenkinsio_v1_Merger(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Original":                            schema_pkg_apis_jenkinsio_v1_Original(ref),
@@ -87,6 +90,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Periodics":                           schema_pkg_apis_jenkinsio_v1_Periodics(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivity":                    schema_pkg_apis_jenkinsio_v1_PipelineActivity(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityList":                schema_pkg_apis_jenkinsio_v1_PipelineActivityList(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityRollback":            schema_pkg_apis_jenkinsio_v1_PipelineActivityRollback(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivitySpec":                schema_pkg_apis_jenkinsio_v1_PipelineActivitySpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStatus":              schema_pkg_apis_jenkinsio_v1_PipelineActivityStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep":                schema_pkg_apis_jenkinsio_v1_PipelineActivityStep(ref),
@@ -103,10 +107,16 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Presubmits":                          schema_pkg_apis_jenkinsio_v1_Presubmits(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewActivityStep":                 schema_pkg_apis_jenkinsio_v1_PreviewActivityStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewGitSpec":                      schema_pkg_apis_jenkinsio_v1_PreviewGitSpec(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewLifecycle":                    schema_pkg_apis_jenkinsio_v1_PreviewLifecycle(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteActivityStep":                 schema_pkg_apis_jenkinsio_v1_PromoteActivityStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep":              schema_pkg_apis_jenkinsio_v1_PromotePullRequestStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep":                   schema_pkg_apis_jenkinsio_v1_PromoteUpdateStep(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteWorkflowStep":                 schema_pkg_apis_jenkinsio_v1_PromoteWorkflowStep(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreeze":                     schema_pkg_apis_jenkinsio_v1_PromotionFreeze(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreezeOverride":             schema_pkg_apis_jenkinsio_v1_PromotionFreezeOverride(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionGateResult":                 schema_pkg_apis_jenkinsio_v1_PromotionGateResult(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionMetricGate":                 schema_pkg_apis_jenkinsio_v1_PromotionMetricGate(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionPolicy":                     schema_pkg_apis_jenkinsio_v1_PromotionPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProtectionPolicies":                  schema_pkg_apis_jenkinsio_v1_ProtectionPolicies(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ProtectionPolicy":                    schema_pkg_apis_jenkinsio_v1_ProtectionPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PullRequestInfo":                     schema_pkg_apis_jenkinsio_v1_PullRequestInfo(ref),
@@ -122,9 +132,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ReplaceableSliceOfExternalPlugins":   schema_pkg_apis_jenkinsio_v1_ReplaceableSliceOfExternalPlugins(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ReplaceableSliceOfStrings":           schema_pkg_apis_jenkinsio_v1_ReplaceableSliceOfStrings(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.RepoContextPolicy":                   schema_pkg_apis_jenkinsio_v1_RepoContextPolicy(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.RequiredChecksPolicy":                schema_pkg_apis_jenkinsio_v1_RequiredChecksPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference":                   schema_pkg_apis_jenkinsio_v1_ResourceReference(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Restrictions":                        schema_pkg_apis_jenkinsio_v1_Restrictions(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ReviewPolicy":                        schema_pkg_apis_jenkinsio_v1_ReviewPolicy(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.SBOMReference":                       schema_pkg_apis_jenkinsio_v1_SBOMReference(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Scheduler":                           schema_pkg_apis_jenkinsio_v1_Scheduler(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.SchedulerAgent":                      schema_pkg_apis_jenkinsio_v1_SchedulerAgent(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.SchedulerList":                       schema_pkg_apis_jenkinsio_v1_SchedulerList(ref),
@@ -144,6 +156,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSpec":                            schema_pkg_apis_jenkinsio_v1_TeamSpec(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamStatus":                          schema_pkg_apis_jenkinsio_v1_TeamStatus(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Trigger":                             schema_pkg_apis_jenkinsio_v1_Trigger(ref),
+		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TrustPolicy":                         schema_pkg_apis_jenkinsio_v1_TrustPolicy(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.User":                                schema_pkg_apis_jenkinsio_v1_User(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.UserDetails":                         schema_pkg_apis_jenkinsio_v1_UserDetails(ref),
 		"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.UserList":                            schema_pkg_apis_jenkinsio_v1_UserList(ref),
@@ -343,6 +356,69 @@ func schema_pkg_apis_jenkinsio_v1_Approve(ref common.ReferenceCallback) common.O
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_ArtifactSignature(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ArtifactSignature is a signature of an image or chart of the release using the signing payload of cosign",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind the kind of the artifact which is either image or chart",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name the image repository or chart name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version the image tag or chart version",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digest the digest of the image manifest or chart archive such as sha256:abc123",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"payload": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Payload the base64 encoded payload which was signed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"signature": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Signature the base64 encoded signature of the payload",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"keyID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KeyID the fingerprint of the public key which verifies the signature",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Attachment(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -728,6 +804,12 @@ func schema_pkg_apis_jenkinsio_v1_CommitStatusCommitReference(ref common.Referen
 							Format: "",
 						},
 					},
+					"baseRef": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
 				},
 			},
 		},
@@ -945,11 +1027,16 @@ func schema_pkg_apis_jenkinsio_v1_CommitSummary(ref common.ReferenceCallback) co
 							},
 						},
 					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.UserDetails"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.UserDetails", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -1316,6 +1403,62 @@ func schema_pkg_apis_jenkinsio_v1_DependencyUpdateDetails(ref common.ReferenceCa
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_DependencyUpdatePolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DependencyUpdatePolicy how the dependencies of a repository which are older than the version stream are updated",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"disabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled disables dependency updates for the repository",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"grouping": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Grouping how updates are batched into pull requests: 'all' for a single pull request, 'kind' for one per kind of dependency (go, maven, npm or helm) or 'none' for one per dependency. Defaults to 'all'",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule the cron expression of when pull requests are opened such as '0 6 * * MON'. Defaults to every run",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeZone the location the schedule is evaluated in such as Europe/London. Defaults to UTC",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ignore": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ignore the dependencies which are not updated as a name such as 'github.com/pkg/errors' or a name and version such as 'lodash@5*'. Names and versions may end with a * wildcard",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Environment(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1723,11 +1866,42 @@ func schema_pkg_apis_jenkinsio_v1_EnvironmentSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"promotionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PromotionPolicy the gates which must pass before a version is automatically promoted to this Environment",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionPolicy"),
+						},
+					},
+					"freezes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Freezes the periods during which releases are not promoted to this Environment",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreeze"),
+									},
+								},
+							},
+						},
+					},
+					"previewLifecycle": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PreviewLifecycle the time to live and hibernation state of a Preview Environment",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewLifecycle"),
+						},
+					},
+					"trustPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TrustPolicy the keys which must have signed the charts and images promoted to this Environment",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TrustPolicy"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentRepository", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewGitSpec", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSettings"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.EnvironmentRepository", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewGitSpec", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PreviewLifecycle", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreeze", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionPolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TeamSettings", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.TrustPolicy"},
 	}
 }
 
@@ -3045,6 +3219,35 @@ func schema_pkg_apis_jenkinsio_v1_Lgtm(ref common.ReferenceCallback) common.Open
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_LogMaskPattern(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LogMaskPattern a regular expression matching secrets to mask in the build logs",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name the name of the kind of secret matched such as my-api-key",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pattern": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pattern the regular expression matching the secret. If it contains a capture group only the text matched by the first group is masked",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "pattern"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Measurement(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3361,6 +3564,56 @@ func schema_pkg_apis_jenkinsio_v1_PipelineActivityList(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_PipelineActivityRollback(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PipelineActivityRollback records the rollback of the version released by a pipeline in an environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"environment": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"toVersion": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"pullRequestURL": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"rolledBackBy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_PipelineActivitySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3545,11 +3798,37 @@ func schema_pkg_apis_jenkinsio_v1_PipelineActivitySpec(ref common.ReferenceCallb
 							Format: "",
 						},
 					},
+					"freezeOverrides": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FreezeOverrides the releases to environments which this pipeline made during a freeze",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreezeOverride"),
+									},
+								},
+							},
+						},
+					},
+					"rollbacks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rollbacks the rollbacks of the version released by this pipeline in environments",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityRollback"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ExtensionExecution", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.Attachment", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.BatchPipelineActivity", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ExtensionExecution", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityRollback", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PipelineActivityStep", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionFreezeOverride", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -3843,6 +4122,13 @@ func schema_pkg_apis_jenkinsio_v1_PipelineStructureStage(ref common.ReferenceCal
 							Format: "",
 						},
 					},
+					"skipped": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Skipped is true if the stage will not be run because its when conditions were not met",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"name", "depth"},
 			},
@@ -4342,68 +4628,159 @@ func schema_pkg_apis_jenkinsio_v1_PreviewGitSpec(ref common.ReferenceCallback) c
 	}
 }
 
-func schema_pkg_apis_jenkinsio_v1_PromoteActivityStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_jenkinsio_v1_PreviewLifecycle(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PromoteActivityStep is the step of promoting a version of an application to an environment",
+				Description: "PreviewLifecycle the time to live and idle hibernation of a Preview Environment along with whether it is hibernated",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"ttl": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "TTL how long the Preview Environment lives after it was last deployed before it is deleted such as 72h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"description": {
+					"idleTimeout": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "IdleTimeout how long the Preview Environment can go without traffic before it is hibernated such as 8h",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"status": {
+					"lastDeployedTimestamp": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "LastDeployedTimestamp when the Preview Environment was last deployed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"startedTimestamp": {
+					"lastActiveTimestamp": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							Description: "LastActiveTimestamp when the Preview Environment was last deployed, woken up or seen to receive traffic",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"completedTimestamp": {
+					"hibernated": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							Description: "Hibernated whether the Deployments of the Preview Environment are scaled to zero",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"environment": {
+					"hibernatedTimestamp": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"pullRequest": {
+					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep"),
+							Description: "Replicas the replicas of each Deployment before hibernation which are restored when woken up",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
 						},
 					},
-					"update": {
+					"wokenBy": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep"),
+							Description: "WokenBy what last woke up the Preview Environment such as a pull request comment or an ingress request",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"applicationURL": {
+					"wokenTimestamp": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromoteActivityStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromoteActivityStep is the step of promoting a version of an application to an environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"startedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"environment": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"pullRequest": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep"),
+						},
+					},
+					"update": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep"),
+						},
+					},
+					"applicationURL": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"gates": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionGateResult"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotePullRequestStep", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromoteUpdateStep", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionGateResult", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -4537,6 +4914,257 @@ func schema_pkg_apis_jenkinsio_v1_PromoteWorkflowStep(ref common.ReferenceCallba
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_PromotionFreeze(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionFreeze a period during which releases are not promoted to an Environment. A freeze either recurs using a cron schedule and duration or is ad-hoc with an end time",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule the cron expression for the start of each recurring freeze such as '0 17 * * FRI'",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration how long each recurring freeze lasts such as 64h",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeZone the location the schedule is evaluated in such as Europe/London. Defaults to UTC",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start the start of an ad-hoc freeze. Defaults to when the freeze was created",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End the end of an ad-hoc freeze",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"createdBy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromotionFreezeOverride(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionFreezeOverride records a release to an environment during one of its freezes",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"environment": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"freeze": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromotionGateResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionGateResult is the result of the last evaluation of a gate of the PromotionPolicy of the environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"evaluatedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromotionMetricGate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionMetricGate a Prometheus query whose value must compare to a threshold for the gate to pass. The query may refer to $APP, $VERSION and $NAMESPACE which are replaced with the app, its version and the namespace of the previous Environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"query": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"comparison": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Comparison is one of <, <=, >, >= or == and defaults to <=",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"threshold": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+				},
+				Required: []string{"query", "threshold"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_jenkinsio_v1_PromotionPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PromotionPolicy the gates which must pass in the previous Environment before a version is automatically promoted",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"soakTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SoakTime the minimum duration the version must have been running in the previous Environment such as 30m",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tests the names of the pipelines which must have succeeded after the version was promoted to the previous Environment, or after the version was released for the first Environment",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"prometheusURL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PrometheusURL the URL of the Prometheus server used to evaluate the metric gates",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metrics": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Metrics the Prometheus queries whose values must be within their thresholds",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionMetricGate"),
+									},
+								},
+							},
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeout how long to wait for the gates to pass before failing the promotion such as 1h. Defaults to 30m after the soak time",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.PromotionMetricGate"},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_ProtectionPolicies(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4963,11 +5591,35 @@ func schema_pkg_apis_jenkinsio_v1_ReleaseSpec(ref common.ReferenceCallback) comm
 							Format: "",
 						},
 					},
+					"sboms": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.SBOMReference"),
+									},
+								},
+							},
+						},
+					},
+					"signatures": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ArtifactSignature"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CommitSummary", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdate", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.IssueSummary"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ArtifactSignature", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.CommitSummary", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdate", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.IssueSummary", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.SBOMReference"},
 	}
 }
 
@@ -5154,6 +5806,49 @@ func schema_pkg_apis_jenkinsio_v1_RepoContextPolicy(ref common.ReferenceCallback
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_RequiredChecksPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RequiredChecksPolicy the commit status contexts which must pass for commits targeting the matching branches",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"branches": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Branches a regular expression matching the whole name of the target branches such as master|release-.*",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"contexts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Contexts the names of the commit status contexts which must pass. Contexts reported to the git provider by something other than Jenkins X are polled while the aggregate is pending, so they may take a minute to be reflected in it",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"context": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Context the name of the aggregate commit status context. Defaults to jx/required",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"branches"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_ResourceReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -5264,6 +5959,39 @@ func schema_pkg_apis_jenkinsio_v1_ReviewPolicy(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_SBOMReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SBOMReference is a software bill of materials of the release which has been stored or attached to the git release",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"format": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Format the format of the document such as cyclonedx or spdx",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_Scheduler(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -5771,11 +6499,17 @@ func schema_pkg_apis_jenkinsio_v1_SourceRepositorySpec(ref common.ReferenceCallb
 							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference"),
 						},
 					},
+					"dependencyUpdates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DependencyUpdates the policy for the pull requests which update the outdated dependencies of this repository",
+							Ref:         ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdatePolicy"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.DependencyUpdatePolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference"},
 	}
 }
 
@@ -6247,11 +6981,37 @@ func schema_pkg_apis_jenkinsio_v1_TeamSettings(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"requiredChecks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequiredChecks the policies declaring which commit status contexts must pass on branches matching a pattern. They are aggregated into a single commit status context so they can be enforced on any git provider",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.RequiredChecksPolicy"),
+									},
+								},
+							},
+						},
+					},
+					"logMaskPatterns": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogMaskPatterns the regular expressions matching secrets which are masked in the build logs in addition to the values of the secrets in the team namespace and the built in patterns for tokens and private keys",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.LogMaskPattern"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StorageLocation", "k8s.io/api/batch/v1.Job"},
+			"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.LogMaskPattern", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.QuickStartLocation", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.RequiredChecksPolicy", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.ResourceReference", "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1.StorageLocation", "k8s.io/api/batch/v1.Job"},
 	}
 }
 
@@ -6362,6 +7122,54 @@ func schema_pkg_apis_jenkinsio_v1_Trigger(ref common.ReferenceCallback) common.O
 	}
 }
 
+func schema_pkg_apis_jenkinsio_v1_TrustPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TrustPolicy the public keys, in the PEM format of 'cosign generate-key-pair', of which one must have signed the chart of a release and its images before the release is promoted to an Environment",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"publicKeys": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"exclude": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Exclude the names of charts, such as third party charts, which do not need to be signed. Supports wildcards",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"allowUnsignedImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AllowUnsignedImages allows releases without signed images such as charts which only use third party images",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_jenkinsio_v1_User(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3,6 +3,7 @@ package buckets
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"strings"
@@ -103,6 +104,41 @@ func ReadBucketURL(u *url.URL, timeout time.Duration) ([]byte, error) {
 	return data, nil
 }
 
+// ReadBucketFiles reads every file in the bucket with a key starting with the path of the bucket URL of the form
+// 's3://bucketName/foo/bar', returning the contents keyed by the rest of the key such as 'whatnot.txt'
+func ReadBucketFiles(u *url.URL, timeout time.Duration) (map[string][]byte, error) {
+	bucketURL, prefix := SplitBucketURL(u)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	ctx, _ := context.WithTimeout(context.Background(), timeout)
+	bucket, err := blob.Open(ctx, bucketURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open bucket %s", bucketURL)
+	}
+	answer := map[string][]byte{}
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the keys starting with %s in bucket %s", prefix, bucketURL)
+		}
+		if obj.IsDir {
+			continue
+		}
+		data, err := bucket.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read key %s in bucket %s", obj.Key, bucketURL)
+		}
+		answer[strings.TrimPrefix(obj.Key, prefix)] = data
+	}
+	return answer, nil
+}
+
 // WriteBucketURL writes the data to a bucket URL of the for 's3://bucketName/foo/bar/whatnot.txt?param=123'
 // with the given timeout
 func WriteBucketURL(u *url.URL, data []byte, timeout time.Duration) error {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/collector"
 	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/io/secrets"
 	"github.com/jenkins-x/jx/pkg/tekton"
 	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
 	"github.com/jenkins-x/jx/pkg/tekton/syntax"
@@ -50,6 +51,7 @@ type ControllerBuildOptions struct {
 	GitReporting        bool
 	TargetURLTemplate   string
 	FailIfNoGitProvider bool
+	MaskSecretNames     []string
 
 	EnvironmentCache *kube.EnvironmentNamespaceCache
 
@@ -74,26 +76,46 @@ func (w *LongTermStorageLogWriter) WriteLog(logLine logs.LogLine, lch chan<- log
 }
 
 // StreamLog will receive a logs channel and an errors channel which the logs producer will send
-// it will mask the lines marked as ShouldMask then it will append the line's bytes to the already stored ones
+// it will mask the lines marked as ShouldMask then it will append the line's bytes to the already stored ones.
+// Private key blocks are held back until the end of the block so that the whole key is masked
 func (w *LongTermStorageLogWriter) StreamLog(lch <-chan logs.LogLine, ech <-chan error) error {
+	var lineMasker *kube.LogLineMasker
+	if w.logMasker != nil {
+		lineMasker = kube.NewLogLineMasker(w.logMasker)
+	}
+	flush := func() {
+		if lineMasker != nil {
+			w.appendLines(lineMasker.Flush())
+		}
+	}
 	for {
 		select {
 		case l, ok := <-lch:
 			if !ok {
+				flush()
 				return nil
 			}
-			if w.logMasker != nil && l.ShouldMask {
-				l.Line = w.logMasker.MaskLog(l.Line)
+			if lineMasker != nil && l.ShouldMask {
+				w.appendLines(lineMasker.MaskLine(l.Line))
+				continue
 			}
-			line := []byte(l.Line)
-			line = append(line, '\n')
-			w.data = append(w.data, line...)
+			flush()
+			w.appendLines([]string{l.Line})
 		case err := <-ech:
+			flush()
 			return err
 		}
 	}
 }
 
+func (w *LongTermStorageLogWriter) appendLines(lines []string) {
+	for _, l := range lines {
+		line := []byte(l)
+		line = append(line, '\n')
+		w.data = append(w.data, line...)
+	}
+}
+
 // BytesLimit defines the limit of bytes to be used to fetch the logs from the kube API
 // defaulted to 0 for this implementation
 func (w *LongTermStorageLogWriter) BytesLimit() int {
@@ -126,6 +148,8 @@ func NewCmdControllerBuild(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.InitGitCredentials, "git-credentials", "", false, "If enable then lets run the 'jx step git credentials' step to initialise git credentials")
 	cmd.Flags().BoolVarP(&options.FailIfNoGitProvider, "fail-on-git-provider-error", "", false, "If enable then lets terminate quickly if we cannot create a git provider")
 
+	cmd.Flags().StringArrayVarP(&options.MaskSecretNames, "mask-secret", "", nil, "The names of secrets in vault or the local secrets directory whose values are masked in the stored build logs")
+
 	// optional git reporting flags
 	cmd.Flags().StringVarP(&options.TargetURLTemplate, "target-url-template", "", "", "The Go template for generating the target URL of pipeline logs/views if git reporting is enabled")
 	cmd.Flags().BoolVarP(&options.GitReporting, "git-reporting", "", false, "If enabled then lets report pipeline success/failures to the git provider. Note this is purely tactical until we can do this natively inside tekton")
@@ -636,10 +660,7 @@ func (o *ControllerBuildOptions) updatePipelineActivity(kubeClient kubernetes.In
 					log.Logger().Warnf("No GitURL on PipelineActivity %s", activity.Name)
 				}
 			}
-			masker, err := kube.NewLogMasker(kubeClient, ns)
-			if err != nil {
-				log.Logger().Warnf("Failed to create LogMasker in namespace %s: %s", ns, err.Error())
-			}
+			masker := o.createLogMasker(kubeClient, ns, settings)
 			logURL, err := o.generateBuildLogURL(podInterface, ns, activity, buildName, pod, location, settings, o.InitGitCredentials, masker)
 			if err != nil {
 				log.Logger().Warnf("%s", err)
@@ -683,7 +704,7 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 	for i := range spec.Steps {
 		step := &spec.Steps[i]
 		stage := step.Stage
-		if stage != nil {
+		if stage != nil && stage.Status != v1.ActivityStatusTypeSkipped {
 			stageFinished := spec.Status.IsTerminated()
 			if stage.StartedTimestamp != nil && spec.StartedTimestamp == nil {
 				spec.StartedTimestamp = stage.StartedTimestamp
@@ -769,10 +790,7 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 					}
 				}
 
-				masker, err := kube.NewLogMasker(kubeClient, ns)
-				if err != nil {
-					log.Logger().Warnf("Failed to create LogMasker in namespace %s: %s", ns, err.Error())
-				}
+				masker := o.createLogMasker(kubeClient, ns, settings)
 
 				logURL, err := o.generateBuildLogURL(podInterface, ns, activity, pri.PipelineRun, pod, location, settings, o.InitGitCredentials, masker)
 				if err != nil {
@@ -808,6 +826,10 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 
 func updateForStage(si *tekton.StageInfo, a *v1.PipelineActivity) {
 	_, stage, _ := kube.GetOrCreateStage(a, si.GetStageNameIncludingParents())
+	if si.Skipped {
+		stage.Status = v1.ActivityStatusTypeSkipped
+		return
+	}
 	containersTerminated := false
 
 	if si.Pod != nil {
@@ -1050,6 +1072,34 @@ func toYamlString(resource interface{}) string {
 	return string(data)
 }
 
+// createLogMasker creates the masker for the build logs from the secrets in the namespace, the log mask patterns in
+// the team settings and any secrets named with --mask-secret in the secret URL backend
+func (o *ControllerBuildOptions) createLogMasker(kubeClient kubernetes.Interface, ns string, settings *v1.TeamSettings) *kube.LogMasker {
+	masker, err := kube.NewLogMasker(kubeClient, ns)
+	if err != nil {
+		log.Logger().Warnf("Failed to create LogMasker in namespace %s: %s", ns, err.Error())
+	}
+	if settings != nil {
+		err = masker.LoadLogMaskPatterns(settings.LogMaskPatterns)
+		if err != nil {
+			log.Logger().Warnf("Failed to load the log mask patterns of the team settings: %s", err.Error())
+		}
+	}
+	if len(o.MaskSecretNames) == 0 {
+		return masker
+	}
+	secretURLClient, err := o.GetSecretURLClient(secrets.AutoLocationKind)
+	if err != nil {
+		log.Logger().Warnf("Failed to create the secret URL client for the LogMasker: %s", err.Error())
+		return masker
+	}
+	err = masker.LoadSecretURLs(secretURLClient, o.MaskSecretNames...)
+	if err != nil {
+		log.Logger().Warnf("Failed to load the secrets to mask: %s", err.Error())
+	}
+	return masker
+}
+
 // generates the build log URL and returns the URL
 func (o *ControllerBuildOptions) generateBuildLogURL(podInterface typedcorev1.PodInterface, ns string, activity *v1.PipelineActivity, buildName string, pod *corev1.Pod, location v1.StorageLocation, settings *v1.TeamSettings, initGitCredentials bool, logMasker *kube.LogMasker) (string, error) {
 
@@ -1124,9 +1174,21 @@ func (o *ControllerBuildOptions) generateBuildLogURL(podInterface typedcorev1.Po
 		return answer, err
 	}
 	log.Logger().Infof("stored logs for activity %s into storage at %s", activity.Name, fileName)
+
+	err = o.archiveBuildLog(activity, location, coll, answer)
+	if err != nil {
+		// the log is stored so failing to index it should not fail the build
+		log.Logger().Warnf("failed to add the logs for activity %s to the log archive index: %s", activity.Name, err.Error())
+	}
 	return answer, nil
 }
 
+// archiveBuildLog adds the stored log of the activity to the index of the log archive so it can be searched
+func (o *ControllerBuildOptions) archiveBuildLog(activity *v1.PipelineActivity, location v1.StorageLocation, coll collector.Collector, logURL string) error {
+	archive := logs.NewLogArchive(location, coll, o.Git(), nil)
+	return archive.Archive(logs.NewArchiveEntry(activity, logURL))
+}
+
 // ensurePipelineActivityHasLabels older versions of controller build did not add labels properly
 // so lets enrich PipelineActivity on startup
 func (o *ControllerBuildOptions) ensurePipelineActivityHasLabels(jxClient versioned.Interface, ns string) error {
@@ -5,9 +5,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/commitstatus"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -42,9 +44,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// requiredChecksPendingTimeout how long the required checks of a commit are polled for while they are pending
+const requiredChecksPendingTimeout = time.Hour * 24
+
 // ControllerCommitStatusOptions the options for the controller
 type ControllerCommitStatusOptions struct {
 	ControllerOptions
+
+	RequiredChecksPollTime time.Duration
+
+	// pendingRequired the commits whose required checks are pending keyed by git URL and SHA
+	pendingRequired     map[string]*pendingRequiredChecks
+	pendingRequiredLock sync.Mutex
+}
+
+// pendingRequiredChecks a commit whose required checks are pending, which may be waiting for a status reported to the
+// git provider by something other than this controller
+type pendingRequiredChecks struct {
+	details jenkinsv1.CommitStatusDetails
+	since   time.Time
 }
 
 // NewCmdControllerCommitStatus creates a command object for the "create" command
@@ -65,6 +83,7 @@ func NewCmdControllerCommitStatus(commonOpts *opts.CommonOptions) *cobra.Command
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().DurationVarP(&options.RequiredChecksPollTime, "required-checks-poll-time", "", time.Minute, "How often the pending required checks of a commit are recomputed from the statuses on the git provider, as statuses reported by something other than this controller do not trigger an update")
 	return cmd
 }
 
@@ -114,6 +133,9 @@ func (o *ControllerCommitStatusOptions) Run() error {
 	)
 	stop := make(chan struct{})
 	go commitstatusController.Run(stop)
+	if o.RequiredChecksPollTime > 0 {
+		go o.pollPendingRequiredChecks(jxClient, ns)
+	}
 
 	podListWatch := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "pods", ns, fields.Everything())
 	kube.SortListWatchByName(podListWatch)
@@ -189,6 +211,12 @@ func (o *ControllerCommitStatusOptions) onCommitStatus(check *jenkinsv1.CommitSt
 			}
 			return err
 		}
+		// failing to update the required checks should not stop the controller, for example when the git provider
+		// does not support commit statuses
+		err = o.updateRequiredChecks(&last, jxClient, ns)
+		if err != nil {
+			log.Logger().Warnf("commit status controller: Unable to update the required checks of %s on %s: %s", last.Commit.SHA, last.Commit.GitURL, err)
+		}
 	}
 	return nil
 }
@@ -257,6 +285,7 @@ func (o *ControllerCommitStatusOptions) onPod(pod *corev1.Pod, jxClient jenkinsv
 				}
 
 				sha := pullBaseSha
+				baseRef := branch
 				if pullRequest == "PR-" {
 					pullRequest = ""
 				} else {
@@ -314,7 +343,7 @@ func (o *ControllerCommitStatusOptions) onPod(pod *corev1.Pod, jxClient jenkinsv
 							if pullRequest != "" {
 								name := naming.ToValidName(fmt.Sprintf("%s-%s-%s-%s", org, repo, branch, ctx))
 
-								err = o.UpsertCommitStatusCheck(name, pipelineActName, sourceUrl, sha, pullRequest, ctx, pod.Status.Phase, jxClient, ns)
+								err = o.UpsertCommitStatusCheck(name, pipelineActName, sourceUrl, sha, pullRequest, baseRef, ctx, pod.Status.Phase, jxClient, ns)
 								if err != nil {
 									return err
 								}
@@ -330,7 +359,7 @@ func (o *ControllerCommitStatusOptions) onPod(pod *corev1.Pod, jxClient jenkinsv
 	return nil
 }
 
-func (o *ControllerCommitStatusOptions) UpsertCommitStatusCheck(name string, pipelineActName string, url string, sha string, pullRequest string, context string, phase corev1.PodPhase, jxClient jenkinsv1client.Interface, ns string) error {
+func (o *ControllerCommitStatusOptions) UpsertCommitStatusCheck(name string, pipelineActName string, url string, sha string, pullRequest string, baseRef string, context string, phase corev1.PodPhase, jxClient jenkinsv1client.Interface, ns string) error {
 	if name != "" {
 
 		status, err := jxClient.JenkinsV1().CommitStatuses(ns).Get(name, metav1.GetOptions{})
@@ -376,6 +405,7 @@ func (o *ControllerCommitStatusOptions) UpsertCommitStatusCheck(name string, pip
 					GitURL:      url,
 					PullRequest: pullRequest,
 					SHA:         sha,
+					BaseRef:     baseRef,
 				},
 				PipelineActivity: actRef,
 				Context:          context,
@@ -462,6 +492,99 @@ func (o *ControllerCommitStatusOptions) update(statusDetails *jenkinsv1.CommitSt
 	return nil
 }
 
+// updateRequiredChecks publishes the aggregate status of the required checks of the branch the commit targets
+func (o *ControllerCommitStatusOptions) updateRequiredChecks(statusDetails *jenkinsv1.CommitStatusDetails, jxClient jenkinsv1client.Interface, ns string) error {
+	commit := statusDetails.Commit
+	if commit.BaseRef == "" || commit.SHA == "" {
+		return nil
+	}
+	teamSettings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	policy, err := commitstatus.MatchRequiredChecks(teamSettings.RequiredChecks, commit.BaseRef)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+	statuses, err := jxClient.JenkinsV1().CommitStatuses(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the commit statuses in namespace %s", ns)
+	}
+	details := make([]jenkinsv1.CommitStatusDetails, 0)
+	for _, status := range statuses.Items {
+		for _, d := range status.Spec.Items {
+			if d.Commit.SHA == commit.SHA && d.Commit.GitURL == commit.GitURL {
+				details = append(details, d)
+			}
+		}
+	}
+	gitProvider, gitRepoInfo, err := o.getGitProvider(commit.GitURL)
+	if err != nil {
+		return err
+	}
+	// required contexts may be reported by something other than this controller
+	providerStatuses, err := gitProvider.ListCommitStatus(gitRepoInfo.Organisation, gitRepoInfo.Name, commit.SHA)
+	if err != nil {
+		log.Logger().Warnf("commit status controller: Unable to list the commit statuses of %s on %s: %s", commit.SHA, commit.GitURL, err)
+	}
+	required := commitstatus.Aggregate(policy, details, providerStatuses)
+	log.Logger().Debugf("commit status controller: Required checks of %s on %s are %s: %s", commit.SHA, commit.BaseRef, required.State, required.Description)
+	o.trackPendingRequiredChecks(statusDetails, required.State == commitstatus.StatePending)
+	_, err = extensions.NotifyCommitStatus(commit, required.State, "", required.Description, "", required.Context, gitProvider, gitRepoInfo)
+	return err
+}
+
+// trackPendingRequiredChecks records whether the required checks of the commit are pending so that they are polled
+func (o *ControllerCommitStatusOptions) trackPendingRequiredChecks(statusDetails *jenkinsv1.CommitStatusDetails, pending bool) {
+	key := statusDetails.Commit.GitURL + "@" + statusDetails.Commit.SHA
+	o.pendingRequiredLock.Lock()
+	defer o.pendingRequiredLock.Unlock()
+	if !pending {
+		delete(o.pendingRequired, key)
+		return
+	}
+	if o.pendingRequired == nil {
+		o.pendingRequired = map[string]*pendingRequiredChecks{}
+	}
+	if p, ok := o.pendingRequired[key]; ok {
+		p.details = *statusDetails
+		return
+	}
+	o.pendingRequired[key] = &pendingRequiredChecks{
+		details: *statusDetails,
+		since:   time.Now(),
+	}
+}
+
+// pollPendingRequiredChecks recomputes the pending required checks from the statuses on the git provider, as a check
+// reported by something other than this controller does not change a CommitStatus
+func (o *ControllerCommitStatusOptions) pollPendingRequiredChecks(jxClient jenkinsv1client.Interface, ns string) {
+	for {
+		time.Sleep(o.RequiredChecksPollTime)
+
+		var pending []jenkinsv1.CommitStatusDetails
+		o.pendingRequiredLock.Lock()
+		for key, p := range o.pendingRequired {
+			if time.Since(p.since) > requiredChecksPendingTimeout {
+				delete(o.pendingRequired, key)
+				continue
+			}
+			pending = append(pending, p.details)
+		}
+		o.pendingRequiredLock.Unlock()
+
+		for i := range pending {
+			err := o.updateRequiredChecks(&pending[i], jxClient, ns)
+			if err != nil {
+				log.Logger().Warnf("commit status controller: Unable to update the required checks of %s on %s: %s", pending[i].Commit.SHA, pending[i].Commit.GitURL, err)
+			}
+		}
+	}
+}
+
 func (o *ControllerCommitStatusOptions) getGitProvider(url string) (gits.GitProvider, *gits.GitRepository, error) {
 	// TODO This is an epic hack to get the git stuff working
 	gitInfo, err := gits.ParseGitURL(url)
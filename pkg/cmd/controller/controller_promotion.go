@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerPromotionOptions the options for the promotion controller
+type ControllerPromotionOptions struct {
+	*opts.CommonOptions
+
+	PollTime time.Duration
+}
+
+var (
+	controllerPromotionLong = templates.LongDesc(`
+		Runs the controller which resumes the promotions queued until the promotion policy gates of an Environment pass.
+
+		When 'jx promote --all-auto' finds that the gates of an Environment have not passed yet it records them as pending
+		in the Promote step of the PipelineActivity and lets the pipeline complete. This controller evaluates the pending gates
+		again every poll and resumes the promotion once they pass, or fails the Promote step once the policy times out.
+		Only the latest queued build of a pipeline is resumed and the Promote steps of its older queued builds are aborted.
+`)
+
+	controllerPromotionExample = templates.Examples(`
+		jx controller promotion
+`)
+)
+
+// NewCmdControllerPromotion creates the command
+func NewCmdControllerPromotion(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := ControllerPromotionOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "promotion",
+		Short:   "Runs the controller which resumes promotions once their promotion policy gates pass",
+		Long:    controllerPromotionLong,
+		Example: controllerPromotionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().DurationVarP(&options.PollTime, "poll-time", "", time.Second*30, "How often the pending promotion policy gates are evaluated")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerPromotionOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Watching for queued promotions in namespace %s", util.ColorInfo(ns))
+	for {
+		err = o.resumeQueuedPromotions(jxClient, ns)
+		if err != nil {
+			log.Logger().Warnf("Failed to resume the queued promotions: %s", err)
+		}
+		time.Sleep(o.PollTime)
+	}
+}
+
+// resumeQueuedPromotions resumes the promotion of every PipelineActivity with a Promote step whose gates are pending
+func (o *ControllerPromotionOptions) resumeQueuedPromotions(jxClient versioned.Interface, ns string) error {
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	// only the latest queued build of a pipeline is resumed so that an older version is never promoted over a newer one
+	latest := map[string]*v1.PipelineActivity{}
+	var queued []*v1.PipelineActivity
+	for i := range list.Items {
+		activity := &list.Items[i]
+		if queuedPromotionEnvironment(activity) == "" {
+			continue
+		}
+		queued = append(queued, activity)
+		current := latest[activity.Spec.Pipeline]
+		if current == nil || activityBuildNumber(activity) > activityBuildNumber(current) {
+			latest[activity.Spec.Pipeline] = activity
+		}
+	}
+	for _, activity := range queued {
+		newer := latest[activity.Spec.Pipeline]
+		if newer == activity {
+			continue
+		}
+		// the older queued promotions will never be resumed so they are aborted rather than left pending forever
+		abortSupersededPromotion(activity, newer, time.Now())
+		log.Logger().Infof("Aborting the queued promotion of PipelineActivity %s as it is superseded by build %s", util.ColorInfo(activity.Name), util.ColorInfo(newer.Spec.Build))
+		_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(activity)
+		if err != nil {
+			log.Logger().Warnf("Failed to abort the queued promotion of PipelineActivity %s: %s", activity.Name, err)
+		}
+	}
+	for _, activity := range latest {
+		envName := queuedPromotionEnvironment(activity)
+		log.Logger().Infof("Evaluating the promotion gates of environment %s for PipelineActivity %s", util.ColorInfo(envName), util.ColorInfo(activity.Name))
+		po := &promote.PromoteOptions{
+			Application:       activity.RepositoryName(),
+			Pipeline:          activity.Spec.Pipeline,
+			Build:             activity.Spec.Build,
+			Version:           activity.Spec.Version,
+			AllAutomatic:      true,
+			ResumeEnvironment: envName,
+			NoPoll:            true,
+			IgnoreLocalFiles:  true,
+			HelmRepositoryURL: o.DefaultChartRepositoryURL(),
+			LocalHelmRepoName: kube.LocalHelmRepoName,
+			Namespace:         ns,
+		}
+		po.CommonOptions = o.CommonOptions
+		po.BatchMode = true
+		err = po.Run()
+		if err != nil {
+			log.Logger().Warnf("Failed to resume the promotion of PipelineActivity %s to environment %s: %s", activity.Name, envName, err)
+		}
+	}
+	return nil
+}
+
+// abortSupersededPromotion marks the queued Promote step of the activity as aborted because the promotion of a newer
+// build of the same pipeline is queued
+func abortSupersededPromotion(activity *v1.PipelineActivity, newer *v1.PipelineActivity, now time.Time) {
+	for _, step := range activity.Spec.Steps {
+		ps := step.Promote
+		if ps != nil && ps.Status == v1.ActivityStatusTypePending && len(ps.Gates) > 0 {
+			ps.Status = v1.ActivityStatusTypeAborted
+			ps.Description = fmt.Sprintf("superseded by the promotion of build %s version %s", newer.Spec.Build, newer.Spec.Version)
+			ps.CompletedTimestamp = &metav1.Time{
+				Time: now,
+			}
+		}
+	}
+}
+
+func activityBuildNumber(activity *v1.PipelineActivity) int {
+	n, err := strconv.Atoi(activity.Spec.Build)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// queuedPromotionEnvironment returns the environment of the Promote step waiting for its gates to pass or an empty
+// string if the promotion of the activity is not queued
+func queuedPromotionEnvironment(activity *v1.PipelineActivity) string {
+	if activity.Spec.Version == "" || activity.Spec.Build == "" {
+		return ""
+	}
+	for _, step := range activity.Spec.Steps {
+		ps := step.Promote
+		if ps != nil && ps.Status == v1.ActivityStatusTypePending && len(ps.Gates) > 0 {
+			return ps.Environment
+		}
+	}
+	return ""
+}
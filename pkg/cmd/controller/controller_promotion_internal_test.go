@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func queuedActivity(build string, version string) *v1.PipelineActivity {
+	return &v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{
+			Pipeline: "myorg/myapp/master",
+			Build:    build,
+			Version:  version,
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypePromote,
+					Promote: &v1.PromoteActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Status: v1.ActivityStatusTypePending,
+						},
+						Environment: "production",
+						Gates:       []v1.PromotionGateResult{{Kind: v1.PromotionGateKindSoak}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAbortSupersededPromotion(t *testing.T) {
+	older := queuedActivity("2", "0.0.2")
+	newer := queuedActivity("10", "0.0.10")
+	assert.Equal(t, "production", queuedPromotionEnvironment(older))
+	assert.True(t, activityBuildNumber(newer) > activityBuildNumber(older))
+
+	now := time.Now()
+	abortSupersededPromotion(older, newer, now)
+
+	ps := older.Spec.Steps[0].Promote
+	assert.Equal(t, v1.ActivityStatusTypeAborted, ps.Status)
+	assert.Equal(t, "superseded by the promotion of build 10 version 0.0.10", ps.Description)
+	assert.Equal(t, now, ps.CompletedTimestamp.Time)
+	// an aborted promotion is no longer queued so it is not evaluated again
+	assert.Equal(t, "", queuedPromotionEnvironment(older))
+	assert.Equal(t, "production", queuedPromotionEnvironment(newer))
+}
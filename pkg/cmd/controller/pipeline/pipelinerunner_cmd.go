@@ -5,8 +5,10 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
 	"github.com/jenkins-x/jx/pkg/tekton/metapipeline"
+	"github.com/jenkins-x/jx/pkg/webhooks"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
@@ -32,6 +34,7 @@ type PipelineRunnerOptions struct {
 	UseMetaPipeline      bool
 	MetaPipelineImage    string
 	SemanticRelease      bool
+	MaxDeliveries        int
 }
 
 var (
@@ -67,6 +70,7 @@ func NewCmdControllerPipelineRunner(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().StringVar(&options.ServiceAccount, "service-account", "tekton-bot", "The Kubernetes ServiceAccount to use to run the pipeline.")
 	cmd.Flags().BoolVar(&options.NoGitCredentialsInit, "no-git-init", false, "Disables checking we have setup git credentials on startup.")
 	cmd.Flags().BoolVar(&options.SemanticRelease, "semantic-release", false, "Enable semantic releases")
+	cmd.Flags().IntVar(&options.MaxDeliveries, "max-deliveries", webhooks.DefaultMaxDeliveries, "The number of recent webhook deliveries to keep for 'jx get webhooks' and 'jx step webhook replay'. Use 0 to not record deliveries")
 
 	// TODO - temporary flags until meta pipeline is the default
 	cmd.Flags().BoolVar(&options.UseMetaPipeline, useMetaPipelineOptionName, true, "Uses the meta pipeline to create the pipeline.")
@@ -100,7 +104,7 @@ func (o *PipelineRunnerOptions) Run() error {
 		}
 	}
 
-	jxClient, ns, err := o.getClientsAndNamespace()
+	jxClient, kubeClient, ns, err := o.getClientsAndNamespace()
 	if err != nil {
 		return err
 	}
@@ -122,6 +126,10 @@ func (o *PipelineRunnerOptions) Run() error {
 		ns:                 ns,
 		metaPipelineClient: metapipelineClient,
 	}
+	if o.MaxDeliveries > 0 {
+		controller.deliveries = webhooks.NewDeliveryStore(kubeClient, ns)
+		controller.deliveries.MaxDeliveries = o.MaxDeliveries
+	}
 
 	controller.Start()
 	return nil
@@ -144,16 +152,16 @@ func (o *PipelineRunnerOptions) stepGitCredentials() error {
 	return nil
 }
 
-func (o *PipelineRunnerOptions) getClientsAndNamespace() (jxclient.Interface, string, error) {
+func (o *PipelineRunnerOptions) getClientsAndNamespace() (jxclient.Interface, kubernetes.Interface, string, error) {
 	jxClient, _, err := o.JXClient()
 	if err != nil {
-		return nil, "", errors.Wrap(err, "unable to create JX client")
+		return nil, nil, "", errors.Wrap(err, "unable to create JX client")
 	}
 
-	_, ns, err := o.KubeClientAndDevNamespace()
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
 	if err != nil {
-		return nil, "", errors.Wrap(err, "unable to create Kube client")
+		return nil, nil, "", errors.Wrap(err, "unable to create Kube client")
 	}
 
-	return jxClient, ns, nil
+	return jxClient, kubeClient, ns, nil
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/prow"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/webhooks"
 	"github.com/sirupsen/logrus"
 
 	"github.com/jenkins-x/jx/pkg/cmd/step/create"
@@ -87,6 +88,7 @@ type controller struct {
 	ns                 string
 	jxClient           jxclient.Interface
 	metaPipelineClient metapipeline.Client
+	deliveries         *webhooks.DeliveryStore
 }
 
 func (c *controller) Start() {
@@ -176,14 +178,39 @@ func (c *controller) pipeline(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *controller) handlePostRequest(r *http.Request, w http.ResponseWriter) {
-	requestParams, err := c.parseStartPipelineRequestParameters(r)
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		c.returnStatusBadRequest(err, "could not read the JSON request body: "+err.Error(), w)
+		return
+	}
+	delivery := &webhooks.Delivery{
+		Payload:  string(data),
+		ReplayOf: r.Header.Get(webhooks.ReplayOfHeader),
+	}
+	defer c.recordDelivery(delivery)
+
+	requestParams, err := c.parseStartPipelineRequestParameters(data)
 	if err != nil {
+		delivery.Error = err.Error()
 		c.returnStatusBadRequest(err, "could not read the JSON request body: "+err.Error(), w)
 		return
 	}
+	prowJobSpec := requestParams.ProwJobSpec
+	delivery.Context = prowJobSpec.Context
+	if prowJobSpec.Refs != nil {
+		delivery.Owner = prowJobSpec.Refs.Org
+		delivery.Repository = prowJobSpec.Refs.Repo
+		delivery.Branch = c.getBranch(prowJobSpec)
+		delivery.Revision = prowJobSpec.Refs.BaseSHA
+		if len(prowJobSpec.Refs.Pulls) == 1 {
+			delivery.Revision = prowJobSpec.Refs.Pulls[0].SHA
+		}
+	}
 
-	pipelineRunResponse, err := c.startPipeline(requestParams)
+	pipelineRunResponse, activity, err := c.startPipeline(requestParams)
+	delivery.PipelineActivity = activity
 	if err != nil {
+		delivery.Error = err.Error()
 		c.returnStatusBadRequest(err, "could not start pipeline: "+err.Error(), w)
 		return
 	}
@@ -199,13 +226,20 @@ func (c *controller) handlePostRequest(r *http.Request, w http.ResponseWriter) {
 	}
 }
 
-func (c *controller) parseStartPipelineRequestParameters(r *http.Request) (PipelineRunRequest, error) {
-	request := PipelineRunRequest{}
-	data, err := ioutil.ReadAll(r.Body)
+// recordDelivery stores the webhook delivery so that it can be audited and replayed
+func (c *controller) recordDelivery(delivery *webhooks.Delivery) {
+	if c.deliveries == nil {
+		return
+	}
+	err := c.deliveries.Record(delivery)
 	if err != nil {
-		return request, errors.Wrapf(err, fmt.Sprintf("could not read the JSON request body: %s", err.Error()))
+		logger.Warnf("failed to record webhook delivery: %s", err.Error())
 	}
-	err = json.Unmarshal(data, &request)
+}
+
+func (c *controller) parseStartPipelineRequestParameters(data []byte) (PipelineRunRequest, error) {
+	request := PipelineRunRequest{}
+	err := json.Unmarshal(data, &request)
 	if err != nil {
 		return request, errors.Wrapf(err, fmt.Sprintf("failed to unmarshal the JSON request body: %s", err.Error()))
 	}
@@ -213,15 +247,16 @@ func (c *controller) parseStartPipelineRequestParameters(r *http.Request) (Pipel
 	return request, nil
 }
 
-// startPipeline handles an incoming request to start a pipeline.
-func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunResponse, error) {
+// startPipeline handles an incoming request to start a pipeline returning the name of the PipelineActivity if it is
+// known.
+func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunResponse, string, error) {
 	response := PipelineRunResponse{}
 	var revision string
 	var prNumber string
 
 	prowJobSpec := pipelineRun.ProwJobSpec
 	if prowJobSpec.Refs == nil {
-		return response, errors.New(fmt.Sprintf("no prowJobSpec.refs passed: %s", util.PrettyPrint(pipelineRun)))
+		return response, "", errors.New(fmt.Sprintf("no prowJobSpec.refs passed: %s", util.PrettyPrint(pipelineRun)))
 	}
 
 	// Only if there is one Pull in Refs, it's a PR build so we are going to pass it
@@ -235,7 +270,7 @@ func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunR
 
 	envs, err := downwardapi.EnvForSpec(downwardapi.NewJobSpec(prowJobSpec, "", ""))
 	if err != nil {
-		return response, errors.Wrap(err, "failed to get env vars from prowjob")
+		return response, "", errors.Wrap(err, "failed to get env vars from prowjob")
 	}
 
 	sourceURL := c.getSourceURL(prowJobSpec.Refs.Org, prowJobSpec.Refs.Repo)
@@ -256,10 +291,12 @@ func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunR
 	logger.WithFields(logrus.Fields{"sourceURL": sourceURL, "branch": branch, "revision": revision, "context": prowJobSpec.Context, "meta": c.useMetaPipeline}).Info("triggering pipeline")
 
 	results := PipelineRunResponse{}
+	activity := ""
 	if c.useMetaPipeline {
-		crds, err := c.triggerMetaPipeline(pipelineRun, prNumber, sourceURL, revision, branch, envs)
+		var crds *tekton.CRDWrapper
+		crds, activity, err = c.triggerMetaPipeline(pipelineRun, prNumber, sourceURL, revision, branch, envs)
 		if err != nil {
-			return response, err
+			return response, activity, err
 		}
 
 		results.Resources = crds.ObjectReferences()
@@ -267,12 +304,12 @@ func (c *controller) startPipeline(pipelineRun PipelineRunRequest) (PipelineRunR
 		pipelineCreateOption := c.buildStepCreateTaskOption(prowJobSpec, prNumber, sourceURL, revision, branch, pipelineRun, envs)
 		err = pipelineCreateOption.Run()
 		if err != nil {
-			return response, errors.Wrap(err, "error triggering the pipeline run")
+			return response, "", errors.Wrap(err, "error triggering the pipeline run")
 		}
 		results.Resources = pipelineCreateOption.Results.ObjectReferences()
 	}
 
-	return results, nil
+	return results, activity, nil
 }
 
 func (c *controller) buildStepCreateTaskOption(prowJobSpec prowapi.ProwJobSpec, prNumber string, sourceURL string, revision string, branch string, pipelineRun PipelineRunRequest, envs map[string]string) *create.StepCreateTaskOptions {
@@ -307,13 +344,13 @@ func (c *controller) buildStepCreateTaskOption(prowJobSpec prowapi.ProwJobSpec,
 	return createTaskOption
 }
 
-func (c *controller) triggerMetaPipeline(pipelineRun PipelineRunRequest, prNumber string, sourceURL string, revision string, branch string, envs map[string]string) (*tekton.CRDWrapper, error) {
+func (c *controller) triggerMetaPipeline(pipelineRun PipelineRunRequest, prNumber string, sourceURL string, revision string, branch string, envs map[string]string) (*tekton.CRDWrapper, string, error) {
 	prowJobSpec := pipelineRun.ProwJobSpec
 	pullRefs := c.getPullRefs(prowJobSpec)
 
 	job := pipelineRun.Labels[jobLabel]
 	if job == "" {
-		return nil, errors.Errorf("unable to find prow job name in pipeline request: %s", util.PrettyPrint(pipelineRun))
+		return nil, "", errors.Errorf("unable to find prow job name in pipeline request: %s", util.PrettyPrint(pipelineRun))
 	}
 
 	pullRef := c.prowToMetaPipelinePullRef(sourceURL, &pullRefs)
@@ -331,17 +368,17 @@ func (c *controller) triggerMetaPipeline(pipelineRun PipelineRunRequest, prNumbe
 
 	pipelineActivity, tektonCRDs, err := c.metaPipelineClient.Create(pipelineCreateParam)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create Tekton CRDs")
+		return nil, "", errors.Wrap(err, "unable to create Tekton CRDs")
 	}
 
 	logger.WithField("crds", tektonCRDs.String()).Tracef("generated crds for %s", pipelineActivity.Name)
 
 	err = c.metaPipelineClient.Apply(pipelineActivity, tektonCRDs)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to apply Tekton CRDs")
+		return nil, pipelineActivity.Name, errors.Wrap(err, "unable to apply Tekton CRDs")
 	}
 
-	return &tektonCRDs, nil
+	return &tektonCRDs, pipelineActivity.Name, nil
 }
 
 func (c *controller) marshalPayload(payload interface{}) ([]byte, error) {
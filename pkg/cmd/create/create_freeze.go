@@ -0,0 +1,153 @@
+package create
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	createFreezeLong = templates.LongDesc(`
+		Creates a freeze of an Environment during which releases are not promoted to it.
+
+		A freeze is either ad-hoc until a given time or recurs using a cron schedule and a duration.
+		During a freeze 'jx promote' and 'jx step env apply' fail unless the freeze is overridden with a reason, which is recorded on the PipelineActivity.
+`)
+
+	createFreezeExample = templates.Examples(`
+		# Freeze production for the next 2 days
+		jx create freeze --env production --until 48h --reason "black friday"
+
+		# Freeze production until a given time
+		jx create freeze --env production --until "2019-12-27 09:00" --reason "christmas"
+
+		# Freeze production every Friday evening until Monday morning
+		jx create freeze --env production --name weekends --schedule "0 17 * * FRI" --duration 64h --time-zone Europe/London
+	`)
+)
+
+// CreateFreezeOptions the options for the create freeze command
+type CreateFreezeOptions struct {
+	options.CreateOptions
+
+	Environment string
+	Freeze      v1.PromotionFreeze
+	From        string
+	Until       string
+}
+
+// NewCmdCreateFreeze creates a command object for the "create freeze" command
+func NewCmdCreateFreeze(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateFreezeOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "freeze",
+		Short:   "Creates a freeze of an Environment during which releases are not promoted to it",
+		Long:    createFreezeLong,
+		Example: createFreezeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The Environment to freeze")
+	cmd.Flags().StringVarP(&options.Freeze.Name, "name", "n", "", "The name of the freeze. Required for a recurring freeze")
+	cmd.Flags().StringVarP(&options.Freeze.Reason, "reason", "r", "", "The reason for the freeze")
+	cmd.Flags().StringVarP(&options.From, "from", "", "", "When an ad-hoc freeze starts. Defaults to now")
+	cmd.Flags().StringVarP(&options.Until, "until", "u", "", "When an ad-hoc freeze ends as a duration such as 48h, an RFC 3339 time or a local time such as '2019-12-27 09:00'")
+	cmd.Flags().StringVarP(&options.Freeze.Schedule, "schedule", "s", "", "The cron expression for the start of a recurring freeze such as '0 17 * * FRI'")
+	cmd.Flags().StringVarP(&options.Freeze.Duration, "duration", "d", "", "How long each recurring freeze lasts such as 64h")
+	cmd.Flags().StringVarP(&options.Freeze.TimeZone, "time-zone", "", "", "The time zone the schedule of a recurring freeze is in such as Europe/London. Defaults to UTC")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateFreezeOptions) Run() error {
+	if o.Environment == "" {
+		return util.MissingOption(opts.OptionEnvironment)
+	}
+	freeze := o.Freeze
+	now := time.Now()
+	if freeze.Schedule != "" {
+		if o.Until != "" || o.From != "" {
+			return fmt.Errorf("a recurring freeze cannot use the --from or --until options")
+		}
+		if freeze.Name == "" {
+			return util.MissingOption("name")
+		}
+	} else {
+		if o.Until == "" {
+			return util.MissingOption("until")
+		}
+		start := now
+		var err error
+		if o.From != "" {
+			start, err = promotion.ParseFreezeTime(o.From, now)
+			if err != nil {
+				return util.InvalidOptionError("from", o.From, err)
+			}
+		}
+		end, err := promotion.ParseFreezeTime(o.Until, start)
+		if err != nil {
+			return util.InvalidOptionError("until", o.Until, err)
+		}
+		freeze.Start = &metav1.Time{Time: start}
+		freeze.End = &metav1.Time{Time: end}
+		if freeze.Name == "" {
+			freeze.Name = "freeze-" + start.Format("20060102-1504")
+		}
+	}
+	err := promotion.ValidateFreeze(&freeze)
+	if err != nil {
+		return err
+	}
+	freeze.CreatedBy, err = o.GetUsername("")
+	if err != nil {
+		log.Logger().Warnf("Failed to find the current user: %s", err)
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	_, err = jxClient.JenkinsV1().Environments(ns).Get(o.Environment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the Environment %s", o.Environment)
+	}
+	callback := func(env *v1.Environment) error {
+		for _, f := range env.Spec.Freezes {
+			if f.Name == freeze.Name {
+				return fmt.Errorf("the Environment %s already has a freeze called %s", env.Name, freeze.Name)
+			}
+		}
+		env.Spec.Freezes = append(env.Spec.Freezes, freeze)
+		return nil
+	}
+	err = o.ModifyEnvironment(o.Environment, callback)
+	if err != nil {
+		return err
+	}
+	if freeze.Schedule != "" {
+		log.Logger().Infof("Created freeze %s of Environment %s at %s for %s", util.ColorInfo(freeze.Name), util.ColorInfo(o.Environment), util.ColorInfo(freeze.Schedule), util.ColorInfo(freeze.Duration))
+	} else {
+		log.Logger().Infof("Created freeze %s of Environment %s until %s", util.ColorInfo(freeze.Name), util.ColorInfo(o.Environment), util.ColorInfo(freeze.End.Format(time.RFC1123)))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
@@ -23,6 +24,9 @@ var (
 		# Add a new Git server with a name
 		jx create git server -k bitbucketcloud -u http://bitbucket.org -n MyBitBucket 
 
+		# Add Azure DevOps Repos which defaults to the URL https://dev.azure.com
+		jx create git server --kind azuredevops
+
 		For more documentation see: [https://jenkins-x.io/developing/git/](https://jenkins-x.io/developing/git/)
 
 	`)
@@ -96,6 +100,8 @@ func (o *CreateGitServerOptions) Run() error {
 					return errors.Wrapf(err, "Failed to find %s Git service %s", kind, serviceName)
 				}
 				gitUrl = url
+			} else if kind == gits.KindAzureDevOps {
+				gitUrl = gits.AzureDevOpsURL
 			}
 		}
 	}
@@ -0,0 +1,111 @@
+package deletecmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	deleteFreezeLong = templates.LongDesc(`
+		Deletes one or more freezes of an Environment so that releases can be promoted to it again.
+`)
+
+	deleteFreezeExample = templates.Examples(`
+		# Lift the weekends freeze of production
+		jx delete freeze --env production weekends
+	`)
+)
+
+// DeleteFreezeOptions the options for the delete freeze command
+type DeleteFreezeOptions struct {
+	*opts.CommonOptions
+
+	Environment string
+}
+
+// NewCmdDeleteFreeze defines the command
+func NewCmdDeleteFreeze(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DeleteFreezeOptions{
+		CommonOptions: commonOpts,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "freeze [name]",
+		Short:   "Deletes one or more freezes of an Environment",
+		Long:    deleteFreezeLong,
+		Example: deleteFreezeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The Environment to delete the freezes of")
+	return cmd
+}
+
+// Run implements the command
+func (o *DeleteFreezeOptions) Run() error {
+	if o.Environment == "" {
+		return util.MissingOption(opts.OptionEnvironment)
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(o.Environment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the Environment %s", o.Environment)
+	}
+	var names []string
+	for _, f := range env.Spec.Freezes {
+		names = append(names, f.Name)
+	}
+	if len(names) == 0 {
+		log.Logger().Infof("The Environment %s has no freezes", util.ColorInfo(o.Environment))
+		return nil
+	}
+	args := o.Args
+	if len(args) == 0 {
+		if o.BatchMode {
+			return fmt.Errorf("missing freeze name argument. The freezes of the Environment %s are: %s", o.Environment, strings.Join(names, ", "))
+		}
+		args, err = util.PickNames(names, "Pick the freezes to delete:", "", o.GetIOFileHandles())
+		if err != nil {
+			return err
+		}
+	}
+	for _, arg := range args {
+		if util.StringArrayIndex(names, arg) < 0 {
+			return util.InvalidArg(arg, names)
+		}
+	}
+
+	callback := func(env *v1.Environment) error {
+		var freezes []v1.PromotionFreeze
+		for _, f := range env.Spec.Freezes {
+			if util.StringArrayIndex(args, f.Name) < 0 {
+				freezes = append(freezes, f)
+			}
+		}
+		env.Spec.Freezes = freezes
+		return nil
+	}
+	err = o.ModifyEnvironment(o.Environment, callback)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Deleted the freezes %s of Environment %s", util.ColorInfo(strings.Join(args, ", ")), util.ColorInfo(o.Environment))
+	return nil
+}
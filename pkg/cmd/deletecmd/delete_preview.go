@@ -129,6 +129,13 @@ func (o *DeletePreviewOptions) DeletePreview(name string) error {
 			return err
 		}
 	}
+	for _, dependencyRelease := range kube.GetPreviewDependencyReleaseNames(environment) {
+		log.Logger().Infof("Deleting helm release of preview dependency: %s", util.ColorInfo(dependencyRelease))
+		err = o.Helm().DeleteRelease(ns, dependencyRelease, true)
+		if err != nil {
+			log.Logger().Warnf("Failed to delete helm release %s: %s", dependencyRelease, err)
+		}
+	}
 
 	log.Logger().Infof("Deleting preview environment: %s", util.ColorInfo(name))
 	deleteOptions := &DeleteEnvOptions{
@@ -203,6 +203,8 @@ func (o *RequirementsOptions) applyDefaults() error {
 		switch o.SecretStorage {
 		case "local":
 			r.SecretStorage = config.SecretStorageTypeLocal
+		case "sops":
+			r.SecretStorage = config.SecretStorageTypeSops
 		case "vault":
 			r.SecretStorage = config.SecretStorageTypeVault
 		default:
@@ -13,14 +13,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"strconv"
+	"time"
 
 	"strings"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/previews"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/jenkins-x/jx/pkg/util"
+	"k8s.io/client-go/kubernetes"
 )
 
 // GetOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -30,6 +36,13 @@ type GCPreviewsOptions struct {
 
 	DisableImport bool
 	OutDir        string
+	TTL           string
+	IdleTimeout   string
+	PrometheusURL string
+	RequestsQuery string
+
+	defaults previews.Defaults
+	metrics  promotion.MetricsClient
 }
 
 var (
@@ -37,11 +50,24 @@ var (
 		Garbage collect Jenkins X preview environments.  If a pull request is merged or closed the associated preview
 		environment will be deleted.
 
+		A preview environment is also deleted once it is older than its time to live since it was last deployed.
+		A preview environment which has gone without traffic for longer than its idle timeout is hibernated by scaling its deployments to zero.
+		Traffic is detected by querying the request metrics of the ingress controller in Prometheus, so preview environments are only
+		hibernated when a Prometheus server is specified with --prometheus-url.
+
+		A hibernated preview environment is woken up when someone comments '/preview wake' on its pull request
+		or when a request to its ingress is seen, for example when a reviewer opens the preview URL. Both are only checked when
+		this command runs, so a preview environment is woken up on the next run of the garbage collection CronJob rather than
+		straight away. Waking up by a comment is not supported on Bitbucket and Gerrit as their comments cannot be listed.
+
 `)
 
 	GCPreviewsExample = templates.Examples(`
 		jx garbage collect previews
 		jx gc previews
+
+		# delete previews not deployed for 3 days and hibernate previews without traffic for 8 hours
+		jx gc previews --ttl 72h --idle-timeout 8h --prometheus-url http://prometheus-server.monitoring
 `)
 )
 
@@ -63,15 +89,40 @@ func NewCmdGCPreviews(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVarP(&options.TTL, "ttl", "", "", "The default time to live of preview environments after they were last deployed such as 72h")
+	cmd.Flags().StringVarP(&options.IdleTimeout, "idle-timeout", "", "", "The default duration without traffic after which preview environments are hibernated such as 8h")
+	cmd.Flags().StringVarP(&options.PrometheusURL, "prometheus-url", "", "", "The URL of the Prometheus server used to detect traffic to preview environments. Preview environments are only hibernated when this is specified")
+	cmd.Flags().StringVarP(&options.RequestsQuery, "requests-query", "", previews.DefaultRequestsQuery, "The Prometheus query for the number of requests to a preview namespace which may refer to $NAMESPACE and $DURATION")
 	return cmd
 }
 
 // Run implements this command
 func (o *GCPreviewsOptions) Run() error {
+	var err error
+	if o.TTL != "" {
+		o.defaults.TTL, err = time.ParseDuration(o.TTL)
+		if err != nil {
+			return util.InvalidOptionError("ttl", o.TTL, err)
+		}
+	}
+	if o.IdleTimeout != "" {
+		o.defaults.IdleTimeout, err = time.ParseDuration(o.IdleTimeout)
+		if err != nil {
+			return util.InvalidOptionError("idle-timeout", o.IdleTimeout, err)
+		}
+	}
+	if o.PrometheusURL != "" {
+		o.metrics = promotion.NewPrometheusClient(o.PrometheusURL)
+	}
+
 	client, currentNs, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
 	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
 
 	// cannot use field selectors like `spec.kind=Preview` on CRDs so list all environments
 	envs, err := client.JenkinsV1().Environments(currentNs).List(metav1.ListOptions{})
@@ -123,7 +174,15 @@ func (o *GCPreviewsOptions) Run() error {
 
 			lowerState := strings.ToLower(*pullRequest.State)
 
-			if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
+			closed := strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined")
+			expired, err := previews.Expired(&e, o.defaults, time.Now())
+			if err != nil {
+				log.Logger().Warnf("%s", err)
+			}
+			if closed || expired {
+				if expired && !closed {
+					log.Logger().Infof("Deleting preview environment %s as it has not been deployed within its time to live", util.ColorInfo(e.Name))
+				}
 				// lets delete the preview environment
 				deleteOpts := deletecmd.DeletePreviewOptions{
 					PreviewOptions: preview.PreviewOptions{
@@ -136,6 +195,11 @@ func (o *GCPreviewsOptions) Run() error {
 				if err != nil {
 					return fmt.Errorf("failed to delete preview environment %s: %v\n", e.Name, err)
 				}
+				continue
+			}
+			err = o.hibernateOrWake(kubeClient, client, currentNs, &e, gitProvider, gitInfo, prNum)
+			if err != nil {
+				log.Logger().Warnf("Failed to hibernate or wake up preview environment %s: %s", e.Name, err)
 			}
 		}
 	}
@@ -144,3 +208,90 @@ func (o *GCPreviewsOptions) Run() error {
 	}
 	return nil
 }
+
+// hibernateOrWake wakes up a hibernated preview environment if its pull request asked for it or its ingress received
+// a request, otherwise hibernates it if it has gone without traffic for longer than its idle timeout
+func (o *GCPreviewsOptions) hibernateOrWake(kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string, env *v1.Environment, gitProvider gits.GitProvider, gitInfo *gits.GitRepository, prNum int) error {
+	now := time.Now()
+	if previews.IsHibernated(env) {
+		since := now
+		if env.Spec.PreviewLifecycle.HibernatedTimestamp != nil {
+			since = env.Spec.PreviewLifecycle.HibernatedTimestamp.Time
+		}
+		comments, err := gitProvider.ListPullRequestComments(gitInfo.Organisation, gitInfo.Name, prNum)
+		if err != nil {
+			log.Logger().Warnf("Failed to list the comments of pull request %d of %s so cannot wake up preview environment %s by a comment: %s", prNum, gitInfo.URL, env.Name, err)
+		}
+		wokenBy := ""
+		comment := previews.FindWakeComment(comments, since)
+		if comment != nil {
+			wokenBy = "comment"
+			if comment.User != nil && comment.User.Login != "" {
+				wokenBy = "comment by " + comment.User.Login
+			}
+		} else if o.metrics != nil {
+			requests, err := previews.CountRequests(o.metrics, o.RequestsQuery, env.Spec.Namespace, now.Sub(since))
+			if err != nil {
+				return err
+			}
+			if requests > 0 {
+				wokenBy = "ingress request"
+			}
+		}
+		if wokenBy == "" {
+			return nil
+		}
+		err = previews.Wake(kubeClient, jxClient, ns, env, wokenBy)
+		if err != nil {
+			return err
+		}
+		log.Logger().Infof("Woke up preview environment %s due to a %s", util.ColorInfo(env.Name), wokenBy)
+		if comment != nil {
+			url := env.Spec.PreviewGitSpec.ApplicationURL
+			message := fmt.Sprintf(":sunrise: preview environment **%s** has been woken up", env.Name)
+			if url != "" {
+				message += fmt.Sprintf(" and is available [here](%s)", url)
+			}
+			err = gitProvider.CreateIssueComment(gitInfo.Organisation, gitInfo.Name, prNum, message)
+			if err != nil {
+				log.Logger().Warnf("Failed to comment on pull request %d of %s: %s", prNum, gitInfo.URL, err)
+			}
+		}
+		return nil
+	}
+
+	idleTimeout, err := previews.IdleTimeout(env, o.defaults)
+	if err != nil || idleTimeout <= 0 {
+		return err
+	}
+	if o.metrics == nil {
+		// without a source of traffic a preview environment which is in use cannot be told apart from an idle one
+		log.Logger().Debugf("Not hibernating preview environment %s as no Prometheus server is configured to detect its traffic", env.Name)
+		return nil
+	}
+	requests, err := previews.CountRequests(o.metrics, o.RequestsQuery, env.Spec.Namespace, idleTimeout)
+	if err != nil {
+		return err
+	}
+	if requests > 0 {
+		previews.Lifecycle(env).LastActiveTimestamp = &metav1.Time{Time: now}
+		_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(env)
+		return err
+	}
+	if now.Sub(previews.LastActive(env)) <= idleTimeout {
+		return nil
+	}
+	err = previews.Hibernate(kubeClient, jxClient, ns, env)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Hibernated preview environment %s as it has had no traffic for %s", util.ColorInfo(env.Name), idleTimeout)
+	if prNum > 0 {
+		message := fmt.Sprintf(":zzz: preview environment **%s** has been hibernated as it has had no traffic for %s. Comment `%s` to wake it up, which takes effect the next time preview environments are garbage collected", env.Name, idleTimeout, previews.WakeCommand)
+		err = gitProvider.CreateIssueComment(gitInfo.Organisation, gitInfo.Name, prNum, message)
+		if err != nil {
+			log.Logger().Warnf("Failed to comment on pull request %d of %s: %s", prNum, gitInfo.URL, err)
+		}
+	}
+	return nil
+}
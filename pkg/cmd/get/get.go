@@ -91,6 +91,7 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetIssues(commonOpts))
 	cmd.AddCommand(NewCmdGetLimits(commonOpts))
 	cmd.AddCommand(NewCmdGetLang(commonOpts))
+	cmd.AddCommand(NewCmdGetMetrics(commonOpts))
 	cmd.AddCommand(NewCmdGetPipeline(commonOpts))
 	cmd.AddCommand(NewCmdGetPostPreviewJob(commonOpts))
 	cmd.AddCommand(NewCmdGetPreview(commonOpts))
@@ -100,10 +101,12 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetStorage(commonOpts))
 	cmd.AddCommand(NewCmdGetTeam(commonOpts))
 	cmd.AddCommand(NewCmdGetTeamRole(commonOpts))
+	cmd.AddCommand(NewCmdGetTests(commonOpts))
 	cmd.AddCommand(NewCmdGetToken(commonOpts))
 	cmd.AddCommand(NewCmdGetTracker(commonOpts))
 	cmd.AddCommand(NewCmdGetURL(commonOpts))
 	cmd.AddCommand(NewCmdGetUser(commonOpts))
+	cmd.AddCommand(NewCmdGetWebhooks(commonOpts))
 	cmd.AddCommand(NewCmdGetWorkflow(commonOpts))
 	cmd.AddCommand(NewCmdGetVault(commonOpts))
 	cmd.AddCommand(NewCmdGetSecret(commonOpts))
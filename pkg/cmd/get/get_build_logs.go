@@ -3,6 +3,7 @@ package get
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -44,6 +45,11 @@ type GetBuildLogsOptions struct {
 	WaitForPipelineDuration time.Duration
 	TektonLogger            *logs.TektonLogger
 	FailIfPodFails          bool
+	Grep                    string
+	Since                   time.Duration
+	MaxMatches              int
+	MaxLogs                 int
+	LogArchive              *logs.LogArchive
 }
 
 // CLILogWriter is an implementation of logs.LogWriter that will show logs in the standard output
@@ -55,6 +61,12 @@ var (
 	get_build_log_long = templates.LongDesc(`
 		Display a build log
 
+		With --grep the archived logs in the long term storage are searched instead. The archive index only holds the
+		owner, repository, branch, build number and start time of each build, which are used to pick the builds to search,
+		and then the whole log of each of those builds is downloaded and scanned. Only the latest --max-logs builds which
+		started within --since are searched and a warning is shown if there are more, so use --repo, --branch and --since to
+		narrow the search of a busy team rather than expecting the whole history to be searched.
+
 `)
 
 	get_build_log_example = templates.Examples(`
@@ -75,6 +87,9 @@ var (
 
 		# View the build logs for a specific tekton build pod
 		jx get build log --pod my-pod-name
+
+		# Search the archived logs of the builds of the repo cheese in the last week for an error
+		jx get build log --repo cheese --since 168h --grep "connection refused"
 	`)
 )
 
@@ -113,6 +128,10 @@ func NewCmdGetBuildLogs(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildFilter.GitURL, "giturl", "g", "", "The git URL to filter on. If you specify a link to a github repository or PR we can filter the query of build pods accordingly")
 	cmd.Flags().StringVarP(&options.BuildFilter.Context, "context", "", "", "Filters the context of the build")
 	cmd.Flags().BoolVarP(&options.CurrentFolder, "current", "c", false, "Display logs using current folder as repo name, and parent folder as owner")
+	cmd.Flags().StringVarP(&options.Grep, "grep", "", "", "Searches the archived logs of all builds for lines matching the regular expression rather than displaying a build log")
+	cmd.Flags().DurationVarP(&options.Since, "since", "", time.Hour*24*30, "When searching with --grep only the builds started within this duration are searched")
+	cmd.Flags().IntVarP(&options.MaxMatches, "max-matches", "", 100, "The maximum number of matching lines to display when searching with --grep")
+	cmd.Flags().IntVarP(&options.MaxLogs, "max-logs", "", 100, "The maximum number of build logs to download and scan when searching with --grep, starting with the latest builds. Older builds are not searched")
 	options.JenkinsSelector.AddFlags(cmd)
 	options.AddBaseFlags(cmd)
 
@@ -125,6 +144,9 @@ func (o *GetBuildLogsOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Grep != "" {
+		return o.searchArchivedLogs()
+	}
 	jxClient, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
@@ -346,6 +368,100 @@ func (o *GetBuildLogsOptions) getTektonLogs(kubeClient kubernetes.Interface, tek
 	return false, o.TektonLogger.GetRunningBuildLogs(pa, name, false)
 }
 
+// searchArchivedLogs searches the log archive for lines matching the grep pattern and displays them grouped by build
+func (o *GetBuildLogsOptions) searchArchivedLogs() error {
+	pattern, err := regexp.Compile(o.Grep)
+	if err != nil {
+		return errors.Wrapf(err, "invalid --grep regular expression %s", o.Grep)
+	}
+	if o.CurrentFolder {
+		currentDirectory, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		gitRepository, err := gits.NewGitCLI().Info(currentDirectory)
+		if err != nil {
+			return err
+		}
+		o.BuildFilter.Repository = gitRepository.Name
+		o.BuildFilter.Owner = gitRepository.Organisation
+	}
+
+	if o.LogArchive == nil {
+		o.LogArchive, err = o.createLogArchive()
+		if err != nil {
+			return err
+		}
+	}
+	query := logs.ArchiveQuery{
+		Pattern:    pattern,
+		Owner:      o.BuildFilter.Owner,
+		Repository: o.BuildFilter.Repository,
+		Branch:     o.BuildFilter.Branch,
+		Context:    o.BuildFilter.Context,
+		Build:      o.BuildFilter.Build,
+		MaxMatches: o.MaxMatches,
+		MaxLogs:    o.MaxLogs,
+	}
+	if o.Since > 0 {
+		query.Since = time.Now().Add(-o.Since)
+	}
+	matches, err := o.LogArchive.Search(query)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		log.Logger().Infof("no archived build logs match %s", util.ColorInfo(o.Grep))
+		return nil
+	}
+
+	lastActivity := ""
+	for i := range matches {
+		m := &matches[i]
+		if m.Entry.Activity != lastActivity {
+			lastActivity = m.Entry.Activity
+			name := fmt.Sprintf("%s/%s/%s #%s", m.Entry.Owner, m.Entry.Repository, m.Entry.Branch, m.Entry.Build)
+			if m.Entry.Context != "" {
+				name += " " + m.Entry.Context
+			}
+			fmt.Fprintf(o.Out, "\n%s %s %s\n", util.ColorBold(name), m.Entry.Started.Format(time.RFC3339), util.ColorInfo(m.Link()))
+		}
+		fmt.Fprintf(o.Out, "%6d: %s\n", m.LineNumber, m.Highlight(util.ColorWarning))
+	}
+	if o.MaxMatches > 0 && len(matches) >= o.MaxMatches {
+		log.Logger().Warnf("\nonly the first %d matches are shown, use --max-matches to see more", o.MaxMatches)
+	}
+	return nil
+}
+
+// createLogArchive creates the log archive for the storage location of logs in the team settings
+func (o *GetBuildLogsOptions) createLogArchive() (*logs.LogArchive, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	devEnv, err := kube.GetEnrichedDevEnvironment(kubeClient, jxClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	if devEnv == nil {
+		return nil, fmt.Errorf("No development environment found for namespace %s", ns)
+	}
+	location := devEnv.Spec.TeamSettings.StorageLocationOrDefault(kube.ClassificationLogs)
+	if location.IsEmpty() {
+		return nil, errors.New("no storage location is configured for logs so there is no log archive to search")
+	}
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	return logs.NewLogArchive(location, nil, o.Git(), logs.ArchiveURLReader(authSvc, time.Second*20)), nil
+}
+
 // StreamLog implementation of LogWriter.StreamLog for CLILogWriter, this implementation will tail logs for the provided pod /container through the defined logger
 func (o *CLILogWriter) StreamLog(lch <-chan logs.LogLine, ech <-chan error) error {
 	for {
@@ -23,6 +23,7 @@ type GetCVEOptions struct {
 	Version           string
 	Env               string
 	VulnerabilityType string
+	DBDir             string
 }
 
 var (
@@ -39,6 +40,9 @@ var (
 		jx get cve --app foo --version 1.0.0
 		jx get cve --app foo --environment staging
 		jx get cve --environment staging
+
+		# Scan an image offline using a vulnerability database snapshot instead of Anchore
+		jx get cve --image-name jenkinsxio/nexus --version 0.0.5 --vulnerability-db /vulnerability-db
 	`)
 )
 
@@ -74,6 +78,7 @@ func (o *GetCVEOptions) addGetCVEFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ImageID, "image-id", "", "", "Image ID in CVE engine if already known")
 	cmd.Flags().StringVarP(&o.Version, "version", "", "", "Version or tag e.g. 0.0.1")
 	cmd.Flags().StringVarP(&o.Env, "environment", "e", "", "The Environment to find running applications")
+	cmd.Flags().StringVarP(&o.DBDir, "vulnerability-db", "", "", "The directory of a vulnerability database snapshot to scan images offline instead of using Anchore")
 }
 
 // Run implements this command
@@ -89,25 +94,14 @@ func (o *GetCVEOptions) Run() error {
 		return fmt.Errorf("cannot create jx client: %v", err)
 	}
 
-	externalURL, err := o.EnsureAddonServiceAvailable(kube.AddonServices[create.DefaultAnchoreName])
-	if err != nil {
-		log.Logger().Warnf("no CVE provider service found, are you in your teams dev environment?  Type `jx env` to switch.")
-		return fmt.Errorf("if no CVE provider running, try running `jx create addon anchore` in your teams dev environment: %v", err)
-	}
-
 	// if no flags are set try and guess the image name from the current directory
 	if o.ImageID == "" && o.ImageName == "" && o.Env == "" {
 		return fmt.Errorf("no --image-name, --image-id or --environment flags set\n")
 	}
 
-	server, auth, err := o.GetAddonAuthByKind(kube.ValueKindCVE, externalURL)
+	p, err := o.createProvider()
 	if err != nil {
-		return fmt.Errorf("error getting anchore engine auth details, %v", err)
-	}
-
-	p, err := cve.NewAnchoreProvider(server, auth)
-	if err != nil {
-		return fmt.Errorf("error creating anchore provider, %v", err)
+		return err
 	}
 	table := o.CreateTable()
 	table.AddRow("Image", util.ColorInfo("Severity"), "Vulnerability", "URL", "Package", "Fix")
@@ -135,3 +129,31 @@ func (o *GetCVEOptions) Run() error {
 	table.Render()
 	return nil
 }
+
+// createProvider creates an offline provider if there is a vulnerability database otherwise an Anchore provider
+func (o *GetCVEOptions) createProvider() (cve.CVEProvider, error) {
+	if o.DBDir != "" {
+		p, err := cve.NewLocalProvider(o.DBDir)
+		if err != nil {
+			return nil, fmt.Errorf("error creating offline provider, %v", err)
+		}
+		return p, nil
+	}
+
+	externalURL, err := o.EnsureAddonServiceAvailable(kube.AddonServices[create.DefaultAnchoreName])
+	if err != nil {
+		log.Logger().Warnf("no CVE provider service found, are you in your teams dev environment?  Type `jx env` to switch.")
+		return nil, fmt.Errorf("if no CVE provider running, try running `jx create addon anchore` in your teams dev environment or use --vulnerability-db: %v", err)
+	}
+
+	server, auth, err := o.GetAddonAuthByKind(kube.ValueKindCVE, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting anchore engine auth details, %v", err)
+	}
+
+	p, err := cve.NewAnchoreProvider(server, auth)
+	if err != nil {
+		return nil, fmt.Errorf("error creating anchore provider, %v", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,306 @@
+package get
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetMetricsDORAOptions the command line options
+type GetMetricsDORAOptions struct {
+	GetOptions
+
+	App           string
+	Environment   string
+	FromDate      string
+	ToDate        string
+	BlogOutputDir string
+	BlogName      string
+}
+
+var (
+	getMetricsDORALong = templates.LongDesc(`
+		Display the DevOps Research and Assessment (DORA) metrics of each app and environment.
+
+		* Deployment frequency is the number of successful promotions per week.
+		* Lead time for changes is the median time from a commit being made to the successful promotion which released it.
+		* Change failure rate is the percentage of promotions which failed.
+		* Mean time to recovery (MTTR) is the mean time from a failed promotion to the next successful promotion.
+
+		The promotions are found from the promote steps of the PipelineActivities and the commits released by each version from the Release resources in the environments.
+		The lead time is only known for releases created since the commit timestamps were recorded by 'jx step changelog'.
+`)
+
+	getMetricsDORAExample = templates.Examples(`
+		# Display the DORA metrics of the apps in the last 30 days
+		jx get metrics dora
+
+		# Display the DORA metrics of the app cheese in production as JSON
+		jx get metrics dora --app cheese --env production -o json
+
+		# Generate a blog post with the DORA metrics for October
+		jx get metrics dora --from-date "October 1 2019" --to-date "November 1 2019" --blog-dir ./blog
+	`)
+)
+
+// NewCmdGetMetricsDORA creates the command
+func NewCmdGetMetricsDORA(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetMetricsDORAOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "dora [flags]",
+		Short:   "Display the deployment frequency, lead time, change failure rate and MTTR of each app and environment",
+		Long:    getMetricsDORALong,
+		Example: getMetricsDORAExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.App, "app", "a", "", "The app to display the metrics of. Defaults to all apps")
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The environment to display the metrics of. Defaults to all environments")
+	cmd.Flags().StringVarP(&options.FromDate, "from-date", "f", "", "The date to calculate the metrics from. Defaults to 30 days before the to date. Should be a format: "+util.DateFormat)
+	cmd.Flags().StringVarP(&options.ToDate, "to-date", "t", "", "The date to calculate the metrics up to. Defaults to now. Should be a format: "+util.DateFormat)
+	cmd.Flags().StringVarP(&options.BlogOutputDir, "blog-dir", "", "", "The Hugo-style blog source code to generate a markdown post with the metrics and charts into")
+	cmd.Flags().StringVarP(&options.BlogName, "blog-name", "n", "", "The blog name")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetMetricsDORAOptions) Run() error {
+	from, to, err := o.period()
+	if err != nil {
+		return err
+	}
+	client, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	activities, err := client.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the PipelineActivities")
+	}
+	releases, err := o.loadReleases(client, ns)
+	if err != nil {
+		return err
+	}
+
+	var deployments []reports.Deployment
+	for _, d := range reports.DeploymentsFromActivities(activities.Items, releases) {
+		if o.App != "" && d.App != o.App {
+			continue
+		}
+		if o.Environment != "" && d.Environment != o.Environment {
+			continue
+		}
+		deployments = append(deployments, d)
+	}
+	metrics := reports.CalculateDORAMetrics(deployments, from, to)
+
+	if o.Output != "" {
+		if metrics == nil {
+			metrics = []*reports.DORAMetrics{}
+		}
+		return o.renderResult(metrics, o.Output)
+	}
+	if o.BlogOutputDir != "" {
+		return o.writeBlog(metrics, from, to)
+	}
+	if len(metrics) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+	table := o.CreateTable()
+	table.AddRow("APP", "ENVIRONMENT", "DEPLOYMENTS", "PER WEEK", "LEAD TIME", "CHANGE FAILURE RATE", "MTTR")
+	for _, m := range metrics {
+		table.AddRow(m.App, m.Environment,
+			strconv.Itoa(m.Deployments),
+			fmt.Sprintf("%.1f", m.DeploymentsPerWeek),
+			doraDurationString(m.LeadTime),
+			fmt.Sprintf("%.0f%%", m.ChangeFailureRate*100),
+			doraDurationString(m.MeanTimeToRecovery))
+	}
+	table.Render()
+	return nil
+}
+
+// period returns the start and end of the period to calculate the metrics for
+func (o *GetMetricsDORAOptions) period() (time.Time, time.Time, error) {
+	to := time.Now()
+	var err error
+	if o.ToDate != "" {
+		to, err = util.ParseDate(o.ToDate)
+		if err != nil {
+			return to, to, errors.Wrapf(err, "failed to parse --to-date %s", o.ToDate)
+		}
+	}
+	from := to.Add(-time.Hour * 24 * 30)
+	if o.FromDate != "" {
+		from, err = util.ParseDate(o.FromDate)
+		if err != nil {
+			return from, to, errors.Wrapf(err, "failed to parse --from-date %s", o.FromDate)
+		}
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("the from date %s must be before the to date %s", util.FormatDate(from), util.FormatDate(to))
+	}
+	return from, to, nil
+}
+
+// loadReleases loads the Release resources from the namespaces of the environments
+func (o *GetMetricsDORAOptions) loadReleases(client versioned.Interface, ns string) ([]v1.Release, error) {
+	envList, err := client.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the Environments")
+	}
+	namespaces := map[string]bool{ns: true}
+	for _, env := range envList.Items {
+		if env.Spec.Namespace != "" {
+			namespaces[env.Spec.Namespace] = true
+		}
+	}
+	var answer []v1.Release
+	for envNs := range namespaces {
+		releaseList, err := client.JenkinsV1().Releases(envNs).List(metav1.ListOptions{})
+		if err != nil {
+			log.Logger().Warnf("failed to list the Releases in namespace %s: %s", envNs, err)
+			continue
+		}
+		answer = append(answer, releaseList.Items...)
+	}
+	return answer, nil
+}
+
+// writeBlog generates a Hugo-style markdown post containing the metrics and a bar chart of each of them
+func (o *GetMetricsDORAOptions) writeBlog(metrics []*reports.DORAMetrics, from time.Time, to time.Time) error {
+	if o.BlogName == "" {
+		o.BlogName = "dora-metrics-" + strconv.Itoa(to.Day()) + "-" + strings.ToLower(to.Month().String()) + "-" + strconv.Itoa(to.Year())
+	}
+	contentDir := filepath.Join(o.BlogOutputDir, "content", "news")
+	jsDir := filepath.Join(o.BlogOutputDir, "static", "news", o.BlogName)
+	for _, dir := range []string{contentDir, jsDir} {
+		err := os.MkdirAll(dir, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create directory %s", dir)
+		}
+	}
+
+	toDate := util.FormatDate(to)
+	var buffer bytes.Buffer
+	out := bufio.NewWriter(&buffer)
+	fmt.Fprintf(out, `---
+title: "DORA metrics for %s"
+date: %s
+description: "Deployment frequency, lead time, change failure rate and MTTR up to %s"
+categories: [blog]
+keywords: []
+slug: "%s"
+aliases: []
+author: jenkins-x-bot
+---
+
+## DORA metrics for %s
+
+This blog outlines the DevOps Research and Assessment metrics of the apps from %s to %s.
+
+`, toDate, time.Now().Format(time.RFC3339), toDate, o.BlogName, toDate, util.FormatDate(from), toDate)
+	writeDORAMarkdownTable(out, metrics)
+
+	charts := []struct {
+		name  string
+		title string
+		value func(m *reports.DORAMetrics) string
+	}{
+		{"deployments", "Deployments Per Week", func(m *reports.DORAMetrics) string {
+			return fmt.Sprintf("%.2f", m.DeploymentsPerWeek)
+		}},
+		{"leadTime", "Lead Time In Hours", func(m *reports.DORAMetrics) string {
+			return fmt.Sprintf("%.2f", m.LeadTime.Hours())
+		}},
+		{"changeFailureRate", "Change Failure Rate", func(m *reports.DORAMetrics) string {
+			return fmt.Sprintf("%.0f", m.ChangeFailureRate*100)
+		}},
+		{"mttr", "MTTR In Hours", func(m *reports.DORAMetrics) string {
+			return fmt.Sprintf("%.2f", m.MeanTimeToRecovery.Hours())
+		}},
+	}
+	if len(metrics) > 0 {
+		fmt.Fprintf(out, "\n## Charts\n")
+		for _, c := range charts {
+			fmt.Fprintf(out, "\n### %s\n", c.title)
+			out.Flush()
+			jsFileName := filepath.Join(jsDir, c.name+".js")
+			jsLinkURI := filepath.Join("/news", o.BlogName, c.name+".js")
+			report := reports.NewBlogBarReport(c.name, out, jsFileName, jsLinkURI)
+			for _, m := range metrics {
+				report.AddText(m.App+" "+m.Environment, c.value(m))
+			}
+			err := report.Render()
+			if err != nil {
+				return errors.Wrapf(err, "failed to render the %s chart", c.name)
+			}
+		}
+	}
+	fmt.Fprintf(out, "\nThis blog post was generated via the [jx get metrics dora](https://jenkins-x.io/commands/jx_get_metrics_dora/) command from [Jenkins X](https://jenkins-x.io/).\n")
+	out.Flush()
+
+	blogFile := filepath.Join(contentDir, o.BlogName+".md")
+	err := ioutil.WriteFile(blogFile, buffer.Bytes(), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", blogFile)
+	}
+	log.Logger().Infof("Generated blog post %s", util.ColorInfo(blogFile))
+	return nil
+}
+
+func writeDORAMarkdownTable(out io.Writer, metrics []*reports.DORAMetrics) {
+	if len(metrics) == 0 {
+		fmt.Fprintf(out, "There were no deployments in this period.\n")
+		return
+	}
+	fmt.Fprintf(out, "| App | Environment | Deployments | Per Week | Lead Time | Change Failure Rate | MTTR |\n")
+	fmt.Fprintf(out, "| :-- | :---------- | ----------: | -------: | --------: | ------------------: | ---: |\n")
+	for _, m := range metrics {
+		fmt.Fprintf(out, "| %s | %s | %d | %.1f | %s | %.0f%% | %s |\n", m.App, m.Environment, m.Deployments,
+			m.DeploymentsPerWeek, doraDurationString(m.LeadTime), m.ChangeFailureRate*100, doraDurationString(m.MeanTimeToRecovery))
+	}
+}
+
+// doraDurationString formats a duration in the largest unit of days, hours or minutes
+func doraDurationString(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d >= time.Hour*24:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,251 @@
+package get
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reportingtools"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetTestsOptions the command line options
+type GetTestsOptions struct {
+	GetOptions
+
+	Filter     string
+	Branch     string
+	Classifier string
+	Flaky      bool
+	MaxBuilds  int
+	History    int
+	Timeout    time.Duration
+
+	// ReadURLFn reads the JUnit reports attached to the activities, defaulting to reading buckets and git URLs
+	ReadURLFn func(u string) ([]byte, error)
+}
+
+var (
+	getTestsLong = templates.LongDesc(`
+		Display the results of the tests run by the pipelines, aggregated across builds.
+
+		The JUnit reports attached to the PipelineActivity of each build by 'jx step stash' are used to work out the failure rate and history of each test in each repository.
+		A test is flaky if it both passed and failed when building the same commit.
+`)
+
+	getTestsExample = templates.Examples(`
+		# List the tests whose results flipped on the same commit
+		jx get tests --flaky
+
+		# List the flaky tests of the master branch of the cheese repository as JSON
+		jx get tests --flaky -f cheese --branch master -o json
+
+		# List the failure rate of all tests in the last 20 builds
+		jx get tests --max-builds 20
+	`)
+)
+
+// NewCmdGetTests creates the command
+func NewCmdGetTests(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetTestsOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "tests [flags]",
+		Short:   "Display the failure rate and history of tests across builds",
+		Long:    getTestsLong,
+		Example: getTestsExample,
+		Aliases: []string{"test"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Text to filter the pipeline names")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "", "", "The branch to filter on")
+	cmd.Flags().StringVarP(&options.Classifier, "classifier", "", "tests", "The classifier of the attachments containing the JUnit reports")
+	cmd.Flags().BoolVarP(&options.Flaky, "flaky", "", false, "Only display the tests whose results flipped on the same commit")
+	cmd.Flags().IntVarP(&options.MaxBuilds, "max-builds", "", 50, "The maximum number of the most recent builds to aggregate the test results of")
+	cmd.Flags().IntVarP(&options.History, "history", "", 10, "The number of the most recent results of each test to display in the table")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", time.Second*20, "The timeout to read each JUnit report")
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetTestsOptions) Run() error {
+	client, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	list, err := client.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the PipelineActivities")
+	}
+	if o.ReadURLFn == nil {
+		authSvc, err := o.GitAuthConfigService()
+		if err != nil {
+			return err
+		}
+		httpFn := step.CreateBucketHTTPFn(authSvc)
+		o.ReadURLFn = func(u string) ([]byte, error) {
+			return buckets.ReadURL(u, o.Timeout, httpFn)
+		}
+	}
+
+	activities := o.filterActivities(list.Items)
+	var runs []reportingtools.TestRun
+	for i := range activities {
+		run, ok := o.loadTestRun(activities[i])
+		if ok {
+			runs = append(runs, run)
+		}
+	}
+
+	var histories []*reportingtools.TestHistory
+	for _, h := range reportingtools.AnalyzeTestRuns(runs) {
+		if !o.Flaky || h.IsFlaky() {
+			histories = append(histories, h)
+		}
+	}
+	if o.Output != "" {
+		if histories == nil {
+			histories = []*reportingtools.TestHistory{}
+		}
+		return o.renderResult(histories, o.Output)
+	}
+	if len(histories) == 0 {
+		if o.Flaky {
+			log.Logger().Infof("no flaky tests found in the results of %d builds", len(runs))
+			return nil
+		}
+		return outputEmptyListWarning(o.Out)
+	}
+
+	table := o.CreateTable()
+	table.AddRow("REPOSITORY", "TEST", "RUNS", "FAILURES", "FAILURE RATE", "FLAKY COMMITS", "HISTORY")
+	for _, h := range histories {
+		table.AddRow(h.Owner+"/"+h.Repository,
+			h.Name,
+			strconv.Itoa(h.Runs),
+			strconv.Itoa(h.Failures),
+			fmt.Sprintf("%.0f%%", h.FailureRate*100),
+			strconv.Itoa(len(h.FlakyCommits)),
+			o.historyString(h))
+	}
+	table.Render()
+	return nil
+}
+
+// filterActivities returns the most recent activities matching the filters, which have test reports attached
+func (o *GetTestsOptions) filterActivities(activities []v1.PipelineActivity) []*v1.PipelineActivity {
+	var answer []*v1.PipelineActivity
+	for i := range activities {
+		a := &activities[i]
+		if o.Filter != "" && !strings.Contains(a.Spec.Pipeline, o.Filter) {
+			continue
+		}
+		if o.Branch != "" && a.BranchName() != o.Branch {
+			continue
+		}
+		if len(o.reportURLs(a)) == 0 {
+			continue
+		}
+		answer = append(answer, a)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return activityStarted(answer[i]).After(activityStarted(answer[j]))
+	})
+	if o.MaxBuilds > 0 && len(answer) > o.MaxBuilds {
+		answer = answer[:o.MaxBuilds]
+	}
+	return answer
+}
+
+// reportURLs returns the URLs of the JUnit reports attached to the activity
+func (o *GetTestsOptions) reportURLs(activity *v1.PipelineActivity) []string {
+	var answer []string
+	for _, attachment := range activity.Spec.Attachments {
+		if attachment.Name != o.Classifier {
+			continue
+		}
+		for _, u := range attachment.URLs {
+			if strings.HasSuffix(strings.ToLower(u), ".xml") {
+				answer = append(answer, u)
+			}
+		}
+	}
+	return answer
+}
+
+// loadTestRun reads and parses the JUnit reports of the activity, returning false if none of them could be read
+func (o *GetTestsOptions) loadTestRun(activity *v1.PipelineActivity) (reportingtools.TestRun, bool) {
+	run := reportingtools.TestRun{
+		Activity:   activity.Name,
+		Owner:      activity.RepositoryOwner(),
+		Repository: activity.RepositoryName(),
+		Build:      activity.Spec.Build,
+		Commit:     activity.Spec.LastCommitSHA,
+		Started:    activityStarted(activity),
+	}
+	found := false
+	for _, u := range o.reportURLs(activity) {
+		data, err := o.ReadURLFn(u)
+		if err != nil {
+			log.Logger().Warnf("failed to read the JUnit report %s of %s: %s", u, activity.Name, err)
+			continue
+		}
+		results, err := reportingtools.ParseJUnitReport(data)
+		if err != nil {
+			log.Logger().Warnf("failed to parse the JUnit report %s of %s: %s", u, activity.Name, err)
+			continue
+		}
+		run.Results = append(run.Results, results...)
+		found = true
+	}
+	return run, found
+}
+
+// historyString returns the most recent results of the test, oldest first
+func (o *GetTestsOptions) historyString(h *reportingtools.TestHistory) string {
+	history := h.History
+	if o.History > 0 && len(history) > o.History {
+		history = history[len(history)-o.History:]
+	}
+	var b strings.Builder
+	for _, e := range history {
+		switch e.Status {
+		case reportingtools.TestStatusPassed:
+			b.WriteString(util.ColorInfo("✓"))
+		case reportingtools.TestStatusFailed:
+			b.WriteString(util.ColorError("✗"))
+		default:
+			b.WriteString("-")
+		}
+	}
+	return b.String()
+}
+
+func activityStarted(activity *v1.PipelineActivity) time.Time {
+	if activity.Spec.StartedTimestamp != nil {
+		return activity.Spec.StartedTimestamp.Time
+	}
+	return activity.CreationTimestamp.Time
+}
@@ -0,0 +1,119 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/webhooks"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetWebhooksOptions containers the CLI options
+type GetWebhooksOptions struct {
+	GetOptions
+
+	Repository string
+}
+
+var (
+	getWebhooksLong = templates.LongDesc(`
+		Display the recent webhook deliveries received by the pipeline runner and the PipelineActivity each one triggered.
+
+		Use this to find out whether a webhook arrived when a pipeline did not trigger. Deliveries can be sent again with 'jx step webhook replay'
+` + helper.SeeAlsoText("jx step webhook replay", "jx get activities"))
+
+	getWebhooksExample = templates.Examples(`
+		# List the recent webhook deliveries
+		jx get webhooks
+
+		# List the recent webhook deliveries for a repository
+		jx get webhooks --repo myorg/myapp
+	`)
+)
+
+// NewCmdGetWebhooks creates the new command for: jx get webhooks
+func NewCmdGetWebhooks(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetWebhooksOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "webhooks",
+		Short:   "Display the recent webhook deliveries and the pipelines they triggered",
+		Aliases: []string{"webhook", "hooks", "hook"},
+		Long:    getWebhooksLong,
+		Example: getWebhooksExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Repository, "repo", "r", "", "The repository to show the deliveries of as either owner/name or name")
+
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetWebhooksOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	deliveries, err := webhooks.NewDeliveryStore(kubeClient, ns).List()
+	if err != nil {
+		return err
+	}
+	deliveries = webhooks.FilterByRepository(deliveries, o.Repository)
+	if o.Output != "" {
+		return o.renderResult(deliveries, o.Output)
+	}
+	if len(deliveries) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("ID", "RECEIVED AGO", "REPOSITORY", "BRANCH", "CONTEXT", "PIPELINE ACTIVITY", "STATUS")
+	for _, d := range deliveries {
+		repository := d.Repository
+		if d.Owner != "" {
+			repository = d.Owner + "/" + repository
+		}
+		activityName := ""
+		status := ""
+		activity := webhooks.MatchActivity(d, activities.Items)
+		if activity != nil {
+			activityName = activity.Name
+			status = statusString(activity.Spec.Status)
+		} else if d.PipelineActivity != "" {
+			activityName = d.PipelineActivity
+			status = util.ColorWarning("Removed")
+		}
+		if d.Error != "" {
+			status = util.ColorError("Error: " + d.Error)
+		} else if activity == nil && d.PipelineActivity == "" {
+			status = util.ColorWarning("Not Triggered")
+		}
+		if d.ReplayOf != "" {
+			status += " (replay of " + d.ReplayOf + ")"
+		}
+		table.AddRow(d.ID, timeToString(&metav1.Time{Time: d.Timestamp}), repository, d.Branch, d.Context, activityName, status)
+	}
+	table.Render()
+	return nil
+}
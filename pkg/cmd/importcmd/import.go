@@ -961,10 +961,14 @@ func (options *ImportOptions) doImport() error {
 
 	if isProw {
 		if !options.DisableWebhooks && !githubAppMode {
-			// register the webhook
-			err = options.CreateWebhookProw(gitURL, gitProvider)
-			if err != nil {
-				return err
+			if gits.SignsWebHooks(gitProvider.Kind()) {
+				// register the webhook
+				err = options.CreateWebhookProw(gitURL, gitProvider)
+				if err != nil {
+					return err
+				}
+			} else {
+				log.Logger().Warnf("Not registering a webhook for %s as %s does not sign webhooks so they cannot be validated", gitURL, gitProvider.Kind())
 			}
 		}
 		return options.addProwConfig(gitURL, gitProvider.Kind())
@@ -15,6 +15,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/secreturl"
 	"github.com/jenkins-x/jx/pkg/secreturl/localvault"
+	"github.com/jenkins-x/jx/pkg/secreturl/sops"
 	"github.com/pborman/uuid"
 
 	"github.com/jenkins-x/jx/pkg/environments"
@@ -23,6 +24,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/kube/services"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/packages"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -427,6 +429,13 @@ func (o *CommonOptions) GetSecretURLClient(location secrets.SecretsLocationKind)
 			return o.secretURLClient, errors.Wrapf(err, "getting the file system secrets directory")
 		}
 		o.secretURLClient = localvault.NewFileSystemClient(dir)
+	case secrets.SopsLocationKind:
+		// lets find the encrypted secrets relative to the requirements file in the current directory or its parents
+		requirements, requirementsFileName, err := config.LoadRequirementsConfig("")
+		if err != nil {
+			return o.secretURLClient, errors.Wrapf(err, "loading the requirements to find the sops secrets")
+		}
+		o.secretURLClient, err = o.newSopsClient(requirements, requirementsFileName)
 	case secrets.AutoLocationKind:
 		location := o.detectSecretsLocation()
 		o.secretURLClient, err = o.GetSecretURLClient(location)
@@ -437,6 +446,29 @@ func (o *CommonOptions) GetSecretURLClient(location secrets.SecretsLocationKind)
 	return o.secretURLClient, err
 }
 
+// GetSecretURLClientForRequirements create a new secret URL client for the secret storage of the requirements. Secrets
+// encrypted with sops are found relative to the requirements file
+func (o *CommonOptions) GetSecretURLClientForRequirements(requirements *config.RequirementsConfig, requirementsFileName string) (secreturl.Client, error) {
+	if o.secretURLClient == nil && requirements.SecretStorage == config.SecretStorageTypeSops {
+		client, err := o.newSopsClient(requirements, requirementsFileName)
+		if err != nil {
+			return nil, err
+		}
+		o.secretURLClient = client
+	}
+	return o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+}
+
+// newSopsClient creates a client for the secrets encrypted with sops installing the sops binary if it is missing such
+// as in the pipelines which run boot in the cluster
+func (o *CommonOptions) newSopsClient(requirements *config.RequirementsConfig, requirementsFileName string) (secreturl.Client, error) {
+	err := packages.InstallSops(false)
+	if err != nil {
+		return nil, errors.Wrap(err, "installing sops")
+	}
+	return sops.NewClientForRequirements(requirements, requirementsFileName), nil
+}
+
 // detectSecretsLocation detects dynamically the secrets location by trying to create a vault client
 func (o *CommonOptions) detectSecretsLocation() secrets.SecretsLocationKind {
 	_, err := o.SystemVaultClient(o.devNamespace)
@@ -152,6 +152,8 @@ func (o *CommonOptions) DoInstallMissingDependencies(install []string) error {
 			err = amazon.InstallAwsIamAuthenticator(false)
 		case "kustomize":
 			err = o.InstallKustomize()
+		case "sops":
+			err = packages.InstallSops(false)
 		default:
 			return fmt.Errorf("unknown dependency to install %s\n", i)
 		}
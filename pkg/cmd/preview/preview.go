@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/cenkalti/backoff"
+	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/helm"
 
 	"github.com/jenkins-x/jx/pkg/kserving"
@@ -30,12 +31,15 @@ import (
 	"github.com/jenkins-x/jx/pkg/kube/services"
 
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/environments"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/previews"
 	"github.com/jenkins-x/jx/pkg/util"
 	kserve "github.com/knative/serving/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
@@ -73,6 +77,10 @@ const (
 	optionPostPreviewJobTimeout  = "post-preview-job-timeout"
 	optionPostPreviewJobPollTime = "post-preview-poll-time"
 	optionPreviewHealthTimeout   = "preview-health-timeout"
+	optionTTL                    = "ttl"
+	optionIdleTimeout            = "idle-timeout"
+
+	defaultDependenciesEnvironment = "staging"
 )
 
 // PreviewOptions the options for viewing running PRs
@@ -92,6 +100,8 @@ type PreviewOptions struct {
 	PostPreviewJobTimeout  string
 	PostPreviewJobPollTime string
 	PreviewHealthTimeout   string
+	TTL                    string
+	IdleTimeout            string
 
 	PullRequestName string
 	GitConfDir      string
@@ -103,6 +113,7 @@ type PreviewOptions struct {
 	PostPreviewJobTimeoutDuration time.Duration
 	PostPreviewJobPollDuration    time.Duration
 	PreviewHealthTimeoutDuration  time.Duration
+	SeedJobs                      []batchv1.Job
 
 	HelmValuesConfig config.HelmValuesConfig
 }
@@ -159,6 +170,8 @@ func (o *PreviewOptions) AddPreviewOptions(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.PostPreviewJobPollTime, optionPostPreviewJobPollTime, "", "10s", "The amount of time between polls for the post preview Job status")
 	cmd.Flags().StringVarP(&o.PreviewHealthTimeout, optionPreviewHealthTimeout, "", "5m", "The amount of time to wait for the preview application to become healthy")
 	cmd.Flags().BoolVarP(&o.NoComment, "no-comment", "", false, "Disables commenting on the Pull Request after preview is created.")
+	cmd.Flags().StringVarP(&o.TTL, optionTTL, "", "", "How long the Preview Environment lives after it was last deployed before 'jx gc previews' deletes it such as 72h")
+	cmd.Flags().StringVarP(&o.IdleTimeout, optionIdleTimeout, "", "", "How long the Preview Environment can go without traffic before 'jx gc previews' hibernates it such as 8h")
 }
 
 // Run implements the command
@@ -182,6 +195,18 @@ func (o *PreviewOptions) Run() error {
 			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.Timeout, optionPreviewHealthTimeout, err)
 		}
 	}
+	if o.TTL != "" {
+		_, err = time.ParseDuration(o.TTL)
+		if err != nil {
+			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.TTL, optionTTL, err)
+		}
+	}
+	if o.IdleTimeout != "" {
+		_, err = time.ParseDuration(o.IdleTimeout)
+		if err != nil {
+			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.IdleTimeout, optionIdleTimeout, err)
+		}
+	}
 
 	log.Logger().Info("Creating a preview")
 	/*
@@ -419,12 +444,24 @@ func (o *PreviewOptions) Run() error {
 			}
 		}
 
+		// lets always record the deployment for the time to live and idle timeout of the preview
+		hibernated := previews.IsHibernated(env)
+		o.updatePreviewLifecycle(env)
+		update = true
+
 		if update {
 			env, err = environmentsResource.PatchUpdate(env)
 			if err != nil {
 				return fmt.Errorf("Failed to update Environment %s due to %s", o.Name, err)
 			}
 		}
+		if hibernated {
+			err = previews.Wake(kubeClient, jxClient, ns, env, "deploy")
+			if err != nil {
+				return err
+			}
+			log.Logger().Infof("Woke up hibernated environment %s", util.ColorInfo(env.Name))
+		}
 	} else {
 		// lets create a new preview environment
 		previewGitSpec := v1.PreviewGitSpec{
@@ -463,6 +500,7 @@ func (o *PreviewOptions) Run() error {
 				PreviewGitSpec: previewGitSpec,
 			},
 		}
+		o.updatePreviewLifecycle(env)
 		_, err = environmentsResource.Create(env)
 		if err != nil {
 			return fmt.Errorf("Failed to create environment in namespace %s due to: %s", ns, err)
@@ -475,6 +513,15 @@ func (o *PreviewOptions) Run() error {
 		return err
 	}
 
+	if projectConfig.PreviewEnvironments != nil && projectConfig.PreviewEnvironments.Dependencies != nil {
+		dependencies := projectConfig.PreviewEnvironments.Dependencies
+		err = o.deployPreviewDependencies(jxClient, ns, dependencies)
+		if err != nil {
+			return err
+		}
+		o.SeedJobs = dependencies.SeedJobs
+	}
+
 	domain, err := kube.GetCurrentDomain(kubeClient, ns)
 	if err != nil {
 		return err
@@ -706,7 +753,19 @@ func (o *PreviewOptions) RunPostPreviewSteps(kubeClient kubernetes.Interface, ns
 
 	// Post preview jobs should validate input and behave appropriately. Needs a selector to invoke only relevant PPJs?
 
-	jobs := teamSettings.PostPreviewJobs
+	// lets seed the preview with data before any post preview jobs test it
+	if len(o.SeedJobs) > 0 {
+		log.Logger().Infof("Seeding the preview environment in namespace %s", util.ColorInfo(ns))
+		err = o.runPreviewJobs(kubeClient, ns, o.SeedJobs, envVars)
+		if err != nil {
+			return err
+		}
+	}
+	return o.runPreviewJobs(kubeClient, ns, teamSettings.PostPreviewJobs, envVars)
+}
+
+// runPreviewJobs creates the jobs in the preview namespace with the given environment variables and waits for them to complete
+func (o *PreviewOptions) runPreviewJobs(kubeClient kubernetes.Interface, ns string, jobs []batchv1.Job, envVars map[string]string) error {
 	jobResources := kubeClient.BatchV1().Jobs(ns)
 	createdJobs := []*batchv1.Job{}
 	for _, job := range jobs {
@@ -1034,3 +1093,124 @@ func getImageTag() (string, error) {
 	}
 	return tag, nil
 }
+
+// updatePreviewLifecycle records that the Preview Environment has been deployed along with its time to live and idle timeout
+func (o *PreviewOptions) updatePreviewLifecycle(env *v1.Environment) {
+	lifecycle := previews.Lifecycle(env)
+	if o.TTL != "" {
+		lifecycle.TTL = o.TTL
+	}
+	if o.IdleTimeout != "" {
+		lifecycle.IdleTimeout = o.IdleTimeout
+	}
+	now := &metav1.Time{Time: time.Now()}
+	lifecycle.LastDeployedTimestamp = now
+	lifecycle.LastActiveTimestamp = now
+}
+
+// deployPreviewDependencies deploys the apps the preview depends on into the preview namespace at their versions in the
+// requirements of the configured Environment, recording their releases so they are deleted with the preview
+func (o *PreviewOptions) deployPreviewDependencies(jxClient versioned.Interface, ns string, dependencies *config.PreviewDependenciesConfig) error {
+	if len(dependencies.Apps) == 0 {
+		return nil
+	}
+	envName := dependencies.Environment
+	if envName == "" {
+		envName = defaultDependenciesEnvironment
+	}
+	sourceEnv, err := jxClient.JenkinsV1().Environments(ns).Get(envName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the Environment %s for the preview dependencies", envName)
+	}
+	gitURL := sourceEnv.Spec.Source.URL
+	if gitURL == "" {
+		return fmt.Errorf("the Environment %s has no git repository to find the versions of the preview dependencies", envName)
+	}
+	gitInfo, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the git URL %s of Environment %s", gitURL, envName)
+	}
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	ref := sourceEnv.Spec.Source.Ref
+	if ref == "" {
+		ref = "master"
+	}
+	requirements, err := environments.LoadRequirements(provider, gitInfo.Organisation, gitInfo.Name, ref)
+	if err != nil {
+		return err
+	}
+	if requirements == nil {
+		return fmt.Errorf("the git repository %s of Environment %s has no %s", gitURL, envName, environments.RequirementsPath)
+	}
+	values, err := environments.LoadValues(provider, gitInfo.Organisation, gitInfo.Name, ref)
+	if err != nil {
+		return err
+	}
+	valuesDir, err := ioutil.TempDir("", "preview-dependencies-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory for the values of the preview dependencies")
+	}
+	defer os.RemoveAll(valuesDir)
+
+	var releaseNames []string
+	for _, app := range dependencies.Apps {
+		if app == o.Application {
+			continue
+		}
+		dep := environments.FindDependency(requirements, app)
+		if dep == nil {
+			return fmt.Errorf("the app %s of the preview dependencies is not in %s of Environment %s", app, environments.RequirementsPath, envName)
+		}
+		// use the same values as the environment so the dependency is configured like it is there
+		var valueFiles []string
+		depValues := environments.DependencyValues(values, dep)
+		if len(depValues) > 0 {
+			data, err := yaml.Marshal(depValues)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal the values of %s from Environment %s", app, envName)
+			}
+			valuesFile := filepath.Join(valuesDir, app+"-"+helm.ValuesFileName)
+			err = ioutil.WriteFile(valuesFile, data, util.DefaultWritePermissions)
+			if err != nil {
+				return errors.Wrapf(err, "failed to save the values of %s to %s", app, valuesFile)
+			}
+			valueFiles = append(valueFiles, valuesFile)
+		}
+		releaseName := o.Namespace + "-" + app
+		log.Logger().Infof("Deploying %s version %s from Environment %s into the preview environment", util.ColorInfo(app), util.ColorInfo(dep.Version), util.ColorInfo(envName))
+		err = o.InstallChartWithOptions(helm.InstallChartOptions{
+			ReleaseName: releaseName,
+			Chart:       dep.Name,
+			Version:     dep.Version,
+			Repository:  dep.Repository,
+			Ns:          o.Namespace,
+			ValueFiles:  valueFiles,
+			Wait:        true,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to deploy %s version %s into the preview environment", app, dep.Version)
+		}
+		releaseNames = append(releaseNames, releaseName)
+	}
+	if len(releaseNames) == 0 {
+		return nil
+	}
+
+	environmentsResource := jxClient.JenkinsV1().Environments(ns)
+	env, err := environmentsResource.Get(o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if env.Annotations == nil {
+		env.Annotations = map[string]string{}
+	}
+	env.Annotations[kube.AnnotationPreviewDependencyReleases] = strings.Join(releaseNames, ",")
+	_, err = environmentsResource.PatchUpdate(env)
+	if err != nil {
+		return fmt.Errorf("Failed to update Environment %s due to %s", o.Name, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package promote
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -31,19 +32,24 @@ import (
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/jenkins-x/jx/pkg/signing"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	optionPullRequestPollTime = "pull-request-poll-time"
+	optionPullRequestPollTime  = "pull-request-poll-time"
+	optionFreezeOverrideReason = "freeze-override-reason"
+	optionWaitForFreeze        = "wait-for-freeze"
 
 	GitStatusSuccess = "success"
 )
 
 var (
 	waitAfterPullRequestCreated = time.Second * 3
+	freezePollTime              = time.Minute
 )
 
 // PromoteOptions containers the CLI options
@@ -70,6 +76,9 @@ type PromoteOptions struct {
 	PullRequestPollTime     string
 	Filter                  string
 	Alias                   string
+	FreezeOverrideReason    string
+	WaitForFreeze           bool
+	ResumeEnvironment       string
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -80,6 +89,9 @@ type PromoteOptions struct {
 	releaseResource         *v1.Release
 	ReleaseInfo             *ReleaseInfo
 	prow                    bool
+	freezeChecked           map[string]bool
+	freezeOverridden        map[string]bool
+	signedImages            map[string][]signing.ImageReference
 }
 
 type ReleaseInfo struct {
@@ -93,6 +105,12 @@ var (
 	promote_long = templates.LongDesc(`
 		Promotes a version of an application to zero to many permanent environments.
 
+		When promoting to all the automatic environments, an environment with a promotion policy is only promoted to once its gates pass:
+		the version must have soaked in the previous environment, the required test pipelines must have succeeded since it was promoted there
+		and the Prometheus queries must be within their thresholds. The evaluation of each gate is recorded in the Promote step of the PipelineActivity.
+		If the gates have not passed yet the promotion is queued rather than blocking the pipeline, and is resumed by 'jx controller promotion'
+		once they pass.
+
 		For more documentation see: [https://jenkins-x.io/about/features/#promotion](https://jenkins-x.io/about/features/#promotion)
 
 `)
@@ -161,6 +179,9 @@ func (o *PromoteOptions) AddPromoteOptions(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.NoPoll, "no-poll", "", false, "Disables polling for Pull Request or Pipeline status")
 	cmd.Flags().BoolVarP(&o.NoWaitAfterMerge, "no-wait", "", false, "Disables waiting for completing promotion after the Pull request is merged")
 	cmd.Flags().BoolVarP(&o.IgnoreLocalFiles, "ignore-local-file", "", false, "Ignores the local file system when deducing the Git repository")
+	cmd.Flags().StringVarP(&o.FreezeOverrideReason, optionFreezeOverrideReason, "", "", "Promotes even if the Environment is frozen. The reason is recorded on the PipelineActivity and as a trailer of the commit message so that the Environment pipeline applies the promotion")
+	cmd.Flags().BoolVarP(&o.WaitForFreeze, optionWaitForFreeze, "", false, "Waits for any freeze of the Environment to end rather than failing")
+	cmd.Flags().StringVarP(&o.ResumeEnvironment, "resume-env", "", "", "When promoting to all automatic environments resumes a queued promotion from this Environment, skipping the environments before it")
 }
 
 func (o *PromoteOptions) hasApplicationFlag() bool {
@@ -376,6 +397,12 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 	}
 	kube.SortEnvironments(environments)
 
+	// the environment promoted to before the current one whose policy gates are evaluated against
+	gates := &promotion.GateContext{
+		App:     o.Application,
+		Version: o.Version,
+	}
+	resuming := o.ResumeEnvironment != ""
 	for _, env := range environments {
 		kind := env.Spec.Kind
 		if env.Spec.PromotionStrategy == v1.PromotionStrategyTypeAutomatic && kind.IsPermanent() {
@@ -383,6 +410,28 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 			if ns == "" {
 				return fmt.Errorf("No namespace for environment %s", env.Name)
 			}
+			if resuming && env.Name != o.ResumeEnvironment {
+				// the version was promoted to this environment before the promotion was queued
+				promoted, err := o.promotedTimestamp(&env)
+				if err != nil {
+					return err
+				}
+				gates.PreviousEnvironment = env.Name
+				gates.PreviousNamespace = ns
+				gates.PreviousPromoted = promoted
+				continue
+			}
+			resuming = false
+			if env.Spec.PromotionPolicy != nil {
+				passed, err := o.CheckPromotionGates(&env, gates)
+				if err != nil {
+					return err
+				}
+				if !passed {
+					log.Logger().Infof("The promotion to environment %s is queued until its promotion policy gates pass. It is resumed by 'jx controller promotion'", util.ColorInfo(env.Name))
+					return nil
+				}
+			}
 			releaseInfo, err := o.Promote(ns, &env, false)
 			if err != nil {
 				return err
@@ -394,11 +443,270 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 					return err
 				}
 			}
+			gates.PreviousEnvironment = env.Name
+			gates.PreviousNamespace = ns
+			gates.PreviousPromoted = time.Now()
+		}
+	}
+	if resuming {
+		return fmt.Errorf("cannot resume the promotion as there is no automatic environment %s", o.ResumeEnvironment)
+	}
+	return nil
+}
+
+// CheckPromotionGates evaluates the gates of the PromotionPolicy of the environment once, recording the results in the
+// Promote step of the PipelineActivity so that a pending promotion can be resumed by the promotion controller rather
+// than blocking the pipeline for the soak time. An error is returned if the gates have not passed within the timeout
+// of the policy since the Promote step started
+func (o *PromoteOptions) CheckPromotionGates(env *v1.Environment, gates *promotion.GateContext) (bool, error) {
+	policy := env.Spec.PromotionPolicy
+	timeout, err := promotion.PolicyTimeout(policy)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid promotion policy for environment %s", env.Name)
+	}
+	if policy.PrometheusURL != "" && gates.Metrics == nil {
+		gates.Metrics = promotion.NewPrometheusClient(policy.PrometheusURL)
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return false, err
+	}
+	if o.Activities == nil {
+		o.Activities = jxClient.JenkinsV1().PipelineActivities(ns)
+	}
+
+	var activity *v1.PipelineActivity
+	var ps *v1.PromoteActivityStep
+	promoteKey := o.CreatePromoteKey(env)
+	if promoteKey.IsValid() {
+		activity, _, ps, _, err = promoteKey.GetOrCreatePromote(jxClient, o.Namespace)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get the PipelineActivity %s", promoteKey.Name)
+		}
+		if gates.Released.IsZero() {
+			gates.Released = activity.CreationTimestamp.Time
+			if activity.Spec.StartedTimestamp != nil {
+				gates.Released = activity.Spec.StartedTimestamp.Time
+			}
+		}
+	}
+	if len(policy.Tests) > 0 {
+		list, err := o.Activities.List(metav1.ListOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "failed to list the PipelineActivities")
+		}
+		gates.Activities = list.Items
+	}
+	gates.Now = time.Now()
+	results, err := promotion.EvaluatePolicy(policy, gates)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid promotion policy for environment %s", env.Name)
+	}
+	passed := promotion.GatesPassed(results)
+	log.Logger().Infof("Promotion gates for environment %s: %s", util.ColorInfo(env.Name), promotion.GatesSummary(results))
+
+	if ps == nil {
+		if !passed {
+			log.Logger().Warnf("No PipelineActivity found so the promotion to environment %s cannot be resumed when its gates pass", env.Name)
+		}
+		return passed, nil
+	}
+	expired := !passed && ps.StartedTimestamp != nil && gates.Now.After(ps.StartedTimestamp.Add(timeout))
+	ps.Gates = results
+	if expired {
+		ps.Status = v1.ActivityStatusTypeFailed
+		ps.CompletedTimestamp = &metav1.Time{
+			Time: gates.Now,
+		}
+	} else if ps.Status == v1.ActivityStatusTypeNone {
+		ps.Status = v1.ActivityStatusTypePending
+	}
+	_, err = jxClient.JenkinsV1().PipelineActivities(o.Namespace).PatchUpdate(activity)
+	if err != nil {
+		log.Logger().Warnf("Failed to update PipelineActivity: %s", err)
+	}
+	if expired {
+		return false, fmt.Errorf("the promotion policy gates for environment %s did not pass within %s: %s", env.Name, timeout.String(), promotion.GatesSummary(results))
+	}
+	return passed, nil
+}
+
+// promotedTimestamp returns when the version was promoted to the environment from its Promote step
+func (o *PromoteOptions) promotedTimestamp(env *v1.Environment) (time.Time, error) {
+	promoteKey := o.CreatePromoteKey(env)
+	if !promoteKey.IsValid() {
+		return time.Time{}, fmt.Errorf("cannot find the PipelineActivity of the promotion to environment %s", env.Name)
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, _, ps, created, err := promoteKey.GetOrCreatePromote(jxClient, o.Namespace)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get the PipelineActivity %s", promoteKey.Name)
+	}
+	if created || ps.StartedTimestamp == nil {
+		return time.Time{}, fmt.Errorf("version %s has not been promoted to environment %s", o.Version, env.Name)
+	}
+	if ps.CompletedTimestamp != nil {
+		return ps.CompletedTimestamp.Time, nil
+	}
+	return ps.StartedTimestamp.Time, nil
+}
+
+// CheckFreeze returns an error if the environment is frozen unless the freeze is overridden with a reason, which is
+// recorded on the PipelineActivity, or we are waiting for the freeze to end
+func (o *PromoteOptions) CheckFreeze(env *v1.Environment) error {
+	if env == nil || len(env.Spec.Freezes) == 0 || o.freezeChecked[env.Name] {
+		return nil
+	}
+	waiting := false
+	for {
+		freeze, err := promotion.FindActiveFreeze(env, time.Now())
+		if err != nil {
+			return err
+		}
+		if freeze == nil {
+			break
+		}
+		if o.FreezeOverrideReason != "" {
+			log.Logger().Warnf("Overriding the freeze as %s", freeze.String())
+			jxClient, _, err := o.JXClient()
+			if err != nil {
+				return err
+			}
+			promoteKey := o.CreatePromoteKey(env)
+			err = promoteKey.RecordFreezeOverride(jxClient, o.Namespace, v1.PromotionFreezeOverride{
+				Environment: env.Name,
+				Freeze:      freeze.Name,
+				Reason:      o.FreezeOverrideReason,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to record the override of the freeze %s on the PipelineActivity", freeze.Name)
+			}
+			if o.freezeOverridden == nil {
+				o.freezeOverridden = map[string]bool{}
+			}
+			o.freezeOverridden[env.Name] = true
+			break
+		}
+		if !o.WaitForFreeze {
+			return fmt.Errorf("cannot promote as %s. Use --%s to promote anyway or --%s to wait for the freeze to end",
+				freeze.String(), optionFreezeOverrideReason, optionWaitForFreeze)
+		}
+		if !waiting {
+			log.Logger().Infof("Waiting for the freeze to end as %s", freeze.String())
+			waiting = true
+		}
+		wait := time.Until(freeze.End)
+		if wait > freezePollTime {
+			wait = freezePollTime
+		}
+		time.Sleep(wait)
+
+		// lets reload the environment in case the freeze has been lifted
+		jxClient, ns, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		name := env.Name
+		env, err = jxClient.JenkinsV1().Environments(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to reload the Environment %s", name)
+		}
+	}
+	if o.freezeChecked == nil {
+		o.freezeChecked = map[string]bool{}
+	}
+	o.freezeChecked[env.Name] = true
+	return nil
+}
+
+// AddFreezeOverride adds the reason for overriding the freeze of the environment to the message of the Pull Request
+// as a commit trailer so that the pipeline of the environment applies the promotion during the freeze
+func (o *PromoteOptions) AddFreezeOverride(env *v1.Environment, details *gits.PullRequestDetails) {
+	if env != nil && o.freezeOverridden[env.Name] {
+		details.Message = promotion.AddFreezeOverrideTrailer(details.Message, o.FreezeOverrideReason)
+	}
+}
+
+// CheckSignatures returns an error unless the chart of the version being promoted, and its images, have been signed
+// by a key of the trust policy of the environment. The digest of the chart in the helm repository must be the signed
+// digest and the images its values deploy are resolved to their signed digests, so that they are deployed by digest
+// when the chart is installed. If no version is specified the latest version is resolved first so that the version
+// which is verified is the version which is promoted
+func (o *PromoteOptions) CheckSignatures(env *v1.Environment) error {
+	if env == nil || env.Spec.TrustPolicy == nil {
+		return nil
+	}
+	if _, ok := o.signedImages[env.Name]; ok {
+		return nil
+	}
+	verifier, err := signing.NewVerifier(env.Spec.TrustPolicy)
+	if err != nil {
+		return errors.Wrapf(err, "invalid trust policy of environment %s", env.Name)
+	}
+	app := o.Application
+	var images []signing.ImageReference
+	if !verifier.IsExcluded(app) {
+		if o.Version == "" {
+			o.Version, err = o.findLatestVersion(app)
+			if err != nil {
+				return err
+			}
+		}
+		dir, err := ioutil.TempDir("", "jx-promote-chart-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		archive, err := o.fetchChartArchive(app, o.Version, dir)
+		if err != nil {
+			return err
+		}
+		digest, err := signing.FileDigest(archive)
+		if err != nil {
+			return err
+		}
+		images, err = signing.ChartImages(archive, nil)
+		if err != nil {
+			return err
+		}
+		jxClient, ns, err := o.JXClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		releases, err := jxClient.JenkinsV1().Releases(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list the Releases in namespace %s", ns)
 		}
+		release := signing.FindSignedRelease(releases.Items, app, o.Version)
+		images, err = verifier.VerifyRelease(release, app, o.Version, digest, images)
+		if err != nil {
+			return errors.Wrapf(err, "cannot promote %s version %s to environment %s as it violates the trust policy", app, o.Version, env.Name)
+		}
+		log.Logger().Infof("Verified the signatures of %s version %s", util.ColorInfo(app), util.ColorInfo(o.Version))
+	}
+	if o.signedImages == nil {
+		o.signedImages = map[string][]signing.ImageReference{}
 	}
+	o.signedImages[env.Name] = images
 	return nil
 }
 
+// fetchChartArchive downloads the archive of the chart version from the helm repository into the directory
+func (o *PromoteOptions) fetchChartArchive(app string, version string, dir string) (string, error) {
+	helmer := o.Helm()
+	// without --untar the archive is downloaded into the current directory of helm
+	helmer.SetCWD(dir)
+	defer helmer.SetCWD("")
+	err := helmer.FetchChart(app, version, false, "", o.HelmRepositoryURL, "", "")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch chart %s version %s from %s", app, version, o.HelmRepositoryURL)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.tgz", app, version)), nil
+}
+
 func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAuto bool) (*ReleaseInfo, error) {
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
 	app := o.Application
@@ -445,6 +753,18 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		}
 	}
 
+	err := o.CheckFreeze(env)
+	if err != nil {
+		return releaseInfo, err
+	}
+	err = o.CheckSignatures(env)
+	if err != nil {
+		return releaseInfo, err
+	}
+	// the latest version may have been resolved when checking the signatures
+	version = o.Version
+	releaseInfo.Version = version
+
 	jxClient, _, err := o.JXClient()
 	if err != nil {
 		return releaseInfo, err
@@ -512,6 +832,10 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		NoForce:     true,
 		Wait:        true,
 	}
+	if env != nil {
+		// deploy the images by the digests which were signed
+		helmOptions.SetValues = signing.PinnedSetValues(o.signedImages[env.Name])
+	}
 	err = o.InstallChartWithOptions(helmOptions)
 	if err == nil {
 		err = o.CommentOnIssues(targetNS, env, promoteKey)
@@ -526,8 +850,7 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 }
 
 func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo *ReleaseInfo) error {
-	version := o.Version
-	versionName := version
+	versionName := o.Version
 	if versionName == "" {
 		versionName = "latest"
 	}
@@ -538,6 +861,23 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 		Title:      "chore: " + app + " to " + versionName,
 		Message:    fmt.Sprintf("chore: Promote %s to version %s", app, versionName),
 	}
+	return o.PromoteViaPullRequestWithDetails(env, releaseInfo, &details)
+}
+
+// PromoteViaPullRequestWithDetails creates or updates a Pull Request on the environment git repository which changes
+// the version of the application using the given branch name, title and message
+func (o *PromoteOptions) PromoteViaPullRequestWithDetails(env *v1.Environment, releaseInfo *ReleaseInfo, details *gits.PullRequestDetails) error {
+	err := o.CheckFreeze(env)
+	if err != nil {
+		return err
+	}
+	err = o.CheckSignatures(env)
+	if err != nil {
+		return err
+	}
+	o.AddFreezeOverride(env, details)
+	version := o.Version
+	app := o.Application
 
 	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
 		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
@@ -569,7 +909,7 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 	if releaseInfo.PullRequestInfo != nil && releaseInfo.PullRequestInfo.PullRequest != nil {
 		filter.Number = releaseInfo.PullRequestInfo.PullRequest.Number
 	}
-	info, err := options.Create(env, environmentsDir, &details, filter, "", true)
+	info, err := options.Create(env, environmentsDir, details, filter, "", true)
 	releaseInfo.PullRequestInfo = info
 	return err
 }
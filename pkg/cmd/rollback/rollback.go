@@ -0,0 +1,286 @@
+package rollback
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	optionToVersion = "to-version"
+)
+
+var (
+	rollbackLong = templates.LongDesc(`
+		Rolls back an application in a GitOps Environment to a previous version.
+
+		The previous versions are found in the history of the requirements of the Environment git repository, falling back to the Release resources in the Environment namespace.
+		Unless a version is specified the newest previous version which was successfully promoted to the Environment is used.
+
+		A Pull Request is created on the Environment git repository pinning the version in the same way as 'jx promote' and the rollback is recorded on the PipelineActivity of the version being rolled back.
+`)
+
+	rollbackExample = templates.Examples(`
+		# Roll back myapp in staging to the previous good version
+		jx rollback myapp --env staging
+
+		# Roll back myapp in production to a specific version
+		jx rollback myapp --env production --to-version 1.2.3 --reason "memory leak in 1.2.4"
+	`)
+)
+
+// RollbackOptions the options for the rollback command
+type RollbackOptions struct {
+	promote.PromoteOptions
+
+	ToVersion  string
+	Reason     string
+	MaxCommits int
+}
+
+// NewCmdRollback creates the command for: jx rollback
+func NewCmdRollback(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RollbackOptions{
+		PromoteOptions: promote.PromoteOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "rollback [application]",
+		Short:   "Rolls back an application in an Environment to a previous version",
+		Long:    rollbackLong,
+		Example: rollbackExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The Environment to roll back the application in")
+	cmd.Flags().StringVarP(&options.ToVersion, optionToVersion, "", "", "The version to roll back to. Defaults to the newest previous version which was successfully promoted to the Environment")
+	cmd.Flags().StringVarP(&options.Reason, "reason", "r", "", "The reason for the rollback which is recorded on the PipelineActivity")
+	cmd.Flags().StringVarP(&options.FreezeOverrideReason, "freeze-override-reason", "", "", "Roll back even if the Environment is frozen, recording this reason on the PipelineActivity and as a trailer of the commit message so that the Environment pipeline applies the rollback")
+	cmd.Flags().StringVarP(&options.HelmRepositoryURL, "helm-repo-url", "u", "", "The Helm Repository URL to use for the App")
+	cmd.Flags().IntVarP(&options.MaxCommits, "max-commits", "", 50, "The maximum number of commits of the Environment git repository to search for previous versions")
+	return cmd
+}
+
+// Run implements the command
+func (o *RollbackOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("application")
+	}
+	app := o.Args[0]
+	if o.Environment == "" {
+		return util.MissingOption(opts.OptionEnvironment)
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	env, err := jxClient.JenkinsV1().Environments(ns).Get(o.Environment, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the Environment %s", o.Environment)
+	}
+	if env.Spec.Source.URL == "" {
+		return fmt.Errorf("the Environment %s has no git repository so cannot be rolled back via a Pull Request", env.Name)
+	}
+	if o.HelmRepositoryURL == "" {
+		o.HelmRepositoryURL = o.DefaultChartRepositoryURL()
+	}
+
+	history, err := o.versionHistory(env, app)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("could not find any versions of %s in the Environment %s", app, env.Name)
+	}
+	currentVersion := history[0].Version
+
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list PipelineActivities in namespace %s", ns)
+	}
+
+	toVersion := o.ToVersion
+	if toVersion == "" {
+		for _, previous := range history[1:] {
+			activity := findActivity(activities.Items, app, previous.Version)
+			if activity == nil || promotedTo(activity, env.Name) {
+				toVersion = previous.Version
+				break
+			}
+			log.Logger().Infof("Skipping version %s as it was not successfully promoted to %s", util.ColorInfo(previous.Version), util.ColorInfo(env.Name))
+		}
+		if toVersion == "" {
+			return fmt.Errorf("could not find a previous good version of %s in the Environment %s. Please specify one via --%s", app, env.Name, optionToVersion)
+		}
+	}
+	if trimVersion(toVersion) == trimVersion(currentVersion) {
+		return fmt.Errorf("%s is already at version %s in the Environment %s", app, currentVersion, env.Name)
+	}
+
+	if !o.BatchMode {
+		message := fmt.Sprintf("Roll back %s in %s from %s to %s?", app, env.Name, currentVersion, toVersion)
+		if !util.Confirm(message, true, "Creates a Pull Request on the Environment git repository which pins the previous version", o.GetIOFileHandles()) {
+			return nil
+		}
+	}
+
+	original := findActivity(activities.Items, app, currentVersion)
+	message := fmt.Sprintf("chore: Roll back %s from version %s to %s", app, currentVersion, toVersion)
+	if original != nil {
+		message += fmt.Sprintf("\n\nRolls back the release of PipelineActivity %s", original.Name)
+		if original.Spec.BuildURL != "" {
+			message += fmt.Sprintf(" (%s)", original.Spec.BuildURL)
+		}
+	}
+	if o.Reason != "" {
+		message += "\n\n" + o.Reason
+	}
+	details := &gits.PullRequestDetails{
+		BranchName: "rollback-" + app + "-" + toVersion,
+		Title:      "chore: rollback " + app + " to " + toVersion,
+		Message:    message,
+	}
+
+	o.Application = app
+	o.Version = toVersion
+	o.Namespace = ns
+	o.IgnoreLocalFiles = true
+	if original != nil {
+		// lets record any freeze override on the activity being rolled back
+		o.Pipeline = original.Spec.Pipeline
+		o.Build = original.Spec.Build
+	}
+	releaseInfo := &promote.ReleaseInfo{
+		ReleaseName: env.Spec.Namespace + "-" + app,
+		FullAppName: app,
+		Version:     toVersion,
+	}
+	err = o.PromoteViaPullRequestWithDetails(env, releaseInfo, details)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the rollback Pull Request for %s in %s", app, env.Name)
+	}
+	prURL := ""
+	if releaseInfo.PullRequestInfo != nil && releaseInfo.PullRequestInfo.PullRequest != nil {
+		prURL = releaseInfo.PullRequestInfo.PullRequest.URL
+	}
+	log.Logger().Infof("Created Pull Request %s to roll back %s in %s to %s", util.ColorInfo(prURL), util.ColorInfo(app), util.ColorInfo(env.Name), util.ColorInfo(toVersion))
+
+	if original == nil {
+		log.Logger().Warnf("Could not find the PipelineActivity of %s version %s to record the rollback on", app, currentVersion)
+		return nil
+	}
+	rolledBackBy, err := o.GetUsername("")
+	if err != nil {
+		log.Logger().Warnf("Failed to find the current user: %s", err)
+	}
+	original.Spec.Rollbacks = append(original.Spec.Rollbacks, v1.PipelineActivityRollback{
+		Environment:    env.Name,
+		ToVersion:      toVersion,
+		PullRequestURL: prURL,
+		Reason:         o.Reason,
+		RolledBackBy:   rolledBackBy,
+		Timestamp:      &metav1.Time{Time: time.Now()},
+	})
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(original)
+	if err != nil {
+		return errors.Wrapf(err, "failed to record the rollback on PipelineActivity %s", original.Name)
+	}
+	return nil
+}
+
+// versionHistory returns the versions of the app in the environment newest first using the history of the environment
+// git repository or the releases in the environment namespace if the history is not available
+func (o *RollbackOptions) versionHistory(env *v1.Environment, app string) ([]environments.AppVersion, error) {
+	gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the git URL %s of Environment %s", env.Spec.Source.URL, env.Name)
+	}
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err == nil {
+		history, err := environments.AppVersionHistory(provider, gitInfo.Organisation, gitInfo.Name, app, o.MaxCommits)
+		if err == nil && len(history) > 0 {
+			return history, nil
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to find the history of %s in %s: %s", app, env.Spec.Source.URL, err)
+		}
+	} else {
+		log.Logger().Warnf("Failed to create the git provider for %s: %s", env.Spec.Source.URL, err)
+	}
+
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := jxClient.JenkinsV1().Releases(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the Releases in namespace %s", env.Spec.Namespace)
+	}
+	var matches []v1.Release
+	for _, release := range releases.Items {
+		if release.Spec.Name == app || release.Spec.GitRepository == app {
+			matches = append(matches, release)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[j].CreationTimestamp.Before(&matches[i].CreationTimestamp)
+	})
+	var answer []environments.AppVersion
+	for _, release := range matches {
+		version := trimVersion(release.Spec.Version)
+		if version == "" || (len(answer) > 0 && answer[len(answer)-1].Version == version) {
+			continue
+		}
+		answer = append(answer, environments.AppVersion{Version: version})
+	}
+	return answer, nil
+}
+
+// findActivity returns the newest activity which released the version of the app or nil if there is none
+func findActivity(activities []v1.PipelineActivity, app string, version string) *v1.PipelineActivity {
+	var answer *v1.PipelineActivity
+	for i := range activities {
+		a := &activities[i]
+		if a.RepositoryName() != app || a.Spec.Version == "" || trimVersion(a.Spec.Version) != trimVersion(version) {
+			continue
+		}
+		if answer == nil || answer.CreationTimestamp.Before(&a.CreationTimestamp) {
+			answer = a
+		}
+	}
+	return answer
+}
+
+// promotedTo returns true if the activity successfully promoted to the environment
+func promotedTo(activity *v1.PipelineActivity, envName string) bool {
+	for _, step := range activity.Spec.Steps {
+		promote := step.Promote
+		if promote != nil && promote.Environment == envName && promote.Status == v1.ActivityStatusTypeSucceeded {
+			return true
+		}
+	}
+	return false
+}
+
+func trimVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/bdd"
 	"github.com/jenkins-x/jx/pkg/cmd/step/boot"
 	"github.com/jenkins-x/jx/pkg/cmd/step/buildpack"
+	"github.com/jenkins-x/jx/pkg/cmd/step/cache"
 	"github.com/jenkins-x/jx/pkg/cmd/step/cluster"
 	"github.com/jenkins-x/jx/pkg/cmd/step/create"
 	"github.com/jenkins-x/jx/pkg/cmd/step/e2e"
@@ -22,10 +23,14 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/pr"
 	"github.com/jenkins-x/jx/pkg/cmd/step/pre"
 	"github.com/jenkins-x/jx/pkg/cmd/step/report"
+	"github.com/jenkins-x/jx/pkg/cmd/step/scan"
 	"github.com/jenkins-x/jx/pkg/cmd/step/scheduler"
+	"github.com/jenkins-x/jx/pkg/cmd/step/secrets"
 	"github.com/jenkins-x/jx/pkg/cmd/step/syntax"
 	"github.com/jenkins-x/jx/pkg/cmd/step/update"
 	"github.com/jenkins-x/jx/pkg/cmd/step/verify"
+	"github.com/jenkins-x/jx/pkg/cmd/step/versionstream"
+	"github.com/jenkins-x/jx/pkg/cmd/step/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -50,6 +55,7 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(boot.NewCmdStepBoot(commonOpts))
 	cmd.AddCommand(buildpack.NewCmdStepBuildPack(commonOpts))
 	cmd.AddCommand(bdd.NewCmdStepBDD(commonOpts))
+	cmd.AddCommand(cache.NewCmdStepCache(commonOpts))
 	cmd.AddCommand(e2e.NewCmdStepE2E(commonOpts))
 	cmd.AddCommand(step.NewCmdStepBlog(commonOpts))
 	cmd.AddCommand(step.NewCmdStepChangelog(commonOpts))
@@ -72,17 +78,22 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(post.NewCmdStepPost(commonOpts))
 	cmd.AddCommand(step.NewCmdStepRelease(commonOpts))
 	cmd.AddCommand(step.NewCmdStepReplicate(commonOpts))
+	cmd.AddCommand(step.NewCmdStepSign(commonOpts))
 	cmd.AddCommand(step.NewCmdStepSplitMonorepo(commonOpts))
 	cmd.AddCommand(syntax.NewCmdStepSyntax(commonOpts))
 	cmd.AddCommand(step.NewCmdStepTag(commonOpts))
 	cmd.AddCommand(step.NewCmdStepValidate(commonOpts))
 	cmd.AddCommand(verify.NewCmdStepVerify(commonOpts))
+	cmd.AddCommand(versionstream.NewCmdStepVersionStream(commonOpts))
+	cmd.AddCommand(webhook.NewCmdStepWebhook(commonOpts))
 	cmd.AddCommand(step.NewCmdStepWaitForArtifact(commonOpts))
 	cmd.AddCommand(step.NewCmdStepWaitForChart(commonOpts))
 	cmd.AddCommand(step.NewCmdStepStash(commonOpts))
 	cmd.AddCommand(step.NewCmdStepUnstash(commonOpts))
 	cmd.AddCommand(step.NewCmdStepValuesSchemaTemplate(commonOpts))
+	cmd.AddCommand(scan.NewCmdStepScan(commonOpts))
 	cmd.AddCommand(scheduler.NewCmdStepScheduler(commonOpts))
+	cmd.AddCommand(secrets.NewCmdStepSecrets(commonOpts))
 	cmd.AddCommand(config.NewCmdStepPatchConfigMap(commonOpts))
 	cmd.AddCommand(update.NewCmdStepUpdate(commonOpts))
 	cmd.AddCommand(report.NewCmdStepReport(commonOpts))
@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheStorageOptions contains the flags shared by the cache save and restore commands
+type StepCacheStorageOptions struct {
+	step.StepOptions
+
+	Name            string
+	Inputs          []string
+	Paths           []string
+	Dir             string
+	StorageLocation v1.StorageLocation
+}
+
+func (o *StepCacheStorageOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Name, "name", "n", "", "The name of the cache")
+	cmd.Flags().StringArrayVarP(&o.Inputs, "input", "i", nil, "The patterns of the files whose contents are used to create the cache key. Patterns starting with '**/' match in any directory")
+	cmd.Flags().StringArrayVarP(&o.Paths, "path", "p", nil, "The directories to cache. Relative paths are relative to the --dir directory and paths starting with ~/ are in the home directory")
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", "", "The directory the input patterns and paths are relative to. Defaults to the current directory")
+	cmd.Flags().StringVarP(&o.StorageLocation.BucketURL, "bucket-url", "", "", "Specify the cloud storage bucket URL to store the cache in. e.g. use 's3://nameOfBucket' on AWS, gs://anotherBucket' on GCP or on Azure 'azblob://thatBucket'")
+}
+
+func (o *StepCacheStorageOptions) validate() error {
+	if o.Name == "" {
+		return util.MissingOption("name")
+	}
+	if len(o.Inputs) == 0 {
+		return util.MissingOption("input")
+	}
+	if len(o.Paths) == 0 {
+		return util.MissingOption("path")
+	}
+	if o.Dir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		o.Dir = dir
+	}
+	return nil
+}
+
+// storageLocation returns the storage location for caches, defaulting to the team settings. Caches are only stored in
+// cloud storage buckets as every new cache key would add a large archive to the history of a git repository
+func (o *StepCacheStorageOptions) storageLocation() (v1.StorageLocation, error) {
+	if !o.StorageLocation.IsEmpty() {
+		return o.StorageLocation, nil
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return o.StorageLocation, err
+	}
+	location := settings.StorageLocationOrDefault(kube.ClassificationCache)
+	if location.BucketURL == "" {
+		return location, errors.Errorf("caches can only be stored in a cloud storage bucket, configure one with: jx edit storage -c %s --bucket-url s3://nameOfBucket", kube.ClassificationCache)
+	}
+	return location, nil
+}
+
+// resolvePaths returns the absolute directories of the cache paths, with relative paths resolved against the directory
+// and a leading ~ replaced with the home directory
+func resolvePaths(dir string, paths []string) []string {
+	answer := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = expandHome(p)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		answer = append(answer, p)
+	}
+	return answer
+}
+
+// storagePath returns the path in storage of the cache archive for the given key
+func (o *StepCacheStorageOptions) storagePath(key string) (string, error) {
+	gitInfo, err := o.FindGitInfo(o.Dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the git information in the directory %s", o.Dir)
+	}
+	return filepath.Join("jenkins-x", kube.ClassificationCache, gitInfo.Organisation, gitInfo.Name, o.Name, key+".tar.gz"), nil
+}
+
+// ComputeKey returns the key for the named cache, made up of the name and a hash of the contents of the files matching
+// the input patterns in the given directory. At least one file must match or the key would never change
+func ComputeKey(name string, dir string, inputs []string) (string, error) {
+	files, err := matchInputFiles(dir, inputs)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", errors.Errorf("no files in %s match the inputs %s", dir, strings.Join(inputs, ", "))
+	}
+
+	hash := sha256.New()
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read input file %s", f)
+		}
+		fmt.Fprintf(hash, "%s\n%d\n", f, len(data))
+		hash.Write(data)
+	}
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(hash.Sum(nil))[0:16]), nil
+}
+
+// matchInputFiles returns the sorted paths, relative to dir, of all the files matching the patterns
+func matchInputFiles(dir string, patterns []string) ([]string, error) {
+	var files []string
+	if len(patterns) == 0 {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range patterns {
+			if matchesInput(p, rel) {
+				files = append(files, rel)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find input files in %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// matchesInput returns true if the relative file name matches the pattern. A pattern starting with **/ matches the
+// rest of the pattern in any directory.
+func matchesInput(pattern string, name string) bool {
+	if strings.HasPrefix(pattern, "**/") {
+		rest := strings.TrimPrefix(pattern, "**/")
+		for {
+			if matched, _ := filepath.Match(rest, name); matched {
+				return true
+			}
+			idx := strings.Index(name, "/")
+			if idx < 0 {
+				return false
+			}
+			name = name[idx+1:]
+		}
+	}
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
+
+// expandHome replaces a leading ~ in the path with the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(util.HomeDir(), strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+// CreateArchive writes a gzipped tar of the given directories. The contents of each directory are stored under its
+// index in the list so that they can be restored to the same list of directories.
+func CreateArchive(w io.Writer, paths []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for i, root := range paths {
+		exists, err := util.DirExists(root)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				// keep symlinks such as the ones in node_modules/.bin rather than the files they point to
+				link, err = os.Readlink(path)
+				if err != nil {
+					return err
+				}
+			} else if !info.Mode().IsRegular() && !info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(filepath.Join(strconv.Itoa(i), rel))
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive %s", root)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ExtractArchive extracts an archive created by CreateArchive into the given directories
+func ExtractArchive(r io.Reader, paths []string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to read cache archive")
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read cache archive")
+		}
+
+		parts := strings.SplitN(header.Name, "/", 2)
+		idx, err := strconv.Atoi(parts[0])
+		if err != nil || idx < 0 || idx >= len(paths) {
+			continue
+		}
+		root := paths[idx]
+		target := root
+		if len(parts) > 1 {
+			target = filepath.Join(root, filepath.FromSlash(parts[1]))
+		}
+		if !isWithinDir(root, target) {
+			return errors.Errorf("invalid file %s in cache archive", header.Name)
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			err = extractSymlink(header, root, target)
+		} else {
+			err = util.UnTarFile(header, target, tr)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to extract %s", target)
+		}
+	}
+}
+
+// extractSymlink creates the symlink in the header, as long as it points to a file inside the root directory
+func extractSymlink(header *tar.Header, root string, target string) error {
+	if filepath.IsAbs(header.Linkname) || !isWithinDir(root, filepath.Join(filepath.Dir(target), header.Linkname)) {
+		return errors.Errorf("invalid link %s to %s in cache archive", header.Name, header.Linkname)
+	}
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(header.Linkname, target)
+}
+
+// isWithinDir returns true if the path is the directory or is inside it
+func isWithinDir(dir string, path string) bool {
+	dir = filepath.Clean(dir)
+	path = filepath.Clean(path)
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheOptions contains the command line flags
+type StepCacheOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepCache creates the command object for the "step cache" command
+func NewCmdStepCache(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCacheOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "cache [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepCacheRestore(commonOpts))
+	cmd.AddCommand(NewCmdStepCacheSave(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCacheOptions) Run() error {
+	return o.Cmd.Help()
+}
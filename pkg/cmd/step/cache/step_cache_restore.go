@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	stepcmd "github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheRestoreOptions contains the command line flags
+type StepCacheRestoreOptions struct {
+	StepCacheStorageOptions
+
+	Timeout time.Duration
+}
+
+var (
+	stepCacheRestoreLong = templates.LongDesc(`
+		This pipeline step restores cached directories saved by a previous build with the same cache key.
+
+		The cache key is made up of the cache name and a hash of the contents of the input files. If there is no cache
+		for the key the directories are left untouched.
+
+		In a pipeline this step runs in its own container, so only directories in the workspace or the home directory are shared with the other steps of the stage.
+
+		Caches are read from the cloud storage bucket of the 'cache' storage location, which is configured with 'jx edit storage'.
+` + helper.SeeAlsoText("jx step cache save", "jx edit storage"))
+
+	stepCacheRestoreExample = templates.Examples(`
+		# restore the local maven repository if the pom.xml files have not changed
+		jx step cache restore --name maven --input '**/pom.xml' --path ~/.m2/repository
+`)
+)
+
+// NewCmdStepCacheRestore creates the CLI command
+func NewCmdStepCacheRestore(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepCacheRestoreOptions{
+		StepCacheStorageOptions: StepCacheStorageOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restores cached directories saved by a previous build with the same cache key",
+		Long:    stepCacheRestoreLong,
+		Example: stepCacheRestoreExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.addFlags(cmd)
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "t", time.Minute*5, "The timeout period for downloading the cache")
+	return cmd
+}
+
+// Run runs the command
+func (o *StepCacheRestoreOptions) Run() error {
+	err := o.validate()
+	if err != nil {
+		return err
+	}
+	// failing to find the cache should never fail the build
+	key, err := ComputeKey(o.Name, o.Dir, o.Inputs)
+	if err != nil {
+		log.Logger().Warnf("failed to compute the key for cache %s: %s", o.Name, err)
+		return nil
+	}
+	location, err := o.storageLocation()
+	if err != nil {
+		log.Logger().Warnf("not restoring cache %s: %s", o.Name, err)
+		return nil
+	}
+	path, err := o.storagePath(key)
+	if err != nil {
+		return err
+	}
+	u, err := collector.StorageURL(location, path)
+	if err != nil {
+		return err
+	}
+
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	data, err := buckets.ReadURL(u, o.Timeout, stepcmd.CreateBucketHTTPFn(authSvc))
+	if err != nil {
+		// a cache miss should never fail the build
+		log.Logger().Infof("no cache found for key %s", util.ColorInfo(key))
+		log.Logger().Debugf("failed to read %s: %s", u, err)
+		return nil
+	}
+
+	err = ExtractArchive(bytes.NewReader(data), resolvePaths(o.Dir, o.Paths))
+	if err != nil {
+		return errors.Wrapf(err, "failed to restore cache %s", key)
+	}
+	log.Logger().Infof("restored cache %s from %s", util.ColorInfo(key), util.ColorInfo(u))
+	return nil
+}
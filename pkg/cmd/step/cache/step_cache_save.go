@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	stepcmd "github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheSaveOptions contains the command line flags
+type StepCacheSaveOptions struct {
+	StepCacheStorageOptions
+}
+
+var (
+	stepCacheSaveLong = templates.LongDesc(`
+		This pipeline step saves directories into the cache so that later builds with the same cache key can restore them.
+
+		The cache key is made up of the cache name and a hash of the contents of the input files. If a cache already
+		exists for the key it is not saved again. Failing to save the cache only logs a warning so that it does not fail the build.
+
+		In a pipeline this step runs in its own container, so only directories in the workspace or the home directory are shared with the other steps of the stage.
+
+		Caches are stored in the cloud storage bucket of the 'cache' storage location, which is configured with 'jx edit storage'.
+		They are never stored in a git repository as every new cache key would add a large archive to its history.
+` + helper.SeeAlsoText("jx step cache restore", "jx edit storage"))
+
+	stepCacheSaveExample = templates.Examples(`
+		# save the local maven repository keyed on the contents of the pom.xml files
+		jx step cache save --name maven --input '**/pom.xml' --path ~/.m2/repository
+`)
+)
+
+// NewCmdStepCacheSave creates the CLI command
+func NewCmdStepCacheSave(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepCacheSaveOptions{
+		StepCacheStorageOptions: StepCacheStorageOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "save",
+		Short:   "Saves directories into the cache for use by later builds",
+		Long:    stepCacheSaveLong,
+		Example: stepCacheSaveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run runs the command
+func (o *StepCacheSaveOptions) Run() error {
+	err := o.validate()
+	if err != nil {
+		return err
+	}
+	err = o.save()
+	if err != nil {
+		// failing to save the cache should never fail the build
+		log.Logger().Warnf("failed to save cache %s: %s", o.Name, err)
+	}
+	return nil
+}
+
+func (o *StepCacheSaveOptions) save() error {
+	key, err := ComputeKey(o.Name, o.Dir, o.Inputs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compute the key for cache %s", o.Name)
+	}
+	location, err := o.storageLocation()
+	if err != nil {
+		return err
+	}
+	path, err := o.storagePath(key)
+	if err != nil {
+		return err
+	}
+
+	u, err := collector.StorageURL(location, path)
+	if err != nil {
+		return err
+	}
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	if _, err := buckets.ReadURL(u, time.Second*20, stepcmd.CreateBucketHTTPFn(authSvc)); err == nil {
+		log.Logger().Infof("cache %s already exists so not saving it again", util.ColorInfo(key))
+		return nil
+	}
+
+	// the archive is written to a file as caches such as a maven repository can be too big to hold in memory
+	tmpDir, err := ioutil.TempDir("", "jx-step-cache-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	fileName := filepath.Join(tmpDir, filepath.Base(path))
+	err = o.createArchiveFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to archive cache %s", key)
+	}
+
+	coll, err := collector.NewCollector(location, o.Git())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the collector for storage settings %s", location.Description())
+	}
+	urls, err := coll.CollectFiles([]string{fileName}, filepath.Dir(path), tmpDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save cache %s", key)
+	}
+	log.Logger().Infof("saved cache %s to %s", util.ColorInfo(key), util.ColorInfo(strings.Join(urls, ", ")))
+	return nil
+}
+
+func (o *StepCacheSaveOptions) createArchiveFile(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	err = CreateArchive(f, resolvePaths(o.Dir, o.Paths))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-cache-key")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "module"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "module", "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("hello"), 0644))
+
+	key, err := ComputeKey("maven", dir, []string{"**/pom.xml"})
+	require.NoError(t, err)
+	assert.Regexp(t, "^maven-[0-9a-f]{16}$", key)
+
+	// files which are not inputs don't change the key
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("changed"), 0644))
+	unchanged, err := ComputeKey("maven", dir, []string{"**/pom.xml"})
+	require.NoError(t, err)
+	assert.Equal(t, key, unchanged)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "module", "pom.xml"), []byte("<project><dependencies/></project>"), 0644))
+	changed, err := ComputeKey("maven", dir, []string{"**/pom.xml"})
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed)
+
+	topLevelOnly, err := ComputeKey("maven", dir, []string{"pom.xml"})
+	require.NoError(t, err)
+	assert.NotEqual(t, changed, topLevelOnly)
+
+	// a key without any inputs would never change so the cache would never be refreshed
+	_, err = ComputeKey("maven", dir, []string{"**/package-lock.json"})
+	assert.Error(t, err)
+}
+
+func TestResolvePaths(t *testing.T) {
+	paths := resolvePaths("/workspace/source", []string{"node_modules", "~/.m2/repository", "/cache"})
+	assert.Equal(t, []string{
+		filepath.Join("/workspace/source", "node_modules"),
+		filepath.Join(util.HomeDir(), ".m2", "repository"),
+		"/cache",
+	}, paths)
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-cache-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo := filepath.Join(dir, "repository")
+	modules := filepath.Join(dir, "node_modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "org", "example"), 0755))
+	require.NoError(t, os.MkdirAll(modules, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "org", "example", "example.jar"), []byte("jar"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(modules, "index.js"), []byte("js"), 0644))
+
+	var data bytes.Buffer
+	require.NoError(t, CreateArchive(&data, []string{repo, filepath.Join(dir, "missing"), modules}))
+
+	restoreDir := filepath.Join(dir, "restored")
+	paths := []string{filepath.Join(restoreDir, "repository"), filepath.Join(restoreDir, "missing"), filepath.Join(restoreDir, "node_modules")}
+	require.NoError(t, ExtractArchive(&data, paths))
+
+	jar, err := ioutil.ReadFile(filepath.Join(paths[0], "org", "example", "example.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "jar", string(jar))
+	js, err := ioutil.ReadFile(filepath.Join(paths[2], "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "js", string(js))
+	_, err = os.Stat(paths[1])
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestArchiveKeepsSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-cache-symlinks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	modules := filepath.Join(dir, "node_modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(modules, "mocha", "bin"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(modules, ".bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(modules, "mocha", "bin", "mocha"), []byte("#!/usr/bin/env node"), 0755))
+	require.NoError(t, os.Symlink("../mocha/bin/mocha", filepath.Join(modules, ".bin", "mocha")))
+
+	var data bytes.Buffer
+	require.NoError(t, CreateArchive(&data, []string{modules}))
+
+	restored := filepath.Join(dir, "restored")
+	require.NoError(t, ExtractArchive(&data, []string{restored}))
+	link, err := os.Readlink(filepath.Join(restored, ".bin", "mocha"))
+	require.NoError(t, err)
+	assert.Equal(t, "../mocha/bin/mocha", link)
+	script, err := ioutil.ReadFile(filepath.Join(restored, ".bin", "mocha"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/usr/bin/env node", string(script))
+}
+
+func TestExtractArchiveRejectsFilesOutsideThePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-cache-traversal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	work := filepath.Join(dir, "work")
+	assert.Error(t, ExtractArchive(createTestArchive(t, &tar.Header{Name: "0/../workevil/file", Mode: 0644, Typeflag: tar.TypeReg}), []string{work}))
+	_, err = os.Stat(filepath.Join(dir, "workevil"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, ExtractArchive(createTestArchive(t, &tar.Header{Name: "0/link", Linkname: "../../etc", Typeflag: tar.TypeSymlink}), []string{work}))
+	assert.Error(t, ExtractArchive(createTestArchive(t, &tar.Header{Name: "0/link", Linkname: "/etc", Typeflag: tar.TypeSymlink}), []string{work}))
+}
+
+func createTestArchive(t *testing.T, header *tar.Header) io.Reader {
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(header))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return &buffer
+}
@@ -33,6 +33,7 @@ func NewCmdStepCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepCreateValues(commonOpts))
 	cmd.AddCommand(pr.NewCmdStepCreatePr(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateTemplatedConfig(commonOpts))
+	cmd.AddCommand(NewCmdStepCreateSBOM(commonOpts))
 	return cmd
 }
 
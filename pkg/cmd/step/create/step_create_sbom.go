@@ -0,0 +1,347 @@
+package create
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	stepcmd "github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/sbom"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/version"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultSBOMVersionFile = "VERSION"
+)
+
+// StepCreateSBOMOptions contains the command line flags
+type StepCreateSBOMOptions struct {
+	step.StepOptions
+
+	Dir             string
+	Name            string
+	Version         string
+	Image           string
+	ImageFile       string
+	Formats         []string
+	OutputDir       string
+	NoStore         bool
+	NoReleaseAsset  bool
+	NoRelease       bool
+	StorageLocation v1.StorageLocation
+}
+
+var (
+	stepCreateSBOMLong = templates.LongDesc(`
+		Creates a software bill of materials (SBOM) for a release in the CycloneDX and SPDX formats.
+
+		The SBOM lists the resolved versions of the go modules, maven dependencies and npm packages of the project including transitive dependencies along with the operating system packages installed in the image of the release.
+
+		Go modules are listed with 'go list -m all', falling back to the highest version of each module in go.sum when the modules cannot be listed. Maven dependencies of the compile and runtime scopes are listed with 'mvn dependency:list' so the go and mvn tools must be available for go and maven projects. npm packages are read from package-lock.json, skipping development dependencies. A package.json without a package-lock.json is skipped with a warning as its versions are not resolved.
+
+		Each document is stored in the storage location of the 'sbom' classification of the team, uploaded as an asset of the git provider release and referenced from the Release resource created by 'jx step changelog'.
+` + stepcmd.StorageSupportDescription + helper.SeeAlsoText("jx step changelog", "jx edit storage"))
+
+	stepCreateSBOMExample = templates.Examples(`
+		# Create the SBOM of the current release including the packages of its image
+		jx step create sbom --version $VERSION --image gcr.io/myorg/myapp:$VERSION
+
+		# Create a CycloneDX SBOM of the project dependencies only without storing or uploading it
+		jx step create sbom --version 1.0.1 --format cyclonedx --no-store --no-release-asset --no-release
+	`)
+)
+
+// NewCmdStepCreateSBOM creates the command
+func NewCmdStepCreateSBOM(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCreateSBOMOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sbom",
+		Short:   "Creates a software bill of materials for a release",
+		Long:    stepCreateSBOMLong,
+		Example: stepCreateSBOMExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of the project. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.Name, "name", "n", "", "The name of the application. Defaults to the name of the git repository")
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The version of the release. Defaults to the contents of the VERSION file")
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image of the release whose packages are included which is saved using 'docker save'")
+	cmd.Flags().StringVarP(&options.ImageFile, "image-file", "f", "", "The tarball of the image of the release in the format of 'docker save'")
+	cmd.Flags().StringArrayVarP(&options.Formats, "format", "", sbom.Formats, fmt.Sprintf("The formats of the SBOM. Supported values are: %s", strings.Join(sbom.Formats, ", ")))
+	cmd.Flags().StringVarP(&options.OutputDir, "output-dir", "o", "", "The directory to write the SBOM documents to. Defaults to a temporary directory")
+	cmd.Flags().BoolVarP(&options.NoStore, "no-store", "", false, "Do not store the SBOM in the storage location of the team")
+	cmd.Flags().BoolVarP(&options.NoReleaseAsset, "no-release-asset", "", false, "Do not upload the SBOM as an asset of the git provider release")
+	cmd.Flags().BoolVarP(&options.NoRelease, "no-release", "", false, "Do not reference the SBOM from the Release resource in the development namespace")
+	cmd.Flags().StringVarP(&options.StorageLocation.BucketURL, "bucket-url", "", "", "The cloud storage bucket URL to store the SBOM in. Defaults to the storage location of the team")
+	cmd.Flags().StringVarP(&options.StorageLocation.GitURL, "git-url", "", "", "The git URL of the repository to store the SBOM in. Defaults to the storage location of the team")
+	cmd.Flags().StringVarP(&options.StorageLocation.GitBranch, "git-branch", "", "gh-pages", "The branch to store the SBOM in when using a git repository")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCreateSBOMOptions) Run() error {
+	var err error
+	if o.Dir == "" {
+		o.Dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	for _, format := range o.Formats {
+		if util.StringArrayIndex(sbom.Formats, format) < 0 {
+			return util.InvalidOption("format", format, sbom.Formats)
+		}
+	}
+	gitInfo, err := o.FindGitInfo(o.Dir)
+	if err != nil {
+		log.Logger().Warnf("could not find the git repository of %s: %s", o.Dir, err.Error())
+	}
+	if o.Name == "" && gitInfo != nil {
+		o.Name = gitInfo.Name
+	}
+	if o.Name == "" {
+		return util.MissingOption("name")
+	}
+	if o.Version == "" {
+		data, err := ioutil.ReadFile(filepath.Join(o.Dir, defaultSBOMVersionFile))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		o.Version = strings.TrimSpace(string(data))
+	}
+	if o.Version == "" {
+		return util.MissingOption("version")
+	}
+
+	bom := sbom.NewBOM(o.Name, o.Version, version.GetVersion())
+	components, err := sbom.FindComponents(o.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the dependencies of %s", o.Dir)
+	}
+	bom.AddComponents(components...)
+	imageComponents, err := o.imageComponents()
+	if err != nil {
+		return err
+	}
+	bom.AddComponents(imageComponents...)
+	log.Logger().Infof("found %d components of %s version %s", len(bom.Components), util.ColorInfo(o.Name), util.ColorInfo(o.Version))
+
+	if o.OutputDir == "" {
+		o.OutputDir, err = ioutil.TempDir("", "jx-sbom-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(o.OutputDir)
+	}
+	err = os.MkdirAll(o.OutputDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", o.OutputDir)
+	}
+
+	var coll collector.Collector
+	if !o.NoStore {
+		coll, err = o.createCollector(gitInfo)
+		if err != nil {
+			return err
+		}
+	}
+	var gitProvider gits.GitProvider
+	var gitRelease *gits.GitRelease
+	if !o.NoReleaseAsset && gitInfo != nil {
+		gitProvider, gitRelease = o.findGitRelease(gitInfo)
+	}
+
+	var references []v1.SBOMReference
+	for _, format := range o.Formats {
+		data, err := bom.Document(format)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the %s SBOM", format)
+		}
+		fileName := bom.FileName(format)
+		path := filepath.Join(o.OutputDir, fileName)
+		err = ioutil.WriteFile(path, data, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+		log.Logger().Infof("generated %s", util.ColorInfo(path))
+
+		reference := v1.SBOMReference{
+			Format: format,
+			Name:   fileName,
+		}
+		if coll != nil {
+			storagePath := filepath.Join("jenkins-x", kube.ClassificationSBOM, o.Name, o.Version, fileName)
+			if gitInfo != nil {
+				storagePath = filepath.Join("jenkins-x", kube.ClassificationSBOM, gitInfo.Organisation, gitInfo.Name, o.Version, fileName)
+			}
+			reference.URL, err = coll.CollectData(data, storagePath)
+			if err != nil {
+				return errors.Wrapf(err, "failed to store %s", fileName)
+			}
+			log.Logger().Infof("stored %s at %s", fileName, util.ColorInfo(reference.URL))
+		}
+		if gitRelease != nil {
+			asset, err := o.uploadReleaseAsset(gitProvider, gitInfo, gitRelease, path)
+			if err != nil {
+				log.Logger().Warnf("failed to upload %s to release %s: %s", fileName, gitRelease.TagName, err.Error())
+			} else if reference.URL == "" {
+				reference.URL = asset.BrowserDownloadURL
+			}
+		}
+		references = append(references, reference)
+	}
+
+	if !o.NoRelease {
+		err = o.updateRelease(references)
+		if err != nil {
+			log.Logger().Warnf("failed to reference the SBOM from the Release: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// imageComponents returns the operating system packages of the image of the release if one is specified
+func (o *StepCreateSBOMOptions) imageComponents() ([]sbom.Component, error) {
+	fileName := o.ImageFile
+	image := o.Image
+	if fileName == "" {
+		if image == "" {
+			return nil, nil
+		}
+		dir, err := ioutil.TempDir("", "jx-sbom-image-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		fileName = filepath.Join(dir, "image.tar")
+		err = cve.DockerSave(image, fileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to save image %s", image)
+		}
+	}
+	if image == "" {
+		image = fileName
+	}
+	contents, err := cve.ReadImageContents(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the packages of image %s", image)
+	}
+	return sbom.ImageComponents(contents, image), nil
+}
+
+// createCollector returns the collector for the storage location of SBOMs, defaulting to the team settings and then
+// to the current git repository
+func (o *StepCreateSBOMOptions) createCollector(gitInfo *gits.GitRepository) (collector.Collector, error) {
+	location := o.StorageLocation
+	if location.IsEmpty() {
+		settings, err := o.TeamSettings()
+		if err != nil {
+			return nil, err
+		}
+		location = settings.StorageLocationOrDefault(kube.ClassificationSBOM)
+		if location.IsEmpty() {
+			if gitInfo == nil {
+				return nil, fmt.Errorf("missing option --git-url and we could not detect the current git repository URL")
+			}
+			location.GitURL = gitInfo.URL
+		}
+	}
+	location.Classifier = kube.ClassificationSBOM
+	coll, err := collector.NewCollector(location, o.Git())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the collector for storage settings %s", location.Description())
+	}
+	return coll, nil
+}
+
+// findGitRelease returns the git provider release of the version or nil if there is none
+func (o *StepCreateSBOMOptions) findGitRelease(gitInfo *gits.GitRepository) (gits.GitProvider, *gits.GitRelease) {
+	gitProvider, err := o.GitProviderForURL(gitInfo.URL, "uploading the SBOM to the release")
+	if err != nil {
+		log.Logger().Warnf("failed to create the git provider for %s: %s", gitInfo.URL, err.Error())
+		return nil, nil
+	}
+	tags := []string{o.Version}
+	if !strings.HasPrefix(o.Version, "v") {
+		tags = []string{"v" + o.Version, o.Version}
+	}
+	for _, tag := range tags {
+		release, err := gitProvider.GetRelease(gitInfo.Organisation, gitInfo.Name, tag)
+		if err == nil && release != nil {
+			return gitProvider, release
+		}
+	}
+	log.Logger().Warnf("could not find release %s of %s/%s so not uploading the SBOM", o.Version, gitInfo.Organisation, gitInfo.Name)
+	return nil, nil
+}
+
+func (o *StepCreateSBOMOptions) uploadReleaseAsset(gitProvider gits.GitProvider, gitInfo *gits.GitRepository, release *gits.GitRelease, path string) (*gits.GitReleaseAsset, error) {
+	file, err := os.Open(path)
+	// The file will be closed by the release asset uploader
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", path)
+	}
+	asset, err := gitProvider.UploadReleaseAsset(gitInfo.Organisation, gitInfo.Name, release.ID, filepath.Base(path), file)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Infof("uploaded %s to release asset %s", filepath.Base(path), util.ColorInfo(asset.BrowserDownloadURL))
+	return asset, nil
+}
+
+// updateRelease adds the references to the SBOM documents to the Release created by 'jx step changelog'
+func (o *StepCreateSBOMOptions) updateRelease(references []v1.SBOMReference) error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	name := naming.ToValidName(o.Name + "-" + strings.TrimPrefix(o.Version, "v"))
+	releases := jxClient.JenkinsV1().Releases(ns)
+	release, err := releases.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find Release %s in namespace %s", name, ns)
+	}
+	for _, reference := range references {
+		found := false
+		for i, existing := range release.Spec.SBOMs {
+			if existing.Format == reference.Format && existing.Name == reference.Name {
+				release.Spec.SBOMs[i] = reference
+				found = true
+			}
+		}
+		if !found {
+			release.Spec.SBOMs = append(release.Spec.SBOMs, reference)
+		}
+	}
+	_, err = releases.PatchUpdate(release)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Release %s in namespace %s", name, ns)
+	}
+	log.Logger().Infof("added the SBOM to Release %s", util.ColorInfo(name))
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/step/git"
 
 	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	jxclient "github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
@@ -31,6 +33,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/jenkinsfile/gitresolver"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/secreturl/sops"
 	"github.com/jenkins-x/jx/pkg/tekton"
 	"github.com/jenkins-x/jx/pkg/tekton/syntax"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -127,8 +130,11 @@ type StepCreateTaskOptions struct {
 	pipelineParams       []pipelineapi.Param
 	version              string
 	previewVersionPrefix string
+	whenContext          *syntax.WhenContext
 	VersionResolver      *versionstream.VersionResolver
 	CloneDir             string
+	sopsKeysSecret       *corev1.Secret
+	sopsKeysLoaded       bool
 }
 
 // NewCmdStepCreateTask Creates a new Command object
@@ -333,8 +339,19 @@ func (o *StepCreateTaskOptions) Run() error {
 		return errors.Wrapf(err, "failed to set the version on release pipelines")
 	}
 
+	// finding the changed files and pull request labels needs git and the git provider so only do it if they are used
+	if effectiveProjectConfig != nil {
+		parsed, err := effectiveProjectConfig.GetPipeline(o.PipelineKind)
+		if err == nil && parsed != nil && parsed.HasWhenConditions() {
+			o.whenContext = o.createWhenContext(pr)
+		}
+	}
+
 	log.Logger().Debug("Creating Tekton CRDs")
 	tektonCRDs, err := o.generateTektonCRDs(effectiveProjectConfig, ns, pipelineName, resourceName)
+	if errors.Cause(err) == syntax.ErrAllStagesSkipped {
+		return o.completeSkippedBuild(jxClient, ns, pr)
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to generate Tekton CRDs")
 	}
@@ -379,6 +396,31 @@ func (o *StepCreateTaskOptions) Run() error {
 	return nil
 }
 
+// completeSkippedBuild records the build as succeeded when every stage of the pipeline was skipped by its when
+// conditions as there is nothing to run
+func (o *StepCreateTaskOptions) completeSkippedBuild(jxClient jxclient.Interface, ns string, pr *tekton.PullRefs) error {
+	log.Logger().Infof("Every stage of the pipeline was skipped by its when conditions so there is nothing to run")
+	if o.ViewSteps || o.InterpretMode || *o.NoApply || o.DryRun {
+		return nil
+	}
+	activityKey := tekton.GeneratePipelineActivity(o.BuildNumber, o.Branch, o.GitInfo, o.Context, pr)
+	activity, _, err := activityKey.GetOrCreate(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the PipelineActivity %s", activityKey.Name)
+	}
+	now := metav1.Now()
+	if activity.Spec.StartedTimestamp == nil {
+		activity.Spec.StartedTimestamp = &now
+	}
+	activity.Spec.CompletedTimestamp = &now
+	activity.Spec.Status = v1.ActivityStatusTypeSucceeded
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(activity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to mark the PipelineActivity %s as succeeded", activity.Name)
+	}
+	return nil
+}
+
 func (o *StepCreateTaskOptions) waitForPreviousPipeline(tektonClient tektonclient.Interface, ns string, defaultWait time.Duration) {
 	fallbackWait := true
 	labelSelector := fmt.Sprintf("owner=%s,repository=%s,branch=%s", o.GitInfo.Organisation, o.GitInfo.Name, o.Branch)
@@ -649,6 +691,7 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 		Labels:             o.labels,
 		DefaultImage:       "",
 		InterpretMode:      o.InterpretMode,
+		WhenContext:        o.whenContext,
 	}
 	pipeline, tasks, structure, err := effectivePipeline.GenerateCRDs(crdParams)
 	if err != nil {
@@ -1020,6 +1063,23 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 			})
 		}
 	}
+	if sopsKeys := o.sopsKeys(); sopsKeys != nil {
+		keyFiles := []struct {
+			env string
+			key string
+		}{
+			{sops.AgeKeyFileEnvVar, sops.AgeKeyFile},
+			{sops.PGPKeyFileEnvVar, sops.PGPKeyFile},
+		}
+		for _, keyFile := range keyFiles {
+			if sopsKeys.Data[keyFile.key] != nil && kube.GetSliceEnvVar(envVars, keyFile.env) == nil {
+				envVars = append(envVars, corev1.EnvVar{
+					Name:  keyFile.env,
+					Value: filepath.Join(sops.KeysMountPath, keyFile.key),
+				})
+			}
+		}
+	}
 	if kube.GetSliceEnvVar(envVars, "PREVIEW_VERSION") == nil && kube.GetSliceEnvVar(envVars, "VERSION") != nil {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "PREVIEW_VERSION",
@@ -1037,6 +1097,26 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 	container.Env = envVars
 }
 
+// sopsKeys returns the secret of the keys which decrypt the secrets encrypted with sops or nil if there is none
+func (o *StepCreateTaskOptions) sopsKeys() *corev1.Secret {
+	if o.sopsKeysLoaded {
+		return o.sopsKeysSecret
+	}
+	o.sopsKeysLoaded = true
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		log.Logger().Warnf("failed to find the sops keys secret: %s", err)
+		return nil
+	}
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(kube.SecretSopsKeys, metav1.GetOptions{})
+	if err != nil {
+		log.Logger().Debugf("not mounting the sops keys as secret %s in namespace %s could not be found: %s", kube.SecretSopsKeys, ns, err)
+		return nil
+	}
+	o.sopsKeysSecret = secret
+	return secret
+}
+
 func (o *StepCreateTaskOptions) modifyVolumes(container *corev1.Container, volumes []corev1.Volume) []corev1.Volume {
 	answer := volumes
 
@@ -1093,6 +1173,30 @@ func (o *StepCreateTaskOptions) modifyVolumes(container *corev1.Container, volum
 		}
 	}
 
+	if sopsKeys := o.sopsKeys(); sopsKeys != nil {
+		// lets mount the keys which decrypt the secrets encrypted with sops such as when running boot in the cluster
+		volumeName := "sops-keys"
+		volume := corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: sopsKeys.Name,
+				},
+			},
+		}
+		if !kube.ContainsVolume(answer, volume) {
+			answer = append(answer, volume)
+		}
+		volumeMount := corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: sops.KeysMountPath,
+			ReadOnly:  true,
+		}
+		if !kube.ContainsVolumeMount(container.VolumeMounts, volumeMount) {
+			container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+		}
+	}
+
 	podInfoName := "podinfo"
 	volume := corev1.Volume{
 		Name: podInfoName,
@@ -1205,6 +1309,77 @@ func (o *StepCreateTaskOptions) parsePullRefs() (*tekton.PullRefs, error) {
 	return pr, nil
 }
 
+// createWhenContext gathers the branch, environment, changed files and pull request labels used to decide which
+// stages of the pipeline should be skipped.
+func (o *StepCreateTaskOptions) createWhenContext(pr *tekton.PullRefs) *syntax.WhenContext {
+	ctx := &syntax.WhenContext{
+		Branch: o.Branch,
+		Env:    map[string]string{},
+	}
+	for _, envVar := range o.CustomEnvs {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) == 2 {
+			ctx.Env[parts[0]] = parts[1]
+		}
+	}
+	for k, v := range o.AdditionalEnvVars {
+		ctx.Env[k] = v
+	}
+
+	ctx.ChangedFiles, ctx.ChangedFilesUnknown = o.findChangedFiles(pr)
+
+	if pr == nil {
+		return ctx
+	}
+
+	if o.GitInfo != nil && len(pr.ToMerge) > 0 {
+		provider, err := o.GitProviderForURL(o.GitInfo.URL, "pull request labels")
+		if err != nil {
+			log.Logger().Warnf("failed to create the git provider for %s: %s", o.GitInfo.URL, err)
+			return ctx
+		}
+		for prNumber := range pr.ToMerge {
+			number, err := strconv.Atoi(prNumber)
+			if err != nil {
+				continue
+			}
+			gitPR, err := provider.GetPullRequest(o.GitInfo.Organisation, o.GitInfo, number)
+			if err != nil {
+				log.Logger().Warnf("failed to find pull request %d: %s", number, err)
+				continue
+			}
+			for _, label := range gitPR.Labels {
+				if label != nil && label.Name != nil {
+					ctx.Labels = append(ctx.Labels, *label.Name)
+				}
+			}
+		}
+	}
+	return ctx
+}
+
+// findChangedFiles finds the files changed by the pull request, or by the last commit for release builds. If they
+// can't be found the second value is true so that stages with changedFiles conditions are run rather than skipped.
+func (o *StepCreateTaskOptions) findChangedFiles(pr *tekton.PullRefs) ([]string, bool) {
+	base := "HEAD~1"
+	if pr != nil && len(pr.ToMerge) > 0 && pr.BaseSha != "" {
+		base = pr.BaseSha
+	}
+	changes, err := o.Git().ListChangedFilesFromBranch(o.CloneDir, base)
+	if err != nil {
+		// the repository is shallow cloned so the base commit may not have been fetched
+		err = o.Git().FetchUnshallow(o.CloneDir)
+		if err == nil {
+			changes, err = o.Git().ListChangedFilesFromBranch(o.CloneDir, base)
+		}
+	}
+	if err != nil {
+		log.Logger().Warnf("failed to list the files changed since %s so stages with changedFiles conditions will be run: %s", base, err)
+		return nil, true
+	}
+	return syntax.ParseChangedFiles(changes), false
+}
+
 // mergePullRefs merges the pull refs specified into the git repository specified via CloneDir.
 func (o *StepCreateTaskOptions) mergePullRefs(pr *tekton.PullRefs, cloneDir string) error {
 	if pr == nil {
@@ -110,7 +110,7 @@ func NewCmdStepCreateValues(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Name, "name", "", "values", "the kind of the file to create (and, by default, the schema name)")
 	cmd.Flags().StringVarP(&options.BasePath, "secret-base-path", "", "", fmt.Sprintf("the secret path used to store secrets in vault / file system. Typically a unique name per cluster+team. If none is specified we will default it to the cluster name from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
 	cmd.Flags().StringVarP(&options.ValuesFile, "out", "", "", "the path to the file to create, overrides --dir and --name")
-	cmd.Flags().StringVarP(&options.SecretsScheme, optionSecretsScheme, "", "", fmt.Sprintf("the scheme to store/reference any secrets in, valid options are vault and local. If none are specified we will default it from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
+	cmd.Flags().StringVarP(&options.SecretsScheme, optionSecretsScheme, "", "", fmt.Sprintf("the scheme to store/reference any secrets in, valid options are vault, local and sops. If none are specified we will default it from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
 	return cmd
 }
 
@@ -155,8 +155,8 @@ func (o *StepCreateValuesOptions) Run() error {
 		}
 
 	}
-	if !(o.SecretsScheme == "vault" || o.SecretsScheme == "local") {
-		util.InvalidArgf(optionSecretsScheme, "Use one of vault or local")
+	if !(o.SecretsScheme == "vault" || o.SecretsScheme == "local" || o.SecretsScheme == "sops") {
+		util.InvalidArgf(optionSecretsScheme, "Use one of vault, local or sops")
 	}
 	if o.Schema == "" {
 		o.Schema = filepath.Join(o.Dir, fmt.Sprintf("%s.schema.json", o.Name))
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/platform"
 
@@ -21,9 +22,13 @@ import (
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/jenkins-x/jx/pkg/signing"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // StepEnvApplyOptions contains the command line flags
@@ -38,6 +43,8 @@ type StepEnvApplyOptions struct {
 	DisableHelmVersion bool
 	ChangeNs           bool
 	Vault              bool
+
+	FreezeOverrideReason string
 }
 
 var (
@@ -82,6 +89,7 @@ func NewCmdStepEnvApply(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory to look for the environment chart")
 	cmd.Flags().BoolVarP(&options.ChangeNs, "change-namespace", "", false, "Set the given namespace as the current namespace in Kubernetes configuration")
 	cmd.Flags().BoolVarP(&options.Vault, "vault", "", false, "Environment secrets are stored in vault")
+	cmd.Flags().StringVarP(&options.FreezeOverrideReason, "freeze-override-reason", "", "", "Applies the changes even if the Environment is frozen. The reason is recorded on the PipelineActivity. Defaults to the Freeze-Override-Reason trailer of the commit messages of the change")
 
 	// step helm apply flags
 	cmd.Flags().BoolVarP(&options.Wait, "wait", "", true, "Wait for Kubernetes readiness probe to confirm deployment")
@@ -110,6 +118,10 @@ func (o *StepEnvApplyOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	err = o.checkFreeze(ns, dir)
+	if err != nil {
+		return err
+	}
 	o.SetDevNamespace(ns)
 
 	apisClient, err := o.ApiExtensionsClient()
@@ -214,6 +226,16 @@ func (o *StepEnvApplyOptions) Run() error {
 		return errors.Wrapf(err, "building helm chart in dir %s", dir)
 	}
 
+	imagesFile, err := o.verifySignatures(ns, dir)
+	if err != nil {
+		return err
+	}
+	var valueFiles []string
+	if imagesFile != "" {
+		defer os.Remove(imagesFile)
+		valueFiles = append(valueFiles, imagesFile)
+	}
+
 	stepApply := &helm_cmd.StepHelmApplyOptions{
 		StepHelmOptions:    stepHelmBuild.StepHelmOptions,
 		Namespace:          ns,
@@ -222,6 +244,7 @@ func (o *StepEnvApplyOptions) Run() error {
 		DisableHelmVersion: o.DisableHelmVersion,
 		Force:              o.Force,
 		Vault:              o.Vault,
+		ValueFiles:         valueFiles,
 	}
 	err = stepApply.Run()
 	if err != nil {
@@ -230,3 +253,172 @@ func (o *StepEnvApplyOptions) Run() error {
 	log.Logger().Infof("Environment applied in namespace %s", util.ColorInfo(ns))
 	return nil
 }
+
+// checkFreeze returns an error if the Environment for the namespace is frozen unless the freeze is overridden, either
+// with --freeze-override-reason or by the trailer which jx promote and jx rollback add to the commit message when
+// they override the freeze
+func (o *StepEnvApplyOptions) checkFreeze(ns string, dir string) error {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envList, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the CRDs are not registered yet when first applying the dev environment so there is no freeze
+			return nil
+		}
+		return errors.Wrapf(err, "failed to list the Environments in namespace %s to check for a freeze", devNs)
+	}
+	for i := range envList.Items {
+		env := &envList.Items[i]
+		if env.Spec.Namespace != ns {
+			continue
+		}
+		freeze, err := promotion.FindActiveFreeze(env, time.Now())
+		if err != nil {
+			return err
+		}
+		if freeze == nil {
+			return nil
+		}
+		reason := o.FreezeOverrideReason
+		if reason == "" {
+			reason = o.findFreezeOverrideReason(dir)
+		}
+		if reason == "" {
+			return fmt.Errorf("cannot apply the changes as %s. Use --freeze-override-reason to apply them anyway", freeze.String())
+		}
+		log.Logger().Warnf("Overriding the freeze as %s", freeze.String())
+		pipeline, build := o.GetPipelineName(nil, "", "", "")
+		if pipeline != "" && build != "" {
+			key := &kube.PipelineActivityKey{
+				Name:     pipeline + "-" + build,
+				Pipeline: pipeline,
+				Build:    build,
+			}
+			err = key.RecordFreezeOverride(jxClient, devNs, v1.PromotionFreezeOverride{
+				Environment: env.Name,
+				Freeze:      freeze.Name,
+				Reason:      reason,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to record the override of the freeze %s on the PipelineActivity", freeze.Name)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// findFreezeOverrideReason returns the reason of the freeze override trailer in the commit messages of the change
+// being applied, which are the commits of the merged Pull Request or the latest commit, or an empty string
+func (o *StepEnvApplyOptions) findFreezeOverrideReason(dir string) string {
+	gitter := o.Git()
+	commits, err := gitter.GetCommits(dir, "HEAD^1", "HEAD")
+	if err == nil {
+		for _, commit := range commits {
+			reason := promotion.FindFreezeOverrideReason(commit.Message)
+			if reason != "" {
+				return reason
+			}
+		}
+		return ""
+	}
+	log.Logger().Debugf("failed to find the commits in dir %s: %s", dir, err)
+	message, err := gitter.GetLatestCommitMessage(dir)
+	if err != nil {
+		log.Logger().Debugf("failed to find the latest commit message in dir %s: %s", dir, err)
+		return ""
+	}
+	return promotion.FindFreezeOverrideReason(message)
+}
+
+// verifySignatures returns an error unless the charts of the environment, along with their images, have been signed
+// by a key of the trust policy of the Environment for the namespace. It returns the name of a values file, or an empty
+// string if there is no trust policy, which deploys the images of the charts by the digests which were signed
+func (o *StepEnvApplyOptions) verifySignatures(ns string, dir string) (string, error) {
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return "", err
+	}
+	envList, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// the CRDs are not registered yet when first applying the dev environment so there is no trust policy
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to list the Environments in namespace %s to verify the signatures of the charts", devNs)
+	}
+	var env *v1.Environment
+	for i := range envList.Items {
+		if envList.Items[i].Spec.Namespace == ns {
+			env = &envList.Items[i]
+			break
+		}
+	}
+	if env == nil || env.Spec.TrustPolicy == nil {
+		return "", nil
+	}
+	verifier, err := signing.NewVerifier(env.Spec.TrustPolicy)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid trust policy of environment %s", env.Name)
+	}
+	requirements, err := helm.LoadRequirementsFile(filepath.Join(dir, helm.RequirementsFileName))
+	if err != nil {
+		return "", err
+	}
+	envValues, err := helm.LoadValuesFile(filepath.Join(dir, helm.ValuesFileName))
+	if err != nil {
+		return "", err
+	}
+	releases, err := jxClient.JenkinsV1().Releases(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list the Releases in namespace %s", devNs)
+	}
+	pinnedValues := map[string]interface{}{}
+	for _, dep := range requirements.Dependencies {
+		if dep == nil || verifier.IsExcluded(dep.Name) {
+			continue
+		}
+		key := dep.Name
+		if dep.Alias != "" {
+			key = dep.Alias
+		}
+		archive := filepath.Join(dir, "charts", fmt.Sprintf("%s-%s.tgz", dep.Name, dep.Version))
+		digest, err := signing.FileDigest(archive)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to find the digest of chart %s version %s", dep.Name, dep.Version)
+		}
+		overrides, _ := envValues[key].(map[string]interface{})
+		images, err := signing.ChartImages(archive, overrides)
+		if err != nil {
+			return "", err
+		}
+		release := signing.FindSignedRelease(releases.Items, dep.Name, dep.Version)
+		images, err = verifier.VerifyRelease(release, dep.Name, dep.Version, digest, images)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot apply environment %s as it violates the trust policy", env.Name)
+		}
+		values := signing.PinnedValues(images)
+		if len(values) > 0 {
+			pinnedValues[key] = values
+		}
+	}
+	log.Logger().Infof("Verified the signatures of the charts of environment %s", util.ColorInfo(env.Name))
+
+	data, err := yaml.Marshal(pinnedValues)
+	if err != nil {
+		return "", err
+	}
+	tmpFile, err := ioutil.TempFile("", "jx-env-images-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the image digests to %s", tmpFile.Name())
+	}
+	return tmpFile.Name(), nil
+}
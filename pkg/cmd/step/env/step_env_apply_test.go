@@ -0,0 +1,127 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/pkg/cmd/testhelpers"
+	"github.com/jenkins-x/jx/pkg/gits"
+	helm_test "github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/pkg/kube"
+	resources_mock "github.com/jenkins-x/jx/pkg/kube/resources/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCheckFreezeAppliesPromotionOverridingFreeze(t *testing.T) {
+	for name, value := range map[string]string{
+		"REPO_OWNER":    "myorg",
+		"REPO_NAME":     "environment-staging",
+		"BRANCH_NAME":   "master",
+		"BUILD_NUMBER":  "2",
+		"BUILD_URL":     "https://example.com/build",
+		"BUILD_LOG_URL": "https://example.com/logs",
+	} {
+		original, found := os.LookupEnv(name)
+		os.Setenv(name, value)
+		if found {
+			defer os.Setenv(name, original)
+		} else {
+			defer os.Unsetenv(name)
+		}
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://fake.git/myorg/environment-staging.git")
+	end := metav1.NewTime(time.Now().Add(time.Hour))
+	staging.Spec.Freezes = []v1.PromotionFreeze{
+		{
+			Name:   "release",
+			Reason: "end of quarter",
+			End:    &end,
+		},
+	}
+
+	gitter := gits.NewGitCLI()
+	commonOpts := &opts.CommonOptions{}
+	testhelpers.ConfigureTestOptionsWithResources(commonOpts,
+		[]runtime.Object{},
+		[]runtime.Object{staging},
+		gitter,
+		nil,
+		helm_test.NewMockHelmer(),
+		resources_mock.NewMockInstaller(),
+	)
+	jxClient, ns, err := commonOpts.JXClientAndDevNamespace()
+	require.NoError(t, err)
+
+	// the promotion overrides the freeze so the Pull Request carries the reason
+	promoteOptions := &promote.PromoteOptions{
+		CommonOptions:        commonOpts,
+		Namespace:            ns,
+		Application:          "myapp",
+		Version:              "1.0.0",
+		Pipeline:             "myorg/myapp/master",
+		Build:                "1",
+		IgnoreLocalFiles:     true,
+		FreezeOverrideReason: "fix the checkout outage",
+	}
+	err = promoteOptions.CheckFreeze(staging)
+	require.NoError(t, err)
+	details := &gits.PullRequestDetails{
+		Title:   "chore: myapp to 1.0.0",
+		Message: "chore: Promote myapp to version 1.0.0",
+	}
+	promoteOptions.AddFreezeOverride(staging, details)
+
+	// the environment pipeline applies the merged Pull Request
+	dir, err := ioutil.TempDir("", "test-step-env-apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, gitter.Init(dir))
+	require.NoError(t, gitter.SetUsername(dir, "test"))
+	require.NoError(t, gitter.SetEmail(dir, "test@example.com"))
+	commit := func(message string) {
+		err := ioutil.WriteFile(filepath.Join(dir, "requirements.yaml"), []byte(message), 0600)
+		require.NoError(t, err)
+		require.NoError(t, gitter.Add(dir, "."))
+		require.NoError(t, gitter.CommitDir(dir, message))
+	}
+	commit("initial import")
+	base, err := gitter.Branch(dir)
+	require.NoError(t, err)
+
+	options := &StepEnvApplyOptions{
+		StepEnvOptions: StepEnvOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	err = options.checkFreeze(staging.Spec.Namespace, dir)
+	assert.Error(t, err, "the freeze should block changes without an override")
+
+	require.NoError(t, gitter.CreateBranch(dir, "promote-myapp-1.0.0"))
+	require.NoError(t, gitter.Checkout(dir, "promote-myapp-1.0.0"))
+	commit(details.Message)
+	require.NoError(t, gitter.Checkout(dir, base))
+	require.NoError(t, gitter.Merge(dir, "promote-myapp-1.0.0"))
+
+	err = options.checkFreeze(staging.Spec.Namespace, dir)
+	require.NoError(t, err)
+
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get("myorg-environment-staging-master-2", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, activity.Spec.FreezeOverrides, 1)
+	assert.Equal(t, "staging", activity.Spec.FreezeOverrides[0].Environment)
+	assert.Equal(t, "release", activity.Spec.FreezeOverrides[0].Freeze)
+	assert.Equal(t, "fix the checkout outage", activity.Spec.FreezeOverrides[0].Reason)
+}
@@ -44,6 +44,8 @@ type StepHelmApplyOptions struct {
 	NoVault            bool
 	NoMasking          bool
 	ProviderValuesDir  string
+	// ValueFiles additional values files which override the other values of the chart
+	ValueFiles []string
 }
 
 var (
@@ -244,12 +246,14 @@ func (o *StepHelmApplyOptions) Run() error {
 		}()
 	}
 
+	valueFiles = append(valueFiles, o.ValueFiles...)
+
 	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir)
 	if err != nil {
 		return err
 	}
 
-	secretURLClient, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+	secretURLClient, err := o.GetSecretURLClientForRequirements(requirements, requirementsFileName)
 	if err != nil {
 		return errors.Wrap(err, "failed to create a Secret RL client")
 	}
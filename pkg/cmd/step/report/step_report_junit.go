@@ -44,42 +44,6 @@ type StepReportJUnitOptions struct {
 	DeleteReportFn   func(reportName string) error
 }
 
-// TestSuites is the representation of the root of a *.junit.xml xml file
-type TestSuites struct {
-	XMLName    xml.Name    `xml:"testsuites"`
-	Text       string      `xml:",chardata"`
-	TestSuites []TestSuite `xml:"testsuite"`
-}
-
-// TestSuite is the representation of a <testsuite> of a *.junit.xml xml file
-type TestSuite struct {
-	XMLName  xml.Name   `xml:"testsuite"`
-	Text     string     `xml:",chardata"`
-	Name     string     `xml:"name,attr"`
-	Tests    string     `xml:"tests,attr"`
-	Failures string     `xml:"failures,attr"`
-	Errors   string     `xml:"errors,attr"`
-	Time     string     `xml:"time,attr"`
-	TestCase []TestCase `xml:"testcase"`
-}
-
-// TestCase is the representation of an individual test case within a TestSuite in a *.junit.xml xml file
-type TestCase struct {
-	XMLName   xml.Name `xml:"testcase"`
-	Text      string   `xml:",chardata"`
-	Name      string   `xml:"name,attr"`
-	Classname string   `xml:"classname,attr"`
-	Time      string   `xml:"time,attr"`
-	Failure   *Failure `xml:"failure,omitempty"`
-	SystemOut string   `xml:"system-out"`
-}
-
-// Failure is the representation of a Failure that can be present in a TestCase within a TestSuite in a *.junit.xml xml file
-type Failure struct {
-	Text string `xml:",chardata"`
-	Type string `xml:"type,attr"`
-}
-
 // NewCmdStepReportJUnit Creates a new Command object
 func NewCmdStepReportJUnit(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := &StepReportJUnitOptions{
@@ -208,29 +172,18 @@ func (o *StepReportJUnitOptions) prepareSingleFileForParsing(resultFileName stri
 func (o *StepReportJUnitOptions) mergeJUnitReportFiles(jUnitReportFiles []string, resultFileName string) error {
 	log.Logger().Infof(util.ColorInfo("Performing merge of *.junit.xml files in %s"), o.ReportsDir)
 
-	aggregatedTestSuites := TestSuites{}
+	aggregatedTestSuites := reportingtools.TestSuites{}
 	for _, v := range jUnitReportFiles {
 		bytes, err := ioutil.ReadFile(v)
 		if err != nil {
 			return err
 		}
 
-		// trying to parse <testsuites></testsuites>
-		var testSuites TestSuites
-		err = xml.Unmarshal(bytes, &testSuites)
+		testSuites, err := reportingtools.UnmarshalJUnitReport(bytes)
 		if err != nil {
-			// If no <testsuites></testsuites>, trying to parse <testsuite></testsuite>
-			var testSuite TestSuite
-			err = xml.Unmarshal(bytes, &testSuite)
-			if err != nil {
-				return err
-			}
-			aggregatedTestSuites.TestSuites = append(aggregatedTestSuites.TestSuites, testSuite)
-		} else {
-			for _, testSuite := range testSuites.TestSuites {
-				aggregatedTestSuites.TestSuites = append(aggregatedTestSuites.TestSuites, testSuite)
-			}
+			return err
 		}
+		aggregatedTestSuites.TestSuites = append(aggregatedTestSuites.TestSuites, testSuites...)
 	}
 
 	suitesBytes, err := xml.Marshal(aggregatedTestSuites)
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	log2 "github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/reportingtools"
 	reportingtools_test "github.com/jenkins-x/jx/pkg/reportingtools/mocks"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/petergtz/pegomock"
@@ -120,7 +121,7 @@ func TestReportWithMultipleFiles(t *testing.T) {
 	reportBytes, err := ioutil.ReadFile(targetFileName)
 	assert.NoError(t, err)
 
-	var testSuites TestSuites
+	var testSuites reportingtools.TestSuites
 	err = xml.Unmarshal(reportBytes, &testSuites)
 	assert.NoError(t, err, "There shouldn't be an error Unmarshalling the resulting merged report")
 
@@ -0,0 +1,240 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// envVulnerabilityDB the environment variable for the default directory of the vulnerability database
+	envVulnerabilityDB = "JX_VULNERABILITY_DB"
+	optionDB           = "db"
+)
+
+// StepScanImageOptions contains the command line flags
+type StepScanImageOptions struct {
+	step.StepOptions
+
+	Image         string
+	ImageFile     string
+	DBDir         string
+	Dir           string
+	FailOn        string
+	Ignore        []string
+	IgnoreUnfixed bool
+	IgnoreUnknown bool
+	NoFact        bool
+}
+
+var (
+	stepScanImageLong = templates.LongDesc(`
+		Scans an image for vulnerabilities using a local snapshot of a vulnerability database so that no CVE server is required.
+
+		The operating system packages of the image are compared to the advisories of the database. The 'imageScan' section of the jenkins-x.yml file of the project configures the severity which fails the pipeline and the vulnerabilities to ignore. The command line flags override it.
+
+		The findings are recorded as a Fact about the PipelineActivity of the current pipeline.
+
+		The vulnerability database is a directory of JSON files which defaults to the $JX_VULNERABILITY_DB environment variable. The files can be downloaded from the Alpine security database (` + cve.AlpineSecDBURL + `) such as v3.10/main.json and from the Debian security tracker (` + cve.DebianSecurityTrackerURL + `). Alpine advisories have no severity so they are reported as Unknown. Vulnerabilities of an Unknown severity fail the scan whenever there is a severity to fail on unless --ignore-unknown-severity is used.
+` + helper.SeeAlsoText("jx get cve"))
+
+	stepScanImageExample = templates.Examples(`
+		# Download the vulnerability database of Alpine 3.10 and Debian
+		mkdir -p /vulnerability-db
+		curl -o /vulnerability-db/alpine-v3.10-main.json https://secdb.alpinelinux.org/v3.10/main.json
+		curl -o /vulnerability-db/debian.json https://security-tracker.debian.org/tracker/data/json
+
+		# Fail the pipeline if the image has high or critical vulnerabilities
+		jx step scan image gcr.io/myorg/myapp:1.0.1 --db /vulnerability-db --fail-on high
+
+		# Scan the tarball of an image built with kaniko --tarPath
+		jx step scan image --image-file /workspace/image.tar --db /vulnerability-db
+	`)
+)
+
+// NewCmdStepScanImage creates the command
+func NewCmdStepScanImage(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepScanImageOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "image [image]",
+		Short:   "Scans an image for vulnerabilities and fails if they violate the policy of the project",
+		Long:    stepScanImageLong,
+		Example: stepScanImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to scan which is saved using 'docker save'")
+	cmd.Flags().StringVarP(&options.ImageFile, "image-file", "f", "", "The tarball of the image to scan in the format of 'docker save'")
+	cmd.Flags().StringVarP(&options.DBDir, optionDB, "", "", "The directory of the vulnerability database. Defaults to $"+envVulnerabilityDB)
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory of the project containing the jenkins-x.yml file")
+	cmd.Flags().StringVarP(&options.FailOn, "fail-on", "", "", fmt.Sprintf("The lowest severity which fails the scan. Supported values are: none, %s", strings.ToLower(strings.Join(cve.Severities, ", "))))
+	cmd.Flags().StringArrayVarP(&options.Ignore, "ignore", "", nil, "The vulnerability IDs, wildcards or package names to ignore in addition to those of the jenkins-x.yml file")
+	cmd.Flags().BoolVarP(&options.IgnoreUnfixed, "ignore-unfixed", "", false, "Ignore vulnerabilities which have no fixed version yet")
+	cmd.Flags().BoolVarP(&options.IgnoreUnknown, "ignore-unknown-severity", "", false, "Do not fail on vulnerabilities of an unknown severity such as Alpine advisories")
+	cmd.Flags().BoolVarP(&options.NoFact, "no-fact", "", false, "Do not record the findings as a Fact about the PipelineActivity")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepScanImageOptions) Run() error {
+	if o.Image == "" && len(o.Args) > 0 {
+		o.Image = o.Args[0]
+	}
+	if o.Image == "" && o.ImageFile == "" {
+		return util.MissingOption("image")
+	}
+	if o.DBDir == "" {
+		o.DBDir = os.Getenv(envVulnerabilityDB)
+	}
+	if o.DBDir == "" {
+		return util.MissingOption(optionDB)
+	}
+	policy, err := o.loadPolicy()
+	if err != nil {
+		return err
+	}
+
+	provider, err := cve.NewLocalProvider(o.DBDir)
+	if err != nil {
+		return err
+	}
+	image := o.Image
+	var contents *cve.ImageContents
+	var vulnerabilities []cve.Vulnerability
+	if o.ImageFile != "" {
+		if image == "" {
+			image = o.ImageFile
+		}
+		contents, vulnerabilities, err = provider.ScanImageFile(o.ImageFile)
+	} else {
+		contents, vulnerabilities, err = provider.ScanImage(o.Image)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to scan image %s", image)
+	}
+	result := policy.Evaluate(vulnerabilities)
+	o.renderResult(image, contents, result)
+
+	if !o.NoFact {
+		err = o.recordFact(image, policy, result)
+		if err != nil {
+			log.Logger().Warnf("failed to record the scan of %s as a Fact: %s", image, err.Error())
+		}
+	}
+	if !result.Passed() {
+		if policy.IgnoreUnknownSeverity {
+			return fmt.Errorf("image %s has %d vulnerabilities with a severity of %s or higher", image, len(result.Violations), policy.FailOn)
+		}
+		return fmt.Errorf("image %s has %d vulnerabilities with a severity of %s or higher or an unknown severity", image, len(result.Violations), policy.FailOn)
+	}
+	return nil
+}
+
+// loadPolicy returns the policy of the jenkins-x.yml file overridden by the command line flags
+func (o *StepScanImageOptions) loadPolicy() (*cve.Policy, error) {
+	projectConfig, fileName, err := config.LoadProjectConfig(o.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", fileName)
+	}
+	policy := &cve.Policy{}
+	if projectConfig.ImageScan != nil {
+		policy.FailOn = projectConfig.ImageScan.FailOn
+		policy.Ignore = projectConfig.ImageScan.Ignore
+		policy.IgnoreUnfixed = projectConfig.ImageScan.IgnoreUnfixed
+		policy.IgnoreUnknownSeverity = projectConfig.ImageScan.IgnoreUnknownSeverity
+	}
+	if o.FailOn != "" {
+		policy.FailOn = o.FailOn
+	}
+	policy.Ignore = append(policy.Ignore, o.Ignore...)
+	if o.IgnoreUnfixed {
+		policy.IgnoreUnfixed = true
+	}
+	if o.IgnoreUnknown {
+		policy.IgnoreUnknownSeverity = true
+	}
+	return policy, policy.Validate()
+}
+
+func (o *StepScanImageOptions) renderResult(image string, contents *cve.ImageContents, result *cve.PolicyResult) {
+	log.Logger().Infof("scanned %d packages of image %s running %s %s", len(contents.Packages), util.ColorInfo(image),
+		contents.Distro, contents.DistroVersion)
+	if len(result.Vulnerabilities) == 0 {
+		log.Logger().Infof("no vulnerabilities found")
+		return
+	}
+	table := o.CreateTable()
+	table.AddRow("SEVERITY", "VULNERABILITY", "PACKAGE", "FIX", "STATUS")
+	ignored := map[cve.Vulnerability]bool{}
+	for _, v := range result.Ignored {
+		ignored[v] = true
+	}
+	violations := map[cve.Vulnerability]bool{}
+	for _, v := range result.Violations {
+		violations[v] = true
+	}
+	for _, v := range result.Vulnerabilities {
+		status := ""
+		if ignored[v] {
+			status = "ignored"
+		} else if violations[v] {
+			status = util.ColorError("violation")
+		}
+		table.AddRow(cve.ColorSeverity(v.Severity), v.Vuln, v.Package, v.Fix, status)
+	}
+	table.Render()
+}
+
+// recordFact saves the findings as a Fact about the PipelineActivity of the current pipeline
+func (o *StepScanImageOptions) recordFact(image string, policy *cve.Policy, result *cve.PolicyResult) error {
+	pipeline, build := o.GetPipelineName(nil, "", "", "")
+	if pipeline == "" || build == "" {
+		log.Logger().Debugf("not recording the scan of %s as a Fact as there is no current pipeline", image)
+		return nil
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	name := naming.ToValidName(pipeline + "-" + build)
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find PipelineActivity %s", name)
+	}
+	fact := cve.NewImageScanFact(activity, image, policy, result)
+	facts := jxClient.JenkinsV1().Facts(ns)
+	existing, err := facts.Get(fact.Name, metav1.GetOptions{})
+	if err == nil {
+		existing.Labels = fact.Labels
+		existing.Spec = fact.Spec
+		_, err = facts.PatchUpdate(existing)
+	} else {
+		_, err = facts.Create(fact)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save Fact %s", fact.Name)
+	}
+	log.Logger().Infof("recorded the scan of %s as Fact %s", util.ColorInfo(image), util.ColorInfo(fact.Name))
+	return nil
+}
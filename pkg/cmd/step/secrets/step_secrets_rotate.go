@@ -0,0 +1,262 @@
+package secrets
+
+import (
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/cmd/update"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/vault/rotation"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultMaxAgeDays = 90
+)
+
+// StepSecretsRotateOptions contains the command line flags
+type StepSecretsRotateOptions struct {
+	step.StepOptions
+
+	Dir            string
+	File           string
+	Names          []string
+	MaxAgeDays     int
+	Force          bool
+	DryRun         bool
+	GitToken       string
+	DockerPassword string
+	Endpoint       string
+}
+
+var (
+	stepSecretsRotateLong = templates.LongDesc(`
+		Rotates the secrets in Vault which are older than the maximum age.
+
+		The secrets are configured in a secret-rotations.yml file. Each rotation first updates the systems which check the secret, such as the webhooks of the git repositories, then writes a new version of the secret to Vault, updates the Kubernetes secrets which contain it and rolls the Deployments, StatefulSets and DaemonSets which use those Kubernetes secrets. If Vault or a Kubernetes secret cannot be updated the previous value is restored everywhere. An audit record of each rotation is stored in the jx-secret-rotations ConfigMap which is also used to find the secrets which are due to be rotated.
+
+		The kinds of secret are:
+
+		* webhook - a new HMAC token is generated and the webhooks of the SourceRepositories are updated to use it
+		* password - a new password is generated such as the passwords of charts. Only passwords which are read from the Kubernetes secrets alone, such as the basic auth password of chartmuseum, can be rotated by rolling the workloads so mark them with stateless: true. Passwords which are also stored by the system which checks them, such as the passwords of Postgres or Nexus kept in their data volumes, need an applyCommand which changes the password from $PREVIOUS_VALUE to $ROTATED_VALUE
+		* gitToken - git providers cannot create API tokens so create a new token and pass it with --git-token. It is checked with the git provider before it is stored
+		* docker - change the password in the docker registry then pass it with --docker-password. Docker config secrets have the auth of the registry updated
+
+`)
+
+	stepSecretsRotateExample = templates.Examples(`
+		# Rotate the secrets which have not been rotated in the last 90 days
+		jx step secrets rotate
+
+		# Rotate the HMAC token of the webhooks now
+		jx step secrets rotate --name hmac-token --force
+
+		# Rotate the git API token of the pipeline user
+		jx step secrets rotate --name pipeline-git-token --git-token $NEW_TOKEN
+
+		# An example secret-rotations.yml file
+		secrets:
+		- name: hmac-token
+		  kind: webhook
+		  vaultPath: jx/hmac
+		  kubernetesSecrets:
+		  - name: hmac-token
+		- name: chartmuseum
+		  kind: password
+		  vaultPath: jx/chartmuseum
+		  stateless: true
+		  kubernetesSecrets:
+		  - name: jenkins-x-chartmuseum
+		    key: BASIC_AUTH_PASS
+	`)
+)
+
+// NewCmdStepSecretsRotate creates the command
+func NewCmdStepSecretsRotate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSecretsRotateOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "rotate",
+		Short:   "Rotates the secrets in Vault which are older than the maximum age",
+		Long:    stepSecretsRotateLong,
+		Example: stepSecretsRotateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory containing the secret rotations file")
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The secret rotations file. Defaults to "+rotation.ConfigFileName+" in the directory")
+	cmd.Flags().StringArrayVarP(&options.Names, "name", "n", nil, "The names of the secrets to rotate. Defaults to all the secrets")
+	cmd.Flags().IntVarP(&options.MaxAgeDays, "max-age", "", defaultMaxAgeDays, "The number of days after which a secret is rotated")
+	cmd.Flags().BoolVarP(&options.Force, "force", "", false, "Rotates the secrets even if they are not older than the maximum age")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Lists the secrets which are due to be rotated without rotating them")
+	cmd.Flags().StringVarP(&options.GitToken, "git-token", "", "", "The new API token of the gitToken secrets")
+	cmd.Flags().StringVarP(&options.DockerPassword, "docker-password", "", "", "The new password of the docker secrets")
+	cmd.Flags().StringVarP(&options.Endpoint, "endpoint", "", "", "The endpoint of the webhooks. Defaults to the endpoint from the cluster")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSecretsRotateOptions) Run() error {
+	fileName := o.File
+	if fileName == "" {
+		fileName = filepath.Join(o.Dir, rotation.ConfigFileName)
+	}
+	config, err := rotation.LoadConfig(fileName)
+	if err != nil {
+		return err
+	}
+	var secrets []*rotation.Secret
+	if len(o.Names) == 0 {
+		for i := range config.Secrets {
+			secrets = append(secrets, &config.Secrets[i])
+		}
+	}
+	for _, name := range o.Names {
+		secret := config.Find(name)
+		if secret == nil {
+			return util.InvalidOption("name", name, secretNames(config))
+		}
+		secrets = append(secrets, secret)
+	}
+
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	audit := rotation.NewAuditStore(kubeClient, ns)
+	maxAge := time.Duration(o.MaxAgeDays) * 24 * time.Hour
+	now := time.Now()
+	var due []*rotation.Secret
+	for _, secret := range secrets {
+		if !o.Force {
+			isDue, err := audit.IsDue(secret.Name, maxAge, now)
+			if err != nil {
+				return err
+			}
+			if !isDue {
+				log.Logger().Debugf("secret %s has been rotated in the last %d days", secret.Name, o.MaxAgeDays)
+				continue
+			}
+		}
+		due = append(due, secret)
+	}
+	if len(due) == 0 {
+		log.Logger().Infof("no secrets are due to be rotated")
+		return nil
+	}
+	if o.DryRun {
+		for _, secret := range due {
+			log.Logger().Infof("secret %s of kind %s at Vault path %s is due to be rotated", util.ColorInfo(secret.Name), secret.Kind, secret.VaultPath)
+		}
+		return nil
+	}
+
+	vaultClient, err := o.SystemVaultClient(ns)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the Vault client")
+	}
+	r := &rotation.Rotation{
+		KubeClient:  kubeClient,
+		VaultClient: vaultClient,
+		Namespace:   ns,
+		Rotators:    o.createRotators(),
+		Audit:       audit,
+	}
+	u, err := user.Current()
+	if err == nil {
+		r.User = u.Username
+	}
+
+	var failed []string
+	for _, secret := range due {
+		record, err := r.Rotate(secret)
+		if err != nil {
+			log.Logger().Errorf("failed to rotate secret %s: %s", secret.Name, err)
+			failed = append(failed, secret.Name)
+			continue
+		}
+		log.Logger().Infof("rotated secret %s to Vault version %s updating %d Kubernetes secrets and rolling %d workloads",
+			util.ColorInfo(secret.Name), record.VaultVersion, len(record.KubernetesSecrets), len(record.Workloads))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to rotate secrets %v", failed)
+	}
+	return nil
+}
+
+func (o *StepSecretsRotateOptions) createRotators() map[string]rotation.Rotator {
+	return map[string]rotation.Rotator{
+		rotation.KindPassword: &rotation.PasswordRotator{},
+		rotation.KindWebhook: &rotation.WebhookRotator{
+			UpdateWebhooks: o.updateWebhooks,
+		},
+		rotation.KindGitToken: &rotation.GitTokenRotator{
+			Token:         o.GitToken,
+			ValidateToken: o.validateGitToken,
+		},
+		rotation.KindDocker: &rotation.DockerRotator{
+			Password: o.DockerPassword,
+		},
+	}
+}
+
+// updateWebhooks updates the webhooks of the SourceRepositories with the new HMAC token
+func (o *StepSecretsRotateOptions) updateWebhooks(hmacToken string) error {
+	isProw, err := o.IsProw()
+	if err != nil {
+		return err
+	}
+	if !isProw {
+		return fmt.Errorf("webhooks only use an HMAC token with prow or lighthouse")
+	}
+	options := &update.UpdateWebhooksOptions{
+		CommonOptions:  o.CommonOptions,
+		ExactHookMatch: true,
+		HMAC:           hmacToken,
+		Endpoint:       o.Endpoint,
+	}
+	return options.Run()
+}
+
+// validateGitToken checks the token can be used with the git provider
+func (o *StepSecretsRotateOptions) validateGitToken(secret *rotation.Secret, token string) error {
+	server := &auth.AuthServer{
+		URL:  secret.GitServer,
+		Kind: secret.GitKind,
+	}
+	userAuth := &auth.UserAuth{
+		Username: secret.Username,
+		ApiToken: token,
+	}
+	provider, err := gits.CreateProvider(server, userAuth, o.Git())
+	if err != nil {
+		return err
+	}
+	_, err = provider.ListOrganisations()
+	return err
+}
+
+func secretNames(config *rotation.Config) []string {
+	var answer []string
+	for _, s := range config.Secrets {
+		answer = append(answer, s.Name)
+	}
+	return answer
+}
@@ -456,9 +456,10 @@ func (o *StepChangelogOptions) Run() error {
 			tagName = vVersion
 		}
 		releaseInfo := &gits.GitRelease{
-			Name:    version,
-			TagName: tagName,
-			Body:    markdown,
+			Name:            version,
+			TagName:         tagName,
+			Body:            markdown,
+			TargetCommitish: currentRev,
 		}
 		url := releaseInfo.HTMLURL
 		if url == "" {
@@ -689,6 +690,9 @@ func (o *StepChangelogOptions) addCommit(spec *v1.ReleaseSpec, commit *object.Co
 		Branch:    branch,
 		Committer: &committerDetails,
 	}
+	if !commit.Committer.When.IsZero() {
+		commitSummary.Timestamp = kube.ToMetaTime(&commit.Committer.When)
+	}
 
 	err = o.addIssuesAndPullRequests(spec, &commitSummary, commit)
 	if err != nil {
@@ -46,6 +46,10 @@ type StepNextVersionOptions struct {
 	UseGitTagOnly   bool
 	NewVersion      string
 	SemanticRelease bool
+	ReleasePolicy   string
+	Module          string
+	Branch          string
+	DryRun          bool
 	step.StepOptions
 }
 
@@ -99,6 +103,10 @@ func NewCmdStepNextVersion(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Tag, "tag", "t", false, "tag and push new version")
 	cmd.Flags().BoolVarP(&options.UseGitTagOnly, "use-git-tag-only", "", false, "only use a git tag so work out new semantic version, else specify filename [pom.xml,package.json,Makefile,Chart.yaml]")
 	cmd.Flags().BoolVarP(&options.SemanticRelease, "semantic-release", "", false, "use conventional commits to determine next version. Ignores the --use-git-tag-only and --version options See https://github.com/angular/angular.js/blob/master/DEVELOPERS.md#-git-commit-guidelines")
+	cmd.Flags().StringVarP(&options.ReleasePolicy, "release-policy", "", "", fmt.Sprintf("the release policy file used with --semantic-release. Defaults to %s in the directory if it exists", semrel.PolicyFileName))
+	cmd.Flags().StringVarP(&options.Module, "module", "m", "", "the module of the release policy to version when using --semantic-release in a monorepo")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "", "", "the branch used to choose the pre-release channel of the release policy. Defaults to the current branch")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "explain how the next version is worked out when using --semantic-release without writing or tagging it")
 	return cmd
 }
 
@@ -106,23 +114,18 @@ func (o *StepNextVersionOptions) Run() error {
 
 	var err error
 	if o.SemanticRelease {
-		err := o.Git().FetchTags(o.Dir)
+		report, err := o.getSemanticReleaseReport()
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
-		rev, tag, err := o.Git().GetCommitPointedToByLatestTag(o.Dir)
-		if err != nil {
-			return errors.WithStack(err)
+		if o.DryRun {
+			log.Logger().Infof("%s", report.String())
+			return nil
 		}
-		cur, err := o.Git().RevParse(o.Dir, "HEAD")
-		if err != nil {
-			return errors.WithStack(err)
+		if report.Next == nil {
+			return errors.Errorf("none of the commits since %s cause a new release:\n%s", report.Current.String(), report.String())
 		}
-		newVersion, err := semrel.GetNewVersion(o.Dir, cur, o.Git(), tag, rev)
-		if err != nil {
-			return errors.Wrapf(err, "getting new semantic release version for %s", tag)
-		}
-		o.NewVersion = newVersion.String()
+		o.NewVersion = report.Next.String()
 	} else if o.NewVersion == "" {
 		o.NewVersion, err = o.getNewVersionFromTagAndFile()
 		if err != nil {
@@ -163,6 +166,59 @@ func (o *StepNextVersionOptions) Run() error {
 	return nil
 }
 
+// getSemanticReleaseReport uses the release policy to work out the next version from the conventional commits since
+// the latest tag
+func (o *StepNextVersionOptions) getSemanticReleaseReport() (*semrel.Report, error) {
+	policyFile := o.ReleasePolicy
+	if policyFile == "" {
+		policyFile = filepath.Join(o.Dir, semrel.PolicyFileName)
+	}
+	policy, err := semrel.LoadPolicy(policyFile)
+	if err != nil {
+		return nil, err
+	}
+	if o.Module != "" && o.Tag {
+		return nil, errors.New("--tag cannot be used with --module, tag the module release using the tag prefix of the module instead")
+	}
+
+	err = o.Git().FetchTags(o.Dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var rev, tag string
+	if o.Module != "" {
+		module, err := policy.Module(o.Module)
+		if err != nil {
+			return nil, err
+		}
+		tag, rev, err = semrel.GetLatestModuleTag(o.Dir, o.Git(), module)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rev, tag, err = o.Git().GetCommitPointedToByLatestTag(o.Dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	cur, err := o.Git().RevParse(o.Dir, "HEAD")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	branch := o.Branch
+	if branch == "" && len(policy.Channels) > 0 {
+		branch, err = o.Git().Branch(o.Dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find the current branch in %s", o.Dir)
+		}
+	}
+	report, err := semrel.GetNextVersionReport(o.Dir, cur, o.Git(), tag, rev, policy, branch, o.Module)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting new semantic release version for %s", tag)
+	}
+	return report, nil
+}
+
 // GetVersion gets the version from a source file
 func (o *StepNextVersionOptions) GetVersion() (string, error) {
 	if o.UseGitTagOnly {
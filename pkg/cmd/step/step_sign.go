@@ -0,0 +1,272 @@
+package step
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/io/secrets"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/signing"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/helm/pkg/chartutil"
+)
+
+const (
+	defaultSigningKeySecret = "signing/cosign"
+	signingKeyField         = "key"
+	signingPasswordField    = "password"
+)
+
+// StepSignOptions contains the command line flags
+type StepSignOptions struct {
+	step.StepOptions
+
+	Dir           string
+	Name          string
+	Version       string
+	Images        []string
+	ChartFiles    []string
+	KeySecret     string
+	SecretsScheme string
+	OutputDir     string
+}
+
+var (
+	stepSignLong = templates.LongDesc(`
+		Signs the images and helm charts of a release so that they can be promoted to Environments with a trust policy.
+
+		The signing key is read from the 'key' and 'password' fields of a secret of the Vault or local file system secrets. It is a private key created by 'cosign generate-key-pair' or an unencrypted PEM encoded ECDSA key.
+
+		The signatures are recorded on the Release created by 'jx step changelog'. They are not pushed to the image registry so 'cosign verify' and admission controllers which look up signatures in the registry do not see them. When an output directory is specified the payload and signature of each artifact are also written to it so they can be checked with 'cosign verify-blob'.
+
+		The 'trustPolicy' of an Environment lists the public keys which must have signed a release before it is promoted. Each image configured in the values of the chart must have a signature of its tag, or of its digest, and is deployed by the digest which was signed.
+` + helper.SeeAlsoText("jx step changelog", "jx promote"))
+
+	stepSignExample = templates.Examples(`
+		# Sign the image and chart of the release
+		jx step sign --version $VERSION --image gcr.io/myorg/myapp:$VERSION --chart-file myapp-$VERSION.tgz
+	`)
+)
+
+// NewCmdStepSign creates the command
+func NewCmdStepSign(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSignOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sign",
+		Short:   "Signs the images and charts of a release",
+		Long:    stepSignLong,
+		Example: stepSignExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of the project. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.Name, "name", "n", "", "The name of the application of the Release. Defaults to the name of the git repository")
+	cmd.Flags().StringVarP(&options.Version, VERSION, "v", "", "The version of the release. Defaults to the contents of the VERSION file")
+	cmd.Flags().StringArrayVarP(&options.Images, "image", "i", nil, "The images to sign. If an image has no digest it is found using 'docker inspect'")
+	cmd.Flags().StringArrayVarP(&options.ChartFiles, "chart-file", "c", nil, "The chart archives to sign as created by 'helm package'")
+	cmd.Flags().StringVarP(&options.KeySecret, "key-secret", "k", defaultSigningKeySecret, "The name of the secret containing the signing key")
+	cmd.Flags().StringVarP(&options.SecretsScheme, "secrets-scheme", "", "", "The scheme of the secrets which is either vault or local. Defaults to detecting it")
+	cmd.Flags().StringVarP(&options.OutputDir, "output-dir", "o", "", "The directory to write the payload and signature files to")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSignOptions) Run() error {
+	if len(o.Images) == 0 && len(o.ChartFiles) == 0 {
+		return util.MissingOption("image")
+	}
+	var err error
+	if o.Dir == "" {
+		o.Dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	if o.Name == "" {
+		gitInfo, err := o.FindGitInfo(o.Dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the git repository of %s. Use --name to specify the application", o.Dir)
+		}
+		o.Name = gitInfo.Name
+	}
+	if o.Version == "" {
+		data, err := ioutil.ReadFile(filepath.Join(o.Dir, defaultVersionFile))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		o.Version = strings.TrimSpace(string(data))
+	}
+	if o.Version == "" {
+		return util.MissingOption(VERSION)
+	}
+
+	signer, err := o.createSigner()
+	if err != nil {
+		return err
+	}
+	var signatures []v1.ArtifactSignature
+	for _, image := range o.Images {
+		repository, tag, digest := signing.ParseImage(image)
+		if digest == "" {
+			digest, err = imageDigest(image, repository)
+			if err != nil {
+				return err
+			}
+		}
+		sig, err := signer.Sign(v1.ArtifactKindImage, repository, tag, digest)
+		if err != nil {
+			return err
+		}
+		signatures = append(signatures, *sig)
+	}
+	for _, fileName := range o.ChartFiles {
+		chart, err := chartutil.LoadFile(fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load chart %s", fileName)
+		}
+		digest, err := signing.FileDigest(fileName)
+		if err != nil {
+			return err
+		}
+		sig, err := signer.Sign(v1.ArtifactKindChart, chart.Metadata.Name, chart.Metadata.Version, digest)
+		if err != nil {
+			return err
+		}
+		signatures = append(signatures, *sig)
+	}
+	for _, sig := range signatures {
+		log.Logger().Infof("signed %s %s %s with key %s", sig.Kind, util.ColorInfo(sig.Name), sig.Digest, sig.KeyID)
+	}
+	if o.OutputDir != "" {
+		err = writeSignatureFiles(o.OutputDir, signatures)
+		if err != nil {
+			return err
+		}
+	}
+	return o.updateRelease(signatures)
+}
+
+// createSigner loads the signing key from the secret
+func (o *StepSignOptions) createSigner() (*signing.Signer, error) {
+	location := secrets.AutoLocationKind
+	if o.SecretsScheme != "" {
+		location = secrets.ToSecretsLocation(o.SecretsScheme)
+	}
+	client, err := o.GetSecretURLClient(location)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the secrets client")
+	}
+	secret, err := client.Read(o.KeySecret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the signing key secret %s", o.KeySecret)
+	}
+	keyData, _ := secret[signingKeyField].(string)
+	if keyData == "" {
+		return nil, fmt.Errorf("the secret %s has no %s field", o.KeySecret, signingKeyField)
+	}
+	password, _ := secret[signingPasswordField].(string)
+	key, err := signing.LoadPrivateKey([]byte(keyData), []byte(password))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the signing key of secret %s", o.KeySecret)
+	}
+	return signing.NewSigner(key)
+}
+
+// imageDigest returns the digest of the image in the repository using 'docker inspect'
+func imageDigest(image string, repository string) (string, error) {
+	cmd := util.Command{
+		Name: "docker",
+		Args: []string{"inspect", "--format", `{{join .RepoDigests "\n"}}`, image},
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the digest of image %s. Specify the image as name@digest", image)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		name, _, digest := signing.ParseImage(strings.TrimSpace(line))
+		if name == repository && digest != "" {
+			return digest, nil
+		}
+	}
+	return "", fmt.Errorf("image %s has not been pushed to %s so has no digest", image, repository)
+}
+
+// writeSignatureFiles writes the payload and signature of each artifact so that they can be checked with
+// 'cosign verify-blob --key cosign.pub --signature <name>.sig <name>.payload'
+func writeSignatureFiles(dir string, signatures []v1.ArtifactSignature) error {
+	err := os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+	for _, sig := range signatures {
+		name := naming.ToValidName(fmt.Sprintf("%s-%s-%s", sig.Kind, sig.Name, sig.Version))
+		payload, err := base64.StdEncoding.DecodeString(sig.Payload)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, name+".payload"), payload, util.DefaultWritePermissions)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, name+".sig"), []byte(sig.Signature), util.DefaultWritePermissions)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("wrote the signatures to %s", util.ColorInfo(dir))
+	return nil
+}
+
+// updateRelease records the signatures on the Release created by 'jx step changelog' replacing any previous
+// signatures of the same artifacts
+func (o *StepSignOptions) updateRelease(signatures []v1.ArtifactSignature) error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	name := naming.ToValidName(o.Name + "-" + strings.TrimPrefix(o.Version, "v"))
+	releases := jxClient.JenkinsV1().Releases(ns)
+	release, err := releases.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find Release %s in namespace %s. Run 'jx step changelog' before signing", name, ns)
+	}
+	for _, sig := range signatures {
+		found := false
+		for i, existing := range release.Spec.Signatures {
+			if existing.Kind == sig.Kind && existing.Name == sig.Name && existing.Version == sig.Version {
+				release.Spec.Signatures[i] = sig
+				found = true
+			}
+		}
+		if !found {
+			release.Spec.Signatures = append(release.Spec.Signatures, sig)
+		}
+	}
+	_, err = releases.PatchUpdate(release)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Release %s in namespace %s", name, ns)
+	}
+	log.Logger().Infof("added the signatures to Release %s", util.ColorInfo(name))
+	return nil
+}
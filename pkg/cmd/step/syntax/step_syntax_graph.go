@@ -0,0 +1,173 @@
+package syntax
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepSyntaxGraphOptions contains the command line flags
+type StepSyntaxGraphOptions struct {
+	step.StepOptions
+
+	File         string
+	Context      string
+	PipelineKind string
+	Format       string
+	OutputFile   string
+	Branch       string
+	VersionsDir  string
+	NoOverrides  bool
+}
+
+var (
+	stepSyntaxGraphLong = templates.LongDesc(`
+		Renders the structure of a pipeline as a Graphviz DOT or Mermaid diagram.
+
+		The diagram shows the nested and parallel stages of the pipeline, the order the stages run in, which stages provide the workspace used by later stages and the overrides applied to each stage.
+
+		Stages with when conditions are shown as conditional as the diagram can't know the changed files, pull request labels or environment variables of a build. If --branch is specified the stages whose branch conditions don't match it are shown as skipped.
+
+		The overrides of the pipeline file are applied before the pipeline is rendered. Use --no-overrides for the output of 'jx step syntax effective', which already has them applied.
+
+		The pipeline is read from the jenkins-x.yml in the current directory, which must either use the pipeline syntax directly or be the output of 'jx step syntax effective --output-file'.
+`)
+
+	stepSyntaxGraphExample = templates.Examples(`
+		# render the release pipeline as a PNG using Graphviz
+		jx step syntax graph | dot -Tpng -o pipeline.png
+
+		# render the pull request pipeline of an effective pipeline file as Mermaid
+		jx step syntax effective --output-file jenkins-x-effective.yml
+		jx step syntax graph -f jenkins-x-effective.yml --pipeline pullrequest --format mermaid
+`)
+)
+
+// NewCmdStepSyntaxGraph Creates a new Command object
+func NewCmdStepSyntaxGraph(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSyntaxGraphOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "graph",
+		Short:   "Renders the structure of a pipeline as a Graphviz DOT or Mermaid diagram",
+		Long:    stepSyntaxGraphLong,
+		Example: stepSyntaxGraphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The pipeline file to render. Defaults to the jenkins-x.yml for the context in the current directory")
+	cmd.Flags().StringVarP(&options.Context, "context", "c", "", "The pipeline context if there are multiple separate pipelines for a given branch")
+	cmd.Flags().StringVarP(&options.PipelineKind, "pipeline", "p", jenkinsfile.PipelineKindRelease, fmt.Sprintf("The kind of pipeline to render. Possible values: %s", strings.Join(jenkinsfile.PipelineKinds, ", ")))
+	cmd.Flags().StringVarP(&options.Format, "format", "o", syntax.GraphFormatDot, fmt.Sprintf("The output format. Possible values: %s", strings.Join(syntax.GraphFormats, ", ")))
+	cmd.Flags().StringVarP(&options.OutputFile, "output-file", "", "", "The file to write the diagram to. Defaults to STDOUT")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "", "", "If specified the stages whose branch conditions don't match the branch are shown as skipped")
+	cmd.Flags().BoolVarP(&options.NoOverrides, "no-overrides", "", false, "Don't apply the overrides as they have already been applied to the pipeline")
+	cmd.Flags().StringVarP(&options.VersionsDir, "versions-dir", "", "", "The version stream directory used to resolve the versions of step images")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSyntaxGraphOptions) Run() error {
+	if util.StringArrayIndex(jenkinsfile.PipelineKinds, o.PipelineKind) < 0 {
+		return util.InvalidOption("pipeline", o.PipelineKind, jenkinsfile.PipelineKinds)
+	}
+	if util.StringArrayIndex(syntax.GraphFormats, o.Format) < 0 {
+		return util.InvalidOption("format", o.Format, syntax.GraphFormats)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	parsed, pipelineConfig, err := loadPipelineFile(dir, o.File, o.Context, o.PipelineKind)
+	if err != nil {
+		return err
+	}
+
+	var overrides []*syntax.PipelineOverride
+	for _, override := range pipelineConfig.Pipelines.Overrides {
+		if override.MatchesPipeline(o.PipelineKind) {
+			overrides = append(overrides, override)
+		}
+	}
+	if !o.NoOverrides {
+		parsed = applyOverrides(parsed, overrides)
+	}
+
+	params := syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "graph",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "graph",
+		SourceDir:          "source",
+		VersionsDir:        o.VersionsDir,
+	}
+	if o.Branch != "" {
+		// only the branch is known so stages with any other conditions are shown as conditional rather than skipped
+		params.WhenContext = &syntax.WhenContext{
+			Branch:              o.Branch,
+			ChangedFilesUnknown: true,
+			EnvUnknown:          true,
+			LabelsUnknown:       true,
+		}
+	}
+	pipeline, _, structure, err := parsed.DeepCopy().GenerateCRDs(params)
+	if err != nil && errors.Cause(err) != syntax.ErrAllStagesSkipped {
+		return errors.Wrapf(err, "failed to generate the CRDs for the %s pipeline", o.PipelineKind)
+	}
+
+	graph := syntax.CreatePipelineGraph(parsed, pipeline, structure, overrides, params.WhenContext)
+	text, err := graph.Render(o.Format)
+	if err != nil {
+		return err
+	}
+	if o.OutputFile == "" {
+		_, err = fmt.Fprint(o.Out, text)
+		return err
+	}
+	err = ioutil.WriteFile(o.OutputFile, []byte(text), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the graph to %s", o.OutputFile)
+	}
+	log.Logger().Infof("Pipeline graph written to %s", util.ColorInfo(o.OutputFile))
+	return nil
+}
+
+// applyOverrides applies the step overrides and then the other overrides to a copy of the pipeline, the same way as
+// 'jx step syntax effective' does
+func applyOverrides(parsed *syntax.ParsedPipeline, overrides []*syntax.PipelineOverride) *syntax.ParsedPipeline {
+	if len(overrides) == 0 {
+		return parsed
+	}
+	parsed = parsed.DeepCopy()
+	for _, override := range overrides {
+		// an override without anything to apply removes the whole pipeline, which leaves nothing to render
+		if override.Step == nil && len(override.Steps) == 0 && !override.HasNonStepOverrides() && override.Stage == "" {
+			continue
+		}
+		parsed = syntax.ApplyStepOverridesToPipeline(parsed, override)
+	}
+	for _, override := range overrides {
+		parsed = syntax.ApplyNonStepOverridesToPipeline(parsed, override)
+	}
+	return parsed
+}
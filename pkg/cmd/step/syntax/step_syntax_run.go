@@ -0,0 +1,284 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/tekton/local"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StepSyntaxRunOptions contains the command line flags
+type StepSyntaxRunOptions struct {
+	step.StepOptions
+
+	File         string
+	Context      string
+	PipelineKind string
+	Runtime      string
+	Dir          string
+	WorkDir      string
+	SourceName   string
+	DefaultImage string
+	VersionsDir  string
+	BaseBranch   string
+	CustomEnvs   []string
+	SkipSteps    []string
+	Keep         bool
+}
+
+var (
+	stepSyntaxRunLong = templates.LongDesc(`
+		Runs a pipeline on the local machine without needing a cluster.
+
+		Each step runs in its image using a local container runtime, or as a process on the local machine if the 'process' runtime is used.
+		Parallel stages run concurrently, each stage gets the workspace it would get on a cluster and stashes are saved and restored between stages.
+		The output of each parallel branch is streamed as it runs with the name of the branch at the start of each line.
+
+		Stages with 'changedFiles' conditions use the files changed since the last commit for release pipelines, or since
+		the branch was created from the --base branch for pull request pipelines, including any uncommitted changes.
+		Environment variables set with 'valueFrom' are not set as there is no cluster to read them from, so use --env to set them.
+
+		The pipeline is read from the jenkins-x.yml in the current directory, which must either use the pipeline syntax directly or be the output of 'jx step syntax effective --output-file'.
+		The current directory is copied into the workspaces so it is never modified by the pipeline.
+`)
+
+	stepSyntaxRunExample = templates.Examples(`
+		# run the release pipeline in the current directory using docker
+		jx step syntax run
+
+		# run the pull request pipeline of an effective pipeline file using podman
+		jx step syntax effective --output-file jenkins-x-effective.yml
+		jx step syntax run -f jenkins-x-effective.yml --pipeline pullrequest --runtime podman
+
+		# run the steps as processes on the local machine, skipping the git credentials step
+		jx step syntax run --runtime process --skip jx-git-credentials
+`)
+)
+
+// NewCmdStepSyntaxRun Creates a new Command object
+func NewCmdStepSyntaxRun(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSyntaxRunOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "run",
+		Short:   "Runs a pipeline on the local machine without needing a cluster",
+		Long:    stepSyntaxRunLong,
+		Example: stepSyntaxRunExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.File, "file", "f", "", "The pipeline file to run. Defaults to the jenkins-x.yml for the context in the source directory")
+	cmd.Flags().StringVarP(&options.Context, "context", "c", "", "The pipeline context if there are multiple separate pipelines for a given branch")
+	cmd.Flags().StringVarP(&options.PipelineKind, "pipeline", "p", jenkinsfile.PipelineKindRelease, fmt.Sprintf("The kind of pipeline to run. Possible values: %s", strings.Join(jenkinsfile.PipelineKinds, ", ")))
+	cmd.Flags().StringVarP(&options.Runtime, "runtime", "", local.RuntimeDocker, fmt.Sprintf("How to run the steps. Possible values: %s", strings.Join(local.Runtimes, ", ")))
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The source directory. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.WorkDir, "work-dir", "", "", "The directory to create the workspaces and stashes in. Defaults to a temporary directory")
+	cmd.Flags().BoolVarP(&options.Keep, "keep", "", false, "Keep the workspaces and stashes after the pipeline completes")
+	cmd.Flags().StringVarP(&options.SourceName, "source", "", "source", "The name of the source repository")
+	cmd.Flags().StringVarP(&options.DefaultImage, "default-image", "", syntax.DefaultContainerImage, "Specify the docker image to use if there is no image specified for a step")
+	cmd.Flags().StringVarP(&options.VersionsDir, "versions-dir", "", "", "The version stream directory used to resolve the versions of step images")
+	cmd.Flags().StringVarP(&options.BaseBranch, "base", "", "master", "The branch a pull request pipeline finds the changed files against")
+	cmd.Flags().StringArrayVarP(&options.CustomEnvs, "env", "e", nil, "List of custom environment variables to add to every step")
+	cmd.Flags().StringArrayVarP(&options.SkipSteps, "skip", "", nil, "The names of steps to skip")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSyntaxRunOptions) Run() error {
+	if util.StringArrayIndex(jenkinsfile.PipelineKinds, o.PipelineKind) < 0 {
+		return util.InvalidOption("pipeline", o.PipelineKind, jenkinsfile.PipelineKinds)
+	}
+	runtime, err := local.NewRuntime(o.Runtime)
+	if err != nil {
+		return err
+	}
+
+	dir := o.Dir
+	if dir == "" {
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	parsed, _, err := loadPipelineFile(dir, o.File, o.Context, o.PipelineKind)
+	if err != nil {
+		return err
+	}
+
+	customEnv, err := util.ExtractKeyValuePairs(o.CustomEnvs, "=")
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the custom environment variables")
+	}
+
+	branch, err := o.Git().Branch(dir)
+	if err != nil {
+		log.Logger().Warnf("failed to find the current branch in %s: %s", dir, err)
+	}
+	whenContext := &syntax.WhenContext{
+		Branch: branch,
+		Env:    customEnv,
+	}
+	if parsed.HasWhenConditions() {
+		whenContext.ChangedFiles, whenContext.ChangedFilesUnknown = o.findChangedFiles(dir)
+	}
+	stages, err := parsed.LocalStages(syntax.CRDsFromPipelineParams{
+		SourceDir:    o.SourceName,
+		DefaultImage: o.DefaultImage,
+		VersionsDir:  o.VersionsDir,
+		WhenContext:  whenContext,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare the %s pipeline", o.PipelineKind)
+	}
+	for _, s := range stages {
+		o.prepareStage(s, customEnv)
+	}
+
+	workDir := o.WorkDir
+	if workDir == "" {
+		workDir, err = local.CreateWorkDir()
+		if err != nil {
+			return err
+		}
+	}
+	if o.Keep {
+		log.Logger().Infof("workspaces and stashes are kept in %s", util.ColorInfo(workDir))
+	} else {
+		defer os.RemoveAll(workDir)
+	}
+
+	runner := &local.Runner{
+		Runtime:       runtime,
+		BuildName:     "local",
+		SourceDir:     dir,
+		WorkDir:       workDir,
+		WorkspaceRoot: filepath.Join(syntax.WorkingDirRoot, o.SourceName),
+		Out:           o.Out,
+	}
+	err = runner.Run(stages)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("\nthe %s pipeline completed successfully", util.ColorInfo(o.PipelineKind))
+	return nil
+}
+
+// loadPipelineFile loads and validates the parsed pipeline of the given kind from the pipeline file. If no file is
+// given the jenkins-x.yml for the context in the directory is used.
+func loadPipelineFile(dir string, fileName string, pipelineContext string, kind string) (*syntax.ParsedPipeline, *jenkinsfile.PipelineConfig, error) {
+	if fileName == "" {
+		fileName = filepath.Join(dir, config.ProjectConfigFileName)
+		if pipelineContext != "" {
+			fileName = filepath.Join(dir, fmt.Sprintf("jenkins-x-%s.yml", pipelineContext))
+		}
+	}
+	projectConfig, err := config.LoadProjectConfigFile(fileName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load %s", fileName)
+	}
+	if projectConfig.PipelineConfig == nil {
+		return nil, nil, errors.Errorf("no pipelineConfig found in %s", fileName)
+	}
+	lifecycles, err := projectConfig.PipelineConfig.Pipelines.GetPipeline(kind, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lifecycles == nil || lifecycles.Pipeline == nil {
+		return nil, nil, errors.Errorf("no %s pipeline found in %s. If it comes from a build pack use 'jx step syntax effective --output-file' to generate it", kind, fileName)
+	}
+	parsed := lifecycles.Pipeline
+	parsed.AddContainerEnvVarsToPipeline(projectConfig.PipelineConfig.Env)
+	if validateErr := parsed.Validate(context.Background()); validateErr != nil {
+		return nil, nil, errors.Wrapf(validateErr, "validation failed for the %s pipeline in %s", kind, fileName)
+	}
+	return parsed, projectConfig.PipelineConfig, nil
+}
+
+// findChangedFiles finds the files changed since the last commit, or since the merge base with the base branch for
+// pull request pipelines, including uncommitted changes. If they can't be found the second value is true so that
+// stages with changedFiles conditions are run rather than skipped.
+func (o *StepSyntaxRunOptions) findChangedFiles(dir string) ([]string, bool) {
+	base := "HEAD~1"
+	if o.PipelineKind == jenkinsfile.PipelineKindPullRequest {
+		cmd := util.Command{
+			Dir:  dir,
+			Name: "git",
+			Args: []string{"merge-base", o.BaseBranch, "HEAD"},
+		}
+		mergeBase, err := cmd.RunWithoutRetry()
+		if err != nil {
+			log.Logger().Warnf("failed to find the merge base with %s so stages with changedFiles conditions will be run: %s", o.BaseBranch, err)
+			return nil, true
+		}
+		base = strings.TrimSpace(mergeBase)
+	}
+	changes, err := o.Git().ListChangedFilesFromBranch(dir, base)
+	if err != nil {
+		log.Logger().Warnf("failed to list the files changed since %s so stages with changedFiles conditions will be run: %s", base, err)
+		return nil, true
+	}
+	return syntax.ParseChangedFiles(changes), false
+}
+
+// prepareStage removes any skipped steps and adds the custom environment variables to the remaining ones
+func (o *StepSyntaxRunOptions) prepareStage(stage *syntax.LocalStage, customEnv map[string]string) {
+	var steps []corev1.Container
+	for _, s := range stage.Steps {
+		if util.StringArrayIndex(o.SkipSteps, s.Name) >= 0 {
+			continue
+		}
+		envMap := map[string]corev1.EnvVar{}
+		for _, e := range s.Env {
+			if e.ValueFrom != nil {
+				// secrets and config maps can't be read without a cluster
+				if _, ok := customEnv[e.Name]; !ok {
+					log.Logger().Warnf("the environment variable %s of step %s in stage %s uses valueFrom so it is not set, use --env %s=value to set it",
+						e.Name, s.Name, stage.Name, e.Name)
+				}
+				continue
+			}
+			envMap[e.Name] = e
+		}
+		for k, v := range customEnv {
+			envMap[k] = corev1.EnvVar{Name: k, Value: v}
+		}
+		s.Env = syntax.EnvMapToSlice(envMap)
+		steps = append(steps, s)
+	}
+	stage.Steps = steps
+	for _, s := range stage.Stages {
+		o.prepareStage(s, customEnv)
+	}
+	for _, s := range stage.Parallel {
+		o.prepareStage(s, customEnv)
+	}
+}
@@ -27,6 +27,7 @@ func NewCmdStepUpdate(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdStepUpdateDependencies(commonOpts))
 	cmd.AddCommand(release.NewCmdStepUpdateRelease(commonOpts))
 	return cmd
 }
@@ -0,0 +1,342 @@
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/dependencybot"
+	"github.com/jenkins-x/jx/pkg/dependencymatrix"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/gits/operations"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	stepUpdateDependenciesLong = templates.LongDesc(`
+		Creates Pull Requests which update the dependencies of the SourceRepositories that are older than the version stream.
+
+		Go modules, maven dependencies, npm packages and helm charts are compared to the version stream. Go modules, maven dependencies and npm packages are looked up as the packages 'go/<module>', 'maven/<groupId>/<artifactId>' and 'npm/<name>'. Charts use the prefix of their chart repository.
+
+		The 'dependencyUpdates' policy of a SourceRepository configures how updates are grouped into Pull Requests, the cron schedule of when they are opened and the dependencies which are ignored. Each update is a separate commit and is recorded in the dependency matrix of the repository.
+
+		The go.sum and package-lock.json files of updated go modules and npm packages are updated by running 'go mod tidy' and 'npm install --package-lock-only' so those tools need to be on the PATH.
+
+		This command is designed to be run periodically such as from a CronJob.
+`)
+
+	stepUpdateDependenciesExample = templates.Examples(`
+		# update the dependencies of all the repositories whose schedule is due
+		jx step update dependencies
+
+		# update the dependencies of a repository now
+		jx step update dependencies --repo myorg/myapp --ignore-schedule
+	`)
+)
+
+// StepUpdateDependenciesOptions contains the command line flags
+type StepUpdateDependenciesOptions struct {
+	step.StepUpdateOptions
+
+	Repositories   []string
+	Branch         string
+	IgnoreSchedule bool
+	DryRun         bool
+}
+
+// NewCmdStepUpdateDependencies creates the command
+func NewCmdStepUpdateDependencies(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepUpdateDependenciesOptions{
+		StepUpdateOptions: step.StepUpdateOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "dependencies",
+		Aliases: []string{"dependency", "deps"},
+		Short:   "Creates Pull Requests which update the dependencies of the SourceRepositories to the version stream",
+		Long:    stepUpdateDependenciesLong,
+		Example: stepUpdateDependenciesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.Repositories, "repo", "r", nil, "The repositories to update as either owner/name or name. Defaults to all the SourceRepositories")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "master", "The branch to update and create the Pull Requests into")
+	cmd.Flags().BoolVarP(&options.IgnoreSchedule, "ignore-schedule", "", false, "Update the dependencies even if the schedule of the policy is not due")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Perform the updates without pushing the changes or creating Pull Requests")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepUpdateDependenciesOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return err
+	}
+	list, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the SourceRepositories in namespace %s", ns)
+	}
+	repositories := list.Items
+	sort.Slice(repositories, func(i, j int) bool {
+		return repositories[i].Name < repositories[j].Name
+	})
+
+	var errs []error
+	for i := range repositories {
+		sr := &repositories[i]
+		if !o.matchesRepository(sr) {
+			continue
+		}
+		policy := sr.Spec.DependencyUpdates
+		if policy != nil && policy.Disabled {
+			log.Logger().Debugf("dependency updates are disabled for %s/%s", sr.Spec.Org, sr.Spec.Repo)
+			continue
+		}
+		now := time.Now()
+		if !o.IgnoreSchedule {
+			lastRun, _ := time.Parse(time.RFC3339, sr.Annotations[dependencybot.LastRunAnnotation])
+			due, err := dependencybot.IsDue(policy, lastRun, now)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "invalid dependency update schedule for %s/%s", sr.Spec.Org, sr.Spec.Repo))
+				continue
+			}
+			if !due {
+				log.Logger().Infof("dependency updates of %s/%s are not due yet", sr.Spec.Org, sr.Spec.Repo)
+				continue
+			}
+		}
+		err = o.updateRepository(sr, resolver)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to update the dependencies of %s/%s", sr.Spec.Org, sr.Spec.Repo))
+			continue
+		}
+		if o.DryRun {
+			continue
+		}
+		if sr.Annotations == nil {
+			sr.Annotations = map[string]string{}
+		}
+		sr.Annotations[dependencybot.LastRunAnnotation] = now.UTC().Format(time.RFC3339)
+		_, err = jxClient.JenkinsV1().SourceRepositories(ns).Update(sr)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to save when the dependencies of SourceRepository %s were updated", sr.Name))
+		}
+	}
+	return util.CombineErrors(errs...)
+}
+
+func (o *StepUpdateDependenciesOptions) matchesRepository(sr *v1.SourceRepository) bool {
+	if len(o.Repositories) == 0 {
+		return true
+	}
+	for _, r := range o.Repositories {
+		if strings.EqualFold(r, sr.Spec.Repo) || strings.EqualFold(r, sr.Spec.Org+"/"+sr.Spec.Repo) || r == sr.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// updateRepository finds the outdated dependencies of the repository and opens a Pull Request for each batch of updates
+func (o *StepUpdateDependenciesOptions) updateRepository(sr *v1.SourceRepository, resolver *versionstream.VersionResolver) error {
+	gitURL, err := kube.GetRepositoryGitURL(sr)
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "update-dependencies")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	err = o.Git().ShallowClone(dir, gitURL, o.Branch, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone %s", gitURL)
+	}
+	dependencies, err := dependencybot.FindDependencies(dir)
+	if err != nil {
+		return err
+	}
+	policy := sr.Spec.DependencyUpdates
+	updates, err := dependencybot.FindUpdates(dependencies, resolver, policy)
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		log.Logger().Infof("the %d dependencies of %s are up to date", len(dependencies), util.ColorInfo(gitURL))
+		return nil
+	}
+	grouping := ""
+	if policy != nil {
+		grouping = policy.Grouping
+	}
+	batches, err := dependencybot.GroupUpdates(updates, grouping)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		err = o.createPullRequest(gitURL, batch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createPullRequest opens or updates the Pull Request of the batch with a commit for each update
+func (o *StepUpdateDependenciesOptions) createPullRequest(gitURL string, batch *dependencybot.Batch) error {
+	authorName, authorEmail, _ := gits.EnsureUserAndEmailSetup(o.Git())
+	modifyFns := make([]operations.ChangeFilesFn, 0, len(batch.Updates))
+	for _, update := range batch.Updates {
+		log.Logger().Infof("updating %s from %s to %s in %s", util.ColorInfo(update.Name), strings.Join(update.FromVersions(), ", "),
+			util.ColorInfo(update.ToVersion), gitURL)
+		modifyFns = append(modifyFns, o.changeFilesFn(update, authorName, authorEmail))
+	}
+	pro := operations.PullRequestOperation{
+		CommonOptions: o.CommonOptions,
+		GitURLs:       []string{gitURL},
+		Base:          o.Branch,
+		BranchName:    o.Branch,
+		DryRun:        o.DryRun,
+		SkipCommit:    true, // each update is committed separately
+		AuthorName:    authorName,
+		AuthorEmail:   authorEmail,
+		Labels:        []string{batch.Label},
+		Title:         batch.PullRequestTitle(),
+		Message:       batch.PullRequestMessage(),
+	}
+	info, err := pro.CreatePullRequest("dependencies", func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+		for _, fn := range modifyFns {
+			_, err := fn(dir, gitInfo)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("created Pull Request %s for %d dependency updates", util.ColorInfo(info.PullRequest.URL), len(batch.Updates))
+	}
+	return nil
+}
+
+// changeFilesFn applies and commits an update. Updates of dependencies with a source repository use the release
+// details of that repository; others record the update in the dependency matrix directly
+func (o *StepUpdateDependenciesOptions) changeFilesFn(update *dependencybot.Update, authorName string, authorEmail string) operations.ChangeFilesFn {
+	apply := func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+		oldVersions, err := dependencybot.ApplyUpdate(dir, update)
+		if err != nil {
+			return nil, err
+		}
+		if update.Kind == dependencybot.KindGo && len(oldVersions) > 0 {
+			o.runGoModTidy(dir, update)
+		}
+		if update.Kind == dependencybot.KindNpm && len(oldVersions) > 0 {
+			err = o.updateNpmLockFiles(dir, update)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return oldVersions, nil
+	}
+	if update.GitURL != "" {
+		pro := operations.PullRequestOperation{
+			CommonOptions: o.CommonOptions,
+			SrcGitURL:     update.GitURL,
+			Version:       update.ToVersion,
+			Component:     update.Component,
+			DryRun:        o.DryRun,
+			AuthorName:    authorName,
+			AuthorEmail:   authorEmail,
+		}
+		return pro.WrapChangeFilesWithCommitFn(string(update.Kind), apply)
+	}
+	return func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+		oldVersions, err := apply(dir, gitInfo)
+		if err != nil || len(oldVersions) == 0 {
+			return nil, err
+		}
+		err = dependencymatrix.UpdateDependencyMatrix(dir, update.DependencyUpdate())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to record the update of %s in the dependency matrix", update.Name)
+		}
+		err = o.Git().Add(dir, "-A")
+		if err != nil {
+			return nil, err
+		}
+		message := update.CommitMessage()
+		if authorName != "" && authorEmail != "" {
+			message += fmt.Sprintf("\n\nSigned-off-by: %s <%s>", authorName, authorEmail)
+		}
+		return nil, o.Git().CommitDir(dir, message)
+	}
+}
+
+// runGoModTidy updates the go.sum files of the modules which require the dependency
+func (o *StepUpdateDependenciesOptions) runGoModTidy(dir string, update *dependencybot.Update) {
+	for _, d := range update.Dependencies {
+		cmd := util.Command{
+			Dir:  filepath.Join(dir, filepath.Dir(d.Path)),
+			Name: "go",
+			Args: []string{"mod", "tidy"},
+		}
+		_, err := cmd.RunWithoutRetry()
+		if err != nil {
+			log.Logger().Warnf("failed to run %s so the Pull Request will probably need some manual work to make it pass the CI tests. Failure: %s", cmd.String(), err.Error())
+		}
+	}
+}
+
+// updateNpmLockFiles updates the package-lock.json files next to the package.json files which declare the dependency
+// as 'npm ci' fails if the lock file does not match package.json
+func (o *StepUpdateDependenciesOptions) updateNpmLockFiles(dir string, update *dependencybot.Update) error {
+	for _, d := range update.Dependencies {
+		packageDir := filepath.Join(dir, filepath.Dir(d.Path))
+		exists, err := util.FileExists(filepath.Join(packageDir, "package-lock.json"))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		cmd := util.Command{
+			Dir:  packageDir,
+			Name: "npm",
+			Args: []string{"install", "--package-lock-only", "--ignore-scripts"},
+		}
+		_, err = cmd.RunWithoutRetry()
+		if err != nil {
+			return errors.Wrapf(err, "failed to update the package-lock.json of %s", d.Path)
+		}
+	}
+	return nil
+}
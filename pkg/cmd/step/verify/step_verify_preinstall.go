@@ -24,6 +24,8 @@ import (
 	"github.com/jenkins-x/jx/pkg/kube/cluster"
 	"github.com/jenkins-x/jx/pkg/kube/naming"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/packages"
+	"github.com/jenkins-x/jx/pkg/secreturl/sops"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -171,6 +173,13 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 		return err
 	}
 
+	if requirements.SecretStorage == config.SecretStorageTypeSops {
+		err = o.verifySopsKeys(kubeClient, ns, requirements)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = o.verifyStorage(requirements, requirementsFileName)
 	if err != nil {
 		return err
@@ -434,8 +443,11 @@ func (o *StepVerifyPreInstallOptions) VerifyInstallConfig(kubeClient kubernetes.
 	_, err := kube.DefaultModifyConfigMap(kubeClient, ns, kube.ConfigMapNameJXInstallConfig,
 		func(configMap *corev1.ConfigMap) error {
 			secretsLocation := string(secrets.FileSystemLocationKind)
-			if requirements.SecretStorage == config.SecretStorageTypeVault {
+			switch requirements.SecretStorage {
+			case config.SecretStorageTypeVault:
 				secretsLocation = string(secrets.VaultLocationKind)
+			case config.SecretStorageTypeSops:
+				secretsLocation = string(secrets.SopsLocationKind)
 			}
 			modifyMapIfNotBlank(configMap.Data, kube.KubeProvider, requirements.Cluster.Provider)
 			modifyMapIfNotBlank(configMap.Data, kube.ProjectID, requirements.Cluster.ProjectID)
@@ -451,6 +463,38 @@ func (o *StepVerifyPreInstallOptions) VerifyInstallConfig(kubeClient kubernetes.
 	return nil
 }
 
+// verifySopsKeys installs sops and stores the keys which decrypt the secrets encrypted with sops in a secret which is
+// mounted in the pipelines so that boot can run in the cluster
+func (o *StepVerifyPreInstallOptions) verifySopsKeys(kubeClient kubernetes.Interface, ns string, requirements *config.RequirementsConfig) error {
+	err := packages.InstallSops(false)
+	if err != nil {
+		return errors.Wrap(err, "installing sops")
+	}
+	if o.InCluster() {
+		_, err = kubeClient.CoreV1().Secrets(ns).Get(kube.SecretSopsKeys, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting the Secret %s in namespace %s with the keys which decrypt the secrets. Run 'jx boot' outside of the cluster to create it", kube.SecretSopsKeys, ns)
+		}
+		return nil
+	}
+	data, err := sops.LocalKeys(requirements.Sops.PGPFingerprints)
+	if err != nil {
+		return errors.Wrap(err, "finding the keys which decrypt the secrets")
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("no keys found to decrypt the secrets in the cluster. Create the age keys %s, set $%s or add the fingerprints of your PGP keys to sops.pgpFingerprints in %s",
+			sops.LocalAgeKeyFile(), sops.AgeKeyFileEnvVar, config.RequirementsConfigFileName)
+	}
+	_, err = kube.DefaultModifySecret(kubeClient, ns, kube.SecretSopsKeys, func(secret *corev1.Secret) error {
+		secret.Data = data
+		return nil
+	}, nil)
+	if err != nil {
+		return errors.Wrapf(err, "storing the keys which decrypt the secrets in Secret %s", kube.SecretSopsKeys)
+	}
+	return nil
+}
+
 // gatherRequirements gathers cluster requirements and connects to the cluster if required
 func (o *StepVerifyPreInstallOptions) gatherRequirements(requirements *config.RequirementsConfig, requirementsFileName string) (*config.RequirementsConfig, error) {
 	log.Logger().Debug("Gathering Requirements...")
@@ -652,6 +696,8 @@ func (o *StepVerifyPreInstallOptions) gatherGitRequirements(requirements *config
 		requirements.Cluster.GitKind = "github"
 	} else if gits.IsGitLabServerURL(requirements.Cluster.GitServer) {
 		requirements.Cluster.GitKind = "gitlab"
+	} else if gits.SaasGitKind(requirements.Cluster.GitServer) == gits.KindAzureDevOps {
+		requirements.Cluster.GitKind = gits.KindAzureDevOps
 	}
 
 	var err error
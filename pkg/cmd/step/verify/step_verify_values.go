@@ -12,7 +12,6 @@ import (
 	"github.com/jenkins-x/jx/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/pkg/config"
-	"github.com/jenkins-x/jx/pkg/io/secrets"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/secreturl"
 	"github.com/jenkins-x/jx/pkg/surveyutils"
@@ -132,7 +131,7 @@ func (o *StepVerifyValuesOptions) Run() error {
 		return errors.Wrapf(err, "reading the values from file %q", o.ValuesFile)
 	}
 
-	values, err = o.resolveSecrets(requirements, values)
+	values, err = o.resolveSecrets(requirements, reqFile, values)
 	if err != nil {
 		return errors.Wrapf(err, "resolve the secrets URIs")
 	}
@@ -175,8 +174,8 @@ func (o *StepVerifyValuesOptions) verifySchema(schema []byte, values []byte) err
 	return errors.New("invalid values")
 }
 
-func (o *StepVerifyValuesOptions) resolveSecrets(requirements *config.RequirementsConfig, values []byte) ([]byte, error) {
-	client, err := o.secretClient(requirements.SecretStorage)
+func (o *StepVerifyValuesOptions) resolveSecrets(requirements *config.RequirementsConfig, requirementsFileName string, values []byte) ([]byte, error) {
+	client, err := o.secretClient(requirements, requirementsFileName)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating secret client")
 	}
@@ -187,13 +186,11 @@ func (o *StepVerifyValuesOptions) resolveSecrets(requirements *config.Requiremen
 	return []byte(result), nil
 }
 
-func (o *StepVerifyValuesOptions) secretClient(secretStorage config.SecretStorageType) (secreturl.Client, error) {
+func (o *StepVerifyValuesOptions) secretClient(requirements *config.RequirementsConfig, requirementsFileName string) (secreturl.Client, error) {
 	if o.SecretClient != nil {
 		return o.SecretClient, nil
 	}
-
-	location := secrets.ToSecretsLocation(string(secretStorage))
-	return o.GetSecretURLClient(location)
+	return o.GetSecretURLClientForRequirements(requirements, requirementsFileName)
 }
 
 func convertYamlToJson(yml []byte) ([]byte, error) {
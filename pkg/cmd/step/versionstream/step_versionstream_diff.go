@@ -0,0 +1,306 @@
+package versionstream
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StepVersionStreamDiffOptions contains the command line flags
+type StepVersionStreamDiffOptions struct {
+	step.StepOptions
+
+	URL        string
+	SkipImpact bool
+	Markdown   bool
+
+	cloneDirs []string
+}
+
+var (
+	stepVersionStreamDiffLong = templates.LongDesc(`
+		Lists the charts, images, packages, git repositories and quickstarts whose versions differ between two refs of the version stream along with links to their changelogs.
+
+		Each ref is either a directory containing a version stream or a git branch, tag or commit of the version stream repository of the team.
+
+		Unless impact analysis is skipped the deployments of each Environment and the Apps of the team are compared to the changes so that the environments which would be affected by an upgrade to the new ref are reported.
+` + helper.SeeAlsoText("jx get apps", "jx get environments"))
+
+	stepVersionStreamDiffExample = templates.Examples(`
+		# Compare two releases of the version stream
+		jx step versionstream diff v1.0.100 v1.0.120
+
+		# Compare the version stream of a Pull Request to master as markdown for a review comment
+		jx step versionstream diff master pull/123/head --markdown
+
+		# Compare two local checkouts of the version stream without looking at the cluster
+		jx step versionstream diff ~/old-versions ~/jenkins-x-versions --skip-impact
+	`)
+)
+
+// NewCmdStepVersionStreamDiff creates the command
+func NewCmdStepVersionStreamDiff(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepVersionStreamDiffOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "diff <from> <to>",
+		Short:   "Lists the versions which differ between two refs of the version stream and the environments they affect",
+		Long:    stepVersionStreamDiffLong,
+		Example: stepVersionStreamDiffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The git URL of the version stream. Defaults to the version stream of the team")
+	cmd.Flags().BoolVarP(&options.SkipImpact, "skip-impact", "", false, "Do not look for the environments and apps affected by the changes")
+	cmd.Flags().BoolVarP(&options.Markdown, "markdown", "", false, "Output the report as markdown such as for a Pull Request comment")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepVersionStreamDiffOptions) Run() error {
+	if len(o.Args) != 2 {
+		return errors.Errorf("expected the two refs of the version stream to compare but got %d arguments", len(o.Args))
+	}
+	defer func() {
+		for _, dir := range o.cloneDirs {
+			os.RemoveAll(dir)
+		}
+	}()
+	fromDir, err := o.versionStreamDir(o.Args[0])
+	if err != nil {
+		return err
+	}
+	toDir, err := o.versionStreamDir(o.Args[1])
+	if err != nil {
+		return err
+	}
+	changes, err := versionstream.DiffVersionStreams(fromDir, toDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to compare the version streams")
+	}
+
+	var impacts []*versionstream.Impact
+	if !o.SkipImpact && len(changes) > 0 {
+		workloads, err := o.findWorkloads(toDir)
+		if err != nil {
+			return errors.Wrap(err, "failed to find the workloads of the environments")
+		}
+		impacts = versionstream.FindImpacts(changes, workloads)
+	}
+
+	if o.Markdown {
+		o.renderMarkdown(changes, impacts)
+		return nil
+	}
+	o.renderTables(changes, impacts)
+	return nil
+}
+
+// versionStreamDir returns the directory of the ref, shallow cloning the version stream if it is not a directory
+func (o *StepVersionStreamDiffOptions) versionStreamDir(ref string) (string, error) {
+	exists, err := util.DirExists(ref)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return ref, nil
+	}
+	if o.URL == "" {
+		o.URL = config.DefaultVersionsURL
+		settings, err := o.TeamSettings()
+		if err != nil {
+			log.Logger().Warnf("failed to load the team settings so using the default version stream: %s", err.Error())
+		} else if settings.VersionStreamURL != "" {
+			o.URL = settings.VersionStreamURL
+		}
+	}
+	dir, err := ioutil.TempDir("", "jx-version-stream-")
+	if err != nil {
+		return "", err
+	}
+	o.cloneDirs = append(o.cloneDirs, dir)
+	err = o.Git().ShallowClone(dir, o.URL, ref, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clone %s of the version stream %s", ref, o.URL)
+	}
+	return dir, nil
+}
+
+// findWorkloads returns the deployments of the environments and the apps of the team
+func (o *StepVersionStreamDiffOptions) findWorkloads(versionsDir string) ([]*versionstream.Workload, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	envMap, envNames, err := kube.GetOrderedEnvironments(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the Environments in namespace %s", ns)
+	}
+
+	var answer []*versionstream.Workload
+	envNamespaces := map[string]string{}
+	for _, name := range envNames {
+		env := envMap[name]
+		envNs := env.Spec.Namespace
+		if envNs == "" || env.Spec.Kind == v1.EnvironmentKindTypePreview || env.Spec.RemoteCluster {
+			continue
+		}
+		envNamespaces[envNs] = name
+		deployments, err := kubeClient.AppsV1().Deployments(envNs).List(metav1.ListOptions{})
+		if err != nil {
+			log.Logger().Warnf("failed to list the deployments of environment %s in namespace %s: %s", name, envNs, err.Error())
+			continue
+		}
+		for _, d := range deployments.Items {
+			chart := d.Labels["chart"]
+			if chart == "" {
+				chart = d.Labels["helm.sh/chart"]
+			}
+			answer = append(answer, &versionstream.Workload{
+				Environment: name,
+				Namespace:   envNs,
+				Name:        "deployment/" + d.Name,
+				Chart:       chart,
+				Images:      podImages(&d.Spec.Template.Spec),
+			})
+		}
+	}
+
+	apps, err := jxClient.JenkinsV1().Apps(ns).List(metav1.ListOptions{})
+	if err != nil {
+		log.Logger().Warnf("failed to list the Apps in namespace %s: %s", ns, err.Error())
+		return answer, nil
+	}
+	prefixes, err := versionstream.GetRepositoryPrefixes(versionsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range apps.Items {
+		name := app.Labels[helm.LabelAppName]
+		if name == "" {
+			continue
+		}
+		if prefix := prefixes.PrefixForURL(app.Annotations[helm.AnnotationAppRepository]); prefix != "" {
+			name = prefix + "/" + name
+		}
+		envName := envNamespaces[app.Namespace]
+		if envName == "" {
+			envName = kube.LabelValueDevEnvironment
+		}
+		answer = append(answer, &versionstream.Workload{
+			Environment:  envName,
+			Namespace:    app.Namespace,
+			Name:         "app/" + app.Name,
+			ChartName:    name,
+			ChartVersion: app.Labels[helm.LabelAppVersion],
+		})
+	}
+	return answer, nil
+}
+
+func podImages(spec *corev1.PodSpec) []string {
+	var answer []string
+	for _, c := range spec.InitContainers {
+		answer = append(answer, c.Image)
+	}
+	for _, c := range spec.Containers {
+		answer = append(answer, c.Image)
+	}
+	return answer
+}
+
+func (o *StepVersionStreamDiffOptions) renderTables(changes []*versionstream.VersionChange, impacts []*versionstream.Impact) {
+	if len(changes) == 0 {
+		log.Logger().Infof("there are no version changes between %s and %s", util.ColorInfo(o.Args[0]), util.ColorInfo(o.Args[1]))
+		return
+	}
+	table := o.CreateTable()
+	table.AddRow("KIND", "NAME", "FROM", "TO", "CHANGELOG")
+	for _, c := range changes {
+		table.AddRow(string(c.Kind), c.Name, c.FromVersion, c.ToVersion, c.ChangelogURL)
+	}
+	table.Render()
+
+	if o.SkipImpact {
+		return
+	}
+	if len(impacts) == 0 {
+		log.Logger().Info("\nno running environments are affected by the changes")
+		return
+	}
+	log.Logger().Info("\naffected environments:\n")
+	table = o.CreateTable()
+	table.AddRow("ENVIRONMENT", "NAMESPACE", "RESOURCE", "NAME", "CURRENT", "TO")
+	for _, i := range impacts {
+		table.AddRow(i.Workload.Environment, i.Workload.Namespace, i.Workload.Name, i.Change.Name, i.CurrentVersion, i.Change.ToVersion)
+	}
+	table.Render()
+}
+
+func (o *StepVersionStreamDiffOptions) renderMarkdown(changes []*versionstream.VersionChange, impacts []*versionstream.Impact) {
+	out := o.Out
+	fmt.Fprintf(out, "## Version stream changes from `%s` to `%s`\n\n", o.Args[0], o.Args[1])
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "There are no version changes.")
+		return
+	}
+	fmt.Fprintln(out, "| Kind | Name | From | To | Changelog |")
+	fmt.Fprintln(out, "| --- | --- | --- | --- | --- |")
+	for _, c := range changes {
+		changelog := ""
+		if c.ChangelogURL != "" {
+			changelog = fmt.Sprintf("[changes](%s)", c.ChangelogURL)
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", c.Kind, c.Name, markdownVersion(c.FromVersion), markdownVersion(c.ToVersion), changelog)
+	}
+
+	if o.SkipImpact {
+		return
+	}
+	fmt.Fprint(out, "\n### Affected environments\n\n")
+	if len(impacts) == 0 {
+		fmt.Fprintln(out, "No running environments are affected by the changes.")
+		return
+	}
+	fmt.Fprintln(out, "| Environment | Namespace | Resource | Name | Current | To |")
+	fmt.Fprintln(out, "| --- | --- | --- | --- | --- | --- |")
+	for _, i := range impacts {
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %s |\n", i.Workload.Environment, i.Workload.Namespace, i.Workload.Name,
+			i.Change.Name, markdownVersion(i.CurrentVersion), markdownVersion(i.Change.ToVersion))
+	}
+}
+
+func markdownVersion(version string) string {
+	if version == "" {
+		return "-"
+	}
+	return "`" + strings.TrimSpace(version) + "`"
+}
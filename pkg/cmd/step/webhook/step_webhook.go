@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepWebhookOptions contains the command line flags
+type StepWebhookOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepWebhook Steps a command object for the "step webhook" command
+func NewCmdStepWebhook(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepWebhookOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "webhook [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepWebhookReplay(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepWebhookOptions) Run() error {
+	return o.Cmd.Help()
+}
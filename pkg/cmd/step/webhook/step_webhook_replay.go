@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jenkins-x/jx/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/webhooks"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultPipelineRunnerService = "pipelinerunner"
+)
+
+// StepWebhookReplayOptions contains the command line flags
+type StepWebhookReplayOptions struct {
+	step.StepOptions
+
+	ID      string
+	URL     string
+	Service string
+	Path    string
+}
+
+var (
+	stepWebhookReplayLong = templates.LongDesc(`
+		Sends a recorded webhook delivery to the pipeline runner again.
+
+		The pipeline runner records the deliveries it receives so a delivery can be replayed when a pipeline did not trigger. The delivery is sent through the Kubernetes API server to the pipeline runner service unless a URL is given.
+` + helper.SeeAlsoText("jx get webhooks"))
+
+	stepWebhookReplayExample = templates.Examples(`
+		# Replay a webhook delivery using an ID from 'jx get webhooks'
+		jx step webhook replay 20191021-120000.123456789
+
+		# Replay a webhook delivery to a pipeline runner URL
+		jx step webhook replay 20191021-120000.123456789 --url http://localhost:8080/
+	`)
+)
+
+// NewCmdStepWebhookReplay creates the command
+func NewCmdStepWebhookReplay(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepWebhookReplayOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "replay [id]",
+		Short:   "Sends a recorded webhook delivery to the pipeline runner again",
+		Long:    stepWebhookReplayLong,
+		Example: stepWebhookReplayExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.ID, "id", "i", "", "The ID of the webhook delivery to replay")
+	cmd.Flags().StringVarP(&options.URL, "url", "u", "", "The URL of the pipeline runner to send the delivery to. Defaults to the pipeline runner service")
+	cmd.Flags().StringVarP(&options.Service, "service", "s", defaultPipelineRunnerService, "The name of the pipeline runner service")
+	cmd.Flags().StringVarP(&options.Path, "path", "p", "/", "The path the pipeline runner service listens on for requests to trigger a pipeline")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepWebhookReplayOptions) Run() error {
+	id := o.ID
+	if id == "" && len(o.Args) > 0 {
+		id = o.Args[0]
+	}
+	if id == "" {
+		return util.MissingOption("id")
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	delivery, err := webhooks.NewDeliveryStore(kubeClient, ns).Get(id)
+	if err != nil {
+		return err
+	}
+	if delivery.PayloadOmitted {
+		return fmt.Errorf("webhook delivery %s cannot be replayed as its payload was too large to store", id)
+	}
+	if delivery.Payload == "" {
+		return fmt.Errorf("webhook delivery %s has no payload", id)
+	}
+
+	var data []byte
+	if o.URL != "" {
+		data, err = o.postToURL(delivery)
+	} else {
+		result := kubeClient.CoreV1().RESTClient().Post().
+			Namespace(ns).
+			Resource("services").
+			Name(o.Service).
+			SubResource("proxy").
+			Suffix(o.Path).
+			SetHeader("Content-Type", "application/json").
+			SetHeader(webhooks.ReplayOfHeader, delivery.ID).
+			Body([]byte(delivery.Payload)).
+			Do()
+		data, err = result.Raw()
+		if err != nil {
+			err = errors.Wrapf(err, "failed to send webhook delivery %s to service %s in namespace %s: %s", id, o.Service, ns, string(data))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	log.Logger().Debugf("pipeline runner response: %s", string(data))
+	log.Logger().Infof("Replayed webhook delivery %s for %s/%s. Use %s to see the pipeline it triggered", util.ColorInfo(id),
+		delivery.Owner, delivery.Repository, util.ColorInfo("jx get webhooks"))
+	return nil
+}
+
+func (o *StepWebhookReplayOptions) postToURL(delivery *webhooks.Delivery) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, o.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request to %s", o.URL)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.ReplayOfHeader, delivery.ID)
+	resp, err := util.GetClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send webhook delivery %s to %s", delivery.ID, o.URL)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the response from %s", o.URL)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return data, fmt.Errorf("failed to send webhook delivery %s to %s: status %d: %s", delivery.ID, o.URL, resp.StatusCode, string(data))
+	}
+	return data, nil
+}
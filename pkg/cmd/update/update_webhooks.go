@@ -265,6 +265,10 @@ func (o *UpdateWebhooksOptions) updateRepoHook(git gits.GitProvider, owner strin
 		webHookArgs.Repo.Organisation = owner
 	}
 	if isProwEnabled {
+		if !gits.SignsWebHooks(git.Kind()) {
+			log.Logger().Warnf("Skipping the webhook of repository %s/%s as %s does not sign webhooks so they cannot be validated", owner, repoName, git.Kind())
+			return nil
+		}
 		webHookArgs.Secret = hmacToken
 	}
 	if len(webhooks) > 0 {
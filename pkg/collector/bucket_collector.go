@@ -2,7 +2,7 @@ package collector
 
 import (
 	"bytes"
-	"io/ioutil"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -46,11 +46,12 @@ func (c *BucketCollector) CollectFiles(patterns []string, outputPath string, bas
 			if outputPath != "" {
 				toName = filepath.Join(outputPath, toName)
 			}
-			data, err := ioutil.ReadFile(name)
+			f, err := os.Open(name)
 			if err != nil {
 				return errors.Wrapf(err, "failed to read file %s", name)
 			}
-			url, err := c.provider.UploadFileToBucket(bytes.NewReader(data), toName, c.bucketURL)
+			defer f.Close()
+			url, err := c.provider.UploadFileToBucket(f, toName, c.bucketURL)
 			if err != nil {
 				return err
 			}
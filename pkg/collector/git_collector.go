@@ -39,9 +39,6 @@ func (c *GitCollector) CollectFiles(patterns []string, outputPath string, basedi
 	urls := []string{}
 
 	gitClient := c.gitter
-	storageGitInfo := c.gitInfo
-	storageOrg := storageGitInfo.Organisation
-	storageRepoName := storageGitInfo.Name
 
 	ghPagesDir, err := cloneGitHubPagesBranchToTempDir(c.gitInfo.URL, gitClient, c.gitBranch)
 	if err != nil {
@@ -78,7 +75,7 @@ func (c *GitCollector) CollectFiles(patterns []string, outputPath string, basedi
 
 			rPath := strings.TrimPrefix(strings.TrimPrefix(toFile, ghPagesDir), "/")
 			if rPath != "" {
-				url := c.generateURL(storageOrg, storageRepoName, rPath)
+				url := c.generateURL(rPath)
 				urls = append(urls, url)
 			}
 			return nil
@@ -115,9 +112,6 @@ func (c *GitCollector) CollectFiles(patterns []string, outputPath string, basedi
 func (c *GitCollector) CollectData(data []byte, outputPath string) (string, error) {
 	u := ""
 	gitClient := c.gitter
-	storageGitInfo := c.gitInfo
-	storageOrg := storageGitInfo.Organisation
-	storageRepoName := storageGitInfo.Name
 
 	ghPagesDir, err := cloneGitHubPagesBranchToTempDir(c.gitInfo.URL, gitClient, c.gitBranch)
 	if err != nil {
@@ -137,7 +131,7 @@ func (c *GitCollector) CollectData(data []byte, outputPath string) (string, erro
 		return u, errors.Wrapf(err, "failed to write file %s", toFile)
 	}
 
-	u = c.generateURL(storageOrg, storageRepoName, outputPath)
+	u = c.generateURL(outputPath)
 
 	err = gitClient.Add(ghPagesDir, toDir)
 	if err != nil {
@@ -158,17 +152,58 @@ func (c *GitCollector) CollectData(data []byte, outputPath string) (string, erro
 	return u, err
 }
 
-func (c *GitCollector) generateURL(storageOrg string, storageRepoName string, rPath string) (url string) {
-	if !c.gitInfo.IsGitHub() && gits.SaasGitKind(c.gitInfo.Host) == gits.KindGitHub {
-		url = fmt.Sprintf("https://raw.%s/%s/%s/%s/%s", c.gitInfo.Host, storageOrg, storageRepoName, c.gitBranch, rPath)
-	} else {
-		// TODO only supporting github for now!!!
-		url = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", storageOrg, storageRepoName, c.gitBranch, rPath)
-	}
+func (c *GitCollector) generateURL(rPath string) (url string) {
+	url = gitFileURL(c.gitInfo, c.gitBranch, rPath)
 	log.Logger().Infof("Publishing %s", util.ColorInfo(url))
 	return url
 }
 
+// gitFileURL returns the URL to read the raw content of the file at the given path in the branch of the repository
+func gitFileURL(gitInfo *gits.GitRepository, gitBranch string, rPath string) string {
+	if !gitInfo.IsGitHub() && gits.SaasGitKind(gitInfo.Host) == gits.KindGitHub {
+		return fmt.Sprintf("https://raw.%s/%s/%s/%s/%s", gitInfo.Host, gitInfo.Organisation, gitInfo.Name, gitBranch, rPath)
+	}
+	// TODO only supporting github for now!!!
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", gitInfo.Organisation, gitInfo.Name, gitBranch, rPath)
+}
+
+// readGitFiles clones the branch of the repository and reads the files in the directory, returning the contents
+// keyed by the path relative to the directory
+func readGitFiles(gitter gits.Gitter, gitURL string, gitBranch string, dir string) (map[string][]byte, error) {
+	cloneDir, err := ioutil.TempDir("", "jenkins-x-collect")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	err = gitter.ShallowClone(cloneDir, gitURL, gitBranch, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clone %s branch %s", gitURL, gitBranch)
+	}
+	answer := map[string][]byte{}
+	root := filepath.Join(cloneDir, dir)
+	exists, err := util.DirExists(root)
+	if err != nil || !exists {
+		return answer, err
+	}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", path)
+		}
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		answer[filepath.ToSlash(name)] = data
+		return nil
+	})
+	return answer, err
+}
+
 // cloneGitHubPagesBranchToTempDir clones the github pages branch to a temp dir
 func cloneGitHubPagesBranchToTempDir(sourceURL string, gitClient gits.Gitter, branchName string) (string, error) {
 	// First clone the git repo
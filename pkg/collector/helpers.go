@@ -1,10 +1,15 @@
 package collector
 
 import (
+	"net/url"
+	"time"
+
 	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
 	"github.com/jenkins-x/jx/pkg/cloud/factory"
 	"github.com/jenkins-x/jx/pkg/cmd/clients"
 	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 )
 
@@ -24,3 +29,35 @@ func NewCollector(storageLocation v1.StorageLocation, gitter gits.Gitter) (Colle
 	}
 	return NewBucketCollector(storageLocation.BucketURL, classifier, bucketProvider)
 }
+
+// StorageURL returns the URL that a file collected to the given output path in the storage location can be read from
+func StorageURL(storageLocation v1.StorageLocation, outputPath string) (string, error) {
+	if storageLocation.GitURL != "" {
+		gitInfo, err := gits.ParseGitURL(storageLocation.GitURL)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse git URL %s", storageLocation.GitURL)
+		}
+		return gitFileURL(gitInfo, storageLocation.GetGitBranch(), outputPath), nil
+	}
+	if storageLocation.BucketURL == "" {
+		return "", errors.Errorf("no git URL or bucket URL configured for storage location %s", storageLocation.Description())
+	}
+	return util.UrlJoin(storageLocation.BucketURL, outputPath), nil
+}
+
+// ReadFiles reads the files in the directory of the storage location, returning the contents keyed by the path
+// relative to the directory. Git storage is cloned and buckets are listed so that the latest files are read rather
+// than copies cached by the URLs the files are published at
+func ReadFiles(storageLocation v1.StorageLocation, gitter gits.Gitter, dir string, timeout time.Duration) (map[string][]byte, error) {
+	if storageLocation.GitURL != "" {
+		return readGitFiles(gitter, storageLocation.GitURL, storageLocation.GetGitBranch(), dir)
+	}
+	if storageLocation.BucketURL == "" {
+		return nil, errors.Errorf("no git URL or bucket URL configured for storage location %s", storageLocation.Description())
+	}
+	u, err := url.Parse(util.UrlJoin(storageLocation.BucketURL, dir))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the bucket URL %s", storageLocation.BucketURL)
+	}
+	return buckets.ReadBucketFiles(u, timeout)
+}
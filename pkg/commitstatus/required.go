@@ -0,0 +1,166 @@
+package commitstatus
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRequiredContext the name of the aggregate commit status context of the required checks
+	DefaultRequiredContext = "jx/required"
+
+	// StateSuccess the commit status state when all the required checks passed
+	StateSuccess = "success"
+	// StateFailure the commit status state when any required check failed
+	StateFailure = "failure"
+	// StatePending the commit status state when any required check has not completed yet
+	StatePending = "pending"
+
+	// maxDescriptionLength is the longest commit status description GitHub accepts
+	maxDescriptionLength = 140
+)
+
+// RequiredStatus the aggregate status of the required checks of a commit
+type RequiredStatus struct {
+	Context     string
+	State       string
+	Description string
+	Passed      []string
+	Failed      []string
+	Pending     []string
+}
+
+// MatchRequiredChecks returns the first policy whose branch pattern matches the whole branch name or nil if there is
+// none
+func MatchRequiredChecks(policies []v1.RequiredChecksPolicy, branch string) (*v1.RequiredChecksPolicy, error) {
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Branches == "" {
+			continue
+		}
+		r, err := regexp.Compile("^(?:" + policy.Branches + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid branch pattern %s of the required checks", policy.Branches)
+		}
+		if r.MatchString(branch) {
+			return policy, nil
+		}
+	}
+	return nil, nil
+}
+
+// Aggregate computes the aggregate status of the required checks of the policy for a commit from the commit status
+// details recorded by the commit status controller, falling back to the statuses on the git provider for contexts
+// which are reported by something else
+func Aggregate(policy *v1.RequiredChecksPolicy, details []v1.CommitStatusDetails, providerStatuses []*gits.GitRepoStatus) *RequiredStatus {
+	answer := &RequiredStatus{
+		Context: policy.Context,
+	}
+	if answer.Context == "" {
+		answer.Context = DefaultRequiredContext
+	}
+	latest := latestDetails(details)
+	for _, context := range policy.Contexts {
+		if context == "" || context == answer.Context {
+			continue
+		}
+		state := StatePending
+		if d, ok := latest[context]; ok {
+			state = detailsState(d)
+		} else if s := findProviderStatus(providerStatuses, context); s != nil {
+			state = providerState(s.State)
+		}
+		switch state {
+		case StateSuccess:
+			answer.Passed = append(answer.Passed, context)
+		case StateFailure:
+			answer.Failed = append(answer.Failed, context)
+		default:
+			answer.Pending = append(answer.Pending, context)
+		}
+	}
+	switch {
+	case len(answer.Failed) > 0:
+		answer.State = StateFailure
+		answer.Description = "Required checks failed: " + strings.Join(answer.Failed, ", ")
+	case len(answer.Pending) > 0:
+		answer.State = StatePending
+		answer.Description = "Waiting for required checks: " + strings.Join(answer.Pending, ", ")
+	default:
+		answer.State = StateSuccess
+		answer.Description = fmt.Sprintf("All %d required checks passed", len(answer.Passed))
+	}
+	if len(answer.Description) > maxDescriptionLength {
+		answer.Description = answer.Description[:maxDescriptionLength-3] + "..."
+	}
+	return answer
+}
+
+// latestDetails returns the details of the newest pipeline activity for each context
+func latestDetails(details []v1.CommitStatusDetails) map[string]v1.CommitStatusDetails {
+	sorted := make([]v1.CommitStatusDetails, len(details))
+	copy(sorted, details)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return buildNumber(sorted[i].PipelineActivity.Name) < buildNumber(sorted[j].PipelineActivity.Name)
+	})
+	answer := map[string]v1.CommitStatusDetails{}
+	for _, d := range sorted {
+		answer[d.Context] = d
+	}
+	return answer
+}
+
+// buildNumber returns the build number at the end of the name of a pipeline activity or -1 if it has none
+func buildNumber(pipelineActName string) int {
+	idx := strings.LastIndex(pipelineActName, "-")
+	if idx < 0 {
+		return -1
+	}
+	n, err := strconv.Atoi(pipelineActName[idx+1:])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func detailsState(d v1.CommitStatusDetails) string {
+	if !d.Checked {
+		return StatePending
+	}
+	for _, item := range d.Items {
+		if !item.Pass {
+			return StateFailure
+		}
+	}
+	return StateSuccess
+}
+
+// findProviderStatus returns the most recent status of the context, relying on git providers listing the most
+// recent statuses first
+func findProviderStatus(statuses []*gits.GitRepoStatus, context string) *gits.GitRepoStatus {
+	for _, s := range statuses {
+		if s != nil && s.Context == context {
+			return s
+		}
+	}
+	return nil
+}
+
+// providerState normalises the different states used by the git providers
+func providerState(state string) string {
+	switch strings.ToLower(state) {
+	case "success", "successful":
+		return StateSuccess
+	case "failure", "failed", "error", "stopped", "canceled":
+		return StateFailure
+	default:
+		return StatePending
+	}
+}
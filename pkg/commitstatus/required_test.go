@@ -0,0 +1,86 @@
+package commitstatus_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/commitstatus"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func details(context string, activity string, checked bool, pass ...bool) v1.CommitStatusDetails {
+	d := v1.CommitStatusDetails{
+		PipelineActivity: v1.ResourceReference{Name: activity},
+		Checked:          checked,
+		Commit:           v1.CommitStatusCommitReference{SHA: "abc", BaseRef: "master"},
+		Context:          context,
+	}
+	for _, p := range pass {
+		d.Items = append(d.Items, v1.CommitStatusItem{Name: context, Pass: p})
+	}
+	return d
+}
+
+func TestMatchRequiredChecks(t *testing.T) {
+	t.Parallel()
+	policies := []v1.RequiredChecksPolicy{
+		{Branches: "master", Contexts: []string{"unit", "integration"}},
+		{Branches: "release-.*", Contexts: []string{"unit"}, Context: "jx/release"},
+	}
+
+	policy, err := commitstatus.MatchRequiredChecks(policies, "master")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, []string{"unit", "integration"}, policy.Contexts)
+
+	policy, err = commitstatus.MatchRequiredChecks(policies, "release-1.2")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, "jx/release", policy.Context)
+
+	policy, err = commitstatus.MatchRequiredChecks(policies, "feature-master")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	_, err = commitstatus.MatchRequiredChecks([]v1.RequiredChecksPolicy{{Branches: "release-("}}, "master")
+	assert.Error(t, err)
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+	policy := &v1.RequiredChecksPolicy{Branches: "master", Contexts: []string{"unit", "integration", "lint"}}
+
+	status := commitstatus.Aggregate(policy, []v1.CommitStatusDetails{
+		details("unit", "myorg-myapp-pr-1-2", true, true),
+		details("integration", "myorg-myapp-pr-1-2", false),
+	}, []*gits.GitRepoStatus{
+		{Context: "lint", State: "success"},
+	})
+	assert.Equal(t, commitstatus.DefaultRequiredContext, status.Context)
+	assert.Equal(t, commitstatus.StatePending, status.State)
+	assert.Equal(t, "Waiting for required checks: integration", status.Description)
+	assert.Equal(t, []string{"unit", "lint"}, status.Passed)
+
+	status = commitstatus.Aggregate(policy, []v1.CommitStatusDetails{
+		details("integration", "myorg-myapp-pr-1-3", true, true),
+		details("unit", "myorg-myapp-pr-1-3", true, true),
+		details("integration", "myorg-myapp-pr-1-2", true, false),
+	}, []*gits.GitRepoStatus{
+		{Context: "lint", State: "failed"},
+		{Context: "lint", State: "success"},
+	})
+	assert.Equal(t, commitstatus.StateFailure, status.State)
+	assert.Equal(t, "Required checks failed: lint", status.Description)
+	assert.Equal(t, []string{"unit", "integration"}, status.Passed)
+
+	status = commitstatus.Aggregate(policy, []v1.CommitStatusDetails{
+		details("integration", "myorg-myapp-pr-1-10", true, true),
+		details("unit", "myorg-myapp-pr-1-10", true),
+		details("lint", "myorg-myapp-pr-1-9", true, false),
+		details("lint", "myorg-myapp-pr-1-10", true, true),
+	}, nil)
+	assert.Equal(t, commitstatus.StateSuccess, status.State)
+	assert.Equal(t, "All 3 required checks passed", status.Description)
+}
@@ -124,10 +124,13 @@ const (
 	// SecretStorageTypeLocal specifies that we use the local file system in
 	// `~/.jx/localSecrets` to store secrets
 	SecretStorageTypeLocal SecretStorageType = "local"
+	// SecretStorageTypeSops specifies that we use files encrypted with sops
+	// in the development environment git repository to store secrets
+	SecretStorageTypeSops SecretStorageType = "sops"
 )
 
 // SecretStorageTypeValues the string values for the secret storage
-var SecretStorageTypeValues = []string{"local", "vault"}
+var SecretStorageTypeValues = []string{"local", "sops", "vault"}
 
 // WebhookType is the type of a webhook strategy
 type WebhookType string
@@ -347,6 +350,16 @@ type VaultConfig struct {
 	AWSConfig           *VaultAWSConfig `json:"aws,omitempty"`
 }
 
+// SopsConfig contains the configuration of the secrets encrypted with sops if using sops for secrets
+type SopsConfig struct {
+	// Dir the directory of the encrypted secrets relative to the requirements file. Defaults to secrets
+	Dir string `json:"dir,omitempty"`
+	// AgeRecipients the age public keys which encrypt new secrets
+	AgeRecipients []string `json:"ageRecipients,omitempty"`
+	// PGPFingerprints the fingerprints of the PGP keys which encrypt new secrets
+	PGPFingerprints []string `json:"pgpFingerprints,omitempty"`
+}
+
 // VaultAWSConfig contains all the Vault configuration needed by Vault to be deployed in AWS
 type VaultAWSConfig struct {
 	VaultAWSUnsealConfig
@@ -462,6 +475,9 @@ type RequirementsConfig struct {
 	Repository RepositoryType `json:"repository,omitempty"`
 	// SecretStorage how should we store secrets for the cluster
 	SecretStorage SecretStorageType `json:"secretStorage,omitempty"`
+	// Sops the configuration for the secrets encrypted with sops. If there are no recipients the creation rules
+	// of the .sops.yaml file in the development environment git repository are used
+	Sops SopsConfig `json:"sops,omitempty"`
 	// Storage contains storage requirements
 	Storage StorageConfig `json:"storage"`
 	// Terraform specifies if  we are managing the kubernetes cluster and cloud resources with Terraform
@@ -7,6 +7,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/pkg/tekton/syntax"
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	"sigs.k8s.io/yaml"
 
 	"io/ioutil"
@@ -41,11 +42,41 @@ type ProjectConfig struct {
 	NoReleasePrepare    bool                        `json:"noReleasePrepare,omitempty"`
 	DockerRegistryHost  string                      `json:"dockerRegistryHost,omitempty"`
 	DockerRegistryOwner string                      `json:"dockerRegistryOwner,omitempty"`
+	ImageScan           *ImageScanConfig            `json:"imageScan,omitempty"`
 }
 
 type PreviewEnvironmentConfig struct {
 	Disabled         bool `json:"disabled,omitempty"`
 	MaximumInstances int  `json:"maximumInstances,omitempty"`
+
+	// Dependencies the other apps and seed data deployed into each Preview Environment
+	Dependencies *PreviewDependenciesConfig `json:"dependencies,omitempty"`
+}
+
+// PreviewDependenciesConfig the apps whose versions in a permanent Environment are deployed into each Preview
+// Environment along with the Jobs which seed it with data
+type PreviewDependenciesConfig struct {
+	// Environment the Environment whose requirements provide the versions of the apps and whose values configure them.
+	// Defaults to staging
+	Environment string `json:"environment,omitempty"`
+	// Apps the names or aliases of the apps in the requirements of the Environment
+	Apps []string `json:"apps,omitempty"`
+	// SeedJobs the Jobs run in the Preview Environment after it is deployed and before any post preview Jobs
+	SeedJobs []batchv1.Job `json:"seedJobs,omitempty"`
+}
+
+// ImageScanConfig the policy used by 'jx step scan image' to decide which vulnerabilities of the images of the
+// project fail the pipeline
+type ImageScanConfig struct {
+	// FailOn the lowest severity of vulnerability which fails the pipeline such as high or critical. Defaults to none
+	FailOn string `json:"failOn,omitempty"`
+	// Ignore the vulnerability IDs, wildcards or package names to ignore such as CVE-2019-1547, CVE-2019-* or openssl
+	Ignore []string `json:"ignore,omitempty"`
+	// IgnoreUnfixed ignores vulnerabilities which have no fixed version yet
+	IgnoreUnfixed bool `json:"ignoreUnfixed,omitempty"`
+	// IgnoreUnknownSeverity stops vulnerabilities of an unknown severity, such as Alpine advisories, failing the
+	// pipeline
+	IgnoreUnknownSeverity bool `json:"ignoreUnknownSeverity,omitempty"`
 }
 
 type IssueTrackerConfig struct {
@@ -102,3 +102,39 @@ func TestGetPipeline(t *testing.T) {
 	assert.Equal(t, err.Error(), "no pipeline defined for kind feature")
 	assert.Nil(t, featurePipeline)
 }
+
+func TestProjectConfigPreviewDependencies(t *testing.T) {
+	t.Parallel()
+
+	text := `previewEnvironments:
+  dependencies:
+    environment: staging
+    apps:
+    - orders
+    - payments
+    seedJobs:
+    - metadata:
+        name: seed-orders
+      spec:
+        template:
+          spec:
+            restartPolicy: Never
+            containers:
+            - name: seed
+              image: myorg/orders-seed:1.0.0
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+
+	dependencies := projectConfig.PreviewEnvironments.Dependencies
+	if assert.NotNil(t, dependencies, "projectConfig.PreviewEnvironments.Dependencies") {
+		assert.Equal(t, "staging", dependencies.Environment)
+		assert.Equal(t, []string{"orders", "payments"}, dependencies.Apps)
+		if assert.Equal(t, 1, len(dependencies.SeedJobs), "len(dependencies.SeedJobs)") {
+			job := dependencies.SeedJobs[0]
+			assert.Equal(t, "seed-orders", job.Name)
+			assert.Equal(t, "myorg/orders-seed:1.0.0", job.Spec.Template.Spec.Containers[0].Image)
+		}
+	}
+}
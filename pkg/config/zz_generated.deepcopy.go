@@ -504,6 +504,27 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageScanConfig) DeepCopyInto(out *ImageScanConfig) {
+	*out = *in
+	if in.Ignore != nil {
+		in, out := &in.Ignore, &out.Ignore
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScanConfig.
+func (in *ImageScanConfig) DeepCopy() *ImageScanConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageScanConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 	*out = *in
@@ -900,6 +921,15 @@ func (in *ProjectConfig) DeepCopyInto(out *ProjectConfig) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.ImageScan != nil {
+		in, out := &in.ImageScan, &out.ImageScan
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ImageScanConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -949,6 +979,7 @@ func (in *RequirementsConfig) DeepCopyInto(out *RequirementsConfig) {
 		}
 	}
 	out.Ingress = in.Ingress
+	in.Sops.DeepCopyInto(&out.Sops)
 	out.Storage = in.Storage
 	in.Vault.DeepCopyInto(&out.Vault)
 	out.Velero = in.Velero
@@ -966,6 +997,32 @@ func (in *RequirementsConfig) DeepCopy() *RequirementsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SopsConfig) DeepCopyInto(out *SopsConfig) {
+	*out = *in
+	if in.AgeRecipients != nil {
+		in, out := &in.AgeRecipients, &out.AgeRecipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PGPFingerprints != nil {
+		in, out := &in.PGPFingerprints, &out.PGPFingerprints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SopsConfig.
+func (in *SopsConfig) DeepCopy() *SopsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SopsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 	*out = *in
@@ -186,18 +186,7 @@ func (a AnchoreProvider) addVulnerabilitiesTableRows(table *table.Table, vList *
 	}
 	// TODO sort vList on severity and version?
 
-	for _, v := range vList.Vulnerabilities {
-		var sev string
-		switch v.Severity {
-		case "High":
-			sev = util.ColorError(v.Severity)
-		case "Medium":
-			sev = util.ColorWarning(v.Severity)
-		case "Low":
-			sev = util.ColorStatus(v.Severity)
-		}
-		table.AddRow(image[0].ImageDetails[0].Fulltag, sev, v.Vuln, v.URL, v.Package, v.Fix)
-	}
+	addVulnerabilityRows(table, image[0].ImageDetails[0].Fulltag, vList.Vulnerabilities)
 	return nil
 }
 
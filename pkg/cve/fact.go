@@ -0,0 +1,87 @@
+package cve
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube/naming"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The labels of Facts about a pipeline as recommended by the Fact resource
+const (
+	LabelSubjectKind  = "subjectkind"
+	LabelPipelineName = "pipelineName"
+	LabelOrg          = "org"
+	LabelRepo         = "repo"
+	LabelBranch       = "branch"
+	LabelBuildNumber  = "buildNumber"
+)
+
+// maxLabelLength the maximum length of a label value
+const maxLabelLength = 63
+
+// NewImageScanFact creates a Fact which records the vulnerabilities found when the pipeline of the activity
+// scanned an image along with whether they passed the policy
+func NewImageScanFact(activity *v1.PipelineActivity, image string, policy *Policy, result *PolicyResult) *v1.Fact {
+	name := naming.ToValidNameTruncated(fmt.Sprintf("jx-image-scan-%s-%s", activity.Name, image), 253)
+
+	var measurements []v1.Measurement
+	counts := result.CountBySeverity()
+	for _, s := range Severities {
+		measurements = append(measurements, v1.Measurement{
+			Name:             s,
+			MeasurementType:  v1.MeasurementCount,
+			MeasurementValue: counts[s],
+		})
+	}
+	measurements = append(measurements, v1.Measurement{
+		Name:             v1.ImageScanMeasurementIgnored,
+		MeasurementType:  v1.MeasurementCount,
+		MeasurementValue: len(result.Ignored),
+	})
+
+	var violations []string
+	for _, v := range result.Violations {
+		violations = append(violations, v.Vuln)
+	}
+	failOn := policy.FailOn
+	if failOn == "" {
+		failOn = "none"
+	}
+
+	return &v1.Fact{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				LabelSubjectKind:  "PipelineActivity",
+				LabelPipelineName: naming.ToValidNameTruncated(activity.Name, maxLabelLength),
+				LabelOrg:          naming.ToValidNameTruncated(activity.Spec.GitOwner, maxLabelLength),
+				LabelRepo:         naming.ToValidNameTruncated(activity.Spec.GitRepository, maxLabelLength),
+				LabelBranch:       naming.ToValidNameTruncated(activity.Spec.GitBranch, maxLabelLength),
+				LabelBuildNumber:  activity.Spec.Build,
+			},
+		},
+		Spec: v1.FactSpec{
+			Name:         name,
+			FactType:     v1.FactTypeImageScan,
+			Measurements: measurements,
+			Statements: []v1.Statement{
+				{
+					Name:             v1.ImageScanStatementPolicy,
+					StatementType:    "failOn=" + strings.ToLower(failOn),
+					MeasurementValue: result.Passed(),
+					Tags:             violations,
+				},
+			},
+			Tags: []string{"image=" + image},
+			SubjectReference: v1.ResourceReference{
+				APIVersion: v1.SchemeGroupVersion.String(),
+				Kind:       "PipelineActivity",
+				Name:       activity.Name,
+				UID:        activity.UID,
+			},
+		},
+	}
+}
@@ -0,0 +1,206 @@
+package cve
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AlpineSecDBURL the URL of the Alpine security database which has a JSON file per version and repository
+	// such as v3.10/main.json
+	AlpineSecDBURL = "https://secdb.alpinelinux.org"
+	// DebianSecurityTrackerURL the URL of the JSON export of the Debian security tracker
+	DebianSecurityTrackerURL = "https://security-tracker.debian.org/tracker/data/json"
+
+	debianTrackerURL = "https://security-tracker.debian.org/tracker/"
+	nvdURL           = "https://nvd.nist.gov/vuln/detail/"
+)
+
+// debianReleases the versions of the Debian releases of the security tracker
+var debianReleases = map[string]string{
+	"jessie":   "8",
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+}
+
+// debianUrgencies the severities of the urgencies of the Debian security tracker
+var debianUrgencies = map[string]string{
+	"unimportant": SeverityNegligible,
+	"low":         SeverityLow,
+	"medium":      SeverityMedium,
+	"high":        SeverityHigh,
+}
+
+type alpineSecDB struct {
+	DistroVersion string `json:"distroversion"`
+	Packages      []struct {
+		Pkg struct {
+			Name     string              `json:"name"`
+			SecFixes map[string][]string `json:"secfixes"`
+		} `json:"pkg"`
+	} `json:"packages"`
+}
+
+type debianTrackerRelease struct {
+	Status       string `json:"status"`
+	FixedVersion string `json:"fixed_version"`
+	Urgency      string `json:"urgency"`
+}
+
+type debianTrackerIssue struct {
+	Releases map[string]debianTrackerRelease `json:"releases"`
+}
+
+// ParseVulnerabilityFeeds parses a vulnerability feed in the format of VulnerabilityFeed, a file of the Alpine
+// security database or the JSON export of the Debian security tracker
+func ParseVulnerabilityFeeds(data []byte) ([]*VulnerabilityFeed, error) {
+	fields := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case fields["distro"] != nil:
+		feed := &VulnerabilityFeed{}
+		err = json.Unmarshal(data, feed)
+		if err != nil {
+			return nil, err
+		}
+		return []*VulnerabilityFeed{feed}, nil
+	case fields["distroversion"] != nil && fields["packages"] != nil:
+		feed, err := ConvertAlpineSecDB(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*VulnerabilityFeed{feed}, nil
+	default:
+		return ConvertDebianSecurityTracker(data)
+	}
+}
+
+// ConvertAlpineSecDB converts a file of the Alpine security database such as v3.10/main.json. The database lists the
+// vulnerabilities fixed in each version of the origin packages. Vulnerabilities listed for version 0 never affected
+// the package so they are skipped. The database has no severities
+func ConvertAlpineSecDB(data []byte) (*VulnerabilityFeed, error) {
+	db := &alpineSecDB{}
+	err := json.Unmarshal(data, db)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the Alpine security database")
+	}
+	if db.DistroVersion == "" {
+		return nil, errors.New("the Alpine security database has no distroversion")
+	}
+	feed := &VulnerabilityFeed{
+		Distro:        "alpine",
+		DistroVersion: strings.TrimPrefix(db.DistroVersion, "v"),
+	}
+	for _, p := range db.Packages {
+		versions := make([]string, 0, len(p.Pkg.SecFixes))
+		for version := range p.Pkg.SecFixes {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			if version == "0" {
+				continue
+			}
+			for _, ids := range p.Pkg.SecFixes[version] {
+				// entries may list aliases of the vulnerability such as 'CVE-2019-1547 ALPINE-10491'
+				for _, id := range strings.Fields(ids) {
+					feed.Vulnerabilities = append(feed.Vulnerabilities, Advisory{
+						ID:           id,
+						Package:      p.Pkg.Name,
+						FixedVersion: version,
+						Severity:     SeverityUnknown,
+						URL:          advisoryURL(nvdURL, id),
+					})
+				}
+			}
+		}
+	}
+	return feed, nil
+}
+
+// ConvertDebianSecurityTracker converts the JSON export of the Debian security tracker into a feed for each Debian
+// release. Open issues have no fixed version while issues which were resolved in version 0 never affected the
+// release so they are skipped like undetermined issues
+func ConvertDebianSecurityTracker(data []byte) ([]*VulnerabilityFeed, error) {
+	tracker := map[string]map[string]debianTrackerIssue{}
+	err := json.Unmarshal(data, &tracker)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the Debian security tracker")
+	}
+	feeds := map[string]*VulnerabilityFeed{}
+	packages := make([]string, 0, len(tracker))
+	for name := range tracker {
+		packages = append(packages, name)
+	}
+	sort.Strings(packages)
+	for _, name := range packages {
+		issues := tracker[name]
+		ids := make([]string, 0, len(issues))
+		for id := range issues {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			for release, r := range issues[id].Releases {
+				version := debianReleases[release]
+				if version == "" {
+					continue
+				}
+				fixed := ""
+				switch r.Status {
+				case "open":
+				case "resolved":
+					if r.FixedVersion == "" || r.FixedVersion == "0" {
+						continue
+					}
+					fixed = r.FixedVersion
+				default:
+					continue
+				}
+				feed := feeds[version]
+				if feed == nil {
+					feed = &VulnerabilityFeed{Distro: "debian", DistroVersion: version}
+					feeds[version] = feed
+				}
+				severity := debianUrgencies[strings.TrimRight(r.Urgency, "*")]
+				if severity == "" {
+					severity = SeverityUnknown
+				}
+				feed.Vulnerabilities = append(feed.Vulnerabilities, Advisory{
+					ID:           id,
+					Package:      name,
+					FixedVersion: fixed,
+					Severity:     severity,
+					URL:          advisoryURL(debianTrackerURL, id),
+				})
+			}
+		}
+	}
+	if len(feeds) == 0 {
+		return nil, errors.New("no vulnerabilities of a supported Debian release found")
+	}
+	answer := make([]*VulnerabilityFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		answer = append(answer, feed)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return CompareVersions(answer[i].DistroVersion, answer[j].DistroVersion) < 0
+	})
+	return answer, nil
+}
+
+func advisoryURL(prefix string, id string) string {
+	if !strings.HasPrefix(id, "CVE-") {
+		return ""
+	}
+	return prefix + id
+}
@@ -0,0 +1,306 @@
+package cve
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	osReleaseFile    = "etc/os-release"
+	usrOSReleaseFile = "usr/lib/os-release"
+	dpkgStatusFile   = "var/lib/dpkg/status"
+	apkInstalledFile = "lib/apk/db/installed"
+	whiteoutPrefix   = ".wh."
+	opaqueWhiteout   = ".wh..wh..opq"
+)
+
+// Package an operating system package installed in an image
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source the source package the package was built from if it differs which is what security trackers
+	// report vulnerabilities against
+	Source        string `json:"source,omitempty"`
+	SourceVersion string `json:"sourceVersion,omitempty"`
+}
+
+// SourceNameAndVersion returns the name and version of the source package of the package
+func (p *Package) SourceNameAndVersion() (string, string) {
+	name, version := p.Name, p.Version
+	if p.Source != "" {
+		name = p.Source
+	}
+	if p.SourceVersion != "" {
+		version = p.SourceVersion
+	}
+	return name, version
+}
+
+// ImageContents the operating system and packages installed in an image
+type ImageContents struct {
+	Distro        string    `json:"distro,omitempty"`
+	DistroVersion string    `json:"distroVersion,omitempty"`
+	Packages      []Package `json:"packages,omitempty"`
+}
+
+type archiveManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// ReadImageContents reads the operating system and packages of an image from a tarball in the format of
+// 'docker save' or the '--tarPath' of kaniko. Each layer is streamed once keeping only its package database files
+func ReadImageContents(fileName string) (*ImageContents, error) {
+	var manifestData []byte
+	err := walkArchive(fileName, func(name string, r io.Reader) error {
+		if name != "manifest.json" {
+			return nil
+		}
+		var err error
+		manifestData, err = ioutil.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifestData == nil {
+		return nil, errors.Errorf("%s is not an image archive as it has no manifest.json", fileName)
+	}
+	var manifests []archiveManifest
+	err = json.Unmarshal(manifestData, &manifests)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the manifest.json of %s", fileName)
+	}
+	if len(manifests) == 0 {
+		return nil, errors.Errorf("the image archive %s contains no images", fileName)
+	}
+
+	// the layers may come in any order in the archive so they are read first then applied in the manifest order
+	layers := map[string]*layerChanges{}
+	for _, layer := range manifests[0].Layers {
+		layers[path.Clean(layer)] = nil
+	}
+	err = walkArchive(fileName, func(name string, r io.Reader) error {
+		if _, ok := layers[name]; !ok {
+			return nil
+		}
+		changes, err := readLayerChanges(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read layer %s of %s", name, fileName)
+		}
+		layers[name] = changes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, layer := range manifests[0].Layers {
+		changes := layers[path.Clean(layer)]
+		if changes == nil {
+			return nil, errors.Errorf("the image archive %s is missing layer %s", fileName, layer)
+		}
+		changes.apply(files)
+	}
+
+	answer := &ImageContents{}
+	osRelease := files[osReleaseFile]
+	if osRelease == nil {
+		osRelease = files[usrOSReleaseFile]
+	}
+	answer.Distro, answer.DistroVersion = parseOSRelease(osRelease)
+	answer.Packages = append(parseDpkgStatus(files[dpkgStatusFile]), parseApkInstalled(files[apkInstalledFile])...)
+	return answer, nil
+}
+
+// walkArchive calls fn with the cleaned name and contents of each regular file in the tarball
+func walkArchive(fileName string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open image archive %s", fileName)
+	}
+	defer f.Close()
+	reader := tar.NewReader(f)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read image archive %s", fileName)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		err = fn(path.Clean(header.Name), reader)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// layerChanges the package database files a layer adds and the paths it deletes
+type layerChanges struct {
+	files   map[string][]byte
+	deleted []string
+	// opaque the directories whose contents in the previous layers are hidden
+	opaque []string
+}
+
+// readLayerChanges streams a layer which may be gzipped keeping its package database files and whiteouts
+func readLayerChanges(r io.Reader) (*layerChanges, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	r = buffered
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	answer := &layerChanges{
+		files: map[string][]byte{},
+	}
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return answer, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		base := path.Base(name)
+		if base == opaqueWhiteout {
+			answer.opaque = append(answer.opaque, path.Dir(name))
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			answer.deleted = append(answer.deleted, path.Join(path.Dir(name), strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		}
+		switch name {
+		case osReleaseFile, usrOSReleaseFile, dpkgStatusFile, apkInstalledFile:
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, err := ioutil.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			answer.files[name] = content
+		}
+	}
+}
+
+// apply removes the files of the previous layers which the layer deletes or hides then adds the files of the layer
+func (c *layerChanges) apply(files map[string][]byte) {
+	for f := range files {
+		for _, dir := range c.opaque {
+			if dir == "." || strings.HasPrefix(f, dir+"/") {
+				delete(files, f)
+			}
+		}
+		for _, deleted := range c.deleted {
+			if f == deleted || strings.HasPrefix(f, deleted+"/") {
+				delete(files, f)
+			}
+		}
+	}
+	for name, content := range c.files {
+		files[name] = content
+	}
+}
+
+func parseOSRelease(data []byte) (string, string) {
+	id, version := "", ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			continue
+		}
+		value := strings.Trim(line[idx+1:], `"'`)
+		switch line[:idx] {
+		case "ID":
+			id = value
+		case "VERSION_ID":
+			version = value
+		}
+	}
+	return id, version
+}
+
+// parseDpkgStatus returns the installed packages of a dpkg status file
+func parseDpkgStatus(data []byte) []Package {
+	var answer []Package
+	for _, paragraph := range strings.Split(string(data), "\n\n") {
+		fields := map[string]string{}
+		for _, line := range strings.Split(paragraph, "\n") {
+			idx := strings.Index(line, ":")
+			if idx <= 0 || strings.HasPrefix(line, " ") {
+				continue
+			}
+			fields[line[:idx]] = strings.TrimSpace(line[idx+1:])
+		}
+		if fields["Package"] == "" || !strings.Contains(fields["Status"], "installed") || strings.Contains(fields["Status"], "not-installed") {
+			continue
+		}
+		p := Package{Name: fields["Package"], Version: fields["Version"]}
+		if source := fields["Source"]; source != "" {
+			// the source may include its own version such as 'openssl (1.1.0l-1~deb9u1)'
+			parts := strings.SplitN(source, " ", 2)
+			p.Source = parts[0]
+			if len(parts) == 2 {
+				p.SourceVersion = strings.Trim(parts[1], "()")
+			}
+		}
+		answer = append(answer, p)
+	}
+	return answer
+}
+
+// parseApkInstalled returns the installed packages of an apk database. Alpine advisories are reported against the
+// origin package such as openssl for libssl1.1 and libcrypto1.1
+func parseApkInstalled(data []byte) []Package {
+	var answer []Package
+	for _, paragraph := range strings.Split(string(data), "\n\n") {
+		p := Package{}
+		origin := ""
+		for _, line := range strings.Split(paragraph, "\n") {
+			switch {
+			case strings.HasPrefix(line, "P:"):
+				p.Name = line[2:]
+			case strings.HasPrefix(line, "V:"):
+				p.Version = line[2:]
+			case strings.HasPrefix(line, "o:"):
+				origin = line[2:]
+			}
+		}
+		if origin != p.Name {
+			p.Source = origin
+		}
+		if p.Name != "" {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
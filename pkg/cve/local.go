@@ -0,0 +1,212 @@
+package cve
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/table"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Advisory a vulnerability of a package in the vulnerability database which is fixed in a version of the package.
+// An empty fixed version means that all versions are vulnerable
+type Advisory struct {
+	ID           string `json:"id"`
+	Package      string `json:"package"`
+	FixedVersion string `json:"fixedVersion,omitempty"`
+	Severity     string `json:"severity,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+// VulnerabilityFeed the advisories of a distribution such as debian or alpine. An empty distro version applies to
+// all the versions of the distribution
+type VulnerabilityFeed struct {
+	Distro          string     `json:"distro"`
+	DistroVersion   string     `json:"distroVersion,omitempty"`
+	Vulnerabilities []Advisory `json:"vulnerabilities"`
+}
+
+// VulnerabilityDB a snapshot of vulnerability feeds which can be used to scan images without a CVE server
+type VulnerabilityDB struct {
+	Feeds []*VulnerabilityFeed
+}
+
+// LoadVulnerabilityDB loads the feeds of the JSON files in the directory of a vulnerability database snapshot. The
+// files are either feeds in the format of VulnerabilityFeed or downloads of the Alpine security database or the
+// Debian security tracker which are converted when they are loaded
+func LoadVulnerabilityDB(dir string) (*VulnerabilityDB, error) {
+	db := &VulnerabilityDB{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		feeds, err := ParseVulnerabilityFeeds(data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse vulnerability feed %s", path)
+		}
+		db.Feeds = append(db.Feeds, feeds...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the vulnerability database from %s", dir)
+	}
+	if len(db.Feeds) == 0 {
+		return nil, errors.Errorf("no vulnerability feeds found in %s", dir)
+	}
+	return db, nil
+}
+
+// Match returns the vulnerabilities of the packages installed in the image sorted by severity
+func (db *VulnerabilityDB) Match(contents *ImageContents) []Vulnerability {
+	installed := map[string][]string{}
+	for i := range contents.Packages {
+		name, version := contents.Packages[i].SourceNameAndVersion()
+		if util.StringArrayIndex(installed[name], version) < 0 {
+			installed[name] = append(installed[name], version)
+		}
+	}
+	var answer []Vulnerability
+	for _, feed := range db.Feeds {
+		if !strings.EqualFold(feed.Distro, contents.Distro) || !matchesDistroVersion(feed.DistroVersion, contents.DistroVersion) {
+			continue
+		}
+		for _, a := range feed.Vulnerabilities {
+			for _, version := range installed[a.Package] {
+				if a.FixedVersion != "" && CompareVersions(version, a.FixedVersion) >= 0 {
+					continue
+				}
+				answer = append(answer, Vulnerability{
+					Fix:      a.FixedVersion,
+					Package:  a.Package + "-" + version,
+					Severity: a.Severity,
+					URL:      a.URL,
+					Vuln:     a.ID,
+				})
+			}
+		}
+	}
+	SortVulnerabilities(answer)
+	return answer
+}
+
+// matchesDistroVersion returns true if the feed version is empty or a prefix of the image version such as 3.10 for 3.10.2
+func matchesDistroVersion(feedVersion string, version string) bool {
+	return feedVersion == "" || feedVersion == version || strings.HasPrefix(version, feedVersion+".")
+}
+
+// LocalProvider implements CVEProvider interface by scanning images offline using a vulnerability database snapshot
+type LocalProvider struct {
+	DB *VulnerabilityDB
+	// SaveImage saves an image to a tarball which defaults to using 'docker save'
+	SaveImage func(image string, fileName string) error
+}
+
+// NewLocalProvider creates a provider using the vulnerability database snapshot in the directory
+func NewLocalProvider(dbDir string) (*LocalProvider, error) {
+	db, err := LoadVulnerabilityDB(dbDir)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalProvider{
+		DB:        db,
+		SaveImage: DockerSave,
+	}, nil
+}
+
+// DockerSave saves the image to a tarball using 'docker save'
+func DockerSave(image string, fileName string) error {
+	cmd := util.Command{
+		Name: "docker",
+		Args: []string{"save", "-o", fileName, image},
+	}
+	_, err := cmd.RunWithoutRetry()
+	return err
+}
+
+// ScanImageFile returns the vulnerabilities of an image tarball
+func (p *LocalProvider) ScanImageFile(fileName string) (*ImageContents, []Vulnerability, error) {
+	contents, err := ReadImageContents(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if contents.Distro == "" {
+		log.Logger().Warnf("could not find the operating system of image %s so no packages can be scanned", fileName)
+	}
+	return contents, p.DB.Match(contents), nil
+}
+
+// ScanImage saves the image to a temporary tarball and returns its vulnerabilities
+func (p *LocalProvider) ScanImage(image string) (*ImageContents, []Vulnerability, error) {
+	dir, err := ioutil.TempDir("", "jx-cve-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "image.tar")
+	saveImage := p.SaveImage
+	if saveImage == nil {
+		saveImage = DockerSave
+	}
+	err = saveImage(image, fileName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to save image %s", image)
+	}
+	return p.ScanImageFile(fileName)
+}
+
+// GetImageVulnerabilityTable adds the vulnerabilities of the image or of the images running in the environment to the table
+func (p *LocalProvider) GetImageVulnerabilityTable(jxClient versioned.Interface, client kubernetes.Interface, table *table.Table, query CVEQuery) error {
+	var images []string
+	switch {
+	case query.ImageID != "":
+		images = append(images, query.ImageID)
+	case query.ImageName != "":
+		image := query.ImageName
+		if query.Vesion != "" {
+			image = fmt.Sprintf("%s:%s", image, query.Vesion)
+		}
+		images = append(images, image)
+	case query.Environment != "":
+		podList, err := client.CoreV1().Pods(query.TargetNamespace).List(meta_v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, pod := range podList.Items {
+			for _, c := range pod.Spec.Containers {
+				if util.StringArrayIndex(images, c.Image) < 0 {
+					images = append(images, c.Image)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("choose an image name, an optional version or an image id to find vulnerabilities")
+	}
+
+	for _, image := range images {
+		_, vulnerabilities, err := p.ScanImage(image)
+		if err != nil {
+			if len(images) == 1 {
+				return err
+			}
+			log.Logger().Warnf("failed to scan image %s: %s", image, err.Error())
+			continue
+		}
+		addVulnerabilityRows(table, image, vulnerabilities)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package cve_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const dpkgStatus = `Package: libssl1.1
+Status: install ok installed
+Source: openssl
+Version: 1.1.0j-1~deb9u1
+
+Package: openssl
+Status: install ok installed
+Version: 1.1.0j-1~deb9u1
+
+Package: curl
+Status: install ok installed
+Version: 7.52.1-5+deb9u9
+
+Package: libgcrypt20
+Status: install ok installed
+Version: 1.7.6-2+deb9u3
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+`
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.1.0j-1~deb9u1", "1.1.0l-1~deb9u1", -1},
+		{"1.1.0l-1~deb9u1", "1.1.0l-1~deb9u1", 0},
+		{"1.1.0l-1", "1.1.0l-1~deb9u1", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"7.52.1-5+deb9u10", "7.52.1-5+deb9u9", 1},
+		{"1:1.0", "2.0", 1},
+		{"7.66.0-r0", "7.65.1-r0", 1},
+		{"1.10", "1.9", 1},
+	}
+	for _, test := range tests {
+		actual := cve.CompareVersions(test.a, test.b)
+		switch {
+		case test.expected < 0:
+			assert.True(t, actual < 0, "%s should be older than %s", test.a, test.b)
+		case test.expected > 0:
+			assert.True(t, actual > 0, "%s should be newer than %s", test.a, test.b)
+		default:
+			assert.Equal(t, 0, actual, "%s should equal %s", test.a, test.b)
+		}
+	}
+}
+
+func TestScanImageFile(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-cve")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the second layer removes an apk database left over from the first layer
+	fileName := filepath.Join(dir, "image.tar")
+	writeImageArchive(t, fileName,
+		map[string]string{
+			"etc/os-release":       "ID=debian\nVERSION_ID=\"9\"\n",
+			"lib/apk/db/installed": "P:curl\nV:7.50.0-r0\n",
+			"var/lib/dpkg/status":  "Package: curl\nStatus: install ok installed\nVersion: 7.52.1-5+deb9u1\n",
+		},
+		map[string]string{
+			"lib/apk/db/.wh.installed": "",
+			"var/lib/dpkg/status":      dpkgStatus,
+		})
+
+	provider, err := cve.NewLocalProvider(filepath.Join("test_data", "local"))
+	require.NoError(t, err)
+	contents, vulnerabilities, err := provider.ScanImageFile(fileName)
+	require.NoError(t, err)
+
+	assert.Equal(t, "debian", contents.Distro)
+	assert.Equal(t, "9", contents.DistroVersion)
+	assert.Equal(t, []cve.Package{
+		{Name: "libssl1.1", Version: "1.1.0j-1~deb9u1", Source: "openssl"},
+		{Name: "openssl", Version: "1.1.0j-1~deb9u1"},
+		{Name: "curl", Version: "7.52.1-5+deb9u9"},
+		{Name: "libgcrypt20", Version: "1.7.6-2+deb9u3"},
+	}, contents.Packages)
+
+	actual := []string{}
+	for _, v := range vulnerabilities {
+		actual = append(actual, v.Severity+" "+v.Vuln+" "+v.Package)
+	}
+	assert.Equal(t, []string{
+		"Critical CVE-2019-5481 curl-7.52.1-5+deb9u9",
+		"High CVE-2018-6829 libgcrypt20-1.7.6-2+deb9u3",
+		"Medium CVE-2019-1547 openssl-1.1.0j-1~deb9u1",
+	}, actual)
+}
+
+func TestScanImageFileWithUpstreamFeeds(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-cve")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the second layer hides the dpkg database left over from the first layer with an opaque whiteout
+	fileName := filepath.Join(dir, "image.tar")
+	writeImageArchive(t, fileName,
+		map[string]string{
+			"etc/os-release":       "ID=alpine\nVERSION_ID=3.10.2\n",
+			"lib/apk/db/installed": "P:libssl1.1\nV:1.1.1c-r0\no:openssl\n\nP:curl\nV:7.65.1-r0\no:curl\n",
+			"var/lib/dpkg/status":  dpkgStatus,
+		},
+		map[string]string{
+			"var/lib/dpkg/.wh..wh..opq": "",
+		})
+
+	provider, err := cve.NewLocalProvider(filepath.Join("test_data", "upstream"))
+	require.NoError(t, err)
+	contents, vulnerabilities, err := provider.ScanImageFile(fileName)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alpine", contents.Distro)
+	assert.Equal(t, "3.10.2", contents.DistroVersion)
+	assert.Equal(t, []cve.Package{
+		{Name: "libssl1.1", Version: "1.1.1c-r0", Source: "openssl"},
+		{Name: "curl", Version: "7.65.1-r0"},
+	}, contents.Packages)
+
+	actual := []string{}
+	for _, v := range vulnerabilities {
+		actual = append(actual, v.Severity+" "+v.Vuln+" "+v.Package+" "+v.Fix)
+	}
+	assert.Equal(t, []string{
+		"Unknown CVE-2019-1547 openssl-1.1.1c-r0 1.1.1d-r0",
+		"Unknown CVE-2019-1549 openssl-1.1.1c-r0 1.1.1d-r0",
+		"Unknown CVE-2019-1563 openssl-1.1.1c-r0 1.1.1d-r0",
+		"Unknown CVE-2019-5481 curl-7.65.1-r0 7.66.0-r0",
+		"Unknown CVE-2019-5482 curl-7.65.1-r0 7.66.0-r0",
+	}, actual)
+}
+
+func TestConvertDebianSecurityTracker(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "upstream", "debian-tracker.json"))
+	require.NoError(t, err)
+	feeds, err := cve.ConvertDebianSecurityTracker(data)
+	require.NoError(t, err)
+	require.Len(t, feeds, 2)
+
+	assert.Equal(t, "debian", feeds[0].Distro)
+	assert.Equal(t, "9", feeds[0].DistroVersion)
+	assert.Equal(t, []cve.Advisory{
+		{
+			ID:       "CVE-2020-8169",
+			Package:  "curl",
+			Severity: "Low",
+			URL:      "https://security-tracker.debian.org/tracker/CVE-2020-8169",
+		},
+		{
+			ID:           "CVE-2019-1547",
+			Package:      "openssl",
+			FixedVersion: "1.1.0l-1~deb9u1",
+			Severity:     "Medium",
+			URL:          "https://security-tracker.debian.org/tracker/CVE-2019-1547",
+		},
+	}, feeds[0].Vulnerabilities)
+
+	assert.Equal(t, "10", feeds[1].DistroVersion)
+	require.Len(t, feeds[1].Vulnerabilities, 1)
+	assert.Equal(t, "1.1.1d-0+deb10u1", feeds[1].Vulnerabilities[0].FixedVersion)
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+	critical := cve.Vulnerability{Vuln: "CVE-2019-5481", Package: "curl-7.52.1-5+deb9u9", Severity: "Critical", Fix: "7.52.1-5+deb9u10"}
+	high := cve.Vulnerability{Vuln: "CVE-2018-6829", Package: "libgcrypt20-1.7.6-2+deb9u3", Severity: "High"}
+	medium := cve.Vulnerability{Vuln: "CVE-2019-1547", Package: "openssl-1.1.0j-1~deb9u1", Severity: "Medium", Fix: "1.1.0l-1~deb9u1"}
+	vulnerabilities := []cve.Vulnerability{critical, high, medium}
+
+	result := (&cve.Policy{}).Evaluate(vulnerabilities)
+	assert.True(t, result.Passed(), "no severity to fail on")
+
+	policy := &cve.Policy{FailOn: "high"}
+	require.NoError(t, policy.Validate())
+	result = policy.Evaluate(vulnerabilities)
+	assert.False(t, result.Passed())
+	assert.Equal(t, []cve.Vulnerability{critical, high}, result.Violations)
+
+	policy = &cve.Policy{FailOn: "medium", Ignore: []string{"CVE-2019-54*", "openssl"}, IgnoreUnfixed: true}
+	result = policy.Evaluate(vulnerabilities)
+	assert.True(t, result.Passed())
+	assert.Equal(t, []cve.Vulnerability{critical, high, medium}, result.Ignored)
+
+	assert.Error(t, (&cve.Policy{FailOn: "severe"}).Validate())
+}
+
+func TestPolicyFailsOnAlpineAdvisories(t *testing.T) {
+	t.Parallel()
+	feed, err := cve.ConvertAlpineSecDB([]byte(`{
+  "distroversion": "v3.10",
+  "packages": [
+    {"pkg": {"name": "openssl", "secfixes": {"1.1.1d-r0": ["CVE-2019-1547"]}}}
+  ]
+}`))
+	require.NoError(t, err)
+	require.Len(t, feed.Vulnerabilities, 1)
+	alpine := cve.Vulnerability{
+		Vuln:     feed.Vulnerabilities[0].ID,
+		Package:  "openssl-1.1.1c-r0",
+		Severity: feed.Vulnerabilities[0].Severity,
+		Fix:      feed.Vulnerabilities[0].FixedVersion,
+	}
+	vulnerabilities := []cve.Vulnerability{alpine}
+
+	result := (&cve.Policy{}).Evaluate(vulnerabilities)
+	assert.True(t, result.Passed(), "no severity to fail on")
+
+	result = (&cve.Policy{FailOn: "high"}).Evaluate(vulnerabilities)
+	assert.False(t, result.Passed(), "the severity of Alpine advisories is unknown")
+	assert.Equal(t, []cve.Vulnerability{alpine}, result.Violations)
+
+	result = (&cve.Policy{FailOn: "high", IgnoreUnknownSeverity: true}).Evaluate(vulnerabilities)
+	assert.True(t, result.Passed())
+	assert.Equal(t, 1, result.CountBySeverity()[cve.SeverityUnknown])
+}
+
+func TestNewImageScanFact(t *testing.T) {
+	t.Parallel()
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myapp-master-3", UID: "abc"},
+		Spec: v1.PipelineActivitySpec{
+			Build:         "3",
+			GitOwner:      "myorg",
+			GitRepository: "myapp",
+			GitBranch:     "master",
+		},
+	}
+	vulnerabilities := []cve.Vulnerability{
+		{Vuln: "CVE-2019-5481", Severity: "Critical"},
+		{Vuln: "CVE-2019-1547", Severity: "Medium"},
+		{Vuln: "CVE-2019-1563", Severity: "Low"},
+	}
+	policy := &cve.Policy{FailOn: "High", Ignore: []string{"CVE-2019-1563"}}
+	fact := cve.NewImageScanFact(activity, "gcr.io/myorg/myapp:0.0.3", policy, policy.Evaluate(vulnerabilities))
+
+	assert.Equal(t, "jx-image-scan-myorg-myapp-master-3-gcr-io-myorg-myapp-0-0-3", fact.Name)
+	assert.Equal(t, v1.FactTypeImageScan, fact.Spec.FactType)
+	assert.Equal(t, "PipelineActivity", fact.Labels[cve.LabelSubjectKind])
+	assert.Equal(t, "myorg-myapp-master-3", fact.Labels[cve.LabelPipelineName])
+	assert.Equal(t, "3", fact.Labels[cve.LabelBuildNumber])
+	assert.Equal(t, "abc", string(fact.Spec.SubjectReference.UID))
+
+	counts := map[string]int{}
+	for _, m := range fact.Spec.Measurements {
+		counts[m.Name] = m.MeasurementValue
+	}
+	assert.Equal(t, map[string]int{"Unknown": 0, "Negligible": 0, "Low": 0, "Medium": 1, "High": 0, "Critical": 1, "Ignored": 1}, counts)
+	require.Len(t, fact.Spec.Statements, 1)
+	assert.False(t, fact.Spec.Statements[0].MeasurementValue)
+	assert.Equal(t, []string{"CVE-2019-5481"}, fact.Spec.Statements[0].Tags)
+}
+
+// writeImageArchive writes an image tarball in the format of 'docker save' with a gzipped layer for each of the maps of files
+func writeImageArchive(t *testing.T, fileName string, layers ...map[string]string) {
+	var buffer bytes.Buffer
+	archive := tar.NewWriter(&buffer)
+	manifest := []map[string]interface{}{{"Config": "config.json", "RepoTags": []string{"myapp:latest"}}}
+	var layerNames []string
+	for i, files := range layers {
+		var layer bytes.Buffer
+		gz := gzip.NewWriter(&layer)
+		layerArchive := tar.NewWriter(gz)
+		writeTarFiles(t, layerArchive, files)
+		require.NoError(t, layerArchive.Close())
+		require.NoError(t, gz.Close())
+		name := filepath.Join("layer"+string(rune('0'+i)), "layer.tar")
+		layerNames = append(layerNames, name)
+		writeTarFiles(t, archive, map[string]string{name: layer.String()})
+	}
+	manifest[0]["Layers"] = layerNames
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	writeTarFiles(t, archive, map[string]string{"manifest.json": string(data)})
+	require.NoError(t, archive.Close())
+	require.NoError(t, ioutil.WriteFile(fileName, buffer.Bytes(), 0600))
+}
+
+func writeTarFiles(t *testing.T, w *tar.Writer, files map[string]string) {
+	for name, content := range files {
+		err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Flush())
+}
@@ -0,0 +1,154 @@
+package cve
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severities of vulnerabilities from the least to the most severe
+const (
+	SeverityUnknown    = "Unknown"
+	SeverityNegligible = "Negligible"
+	SeverityLow        = "Low"
+	SeverityMedium     = "Medium"
+	SeverityHigh       = "High"
+	SeverityCritical   = "Critical"
+)
+
+// Severities the severities in order from the least to the most severe
+var Severities = []string{SeverityUnknown, SeverityNegligible, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// SeverityRank returns the rank of the severity in Severities ignoring case or zero if it is unknown
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return 0
+}
+
+// SortVulnerabilities sorts the vulnerabilities from the most severe then by ID and package
+func SortVulnerabilities(vulnerabilities []Vulnerability) {
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		a, b := vulnerabilities[i], vulnerabilities[j]
+		ra, rb := SeverityRank(a.Severity), SeverityRank(b.Severity)
+		if ra != rb {
+			return ra > rb
+		}
+		if a.Vuln != b.Vuln {
+			return a.Vuln < b.Vuln
+		}
+		return a.Package < b.Package
+	})
+}
+
+// Policy decides which vulnerabilities of an image fail a pipeline
+type Policy struct {
+	// FailOn the lowest severity which fails the policy. Empty never fails
+	FailOn string
+	// Ignore the vulnerability IDs, wildcards or package names to ignore such as CVE-2019-1547, CVE-2019-* or openssl
+	Ignore []string
+	// IgnoreUnfixed ignores vulnerabilities with no fixed version
+	IgnoreUnfixed bool
+	// IgnoreUnknownSeverity stops vulnerabilities of an unknown severity, such as Alpine advisories, failing the policy.
+	// Otherwise they fail it whenever there is a severity to fail on as they may be severe
+	IgnoreUnknownSeverity bool
+}
+
+// PolicyResult the vulnerabilities of an image grouped by how the policy treats them
+type PolicyResult struct {
+	Vulnerabilities []Vulnerability
+	Violations      []Vulnerability
+	Ignored         []Vulnerability
+}
+
+// Passed returns true if no vulnerabilities violate the policy
+func (r *PolicyResult) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// CountBySeverity returns the number of vulnerabilities which are not ignored for each severity
+func (r *PolicyResult) CountBySeverity() map[string]int {
+	answer := map[string]int{}
+	for _, s := range Severities {
+		answer[s] = 0
+	}
+	for _, v := range r.Vulnerabilities {
+		if r.isIgnored(v) {
+			continue
+		}
+		s := Severities[SeverityRank(v.Severity)]
+		answer[s]++
+	}
+	return answer
+}
+
+func (r *PolicyResult) isIgnored(v Vulnerability) bool {
+	for _, i := range r.Ignored {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the severity to fail on is not known
+func (p *Policy) Validate() error {
+	if p.FailOn == "" || strings.EqualFold(p.FailOn, "none") {
+		return nil
+	}
+	for _, s := range Severities {
+		if strings.EqualFold(s, p.FailOn) {
+			return nil
+		}
+	}
+	return errors.Errorf("unknown severity %s to fail on. Supported values are: none, %s", p.FailOn, strings.ToLower(strings.Join(Severities, ", ")))
+}
+
+// Evaluate applies the policy to the vulnerabilities of an image
+func (p *Policy) Evaluate(vulnerabilities []Vulnerability) *PolicyResult {
+	answer := &PolicyResult{Vulnerabilities: vulnerabilities}
+	failOn := -1
+	if p.FailOn != "" && !strings.EqualFold(p.FailOn, "none") {
+		failOn = SeverityRank(p.FailOn)
+	}
+	for _, v := range vulnerabilities {
+		if p.IsIgnored(v) {
+			answer.Ignored = append(answer.Ignored, v)
+			continue
+		}
+		if failOn < 0 {
+			continue
+		}
+		rank := SeverityRank(v.Severity)
+		if rank >= failOn || (rank == SeverityRank(SeverityUnknown) && !p.IgnoreUnknownSeverity) {
+			answer.Violations = append(answer.Violations, v)
+		}
+	}
+	return answer
+}
+
+// IsIgnored returns true if the vulnerability or its package is ignored by the policy
+func (p *Policy) IsIgnored(v Vulnerability) bool {
+	if p.IgnoreUnfixed && v.Fix == "" {
+		return true
+	}
+	for _, pattern := range p.Ignore {
+		if strings.HasPrefix(v.Package, pattern+"-") {
+			return true
+		}
+		for _, value := range []string{v.Vuln, v.Package} {
+			if strings.EqualFold(pattern, value) {
+				return true
+			}
+			if matched, err := filepath.Match(pattern, value); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package cve
 import (
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/table"
+	"github.com/jenkins-x/jx/pkg/util"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -20,3 +21,23 @@ type CVEQuery struct {
 type CVEProvider interface {
 	GetImageVulnerabilityTable(jxClient versioned.Interface, client kubernetes.Interface, table *table.Table, query CVEQuery) error
 }
+
+// addVulnerabilityRows adds a row to the table for each vulnerability of the image
+func addVulnerabilityRows(table *table.Table, image string, vulnerabilities []Vulnerability) {
+	for _, v := range vulnerabilities {
+		table.AddRow(image, ColorSeverity(v.Severity), v.Vuln, v.URL, v.Package, v.Fix)
+	}
+}
+
+// ColorSeverity returns the severity colored by how severe it is
+func ColorSeverity(severity string) string {
+	switch SeverityRank(severity) {
+	case SeverityRank(SeverityCritical), SeverityRank(SeverityHigh):
+		return util.ColorError(severity)
+	case SeverityRank(SeverityMedium):
+		return util.ColorWarning(severity)
+	case SeverityRank(SeverityLow):
+		return util.ColorStatus(severity)
+	}
+	return severity
+}
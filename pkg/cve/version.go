@@ -0,0 +1,109 @@
+package cve
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two package versions using the ordering of dpkg which is also a good fit for the
+// versions of apk packages. It returns a negative number if a is older than b, zero if they are equal and a
+// positive number if a is newer than b
+func CompareVersions(a string, b string) int {
+	epochA, upstreamA, revisionA := splitVersion(a)
+	epochB, upstreamB, revisionB := splitVersion(b)
+	if epochA != epochB {
+		return epochA - epochB
+	}
+	if c := compareVersionPart(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return compareVersionPart(revisionA, revisionB)
+}
+
+// splitVersion splits a version into its epoch, upstream version and revision
+func splitVersion(version string) (int, string, string) {
+	epoch := 0
+	version = strings.TrimSpace(version)
+	if idx := strings.Index(version, ":"); idx > 0 {
+		if e, err := strconv.Atoi(version[:idx]); err == nil {
+			epoch = e
+			version = version[idx+1:]
+		}
+	}
+	revision := ""
+	if idx := strings.LastIndex(version, "-"); idx >= 0 {
+		revision = version[idx+1:]
+		version = version[:idx]
+	}
+	return epoch, version, revision
+}
+
+// compareVersionPart compares alternating non digit and digit sections of the versions
+func compareVersionPart(a string, b string) int {
+	for a != "" || b != "" {
+		var textA, textB string
+		textA, a = splitLeading(a, false)
+		textB, b = splitLeading(b, false)
+		if c := compareText(textA, textB); c != 0 {
+			return c
+		}
+		var numberA, numberB string
+		numberA, a = splitLeading(a, true)
+		numberB, b = splitLeading(b, true)
+		if c := compareNumbers(numberA, numberB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func splitLeading(text string, digits bool) (string, string) {
+	i := 0
+	for i < len(text) && isDigit(text[i]) == digits {
+		i++
+	}
+	return text[:i], text[i:]
+}
+
+func compareText(a string, b string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ca, cb byte
+		if i < len(a) {
+			ca = a[i]
+		}
+		if i < len(b) {
+			cb = b[i]
+		}
+		if ca != cb {
+			return charOrder(ca) - charOrder(cb)
+		}
+	}
+	return 0
+}
+
+// charOrder sorts a tilde before the end of a section, the end before letters and letters before other characters
+func charOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c == 0:
+		return 0
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+func compareNumbers(a string, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,106 @@
+package dependencybot
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+var mavenDependencyRegex = regexp.MustCompile(`(?s)<dependency>.*?</dependency>`)
+
+// ApplyUpdate changes the version of each declaration of the dependency in the directory returning the versions
+// which were replaced
+func ApplyUpdate(dir string, update *Update) ([]string, error) {
+	var answer []string
+	for _, d := range update.Dependencies {
+		path := filepath.Join(dir, d.Path)
+		var changed bool
+		var err error
+		if d.Kind == KindHelm {
+			changed, err = applyHelmUpdate(path, d, update.ToVersion)
+		} else {
+			changed, err = applyTextUpdate(path, d, update.ToVersion)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update %s to %s in %s", d.Name, update.ToVersion, d.Path)
+		}
+		if !changed {
+			log.Logger().Warnf("could not find version %s of %s in %s", d.Version, d.Name, d.Path)
+			continue
+		}
+		answer = append(answer, d.Version)
+	}
+	return answer, nil
+}
+
+func applyTextUpdate(path string, d *Dependency, version string) (bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	text := string(data)
+	var updated string
+	switch d.Kind {
+	case KindGo:
+		version = "v" + strings.TrimPrefix(version, "v")
+		r := regexp.MustCompile(`(?m)^(\s*(?:require\s+)?` + regexp.QuoteMeta(d.Name) + `\s+)` + regexp.QuoteMeta(d.Version) + `(\s|$)`)
+		updated = r.ReplaceAllString(text, "${1}"+version+"${2}")
+	case KindNpm:
+		r := regexp.MustCompile(`("` + regexp.QuoteMeta(d.Name) + `"\s*:\s*"[\^~]?)` + regexp.QuoteMeta(d.Version) + `"`)
+		updated = r.ReplaceAllString(text, "${1}"+version+`"`)
+	case KindMaven:
+		updated = replaceMavenVersion(text, d, version)
+	default:
+		return false, nil
+	}
+	if updated == text {
+		return false, nil
+	}
+	return true, ioutil.WriteFile(path, []byte(updated), util.DefaultWritePermissions)
+}
+
+// replaceMavenVersion replaces the version property of the dependency or the version of each of its dependency elements
+func replaceMavenVersion(text string, d *Dependency, version string) string {
+	if d.Property != "" {
+		property := regexp.QuoteMeta(d.Property)
+		r := regexp.MustCompile(`(<` + property + `>\s*)` + regexp.QuoteMeta(d.Version) + `(\s*</` + property + `>)`)
+		return r.ReplaceAllString(text, "${1}"+version+"${2}")
+	}
+	names := strings.SplitN(d.Name, ":", 2)
+	if len(names) != 2 {
+		return text
+	}
+	groupID := regexp.MustCompile(`<groupId>\s*` + regexp.QuoteMeta(names[0]) + `\s*</groupId>`)
+	artifactID := regexp.MustCompile(`<artifactId>\s*` + regexp.QuoteMeta(names[1]) + `\s*</artifactId>`)
+	versionRegex := regexp.MustCompile(`(<version>\s*)` + regexp.QuoteMeta(d.Version) + `(\s*</version>)`)
+	return mavenDependencyRegex.ReplaceAllStringFunc(text, func(element string) string {
+		if !groupID.MatchString(element) || !artifactID.MatchString(element) {
+			return element
+		}
+		return versionRegex.ReplaceAllString(element, "${1}"+version+"${2}")
+	})
+}
+
+func applyHelmUpdate(path string, d *Dependency, version string) (bool, error) {
+	requirements, err := helm.LoadRequirementsFile(path)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for _, r := range requirements.Dependencies {
+		if r != nil && r.Name == d.Name && r.Repository == d.Repository && r.Version == d.Version {
+			r.Version = version
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, helm.SaveFile(path, requirements)
+}
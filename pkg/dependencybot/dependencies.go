@@ -0,0 +1,243 @@
+package dependencybot
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/pkg/errors"
+)
+
+// Kind the kind of a dependency
+type Kind string
+
+const (
+	// KindGo a go module in a go.mod file
+	KindGo Kind = "go"
+
+	// KindMaven a maven dependency in a pom.xml file
+	KindMaven Kind = "maven"
+
+	// KindNpm an npm package in a package.json file
+	KindNpm Kind = "npm"
+
+	// KindHelm a helm chart in a requirements.yaml file
+	KindHelm Kind = "helm"
+)
+
+var (
+	// Kinds all the kinds of dependencies
+	Kinds = []Kind{KindGo, KindMaven, KindNpm, KindHelm}
+
+	// skipDirs the directories which are not searched for dependencies
+	skipDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true, "target": true}
+
+	goRequireRegex     = regexp.MustCompile(`^(?:require\s+)?(\S+)\s+(v\S+)`)
+	npmVersionRegex    = regexp.MustCompile(`^([\^~]?)(\d+(?:\.\d+){0,2}(?:[-+][\w\.-]+)?)$`)
+	mavenPropertyRegex = regexp.MustCompile(`^\$\{([\w\.-]+)\}$`)
+)
+
+// Dependency a versioned dependency declared in a file of a repository
+type Dependency struct {
+	Kind Kind `json:"kind"`
+	// Name the module path, groupId:artifactId, package or chart name
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Path the file declaring the dependency relative to the root of the repository
+	Path string `json:"path"`
+	// Property the maven property which holds the version if the dependency version is a property reference
+	Property string `json:"property,omitempty"`
+	// Repository the chart repository URL of a helm dependency
+	Repository string `json:"repository,omitempty"`
+}
+
+// FindDependencies returns the go modules, maven dependencies, npm packages and helm charts declared in the directory tree
+func FindDependencies(dir string) ([]*Dependency, error) {
+	var answer []*Dependency
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		var deps []*Dependency
+		switch info.Name() {
+		case "go.mod":
+			deps, err = goModDependencies(path)
+		case "pom.xml":
+			deps, err = mavenDependencies(path)
+		case "package.json":
+			deps, err = npmDependencies(path)
+		case helm.RequirementsFileName:
+			deps, err = helmDependencies(path)
+		default:
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the dependencies in %s", rel)
+		}
+		for _, d := range deps {
+			d.Path = rel
+		}
+		answer = append(answer, deps...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// goModDependencies returns the direct requirements of a go.mod file
+func goModDependencies(path string) ([]*Dependency, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*Dependency
+	inRequire := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "// indirect") {
+			continue
+		}
+		if inRequire {
+			if line == ")" {
+				inRequire = false
+				continue
+			}
+		} else if strings.HasPrefix(line, "require (") {
+			inRequire = true
+			continue
+		} else if !strings.HasPrefix(line, "require ") {
+			continue
+		}
+		values := goRequireRegex.FindStringSubmatch(line)
+		if len(values) > 2 {
+			answer = append(answer, &Dependency{Kind: KindGo, Name: values[1], Version: values[2]})
+		}
+	}
+	return answer, nil
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type pom struct {
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+	DependencyManagement []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Properties           struct {
+		Entries []pomProperty `xml:",any"`
+	} `xml:"properties"`
+}
+
+// mavenDependencies returns the dependencies of a pom.xml file which have a version or a version property
+func mavenDependencies(path string) ([]*Dependency, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project := &pom{}
+	err = xml.Unmarshal(data, project)
+	if err != nil {
+		return nil, err
+	}
+	properties := map[string]string{}
+	for _, p := range project.Properties.Entries {
+		properties[p.XMLName.Local] = strings.TrimSpace(p.Value)
+	}
+	var answer []*Dependency
+	for _, d := range append(project.Dependencies, project.DependencyManagement...) {
+		version := strings.TrimSpace(d.Version)
+		if d.GroupID == "" || d.ArtifactID == "" || version == "" {
+			continue
+		}
+		dep := &Dependency{
+			Kind:    KindMaven,
+			Name:    strings.TrimSpace(d.GroupID) + ":" + strings.TrimSpace(d.ArtifactID),
+			Version: version,
+		}
+		values := mavenPropertyRegex.FindStringSubmatch(version)
+		if len(values) > 1 {
+			dep.Property = values[1]
+			dep.Version = properties[dep.Property]
+			if dep.Version == "" {
+				continue
+			}
+		}
+		answer = append(answer, dep)
+	}
+	return answer, nil
+}
+
+type packageJSON struct {
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// npmDependencies returns the dependencies and development dependencies of a package.json file which use an exact,
+// caret or tilde version
+func npmDependencies(path string) ([]*Dependency, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &packageJSON{}
+	err = json.Unmarshal(data, pkg)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*Dependency
+	for _, m := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			values := npmVersionRegex.FindStringSubmatch(strings.TrimSpace(m[name]))
+			if len(values) > 2 {
+				answer = append(answer, &Dependency{Kind: KindNpm, Name: name, Version: values[2]})
+			}
+		}
+	}
+	return answer, nil
+}
+
+// helmDependencies returns the charts of a requirements.yaml file
+func helmDependencies(path string) ([]*Dependency, error) {
+	requirements, err := helm.LoadRequirementsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*Dependency
+	for _, d := range requirements.Dependencies {
+		if d == nil || d.Name == "" || d.Version == "" || d.Repository == "" {
+			continue
+		}
+		answer = append(answer, &Dependency{Kind: KindHelm, Name: d.Name, Version: d.Version, Repository: d.Repository})
+	}
+	return answer, nil
+}
@@ -0,0 +1,212 @@
+package dependencybot_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/dependencybot"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var resolver = &versionstream.VersionResolver{VersionsDir: filepath.Join("testdata", "versions")}
+
+func TestFindDependencies(t *testing.T) {
+	t.Parallel()
+	dependencies, err := dependencybot.FindDependencies(filepath.Join("testdata", "repo"))
+	require.NoError(t, err)
+
+	actual := map[string]string{}
+	for _, d := range dependencies {
+		actual[string(d.Kind)+" "+d.Path+" "+d.Name] = d.Version
+	}
+	assert.Equal(t, map[string]string{
+		"helm charts/myapp/requirements.yaml postgresql": "0.8.0",
+		"helm charts/myapp/requirements.yaml unknown":    "1.0.0",
+		"go go.mod github.com/pkg/errors":                "v0.8.0",
+		"go go.mod github.com/stretchr/testify":          "v1.4.0",
+		"maven pom.xml org.springframework:spring-core":  "5.1.0",
+		"maven pom.xml junit:junit":                      "4.12",
+		"npm web/package.json lodash":                    "4.17.10",
+		"npm web/package.json react":                     "16.8.0",
+		"npm web/package.json @types/node":               "12.0.0",
+	}, actual)
+}
+
+func TestFindUpdates(t *testing.T) {
+	t.Parallel()
+	dependencies, err := dependencybot.FindDependencies(filepath.Join("testdata", "repo"))
+	require.NoError(t, err)
+
+	policy := &v1.DependencyUpdatePolicy{
+		Ignore: []string{"react@16.9*", "@types/node"},
+	}
+	updates, err := dependencybot.FindUpdates(dependencies, resolver, policy)
+	require.NoError(t, err)
+
+	actual := map[string]string{}
+	for _, u := range updates {
+		actual[u.Name] = u.ToVersion
+	}
+	assert.Equal(t, map[string]string{
+		"postgresql":                      "0.9.0",
+		"github.com/pkg/errors":           "0.8.1",
+		"org.springframework:spring-core": "5.2.0",
+		"junit:junit":                     "4.13",
+		"lodash":                          "4.17.15",
+	}, actual)
+
+	for _, u := range updates {
+		if u.Name == "github.com/pkg/errors" {
+			assert.Equal(t, "https://github.com/pkg/errors", u.GitURL)
+			assert.Equal(t, []string{"v0.8.0"}, u.FromVersions())
+			assert.Equal(t, "chore(deps): bump github.com/pkg/errors from v0.8.0 to 0.8.1", u.CommitMessage())
+		}
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-dependencybot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = util.CopyDir(filepath.Join("testdata", "repo"), dir, true)
+	require.NoError(t, err)
+
+	dependencies, err := dependencybot.FindDependencies(dir)
+	require.NoError(t, err)
+	updates, err := dependencybot.FindUpdates(dependencies, resolver, nil)
+	require.NoError(t, err)
+	for _, u := range updates {
+		oldVersions, err := dependencybot.ApplyUpdate(dir, u)
+		require.NoError(t, err)
+		assert.Equal(t, u.FromVersions(), oldVersions, "old versions of %s", u.Name)
+	}
+
+	dependencies, err = dependencybot.FindDependencies(dir)
+	require.NoError(t, err)
+	updates, err = dependencybot.FindUpdates(dependencies, resolver, nil)
+	require.NoError(t, err)
+	assert.Empty(t, updates, "all the dependencies are up to date")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\tgithub.com/pkg/errors v0.8.1\n")
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "pom.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<spring.version>5.2.0</spring.version>")
+	assert.Contains(t, string(data), "<version>${spring.version}</version>")
+	assert.Contains(t, string(data), "<version>4.13</version>")
+	assert.Contains(t, string(data), "<version>1.0.0-SNAPSHOT</version>")
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "web", "package.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"lodash": "^4.17.15"`)
+	assert.Contains(t, string(data), `"react": "~16.9.0"`)
+	assert.Contains(t, string(data), `"@types/node": "12.7.0"`)
+}
+
+func TestGroupUpdates(t *testing.T) {
+	t.Parallel()
+	updates := []*dependencybot.Update{
+		{Kind: dependencybot.KindGo, Name: "github.com/pkg/errors"},
+		{Kind: dependencybot.KindNpm, Name: "lodash"},
+		{Kind: dependencybot.KindGo, Name: "github.com/stretchr/testify"},
+	}
+	labels := func(batches []*dependencybot.Batch) map[string]int {
+		answer := map[string]int{}
+		for _, b := range batches {
+			answer[b.Label] = len(b.Updates)
+		}
+		return answer
+	}
+
+	batches, err := dependencybot.GroupUpdates(updates, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"dependencies": 3}, labels(batches))
+
+	batches, err = dependencybot.GroupUpdates(updates, dependencybot.GroupingKind)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"dependencies/go": 2, "dependencies/npm": 1}, labels(batches))
+
+	batches, err = dependencybot.GroupUpdates(updates, dependencybot.GroupingNone)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"dependencies/github.com/pkg/errors": 1, "dependencies/lodash": 1,
+		"dependencies/github.com/stretchr/testify": 1}, labels(batches))
+
+	_, err = dependencybot.GroupUpdates(updates, "weekly")
+	assert.Error(t, err)
+}
+
+func TestIsDue(t *testing.T) {
+	t.Parallel()
+	// a Wednesday
+	now := time.Date(2019, time.October, 23, 12, 0, 0, 0, time.UTC)
+	policy := &v1.DependencyUpdatePolicy{Schedule: "0 6 * * MON"}
+
+	due, err := dependencybot.IsDue(nil, time.Time{}, now)
+	require.NoError(t, err)
+	assert.True(t, due, "no policy")
+
+	due, err = dependencybot.IsDue(policy, time.Time{}, now)
+	require.NoError(t, err)
+	assert.True(t, due, "never run")
+
+	due, err = dependencybot.IsDue(policy, now.Add(-72*time.Hour), now)
+	require.NoError(t, err)
+	assert.True(t, due, "last run before Monday")
+
+	due, err = dependencybot.IsDue(policy, now.Add(-48*time.Hour), now)
+	require.NoError(t, err)
+	assert.False(t, due, "already run since Monday")
+
+	policy.TimeZone = "Not/AZone"
+	_, err = dependencybot.IsDue(policy, time.Time{}, now)
+	assert.Error(t, err)
+}
+
+func TestIsIgnored(t *testing.T) {
+	t.Parallel()
+	policy := &v1.DependencyUpdatePolicy{
+		Ignore: []string{"github.com/jenkins-x/*", "@angular/core@9*", "lodash"},
+	}
+	assert.True(t, dependencybot.IsIgnored(policy, "github.com/jenkins-x/jx", "2.0.0"))
+	assert.True(t, dependencybot.IsIgnored(policy, "@angular/core", "9.0.1"))
+	assert.False(t, dependencybot.IsIgnored(policy, "@angular/core", "8.2.0"))
+	assert.True(t, dependencybot.IsIgnored(policy, "lodash", "4.17.15"))
+	assert.False(t, dependencybot.IsIgnored(policy, "react", "16.9.0"))
+	assert.False(t, dependencybot.IsIgnored(nil, "lodash", "4.17.15"))
+}
+
+func TestBatchPullRequestDetails(t *testing.T) {
+	t.Parallel()
+	errorsUpdate := &dependencybot.Update{
+		Kind:         dependencybot.KindGo,
+		Name:         "github.com/pkg/errors",
+		ToVersion:    "0.9.1",
+		Dependencies: []*dependencybot.Dependency{{Version: "0.8.1"}},
+	}
+	lodashUpdate := &dependencybot.Update{
+		Kind:         dependencybot.KindNpm,
+		Name:         "lodash",
+		ToVersion:    "4.17.15",
+		Dependencies: []*dependencybot.Dependency{{Version: "4.17.11"}, {Version: "4.17.4"}},
+	}
+
+	batch := &dependencybot.Batch{Updates: []*dependencybot.Update{errorsUpdate}}
+	assert.Equal(t, "chore(deps): bump github.com/pkg/errors from 0.8.1 to 0.9.1", batch.PullRequestTitle())
+
+	batch.Updates = append(batch.Updates, lodashUpdate)
+	assert.Equal(t, "chore(deps): bump github.com/pkg/errors from 0.8.1 to 0.9.1 and lodash from 4.17.11, 4.17.4 to 4.17.15",
+		batch.PullRequestTitle())
+	assert.Equal(t, "Updates the dependencies to the versions of the version stream:\n\n"+
+		"* github.com/pkg/errors from 0.8.1 to 0.9.1\n"+
+		"* lodash from 4.17.11, 4.17.4 to 4.17.15\n", batch.PullRequestMessage())
+}
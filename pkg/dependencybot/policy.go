@@ -0,0 +1,119 @@
+package dependencybot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GroupingAll opens a single pull request for all the updates of a repository
+	GroupingAll = "all"
+
+	// GroupingKind opens a pull request for each kind of dependency
+	GroupingKind = "kind"
+
+	// GroupingNone opens a pull request for each dependency
+	GroupingNone = "none"
+
+	// LastRunAnnotation the annotation on a SourceRepository with the time its dependencies were last updated
+	LastRunAnnotation = "jenkins.io/dependency-updates-last-run"
+
+	// labelPrefix the prefix of the labels which tell the pull requests of different batches apart
+	labelPrefix = "dependencies"
+
+	// maxLabelLength the longest label most git providers allow
+	maxLabelLength = 50
+
+	// scheduleLookback how far back a schedule is checked when the dependencies have not been updated recently
+	scheduleLookback = 31 * 24 * time.Hour
+)
+
+// Batch the updates which are opened as a single pull request
+type Batch struct {
+	// Label tells the pull request of this batch apart from the other dependency update pull requests of the repository
+	Label   string
+	Updates []*Update
+}
+
+// PullRequestTitle returns the title of the pull request of the batch with the versions each dependency is updated
+// from and to
+func (b *Batch) PullRequestTitle() string {
+	bumps := make([]string, 0, len(b.Updates))
+	for _, u := range b.Updates {
+		bumps = append(bumps, u.bump())
+	}
+	text := bumps[len(bumps)-1]
+	if len(bumps) > 1 {
+		text = strings.Join(bumps[:len(bumps)-1], ", ") + " and " + text
+	}
+	return "chore(deps): bump " + text
+}
+
+// PullRequestMessage returns the body of the pull request of the batch listing the updates
+func (b *Batch) PullRequestMessage() string {
+	lines := make([]string, 0, len(b.Updates))
+	for _, u := range b.Updates {
+		lines = append(lines, "* "+u.bump())
+	}
+	return "Updates the dependencies to the versions of the version stream:\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// GroupUpdates batches the updates into pull requests using the grouping of a policy
+func GroupUpdates(updates []*Update, grouping string) ([]*Batch, error) {
+	var answer []*Batch
+	batches := map[string]*Batch{}
+	for _, u := range updates {
+		var label string
+		switch grouping {
+		case "", GroupingAll:
+			label = labelPrefix
+		case GroupingKind:
+			label = labelPrefix + "/" + string(u.Kind)
+		case GroupingNone:
+			label = labelPrefix + "/" + u.Name
+		default:
+			return nil, fmt.Errorf("unknown dependency update grouping '%s' should be one of %s, %s or %s", grouping, GroupingAll, GroupingKind, GroupingNone)
+		}
+		if len(label) > maxLabelLength {
+			label = label[:maxLabelLength]
+		}
+		batch := batches[label]
+		if batch == nil {
+			batch = &Batch{Label: label}
+			batches[label] = batch
+			answer = append(answer, batch)
+		}
+		batch.Updates = append(batch.Updates, u)
+	}
+	return answer, nil
+}
+
+// IsDue returns true if the schedule of the policy has fired since the dependencies were last updated. Policies without
+// a schedule are always due
+func IsDue(policy *v1.DependencyUpdatePolicy, lastRun time.Time, now time.Time) (bool, error) {
+	if policy == nil || policy.Schedule == "" {
+		return true, nil
+	}
+	schedule, err := promotion.ParseCronSchedule(policy.Schedule)
+	if err != nil {
+		return false, err
+	}
+	location := time.UTC
+	if policy.TimeZone != "" {
+		location, err = time.LoadLocation(policy.TimeZone)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid time zone %s", policy.TimeZone)
+		}
+	}
+	limit := now.Add(-scheduleLookback)
+	if lastRun.After(limit) {
+		limit = lastRun
+	}
+	_, fired := schedule.LastBefore(now.In(location), limit.In(location))
+	return fired, nil
+}
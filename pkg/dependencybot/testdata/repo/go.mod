@@ -0,0 +1,9 @@
+module github.com/myorg/myapp
+
+require (
+	github.com/pkg/errors v0.8.0
+	github.com/stretchr/testify v1.4.0
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a // indirect
+)
+
+replace github.com/foo/bar => github.com/foo/baz v1.0.0
@@ -0,0 +1,200 @@
+package dependencybot
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+// Update a dependency which is older than the version stream and the files which declare it
+type Update struct {
+	Kind      Kind
+	Name      string
+	ToVersion string
+	// GitURL the source repository of the dependency from the version stream if it is known
+	GitURL    string
+	Component string
+	// Dependencies the declarations of the dependency which are updated
+	Dependencies []*Dependency
+}
+
+// FromVersions returns the distinct versions the dependency is updated from
+func (u *Update) FromVersions() []string {
+	answer := []string{}
+	for _, d := range u.Dependencies {
+		if util.StringArrayIndex(answer, d.Version) < 0 {
+			answer = append(answer, d.Version)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// CommitMessage returns the commit message for the update which the dependency matrix can parse
+func (u *Update) CommitMessage() string {
+	return "chore(deps): bump " + u.bump()
+}
+
+func (u *Update) bump() string {
+	return fmt.Sprintf("%s from %s to %s", u.Name, strings.Join(u.FromVersions(), ", "), u.ToVersion)
+}
+
+// DependencyUpdate returns the dependency matrix entry for an update of a dependency without a source repository
+func (u *Update) DependencyUpdate() *v1.DependencyUpdate {
+	details := v1.DependencyUpdateDetails{
+		Repo:        u.Name,
+		FromVersion: strings.Join(u.FromVersions(), ", "),
+		ToVersion:   u.ToVersion,
+		Component:   u.Component,
+	}
+	switch u.Kind {
+	case KindGo:
+		paths := strings.SplitN(u.Name, "/", 3)
+		details.Host = paths[0]
+		if len(paths) == 3 {
+			details.Owner = paths[1]
+			details.Repo = paths[2]
+		} else if len(paths) == 2 {
+			details.Repo = paths[1]
+		}
+		details.URL = "https://" + u.Name
+	case KindMaven:
+		details.Host = "repo.maven.apache.org"
+		paths := strings.SplitN(u.Name, ":", 2)
+		if len(paths) == 2 {
+			details.Owner = paths[0]
+			details.Repo = paths[1]
+		}
+		details.URL = "https://search.maven.org/artifact/" + strings.Replace(u.Name, ":", "/", -1)
+	case KindNpm:
+		details.Host = "registry.npmjs.org"
+		if strings.HasPrefix(u.Name, "@") {
+			paths := strings.SplitN(u.Name, "/", 2)
+			if len(paths) == 2 {
+				details.Owner = paths[0]
+				details.Repo = paths[1]
+			}
+		}
+		details.URL = "https://www.npmjs.com/package/" + u.Name
+	case KindHelm:
+		if len(u.Dependencies) > 0 {
+			details.URL = u.Dependencies[0].Repository
+			if repoURL, err := url.Parse(details.URL); err == nil {
+				details.Host = repoURL.Host
+			}
+		}
+	}
+	return &v1.DependencyUpdate{DependencyUpdateDetails: details}
+}
+
+// VersionStreamName returns the kind and name of the dependency in the version stream or an empty name if the
+// dependency cannot be in the version stream. Go modules, maven dependencies and npm packages are packages named
+// go/<module>, maven/<groupId>/<artifactId> and npm/<name>. Charts use the prefix of their chart repository
+func VersionStreamName(dependency *Dependency, prefixes *versionstream.RepositoryPrefixes) (versionstream.VersionKind, string) {
+	switch dependency.Kind {
+	case KindGo:
+		return versionstream.KindPackage, "go/" + dependency.Name
+	case KindMaven:
+		return versionstream.KindPackage, "maven/" + strings.Replace(dependency.Name, ":", "/", -1)
+	case KindNpm:
+		return versionstream.KindPackage, "npm/" + dependency.Name
+	case KindHelm:
+		if prefixes == nil {
+			return versionstream.KindChart, ""
+		}
+		prefix := prefixes.PrefixForURL(strings.TrimSuffix(dependency.Repository, "/"))
+		if prefix == "" {
+			prefix = prefixes.PrefixForURL(dependency.Repository)
+		}
+		if prefix == "" {
+			return versionstream.KindChart, ""
+		}
+		return versionstream.KindChart, prefix + "/" + dependency.Name
+	}
+	return "", ""
+}
+
+// FindUpdates returns the updates of the dependencies which are older than the version stream and not ignored by
+// the policy
+func FindUpdates(dependencies []*Dependency, resolver *versionstream.VersionResolver, policy *v1.DependencyUpdatePolicy) ([]*Update, error) {
+	var prefixes *versionstream.RepositoryPrefixes
+	updates := map[string]*Update{}
+	var answer []*Update
+	for _, d := range dependencies {
+		if d.Kind == KindHelm && prefixes == nil {
+			var err error
+			prefixes, err = resolver.GetRepositoryPrefixes()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to load the chart repository prefixes of the version stream")
+			}
+		}
+		kind, name := VersionStreamName(d, prefixes)
+		if name == "" {
+			continue
+		}
+		stable, err := resolver.StableVersion(kind, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the version stream version of %s", name)
+		}
+		if stable.Version == "" || !IsNewerVersion(d.Version, stable.Version) || IsIgnored(policy, d.Name, stable.Version) {
+			continue
+		}
+		key := string(d.Kind) + "/" + d.Name + "@" + stable.Version
+		update := updates[key]
+		if update == nil {
+			update = &Update{
+				Kind:      d.Kind,
+				Name:      d.Name,
+				ToVersion: stable.Version,
+				GitURL:    stable.GitURL,
+				Component: stable.Component,
+			}
+			updates[key] = update
+			answer = append(answer, update)
+		}
+		update.Dependencies = append(update.Dependencies, d)
+	}
+	return answer, nil
+}
+
+// IsNewerVersion returns true if both versions are semantic versions and the candidate is newer than the current version
+func IsNewerVersion(current string, candidate string) bool {
+	currentVersion, err := semver.ParseTolerant(current)
+	if err != nil {
+		return false
+	}
+	candidateVersion, err := semver.ParseTolerant(candidate)
+	if err != nil {
+		return false
+	}
+	return candidateVersion.GT(currentVersion)
+}
+
+// IsIgnored returns true if the policy ignores the version of the dependency. Each ignore entry is a name pattern
+// optionally followed by @ and a version pattern
+func IsIgnored(policy *v1.DependencyUpdatePolicy, name string, version string) bool {
+	if policy == nil {
+		return false
+	}
+	for _, ignore := range policy.Ignore {
+		namePattern := ignore
+		versionPattern := "*"
+		// scoped npm packages start with @
+		idx := strings.LastIndex(ignore, "@")
+		if idx > 0 {
+			namePattern = ignore[:idx]
+			versionPattern = ignore[idx+1:]
+		}
+		if util.StringMatchesPattern(name, namePattern) && util.StringMatchesPattern(version, versionPattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package environments
+
+import (
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+)
+
+// AppVersion is a version of an app in the requirements of an environment git repository
+type AppVersion struct {
+	Version       string
+	CommitSHA     string
+	CommitMessage string
+}
+
+// AppVersionHistory returns the versions of the app in the history of the requirements of the environment git
+// repository, newest first. Each version refers to the commit which changed the app to that version
+func AppVersionHistory(provider gits.GitProvider, owner string, repo string, app string, maxCommits int) ([]AppVersion, error) {
+	commits, err := provider.ListCommits(owner, repo, &gits.ListCommitsArguments{
+		Path:    RequirementsPath,
+		PerPage: maxCommits,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the commits of %s in %s/%s", RequirementsPath, owner, repo)
+	}
+	var answer []AppVersion
+	for _, commit := range commits {
+		if commit == nil || commit.SHA == "" {
+			continue
+		}
+		requirements, err := LoadRequirements(provider, owner, repo, commit.SHA)
+		if err != nil {
+			return nil, err
+		}
+		if requirements == nil {
+			continue
+		}
+		dep := FindDependency(requirements, app)
+		if dep == nil || dep.Version == "" {
+			continue
+		}
+		version := dep.Version
+		entry := AppVersion{
+			Version:       version,
+			CommitSHA:     commit.SHA,
+			CommitMessage: commit.Message,
+		}
+		// the commits are newest first so an older commit with the same version is the one which introduced it
+		if len(answer) > 0 && answer[len(answer)-1].Version == version {
+			answer[len(answer)-1] = entry
+			continue
+		}
+		answer = append(answer, entry)
+	}
+	return answer, nil
+}
@@ -0,0 +1,76 @@
+package environments_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type historyProvider struct {
+	gits.GitProvider
+
+	commits  []*gits.GitCommit
+	contents map[string]string
+}
+
+func (p *historyProvider) ListCommits(owner string, repo string, opt *gits.ListCommitsArguments) ([]*gits.GitCommit, error) {
+	return p.commits, nil
+}
+
+func (p *historyProvider) GetContent(org string, name string, path string, ref string) (*gits.GitFileContent, error) {
+	text, ok := p.contents[ref]
+	if !ok {
+		return nil, fmt.Errorf("no content for %s", ref)
+	}
+	return &gits.GitFileContent{
+		Encoding: "base64",
+		Content:  base64.StdEncoding.EncodeToString([]byte(text)),
+	}, nil
+}
+
+func requirements(version string) string {
+	return fmt.Sprintf(`dependencies:
+- name: exposecontroller
+  version: 2.3.89
+- name: myapp
+  version: %s
+`, version)
+}
+
+func TestAppVersionHistory(t *testing.T) {
+	t.Parallel()
+	provider := &historyProvider{
+		commits: []*gits.GitCommit{
+			{SHA: "e", Message: "chore: bump exposecontroller"},
+			{SHA: "d", Message: "chore: myapp to 1.0.3"},
+			{SHA: "c", Message: "chore: myapp to 1.0.2"},
+			{SHA: "b", Message: "chore: bump exposecontroller"},
+			{SHA: "a", Message: "chore: myapp to 1.0.1"},
+			{SHA: "0", Message: "initial import"},
+		},
+		contents: map[string]string{
+			"e": requirements("1.0.3"),
+			"d": requirements("1.0.3"),
+			"c": requirements("1.0.2"),
+			"b": requirements("1.0.1"),
+			"a": requirements("1.0.1"),
+			"0": "dependencies:\n- name: exposecontroller\n  version: 2.3.89\n",
+		},
+	}
+
+	history, err := environments.AppVersionHistory(provider, "myorg", "environment-staging", "myapp", 50)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, environments.AppVersion{Version: "1.0.3", CommitSHA: "d", CommitMessage: "chore: myapp to 1.0.3"}, history[0])
+	assert.Equal(t, environments.AppVersion{Version: "1.0.2", CommitSHA: "c", CommitMessage: "chore: myapp to 1.0.2"}, history[1])
+	assert.Equal(t, environments.AppVersion{Version: "1.0.1", CommitSHA: "a", CommitMessage: "chore: myapp to 1.0.1"}, history[2])
+
+	history, err = environments.AppVersionHistory(provider, "myorg", "environment-staging", "another", 50)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
@@ -0,0 +1,86 @@
+package environments
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RequirementsPath the path of the requirements of the chart in an environment git repository
+	RequirementsPath = "env/" + helm.RequirementsFileName
+	// ValuesPath the path of the values of the chart in an environment git repository
+	ValuesPath = "env/" + helm.ValuesFileName
+)
+
+// LoadRequirements loads the requirements of the environment git repository at the given ref or returns nil if there
+// are none
+func LoadRequirements(provider gits.GitProvider, owner string, repo string, ref string) (*helm.Requirements, error) {
+	data, err := loadFile(provider, owner, repo, RequirementsPath, ref)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	requirements, err := helm.LoadRequirements(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s at %s", RequirementsPath, ref)
+	}
+	return requirements, nil
+}
+
+// LoadValues loads the values of the environment git repository at the given ref or returns nil if there are none
+func LoadValues(provider gits.GitProvider, owner string, repo string, ref string) (map[string]interface{}, error) {
+	data, err := loadFile(provider, owner, repo, ValuesPath, ref)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s at %s", ValuesPath, ref)
+	}
+	return values, nil
+}
+
+// loadFile returns the contents of the file in the git repository at the given ref or nil if there is no such file
+func loadFile(provider gits.GitProvider, owner string, repo string, path string, ref string) ([]byte, error) {
+	content, err := provider.GetContent(owner, repo, path, ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s at %s of %s/%s", path, ref, owner, repo)
+	}
+	if content == nil {
+		return nil, nil
+	}
+	data := []byte(content.Content)
+	if content.Encoding == "base64" {
+		data, err = base64.StdEncoding.DecodeString(strings.Replace(content.Content, "\n", "", -1))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s at %s", path, ref)
+		}
+	}
+	return data, nil
+}
+
+// FindDependency returns the dependency of the requirements for the app name or alias or nil if there is none
+func FindDependency(requirements *helm.Requirements, app string) *helm.Dependency {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && (dep.Name == app || dep.Alias == app) {
+			return dep
+		}
+	}
+	return nil
+}
+
+// DependencyValues returns the values which the environment chart overrides for the dependency, which are nested under
+// the alias of the dependency or its name if it has no alias, or nil if there are none
+func DependencyValues(values map[string]interface{}, dep *helm.Dependency) map[string]interface{} {
+	key := dep.Alias
+	if key == "" {
+		key = dep.Name
+	}
+	answer, _ := values[key].(map[string]interface{})
+	return answer
+}
@@ -0,0 +1,40 @@
+package environments_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/environments"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyValues(t *testing.T) {
+	t.Parallel()
+	provider := &historyProvider{
+		contents: map[string]string{
+			"master": `myapp:
+  replicaCount: 2
+  env:
+    DB_HOST: staging-db
+mydb:
+  persistence: true
+expose:
+  enabled: true
+`,
+		},
+	}
+	values, err := environments.LoadValues(provider, "jstrachan", "environment-staging", "master")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount": float64(2),
+		"env": map[string]interface{}{
+			"DB_HOST": "staging-db",
+		},
+	}, environments.DependencyValues(values, &helm.Dependency{Name: "myapp"}))
+	assert.Equal(t, map[string]interface{}{
+		"persistence": true,
+	}, environments.DependencyValues(values, &helm.Dependency{Name: "postgresql", Alias: "mydb"}))
+	assert.Nil(t, environments.DependencyValues(values, &helm.Dependency{Name: "other"}))
+}
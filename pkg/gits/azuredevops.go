@@ -0,0 +1,1888 @@
+package gits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-github/github"
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// azureAPIVersion the version of the Azure DevOps REST API used
+	azureAPIVersion = "5.1"
+	// azureCommentsAPIVersion the version of the Azure DevOps work item comments REST API which is still in preview
+	azureCommentsAPIVersion = "5.1-preview.3"
+	// azureProfileURL the URL of the Azure DevOps profile service used to find the organisations of the current user
+	azureProfileURL = "https://app.vssps.visualstudio.com"
+	// azureWorkItemType the type of work item created for issues
+	azureWorkItemType = "Issue"
+	// azurePageSize the number of values requested per page from the list APIs
+	azurePageSize = 100
+	// azureWorkItemsBatchSize the maximum number of work items which can be requested at once
+	azureWorkItemsBatchSize = 200
+	// azureContinuationTokenHeader the response header with the token of the next page of the list APIs which use them
+	azureContinuationTokenHeader = "x-ms-continuationtoken"
+)
+
+var (
+	// azureWebHookEventTypes the service hook events which are sent to webhooks
+	azureWebHookEventTypes = []string{
+		"git.push",
+		"git.pullrequest.created",
+		"git.pullrequest.updated",
+		"git.pullrequest.merged",
+		"ms.vss-code.git-pullrequest-comment-event",
+	}
+
+	// azureClosedWorkItemStates the work item states of the different Azure Boards processes which mean closed
+	azureClosedWorkItemStates = []string{"Closed", "Done", "Removed", "Resolved"}
+
+	azureCommitSHA = regexp.MustCompile("^[0-9a-f]{40}$")
+)
+
+// AzureDevOpsProvider implements GitProvider interface for Azure DevOps Repos.
+//
+// The owner of a repository is the Azure DevOps organisation. Repository names can be qualified as project/name in
+// which case they are found in that project, otherwise they are found by name across the projects of the organisation
+// failing if the name is not unique. Arguments with a GitRepository use its Project which is parsed from the git URL.
+//
+// Azure DevOps does not sign the payloads of service hooks so Prow and Lighthouse, which only accept webhooks signed
+// with their HMAC token, cannot receive its webhooks. jx import and jx update webhooks do not register them
+type AzureDevOpsProvider struct {
+	Username   string
+	Client     *http.Client
+	ProfileURL string
+
+	Server auth.AuthServer
+	User   auth.UserAuth
+	Git    Gitter
+}
+
+type azureProject struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+type azureRepository struct {
+	ID               string           `json:"id,omitempty"`
+	Name             string           `json:"name,omitempty"`
+	URL              string           `json:"url,omitempty"`
+	RemoteURL        string           `json:"remoteUrl,omitempty"`
+	SSHURL           string           `json:"sshUrl,omitempty"`
+	WebURL           string           `json:"webUrl,omitempty"`
+	DefaultBranch    string           `json:"defaultBranch,omitempty"`
+	IsFork           bool             `json:"isFork,omitempty"`
+	Project          *azureProject    `json:"project,omitempty"`
+	ParentRepository *azureRepository `json:"parentRepository,omitempty"`
+}
+
+type azureIdentity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	UniqueName  string `json:"uniqueName,omitempty"`
+	URL         string `json:"url,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+type azureCommitRef struct {
+	CommitID string `json:"commitId,omitempty"`
+}
+
+type azureLabel struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Active bool   `json:"active,omitempty"`
+}
+
+type azurePullRequest struct {
+	PullRequestID         int              `json:"pullRequestId,omitempty"`
+	Status                string           `json:"status,omitempty"`
+	CreatedBy             *azureIdentity   `json:"createdBy,omitempty"`
+	CreationDate          *time.Time       `json:"creationDate,omitempty"`
+	ClosedDate            *time.Time       `json:"closedDate,omitempty"`
+	Title                 string           `json:"title,omitempty"`
+	Description           string           `json:"description,omitempty"`
+	SourceRefName         string           `json:"sourceRefName,omitempty"`
+	TargetRefName         string           `json:"targetRefName,omitempty"`
+	MergeStatus           string           `json:"mergeStatus,omitempty"`
+	LastMergeSourceCommit *azureCommitRef  `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeCommit       *azureCommitRef  `json:"lastMergeCommit,omitempty"`
+	Repository            *azureRepository `json:"repository,omitempty"`
+	Reviewers             []azureIdentity  `json:"reviewers,omitempty"`
+	Labels                []azureLabel     `json:"labels,omitempty"`
+	URL                   string           `json:"url,omitempty"`
+}
+
+type azureGitUserDate struct {
+	Name  string     `json:"name,omitempty"`
+	Email string     `json:"email,omitempty"`
+	Date  *time.Time `json:"date,omitempty"`
+}
+
+type azureCommit struct {
+	CommitID  string            `json:"commitId,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+	Author    *azureGitUserDate `json:"author,omitempty"`
+	Committer *azureGitUserDate `json:"committer,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	RemoteURL string            `json:"remoteUrl,omitempty"`
+}
+
+type azureStatusContext struct {
+	Name  string `json:"name,omitempty"`
+	Genre string `json:"genre,omitempty"`
+}
+
+type azureStatus struct {
+	ID          int64              `json:"id,omitempty"`
+	State       string             `json:"state,omitempty"`
+	Description string             `json:"description,omitempty"`
+	TargetURL   string             `json:"targetUrl,omitempty"`
+	Context     azureStatusContext `json:"context"`
+}
+
+type azureRef struct {
+	Name           string `json:"name,omitempty"`
+	ObjectID       string `json:"objectId,omitempty"`
+	PeeledObjectID string `json:"peeledObjectId,omitempty"`
+}
+
+type azureAnnotatedTag struct {
+	Name         string            `json:"name,omitempty"`
+	ObjectID     string            `json:"objectId,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	TaggedObject *azureTaggedObj   `json:"taggedObject,omitempty"`
+	TaggedBy     *azureGitUserDate `json:"taggedBy,omitempty"`
+}
+
+type azureTaggedObj struct {
+	ObjectID string `json:"objectId,omitempty"`
+}
+
+type azureItem struct {
+	ObjectID string `json:"objectId,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Content  string `json:"content,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+type azureSubscription struct {
+	ID               string            `json:"id,omitempty"`
+	PublisherID      string            `json:"publisherId,omitempty"`
+	EventType        string            `json:"eventType,omitempty"`
+	ResourceVersion  string            `json:"resourceVersion,omitempty"`
+	ConsumerID       string            `json:"consumerId,omitempty"`
+	ConsumerActionID string            `json:"consumerActionId,omitempty"`
+	PublisherInputs  map[string]string `json:"publisherInputs,omitempty"`
+	ConsumerInputs   map[string]string `json:"consumerInputs,omitempty"`
+}
+
+type azureLink struct {
+	Href string `json:"href,omitempty"`
+}
+
+type azureWorkItem struct {
+	ID     int                    `json:"id,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	Links  map[string]azureLink   `json:"_links,omitempty"`
+	URL    string                 `json:"url,omitempty"`
+}
+
+type azurePatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+type azureComment struct {
+	ID            int64          `json:"id,omitempty"`
+	Content       string         `json:"content,omitempty"`
+	CommentType   string         `json:"commentType,omitempty"`
+	Author        *azureIdentity `json:"author,omitempty"`
+	PublishedDate *time.Time     `json:"publishedDate,omitempty"`
+	IsDeleted     bool           `json:"isDeleted,omitempty"`
+}
+
+type azureThread struct {
+	ID        int64          `json:"id,omitempty"`
+	Comments  []azureComment `json:"comments,omitempty"`
+	IsDeleted bool           `json:"isDeleted,omitempty"`
+}
+
+type azureProfile struct {
+	ID           string `json:"id,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	PublicAlias  string `json:"publicAlias,omitempty"`
+}
+
+type azureAccount struct {
+	AccountID   string `json:"accountId,omitempty"`
+	AccountName string `json:"accountName,omitempty"`
+}
+
+// NewAzureDevOpsProvider creates a git provider for Azure DevOps Repos
+func NewAzureDevOpsProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+	provider := AzureDevOpsProvider{
+		Client:     util.GetClient(),
+		ProfileURL: azureProfileURL,
+		Server:     *server,
+		User:       *user,
+		Username:   user.Username,
+		Git:        git,
+	}
+	return &provider, nil
+}
+
+// AzureDevOpsAccessTokenURL returns the URL to create personal access tokens on Azure DevOps
+func AzureDevOpsAccessTokenURL(url string) string {
+	return util.UrlJoin(url, "_usersSettings/tokens")
+}
+
+// apiURL returns the URL of an API resource in the organisation or organisation/project
+func (p *AzureDevOpsProvider) apiURL(org string, paths ...string) string {
+	return util.UrlJoin(append([]string{p.Server.URL, org, "_apis"}, paths...)...)
+}
+
+// do invokes the Azure DevOps REST API encoding the body and decoding the response into the result if they are not nil
+func (p *AzureDevOpsProvider) do(method string, u string, query url.Values, body interface{}, contentType string, result interface{}) error {
+	_, err := p.send(method, u, query, body, contentType, result)
+	return err
+}
+
+// send invokes the Azure DevOps REST API like do returning the headers of the response
+func (p *AzureDevOpsProvider) send(method string, u string, query url.Values, body interface{}, contentType string, result interface{}) (http.Header, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if query.Get("api-version") == "" {
+		query.Set("api-version", azureAPIVersion)
+	}
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to JSON encode the request body for %s", u)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, u+"?"+query.Encode(), reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the request for %s", u)
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	// Azure DevOps ignores the username of personal access tokens
+	req.SetBasicAuth(p.Username, p.User.ApiToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the response of %s %s", method, u)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(data, &message) == nil && message.Message != "" {
+			return nil, fmt.Errorf("%s %s returned status %d: %s", method, u, resp.StatusCode, message.Message)
+		}
+		return nil, fmt.Errorf("%s %s returned status %d", method, u, resp.StatusCode)
+	}
+	if result != nil && len(data) > 0 {
+		err = json.Unmarshal(data, result)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the response of %s %s", method, u)
+		}
+	}
+	return resp.Header, nil
+}
+
+func (p *AzureDevOpsProvider) get(u string, query url.Values, result interface{}) error {
+	return p.do(http.MethodGet, u, query, nil, "", result)
+}
+
+// getPages gets every page of a list API passing the response of each page to the page function which returns the
+// number of values on the page. Pages follow the continuation token of the response if there is one, otherwise the
+// values already read are skipped using the skip parameter until a page is not full
+func (p *AzureDevOpsProvider) getPages(u string, query url.Values, topParam string, skipParam string, page func(data []byte) (int, error)) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set(topParam, strconv.Itoa(azurePageSize))
+	skip := 0
+	for {
+		data := json.RawMessage{}
+		header, err := p.send(http.MethodGet, u, query, nil, "", &data)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		count, err := page(data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the response of GET %s", u)
+		}
+		token := header.Get(azureContinuationTokenHeader)
+		switch {
+		case token != "":
+			query.Set("continuationToken", token)
+		case skipParam == "" || count < azurePageSize:
+			return nil
+		default:
+			skip += count
+			query.Set(skipParam, strconv.Itoa(skip))
+		}
+	}
+}
+
+// azureRepositoryName returns the name of the repository qualified by its project if it is known
+func azureRepositoryName(repo *GitRepository) string {
+	if repo.Project != "" && !strings.Contains(repo.Name, "/") {
+		return repo.Project + "/" + repo.Name
+	}
+	return repo.Name
+}
+
+// findRepository returns the repository with the name in the organisation or nil if there is none. Names qualified
+// as project/name are only looked up in that project. Unqualified names fail if repositories in different projects
+// have the name
+func (p *AzureDevOpsProvider) findRepository(org string, name string) (*azureRepository, error) {
+	scope := org
+	idx := strings.Index(name, "/")
+	if idx > 0 {
+		scope = util.UrlJoin(org, name[:idx])
+		name = name[idx+1:]
+	}
+	repos, err := p.listRepositories(scope)
+	if err != nil {
+		return nil, err
+	}
+	var answer *azureRepository
+	for i := range repos {
+		r := &repos[i]
+		if !strings.EqualFold(r.Name, name) {
+			continue
+		}
+		if answer != nil {
+			return nil, fmt.Errorf("there is a repository %s in more than one project of Azure DevOps organisation %s. Please qualify it with its project such as %s/%s",
+				name, org, azureProjectName(answer), name)
+		}
+		answer = r
+	}
+	return answer, nil
+}
+
+func azureProjectName(repo *azureRepository) string {
+	if repo.Project == nil {
+		return ""
+	}
+	return repo.Project.Name
+}
+
+// getRepository returns the repository with the name in the organisation failing if there is none
+func (p *AzureDevOpsProvider) getRepository(org string, name string) (*azureRepository, error) {
+	repo, err := p.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("repository %s not found in Azure DevOps organisation %s", name, org)
+	}
+	return repo, nil
+}
+
+// listRepositories lists the repositories of the organisation or organisation/project. The API returns every
+// repository at once rather than paging them
+func (p *AzureDevOpsProvider) listRepositories(scope string) ([]azureRepository, error) {
+	result := struct {
+		Value []azureRepository `json:"value"`
+	}{}
+	err := p.get(p.apiURL(scope, "git/repositories"), nil, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the repositories of Azure DevOps organisation %s", scope)
+	}
+	return result.Value, nil
+}
+
+// defaultProject returns the project new repositories are created in. Names can be qualified as project/name
+// otherwise the only project of the organisation or the project with the same name as the organisation is used
+func (p *AzureDevOpsProvider) defaultProject(org string, name string) (*azureProject, error) {
+	projects := []azureProject{}
+	err := p.getPages(p.apiURL(org, "projects"), nil, "$top", "$skip", func(data []byte) (int, error) {
+		result := struct {
+			Value []azureProject `json:"value"`
+		}{}
+		err := json.Unmarshal(data, &result)
+		projects = append(projects, result.Value...)
+		return len(result.Value), err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the projects of Azure DevOps organisation %s", org)
+	}
+	idx := strings.Index(name, "/")
+	if idx > 0 {
+		for i := range projects {
+			if strings.EqualFold(projects[i].Name, name[:idx]) {
+				return &projects[i], nil
+			}
+		}
+		return nil, fmt.Errorf("project %s not found in Azure DevOps organisation %s", name[:idx], org)
+	}
+	if len(projects) == 1 {
+		return &projects[0], nil
+	}
+	names := []string{}
+	for i := range projects {
+		if strings.EqualFold(projects[i].Name, org) {
+			return &projects[i], nil
+		}
+		names = append(names, projects[i].Name)
+	}
+	return nil, fmt.Errorf("cannot choose a project in Azure DevOps organisation %s for repository %s. Please use one of %s as a prefix such as %s/%s",
+		org, name, strings.Join(names, ", "), strings.Join(names[:1], ""), name)
+}
+
+func (p *AzureDevOpsProvider) toGitRepository(org string, repo *azureRepository) *GitRepository {
+	answer := &GitRepository{
+		Name:         repo.Name,
+		HTMLURL:      repo.WebURL,
+		CloneURL:     repo.RemoteURL,
+		SSHURL:       repo.SSHURL,
+		URL:          repo.WebURL,
+		Fork:         repo.IsFork,
+		Organisation: org,
+		HasIssues:    true,
+		Private:      true,
+	}
+	if repo.Project != nil {
+		answer.Project = repo.Project.Name
+		answer.Private = repo.Project.Visibility != "public"
+	}
+	u, err := url.Parse(repo.WebURL)
+	if err == nil {
+		answer.Scheme = u.Scheme
+		answer.Host = u.Host
+	}
+	return answer
+}
+
+// ListOrganisations lists the Azure DevOps organisations of the current user
+func (p *AzureDevOpsProvider) ListOrganisations() ([]GitOrganisation, error) {
+	answer := []GitOrganisation{}
+	profile, err := p.profile()
+	if err != nil {
+		return answer, err
+	}
+	query := url.Values{}
+	query.Set("memberId", profile.ID)
+	result := struct {
+		Value []azureAccount `json:"value"`
+	}{}
+	err = p.get(util.UrlJoin(p.ProfileURL, "_apis/accounts"), query, &result)
+	if err != nil {
+		return answer, errors.Wrap(err, "failed to list the Azure DevOps organisations")
+	}
+	for _, account := range result.Value {
+		if account.AccountName != "" {
+			answer = append(answer, GitOrganisation{Login: account.AccountName})
+		}
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) profile() (*azureProfile, error) {
+	profile := &azureProfile{}
+	err := p.get(util.UrlJoin(p.ProfileURL, "_apis/profile/profiles/me"), nil, profile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the Azure DevOps profile of the current user")
+	}
+	return profile, nil
+}
+
+func (p *AzureDevOpsProvider) ListRepositories(org string) ([]*GitRepository, error) {
+	answer := []*GitRepository{}
+	repos, err := p.listRepositories(org)
+	if err != nil {
+		return answer, err
+	}
+	for i := range repos {
+		answer = append(answer, p.toGitRepository(org, &repos[i]))
+	}
+	return answer, nil
+}
+
+// CreateRepository creates a repository. Azure DevOps repositories inherit the visibility of their project so private
+// is ignored
+func (p *AzureDevOpsProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
+	project, err := p.defaultProject(org, name)
+	if err != nil {
+		return nil, err
+	}
+	body := &azureRepository{
+		Name:    name[strings.Index(name, "/")+1:],
+		Project: &azureProject{ID: project.ID},
+	}
+	repo := &azureRepository{}
+	err = p.do(http.MethodPost, p.apiURL(org, "git/repositories"), nil, body, "", repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create repository %s in Azure DevOps organisation %s", name, org)
+	}
+	return p.toGitRepository(org, repo), nil
+}
+
+func (p *AzureDevOpsProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return p.toGitRepository(org, repo), nil
+}
+
+func (p *AzureDevOpsProvider) DeleteRepository(org string, name string) error {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return err
+	}
+	return p.do(http.MethodDelete, p.apiURL(org, "git/repositories", repo.ID), nil, nil, "", nil)
+}
+
+// ForkRepository forks a repository into the default project of the destination organisation. Azure DevOps only
+// supports forks within an organisation
+func (p *AzureDevOpsProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
+	if destinationOrg == "" {
+		destinationOrg = originalOrg
+	}
+	if destinationOrg != originalOrg {
+		return nil, fmt.Errorf("Azure DevOps cannot fork repository %s from organisation %s into organisation %s", name, originalOrg, destinationOrg)
+	}
+	parent, err := p.getRepository(originalOrg, name)
+	if err != nil {
+		return nil, err
+	}
+	project, err := p.defaultProject(destinationOrg, parent.Name)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Project != nil && parent.Project.ID == project.ID {
+		return nil, fmt.Errorf("cannot fork repository %s into its own project %s", name, project.Name)
+	}
+	body := &azureRepository{
+		Name:    parent.Name,
+		Project: &azureProject{ID: project.ID},
+		ParentRepository: &azureRepository{
+			ID:      parent.ID,
+			Project: &azureProject{ID: parent.Project.ID},
+		},
+	}
+	repo := &azureRepository{}
+	err = p.do(http.MethodPost, p.apiURL(destinationOrg, "git/repositories"), nil, body, "", repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fork repository %s into project %s", name, project.Name)
+	}
+	return p.toGitRepository(destinationOrg, repo), nil
+}
+
+func (p *AzureDevOpsProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	renamed := &azureRepository{}
+	err = p.do(http.MethodPatch, p.apiURL(org, "git/repositories", repo.ID), nil, &azureRepository{Name: newName}, "", renamed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to rename repository %s to %s", name, newName)
+	}
+	return p.toGitRepository(org, renamed), nil
+}
+
+func (p *AzureDevOpsProvider) ValidateRepositoryName(org string, name string) error {
+	repo, err := p.findRepository(org, name)
+	if err != nil {
+		return err
+	}
+	if repo != nil {
+		return fmt.Errorf("Repository %s already exists", p.Git.RepoName(org, name))
+	}
+	return nil
+}
+
+// azureRefName returns the full git ref name of a branch stripping any owner prefix used for forks
+func azureRefName(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	idx := strings.Index(branch, ":")
+	if idx >= 0 {
+		branch = branch[idx+1:]
+	}
+	return "refs/heads/" + branch
+}
+
+func (p *AzureDevOpsProvider) pullRequestURL(repo *azureRepository, number int) string {
+	return util.UrlJoin(repo.WebURL, "pullrequest", strconv.Itoa(number))
+}
+
+func (p *AzureDevOpsProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	repo, err := p.getRepository(owner, azureRepositoryName(data.GitRepository))
+	if err != nil {
+		return nil, err
+	}
+	body := &azurePullRequest{
+		Title:         data.Title,
+		Description:   data.Body,
+		SourceRefName: azureRefName(data.Head),
+		TargetRefName: azureRefName(data.Base),
+	}
+	for _, label := range data.Labels {
+		body.Labels = append(body.Labels, azureLabel{Name: label})
+	}
+	pr := &azurePullRequest{}
+	err = p.do(http.MethodPost, p.apiURL(owner, "git/repositories", repo.ID, "pullrequests"), nil, body, "", pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the pull request on %s/%s", owner, repo.Name)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+func (p *AzureDevOpsProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	repo, err := p.getRepository(owner, azureRepositoryName(data.GitRepository))
+	if err != nil {
+		return nil, err
+	}
+	body := &azurePullRequest{
+		Title:       data.Title,
+		Description: data.Body,
+	}
+	pr := &azurePullRequest{}
+	err = p.do(http.MethodPatch, p.apiURL(owner, "git/repositories", repo.ID, "pullrequests", strconv.Itoa(number)), nil, body, "", pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update pull request %d on %s/%s", number, owner, repo.Name)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+func (p *AzureDevOpsProvider) getPullRequest(owner string, repo *azureRepository, number int) (*azurePullRequest, error) {
+	pr := &azurePullRequest{}
+	err := p.get(p.apiURL(owner, "git/repositories", repo.ID, "pullrequests", strconv.Itoa(number)), nil, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get pull request %d on %s/%s", number, owner, repo.Name)
+	}
+	return pr, nil
+}
+
+func (p *AzureDevOpsProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	repo, err := p.getRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	source, err := p.getPullRequest(pr.Owner, repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	p.updatePullRequest(pr, repo, source)
+	return nil
+}
+
+func (p *AzureDevOpsProvider) toPullRequest(owner string, repo *azureRepository, source *azurePullRequest) *GitPullRequest {
+	number := source.PullRequestID
+	answer := &GitPullRequest{
+		Owner:  owner,
+		Repo:   repo.Name,
+		Number: &number,
+	}
+	p.updatePullRequest(answer, repo, source)
+	return answer
+}
+
+// updatePullRequest updates the pr with the data from Azure DevOps
+func (p *AzureDevOpsProvider) updatePullRequest(pr *GitPullRequest, repo *azureRepository, source *azurePullRequest) {
+	pr.URL = p.pullRequestURL(repo, source.PullRequestID)
+	pr.Title = source.Title
+	pr.Body = source.Description
+	if source.CreatedBy != nil {
+		pr.Author = toAzureUser(source.CreatedBy)
+	}
+	if source.LastMergeSourceCommit != nil {
+		pr.LastCommitSha = source.LastMergeSourceCommit.CommitID
+	}
+	headRef := strings.TrimPrefix(source.SourceRefName, "refs/heads/")
+	pr.HeadRef = &headRef
+
+	state := "open"
+	merged := source.Status == "completed"
+	if source.Status != "active" {
+		state = "closed"
+		pr.ClosedAt = source.ClosedDate
+	}
+	pr.State = &state
+	pr.Merged = &merged
+	if merged {
+		pr.MergedAt = source.ClosedDate
+		if source.LastMergeCommit != nil {
+			pr.MergeCommitSHA = &source.LastMergeCommit.CommitID
+		}
+	}
+	mergeable := source.MergeStatus == "succeeded"
+	pr.Mergeable = &mergeable
+
+	pr.RequestedReviewers = make([]*GitUser, 0)
+	for i := range source.Reviewers {
+		pr.RequestedReviewers = append(pr.RequestedReviewers, toAzureUser(&source.Reviewers[i]))
+	}
+	pr.Labels = make([]*Label, 0)
+	for i := range source.Labels {
+		l := source.Labels[i]
+		if l.Active {
+			pr.Labels = append(pr.Labels, &Label{Name: &l.Name})
+		}
+	}
+}
+
+func toAzureUser(identity *azureIdentity) *GitUser {
+	return &GitUser{
+		URL:       identity.URL,
+		Login:     identity.UniqueName,
+		Name:      identity.DisplayName,
+		Email:     identity.UniqueName,
+		AvatarURL: identity.ImageURL,
+	}
+}
+
+// AddLabelsToIssue adds labels to pull requests. Work items are tagged using CreateIssue
+func (p *AzureDevOpsProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		err = p.do(http.MethodPost, p.apiURL(owner, "git/repositories", r.ID, "pullRequests", strconv.Itoa(number), "labels"), nil, &azureLabel{Name: label}, "", nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add label %s to pull request %d on %s/%s", label, number, owner, repo)
+		}
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) GetPullRequest(owner string, repo *GitRepository, number int) (*GitPullRequest, error) {
+	r, err := p.getRepository(owner, azureRepositoryName(repo))
+	if err != nil {
+		return nil, err
+	}
+	pr, err := p.getPullRequest(owner, r, number)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, r, pr), nil
+}
+
+func (p *AzureDevOpsProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
+	answer := []*GitPullRequest{}
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return answer, err
+	}
+	query := url.Values{}
+	query.Set("searchCriteria.status", "active")
+	err = p.getPages(p.apiURL(owner, "git/repositories", r.ID, "pullrequests"), query, "$top", "$skip", func(data []byte) (int, error) {
+		result := struct {
+			Value []azurePullRequest `json:"value"`
+		}{}
+		err := json.Unmarshal(data, &result)
+		for i := range result.Value {
+			answer = append(answer, p.toPullRequest(owner, r, &result.Value[i]))
+		}
+		return len(result.Value), err
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list the pull requests on %s/%s", owner, repo)
+	}
+	return answer, nil
+}
+
+func toAzureCommit(commit *azureCommit) *GitCommit {
+	answer := &GitCommit{
+		SHA:     commit.CommitID,
+		Message: commit.Comment,
+		URL:     commit.RemoteURL,
+	}
+	if commit.Author != nil {
+		answer.Author = &GitUser{
+			Login: commit.Author.Email,
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		}
+	}
+	if commit.Committer != nil {
+		answer.Committer = &GitUser{
+			Login: commit.Committer.Email,
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+		}
+	}
+	return answer
+}
+
+func (p *AzureDevOpsProvider) GetPullRequestCommits(owner string, repo *GitRepository, number int) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	r, err := p.getRepository(owner, azureRepositoryName(repo))
+	if err != nil {
+		return answer, err
+	}
+	err = p.getPages(p.apiURL(owner, "git/repositories", r.ID, "pullRequests", strconv.Itoa(number), "commits"), nil, "$top", "", azureCommitsPage(&answer))
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list the commits of pull request %d on %s/%s", number, owner, repo.Name)
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
+	ref := pr.LastCommitSha
+	if ref == "" {
+		return "", fmt.Errorf("Missing String for LastCommitSha %#v", pr)
+	}
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, ref)
+	if err != nil {
+		return "", err
+	}
+	for _, status := range statuses {
+		if status.State != "" {
+			return status.State, nil
+		}
+	}
+	return "", fmt.Errorf("Could not find a status for repository %s/%s with ref %s", pr.Owner, pr.Repo, ref)
+}
+
+// azureStatusStates maps the Azure DevOps commit status states to the commit status states
+var azureStatusStates = map[string]string{
+	"succeeded":     "success",
+	"failed":        "failure",
+	"error":         "error",
+	"pending":       "pending",
+	"notSet":        "pending",
+	"notApplicable": "success",
+}
+
+// ListCommitStatus lists the statuses of a commit. Azure DevOps status contexts have a genre and a name which are
+// joined as genre/name
+func (p *AzureDevOpsProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
+	answer := []*GitRepoStatus{}
+	r, err := p.getRepository(org, repo)
+	if err != nil {
+		return answer, err
+	}
+	err = p.getPages(p.apiURL(org, "git/repositories", r.ID, "commits", sha, "statuses"), nil, "top", "skip", func(data []byte) (int, error) {
+		result := struct {
+			Value []azureStatus `json:"value"`
+		}{}
+		err := json.Unmarshal(data, &result)
+		for i := range result.Value {
+			answer = append(answer, toAzureRepoStatus(&result.Value[i]))
+		}
+		return len(result.Value), err
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "Could not find a status for repository %s/%s with ref %s", org, repo, sha)
+	}
+	return answer, nil
+}
+
+func toAzureRepoStatus(status *azureStatus) *GitRepoStatus {
+	context := status.Context.Name
+	if status.Context.Genre != "" {
+		context = status.Context.Genre + "/" + context
+	}
+	state, ok := azureStatusStates[status.State]
+	if !ok {
+		state = status.State
+	}
+	return &GitRepoStatus{
+		ID:          strconv.FormatInt(status.ID, 10),
+		Context:     context,
+		URL:         status.TargetURL,
+		TargetURL:   status.TargetURL,
+		State:       state,
+		Description: status.Description,
+	}
+}
+
+func (p *AzureDevOpsProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	r, err := p.getRepository(org, repo)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	state := status.State
+	for k, v := range azureStatusStates {
+		if v == status.State && k != "notSet" && k != "notApplicable" {
+			state = k
+		}
+	}
+	body := &azureStatus{
+		State:       state,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Context:     azureStatusContext{Name: status.Context},
+	}
+	idx := strings.LastIndex(status.Context, "/")
+	if idx > 0 {
+		body.Context.Genre = status.Context[:idx]
+		body.Context.Name = status.Context[idx+1:]
+	}
+	result := &azureStatus{}
+	err = p.do(http.MethodPost, p.apiURL(org, "git/repositories", r.ID, "commits", sha, "statuses"), nil, body, "", result)
+	if err != nil {
+		return &GitRepoStatus{}, errors.Wrapf(err, "failed to update the status %s of commit %s on %s/%s", status.Context, sha, org, repo)
+	}
+	return toAzureRepoStatus(result), nil
+}
+
+func (p *AzureDevOpsProvider) ListCommits(owner string, repo string, opt *ListCommitsArguments) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return answer, err
+	}
+	query := url.Values{}
+	if opt != nil {
+		if opt.SHA != "" {
+			query.Set("searchCriteria.itemVersion.version", opt.SHA)
+			query.Set("searchCriteria.itemVersion.versionType", azureVersionType(opt.SHA))
+		}
+		if opt.Path != "" {
+			query.Set("searchCriteria.itemPath", "/"+strings.TrimPrefix(opt.Path, "/"))
+		}
+		if opt.Author != "" {
+			query.Set("searchCriteria.author", opt.Author)
+		}
+		if !opt.Since.IsZero() {
+			query.Set("searchCriteria.fromDate", opt.Since.Format(time.RFC3339))
+		}
+		if !opt.Until.IsZero() {
+			query.Set("searchCriteria.toDate", opt.Until.Format(time.RFC3339))
+		}
+		if opt.PerPage > 0 {
+			query.Set("searchCriteria.$top", strconv.Itoa(opt.PerPage))
+			if opt.Page > 1 {
+				query.Set("searchCriteria.$skip", strconv.Itoa((opt.Page-1)*opt.PerPage))
+			}
+		}
+	}
+	u := p.apiURL(owner, "git/repositories", r.ID, "commits")
+	page := azureCommitsPage(&answer)
+	if query.Get("searchCriteria.$top") != "" {
+		// only the requested page
+		data := json.RawMessage{}
+		err = p.get(u, query, &data)
+		if err == nil && len(data) > 0 {
+			_, err = page(data)
+		}
+	} else {
+		err = p.getPages(u, query, "searchCriteria.$top", "searchCriteria.$skip", page)
+	}
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list the commits on %s/%s", owner, repo)
+	}
+	return answer, nil
+}
+
+// azureCommitsPage returns a page function for getPages which appends the commits of each page to the answer
+func azureCommitsPage(answer *[]*GitCommit) func(data []byte) (int, error) {
+	return func(data []byte) (int, error) {
+		result := struct {
+			Value []azureCommit `json:"value"`
+		}{}
+		err := json.Unmarshal(data, &result)
+		for i := range result.Value {
+			*answer = append(*answer, toAzureCommit(&result.Value[i]))
+		}
+		return len(result.Value), err
+	}
+}
+
+// azureVersionType returns the type of a git version which is either a commit SHA or a branch
+func azureVersionType(version string) string {
+	if azureCommitSHA.MatchString(version) {
+		return "commit"
+	}
+	return "branch"
+}
+
+func (p *AzureDevOpsProvider) MergePullRequest(pr *GitPullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	repo, err := p.getRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	current, err := p.getPullRequest(pr.Owner, repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"status":                "completed",
+		"lastMergeSourceCommit": current.LastMergeSourceCommit,
+		"completionOptions": map[string]interface{}{
+			"mergeCommitMessage": message,
+		},
+	}
+	err = p.do(http.MethodPatch, p.apiURL(pr.Owner, "git/repositories", repo.ID, "pullrequests", strconv.Itoa(*pr.Number)), nil, body, "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to merge pull request %d on %s/%s", *pr.Number, pr.Owner, pr.Repo)
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) webHookOwnerAndRepo(data *GitWebHookArguments) (string, *azureRepository, error) {
+	owner := data.Owner
+	name := ""
+	if data.Repo != nil {
+		if data.Repo.Organisation != "" {
+			owner = data.Repo.Organisation
+		}
+		name = azureRepositoryName(data.Repo)
+	}
+	if owner == "" || name == "" {
+		return "", nil, errors.New("missing the organisation and name of the repository of the webhook")
+	}
+	repo, err := p.getRepository(owner, name)
+	return owner, repo, err
+}
+
+// listSubscriptions lists the webhooks of the repository. The API returns every subscription at once rather than
+// paging them
+func (p *AzureDevOpsProvider) listSubscriptions(org string, repo *azureRepository) ([]azureSubscription, error) {
+	query := url.Values{}
+	query.Set("publisherId", "tfs")
+	query.Set("consumerId", "webHooks")
+	result := struct {
+		Value []azureSubscription `json:"value"`
+	}{}
+	err := p.get(p.apiURL(org, "hooks/subscriptions"), query, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the service hooks of Azure DevOps organisation %s", org)
+	}
+	answer := []azureSubscription{}
+	for _, s := range result.Value {
+		if s.PublisherInputs["repository"] == repo.ID {
+			answer = append(answer, s)
+		}
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) webHookConsumerInputs(data *GitWebHookArguments) (map[string]string, error) {
+	if data.Secret != "" {
+		return nil, fmt.Errorf("cannot use a secret for the webhook %s as Azure DevOps does not sign the payloads of service hooks so the receiver cannot validate them", data.URL)
+	}
+	inputs := map[string]string{
+		"url": data.URL,
+	}
+	if data.InsecureSSL {
+		inputs["acceptUntrustedCerts"] = "true"
+	}
+	return inputs, nil
+}
+
+// CreateWebHook creates Azure DevOps service hooks for the push, pull request and pull request comment events of the
+// repository. Azure DevOps cannot sign the payloads of service hooks so webhooks with a secret are refused
+func (p *AzureDevOpsProvider) CreateWebHook(data *GitWebHookArguments) error {
+	if data.URL == "" {
+		return errors.New("missing property URL")
+	}
+	consumerInputs, err := p.webHookConsumerInputs(data)
+	if err != nil {
+		return err
+	}
+	owner, repo, err := p.webHookOwnerAndRepo(data)
+	if err != nil {
+		return err
+	}
+	subscriptions, err := p.listSubscriptions(owner, repo)
+	if err != nil {
+		return err
+	}
+	for _, s := range subscriptions {
+		if s.ConsumerInputs["url"] == data.URL {
+			log.Logger().Warnf("Already has a webhook registered for %s", data.URL)
+			return nil
+		}
+	}
+	projectID := ""
+	if repo.Project != nil {
+		projectID = repo.Project.ID
+	}
+	for _, eventType := range azureWebHookEventTypes {
+		body := &azureSubscription{
+			PublisherID:      "tfs",
+			EventType:        eventType,
+			ResourceVersion:  "1.0",
+			ConsumerID:       "webHooks",
+			ConsumerActionID: "httpRequest",
+			PublisherInputs: map[string]string{
+				"projectId":  projectID,
+				"repository": repo.ID,
+			},
+			ConsumerInputs: consumerInputs,
+		}
+		err = p.do(http.MethodPost, p.apiURL(owner, "hooks/subscriptions"), nil, body, "", nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the %s webhook on %s/%s", eventType, owner, repo.Name)
+		}
+	}
+	return nil
+}
+
+// ListWebHooks lists the URLs the service hooks of the repository call. The secrets are not returned as Azure DevOps
+// masks the basic authentication passwords of service hooks
+func (p *AzureDevOpsProvider) ListWebHooks(org string, repo string) ([]*GitWebHookArguments, error) {
+	answer := []*GitWebHookArguments{}
+	r, err := p.getRepository(org, repo)
+	if err != nil {
+		return answer, err
+	}
+	subscriptions, err := p.listSubscriptions(org, r)
+	if err != nil {
+		return answer, err
+	}
+	urls := map[string]bool{}
+	for _, s := range subscriptions {
+		u := s.ConsumerInputs["url"]
+		if u == "" || urls[u] {
+			continue
+		}
+		urls[u] = true
+		answer = append(answer, &GitWebHookArguments{
+			Owner: org,
+			URL:   u,
+		})
+	}
+	return answer, nil
+}
+
+// UpdateWebHook updates the service hooks of the repository which call the existing URL creating them if there are
+// none
+func (p *AzureDevOpsProvider) UpdateWebHook(data *GitWebHookArguments) error {
+	consumerInputs, err := p.webHookConsumerInputs(data)
+	if err != nil {
+		return err
+	}
+	owner, repo, err := p.webHookOwnerAndRepo(data)
+	if err != nil {
+		return err
+	}
+	existingURL := data.ExistingURL
+	if existingURL == "" {
+		existingURL = data.URL
+	}
+	subscriptions, err := p.listSubscriptions(owner, repo)
+	if err != nil {
+		return err
+	}
+	updated := false
+	for i := range subscriptions {
+		s := &subscriptions[i]
+		if s.ConsumerInputs["url"] != existingURL {
+			continue
+		}
+		s.ConsumerInputs = consumerInputs
+		err = p.do(http.MethodPut, p.apiURL(owner, "hooks/subscriptions", s.ID), nil, s, "", nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update the %s webhook on %s/%s", s.EventType, owner, repo.Name)
+		}
+		updated = true
+	}
+	if !updated {
+		return p.CreateWebHook(data)
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) IsGitHub() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGitea() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketServer() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGerrit() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) Kind() string {
+	return KindAzureDevOps
+}
+
+func fieldString(fields map[string]interface{}, name string) string {
+	value, ok := fields[name]
+	if !ok || value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		// identity fields are objects
+		for _, key := range []string{"uniqueName", "displayName"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func fieldTime(fields map[string]interface{}, name string) *time.Time {
+	s := fieldString(fields, name)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func (p *AzureDevOpsProvider) toIssue(org string, name string, wi *azureWorkItem) *GitIssue {
+	number := wi.ID
+	state := "open"
+	if util.StringArrayIndex(azureClosedWorkItemStates, fieldString(wi.Fields, "System.State")) >= 0 {
+		state = "closed"
+	}
+	issue := &GitIssue{
+		URL:       p.IssueURL(org, name, number, false),
+		Owner:     org,
+		Repo:      name,
+		Number:    &number,
+		Key:       strconv.Itoa(number),
+		Title:     fieldString(wi.Fields, "System.Title"),
+		Body:      fieldString(wi.Fields, "System.Description"),
+		State:     &state,
+		CreatedAt: fieldTime(wi.Fields, "System.CreatedDate"),
+		UpdatedAt: fieldTime(wi.Fields, "System.ChangedDate"),
+		ClosedAt:  fieldTime(wi.Fields, "Microsoft.VSTS.Common.ClosedDate"),
+	}
+	if link, ok := wi.Links["html"]; ok && link.Href != "" {
+		issue.URL = link.Href
+	}
+	if createdBy := fieldString(wi.Fields, "System.CreatedBy"); createdBy != "" {
+		issue.User = &GitUser{Login: createdBy}
+	}
+	if closedBy := fieldString(wi.Fields, "Microsoft.VSTS.Common.ClosedBy"); closedBy != "" {
+		issue.ClosedBy = &GitUser{Login: closedBy}
+	}
+	for _, tag := range strings.Split(fieldString(wi.Fields, "System.Tags"), ";") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			issue.Labels = append(issue.Labels, GitLabel{Name: tag})
+		}
+	}
+	return issue
+}
+
+// GetIssue returns the work item with the number
+func (p *AzureDevOpsProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
+	wi := &azureWorkItem{}
+	query := url.Values{}
+	query.Set("$expand", "links")
+	err := p.get(p.apiURL(org, "wit/workitems", strconv.Itoa(number)), query, wi)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get work item %d in Azure DevOps organisation %s", number, org)
+	}
+	return p.toIssue(org, name, wi), nil
+}
+
+func (p *AzureDevOpsProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if isPull {
+		repo, err := p.findRepository(org, name)
+		if err == nil && repo != nil {
+			return p.pullRequestURL(repo, number)
+		}
+		return util.UrlJoin(p.Server.URL, org, "_git", name, "pullrequest", strconv.Itoa(number))
+	}
+	return util.UrlJoin(p.Server.URL, org, "_workitems/edit", strconv.Itoa(number))
+}
+
+// queryWorkItems returns the work items of the project of the repository matching the WIQL condition
+func (p *AzureDevOpsProvider) queryWorkItems(org string, name string, condition string) ([]*GitIssue, error) {
+	answer := []*GitIssue{}
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return answer, err
+	}
+	project := ""
+	if repo.Project != nil {
+		project = repo.Project.Name
+	}
+	wiql := fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = '%s'", strings.Replace(project, "'", "''", -1))
+	if condition != "" {
+		wiql += " AND " + condition
+	}
+	wiql += " ORDER BY [System.ChangedDate] DESC"
+	query := url.Values{}
+	query.Set("timePrecision", "true")
+	result := struct {
+		WorkItems []azureWorkItem `json:"workItems"`
+	}{}
+	err = p.do(http.MethodPost, p.apiURL(org, "wit/wiql"), query, map[string]string{"query": wiql}, "", &result)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to query the work items of project %s in Azure DevOps organisation %s", project, org)
+	}
+	if len(result.WorkItems) == 0 {
+		return answer, nil
+	}
+	// the query only returns the IDs of the work items which are then got in batches
+	for start := 0; start < len(result.WorkItems); start += azureWorkItemsBatchSize {
+		end := start + azureWorkItemsBatchSize
+		if end > len(result.WorkItems) {
+			end = len(result.WorkItems)
+		}
+		ids := []string{}
+		for _, wi := range result.WorkItems[start:end] {
+			ids = append(ids, strconv.Itoa(wi.ID))
+		}
+		query = url.Values{}
+		query.Set("ids", strings.Join(ids, ","))
+		query.Set("$expand", "links")
+		workItems := struct {
+			Value []azureWorkItem `json:"value"`
+		}{}
+		err = p.get(p.apiURL(org, "wit/workitems"), query, &workItems)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to get the work items of project %s in Azure DevOps organisation %s", project, org)
+		}
+		for i := range workItems.Value {
+			answer = append(answer, p.toIssue(org, name, &workItems.Value[i]))
+		}
+	}
+	return answer, nil
+}
+
+func azureClosedStatesList() string {
+	states := []string{}
+	for _, s := range azureClosedWorkItemStates {
+		states = append(states, "'"+s+"'")
+	}
+	return "(" + strings.Join(states, ", ") + ")"
+}
+
+// SearchIssues returns the work items of the project of the repository in the state which is open, closed or blank
+// for all work items
+func (p *AzureDevOpsProvider) SearchIssues(org string, name string, state string) ([]*GitIssue, error) {
+	condition := ""
+	switch state {
+	case "open":
+		condition = "[System.State] NOT IN " + azureClosedStatesList()
+	case "closed":
+		condition = "[System.State] IN " + azureClosedStatesList()
+	}
+	return p.queryWorkItems(org, name, condition)
+}
+
+func (p *AzureDevOpsProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
+	condition := fmt.Sprintf("[System.State] IN %s AND [Microsoft.VSTS.Common.ClosedDate] >= '%s'", azureClosedStatesList(), t.UTC().Format(time.RFC3339))
+	return p.queryWorkItems(org, name, condition)
+}
+
+// CreateIssue creates a work item in the project of the repository
+func (p *AzureDevOpsProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if r.Project == nil {
+		return nil, fmt.Errorf("repository %s/%s has no project", owner, repo)
+	}
+	ops := []azurePatchOperation{
+		{Op: "add", Path: "/fields/System.Title", Value: issue.Title},
+	}
+	if issue.Body != "" {
+		ops = append(ops, azurePatchOperation{Op: "add", Path: "/fields/System.Description", Value: issue.Body})
+	}
+	tags := []string{}
+	for _, label := range issue.Labels {
+		tags = append(tags, label.Name)
+	}
+	if len(tags) > 0 {
+		ops = append(ops, azurePatchOperation{Op: "add", Path: "/fields/System.Tags", Value: strings.Join(tags, "; ")})
+	}
+	wi := &azureWorkItem{}
+	err = p.do(http.MethodPost, p.apiURL(util.UrlJoin(owner, r.Project.Name), "wit/workitems", "$"+azureWorkItemType), nil, ops, "application/json-patch+json", wi)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a work item in project %s of Azure DevOps organisation %s", r.Project.Name, owner)
+	}
+	return p.toIssue(owner, repo, wi), nil
+}
+
+func (p *AzureDevOpsProvider) HasIssues() bool {
+	return true
+}
+
+func (p *AzureDevOpsProvider) AddPRComment(pr *GitPullRequest, comment string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	repo, err := p.getRepository(pr.Owner, pr.Repo)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{
+				"parentCommentId": 0,
+				"content":         comment,
+				"commentType":     1,
+			},
+		},
+		"status": 1,
+	}
+	err = p.do(http.MethodPost, p.apiURL(pr.Owner, "git/repositories", repo.ID, "pullRequests", strconv.Itoa(*pr.Number), "threads"), nil, body, "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to comment on pull request %d on %s/%s", *pr.Number, pr.Owner, pr.Repo)
+	}
+	return nil
+}
+
+// CreateIssueComment comments on a work item
+func (p *AzureDevOpsProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return err
+	}
+	if r.Project == nil {
+		return fmt.Errorf("repository %s/%s has no project", owner, repo)
+	}
+	query := url.Values{}
+	query.Set("api-version", azureCommentsAPIVersion)
+	err = p.do(http.MethodPost, p.apiURL(util.UrlJoin(owner, r.Project.Name), "wit/workItems", strconv.Itoa(number), "comments"), query, map[string]string{"text": comment}, "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to comment on work item %d in Azure DevOps organisation %s", number, owner)
+	}
+	return nil
+}
+
+// ListPullRequestComments lists the comments of the threads of a pull request ignoring those made by the system.
+// Comment IDs are only unique within a thread so the thread ID is in the upper bits of the ID. The API returns every
+// thread at once rather than paging them
+func (p *AzureDevOpsProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	answer := []*GitPullRequestComment{}
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return answer, err
+	}
+	result := struct {
+		Value []azureThread `json:"value"`
+	}{}
+	err = p.get(p.apiURL(owner, "git/repositories", r.ID, "pullRequests", strconv.Itoa(number), "threads"), nil, &result)
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list the comments of pull request %d on %s/%s", number, owner, repo)
+	}
+	for _, thread := range result.Value {
+		if thread.IsDeleted {
+			continue
+		}
+		for _, c := range thread.Comments {
+			if c.IsDeleted || c.CommentType == "system" {
+				continue
+			}
+			comment := &GitPullRequestComment{
+				ID:        thread.ID<<32 | c.ID,
+				Body:      c.Content,
+				CreatedAt: c.PublishedDate,
+			}
+			if c.Author != nil {
+				comment.User = toAzureUser(c.Author)
+			}
+			answer = append(answer, comment)
+		}
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) listRefs(org string, repo *azureRepository, filter string) ([]azureRef, error) {
+	query := url.Values{}
+	query.Set("filter", filter)
+	query.Set("peelTags", "true")
+	answer := []azureRef{}
+	err := p.getPages(p.apiURL(org, "git/repositories", repo.ID, "refs"), query, "$top", "", func(data []byte) (int, error) {
+		result := struct {
+			Value []azureRef `json:"value"`
+		}{}
+		err := json.Unmarshal(data, &result)
+		answer = append(answer, result.Value...)
+		return len(result.Value), err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the refs of %s/%s", org, repo.Name)
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) toRelease(repo *azureRepository, ref *azureRef) *GitRelease {
+	tag := strings.TrimPrefix(ref.Name, "refs/tags/")
+	u := repo.WebURL + "?version=GT" + url.QueryEscape(tag)
+	return &GitRelease{
+		Name:    tag,
+		TagName: tag,
+		URL:     u,
+		HTMLURL: u,
+	}
+}
+
+// UpdateRelease creates an annotated tag of the TargetCommitish of the release with the body of the release as its
+// message if the tag does not exist. Azure DevOps has no releases so they are represented by tags
+func (p *AzureDevOpsProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return err
+	}
+	refs, err := p.listRefs(owner, r, "tags/"+tag)
+	if err != nil {
+		return err
+	}
+	for i := range refs {
+		if refs[i].Name == "refs/tags/"+tag {
+			release := p.toRelease(r, &refs[i])
+			releaseInfo.URL = release.URL
+			releaseInfo.HTMLURL = release.HTMLURL
+			log.Logger().Debugf("Tag %s already exists on %s/%s", tag, owner, repo)
+			return nil
+		}
+	}
+	objectID, err := p.resolveCommit(owner, r, releaseInfo.TargetCommitish)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the commit of release %s of %s/%s to tag", tag, owner, repo)
+	}
+	message := releaseInfo.Body
+	if message == "" {
+		message = releaseInfo.Name
+	}
+	if message == "" {
+		message = tag
+	}
+	body := &azureAnnotatedTag{
+		Name:         tag,
+		Message:      message,
+		TaggedObject: &azureTaggedObj{ObjectID: objectID},
+	}
+	err = p.do(http.MethodPost, p.apiURL(owner, "git/repositories", r.ID, "annotatedtags"), nil, body, "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create tag %s on %s/%s", tag, owner, repo)
+	}
+	release := p.toRelease(r, &azureRef{Name: "refs/tags/" + tag})
+	releaseInfo.URL = release.URL
+	releaseInfo.HTMLURL = release.HTMLURL
+	return nil
+}
+
+// resolveCommit returns the SHA of the commit which is either a SHA or the name of a branch or tag
+func (p *AzureDevOpsProvider) resolveCommit(org string, repo *azureRepository, commitish string) (string, error) {
+	if commitish == "" {
+		return "", errors.New("missing the commit of the release")
+	}
+	if azureCommitSHA.MatchString(commitish) {
+		return commitish, nil
+	}
+	name := strings.TrimPrefix(commitish, "refs/")
+	candidates := []string{name}
+	if !strings.HasPrefix(name, "heads/") && !strings.HasPrefix(name, "tags/") {
+		candidates = []string{"heads/" + name, "tags/" + name}
+	}
+	for _, candidate := range candidates {
+		refs, err := p.listRefs(org, repo, candidate)
+		if err != nil {
+			return "", err
+		}
+		for _, ref := range refs {
+			if ref.Name != "refs/"+candidate {
+				continue
+			}
+			if ref.PeeledObjectID != "" {
+				return ref.PeeledObjectID, nil
+			}
+			return ref.ObjectID, nil
+		}
+	}
+	return "", fmt.Errorf("could not find %s", commitish)
+}
+
+// UpdateReleaseStatus does nothing as tags have no pre-release status
+func (p *AzureDevOpsProvider) UpdateReleaseStatus(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	return nil
+}
+
+// ListReleases lists the tags of the repository
+func (p *AzureDevOpsProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
+	answer := []*GitRelease{}
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return answer, err
+	}
+	refs, err := p.listRefs(org, repo, "tags/")
+	if err != nil {
+		return answer, err
+	}
+	for i := range refs {
+		if strings.HasPrefix(refs[i].Name, "refs/tags/") {
+			answer = append(answer, p.toRelease(repo, &refs[i]))
+		}
+	}
+	return answer, nil
+}
+
+// GetRelease returns the tag with the message of annotated tags as the body or nil if there is no such tag
+func (p *AzureDevOpsProvider) GetRelease(org string, name string, tag string) (*GitRelease, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := p.listRefs(org, repo, "tags/"+tag)
+	if err != nil {
+		return nil, err
+	}
+	for i := range refs {
+		ref := &refs[i]
+		if ref.Name != "refs/tags/"+tag {
+			continue
+		}
+		release := p.toRelease(repo, ref)
+		if ref.PeeledObjectID != "" {
+			annotated := &azureAnnotatedTag{}
+			err = p.get(p.apiURL(org, "git/repositories", repo.ID, "annotatedtags", ref.ObjectID), nil, annotated)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get tag %s of %s/%s", tag, org, name)
+			}
+			release.Body = annotated.Message
+		}
+		return release, nil
+	}
+	return nil, nil
+}
+
+func (p *AzureDevOpsProvider) UploadReleaseAsset(org string, repo string, id int64, name string, asset *os.File) (*GitReleaseAsset, error) {
+	return nil, fmt.Errorf("uploading release assets is not supported on Azure DevOps")
+}
+
+// GetLatestRelease returns the tag with the highest semantic version
+func (p *AzureDevOpsProvider) GetLatestRelease(org string, name string) (*GitRelease, error) {
+	releases, err := p.ListReleases(org, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting releases for %s/%s", org, name)
+	}
+	var answer *GitRelease
+	var latest *semver.Version
+	for _, release := range releases {
+		v, err := semver.NewVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+			answer = release
+		}
+	}
+	if answer == nil {
+		return nil, fmt.Errorf("no releases found for %s/%s", org, name)
+	}
+	return p.GetRelease(org, name, answer.TagName)
+}
+
+func (p *AzureDevOpsProvider) GetContent(org string, name string, path string, ref string) (*GitFileContent, error) {
+	repo, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("includeContent", "true")
+	query.Set("$format", "json")
+	if ref != "" {
+		query.Set("versionDescriptor.version", ref)
+		query.Set("versionDescriptor.versionType", azureVersionType(ref))
+	}
+	item := &azureItem{}
+	err = p.get(p.apiURL(org, "git/repositories", repo.ID, "items"), query, item)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s from %s/%s", path, org, name)
+	}
+	return &GitFileContent{
+		Type:    "file",
+		Name:    filepath.Base(item.Path),
+		Path:    strings.TrimPrefix(item.Path, "/"),
+		Content: item.Content,
+		Size:    len(item.Content),
+		Sha:     item.ObjectID,
+		Url:     item.URL,
+	}, nil
+}
+
+// JenkinsWebHookPath returns the path of the git plugin notify commit endpoint as Jenkins has no Azure DevOps webhook
+func (p *AzureDevOpsProvider) JenkinsWebHookPath(gitURL string, secret string) string {
+	return "/git/notifyCommit?url=" + url.QueryEscape(gitURL)
+}
+
+func (p *AzureDevOpsProvider) Label() string {
+	return p.Server.Label()
+}
+
+func (p *AzureDevOpsProvider) ServerURL() string {
+	return p.Server.URL
+}
+
+func (p *AzureDevOpsProvider) BranchArchiveURL(org string, name string, branch string) string {
+	repo, err := p.findRepository(org, name)
+	if err != nil || repo == nil {
+		return ""
+	}
+	query := url.Values{}
+	query.Set("path", "/")
+	query.Set("versionDescriptor.version", branch)
+	query.Set("versionDescriptor.versionType", "branch")
+	query.Set("$format", "zip")
+	query.Set("download", "true")
+	query.Set("api-version", azureAPIVersion)
+	return p.apiURL(org, "git/repositories", repo.ID, "items") + "?" + query.Encode()
+}
+
+func (p *AzureDevOpsProvider) CurrentUsername() string {
+	return p.Username
+}
+
+func (p *AzureDevOpsProvider) UserAuth() auth.UserAuth {
+	return p.User
+}
+
+// UserInfo returns the profile of the current user. Other users are only known by their login
+func (p *AzureDevOpsProvider) UserInfo(username string) *GitUser {
+	user := &GitUser{
+		Login: username,
+	}
+	if username != p.Username {
+		return user
+	}
+	profile, err := p.profile()
+	if err != nil {
+		log.Logger().Debugf("%s", err)
+		return user
+	}
+	user.Name = profile.DisplayName
+	user.Email = profile.EmailAddress
+	return user
+}
+
+func (p *AzureDevOpsProvider) AddCollaborator(user string, organisation string, repo string) error {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps. Please add user: %v as a contributor to the project of this repository.", user)
+	return nil
+}
+
+func (p *AzureDevOpsProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.")
+	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+}
+
+func (p *AzureDevOpsProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.")
+	return &github.Response{}, nil
+}
+
+// ShouldForkForPullRequest returns false as Azure DevOps pull requests are created from branches of the repository
+// and forks are only supported within an organisation
+func (p *AzureDevOpsProvider) ShouldForkForPullRequest(originalOwner string, repoName string, username string) bool {
+	return false
+}
+
+// GetBranch returns the branch information for an owner/repo, including the commit at the tip
+func (p *AzureDevOpsProvider) GetBranch(owner string, repo string, branch string) (*GitBranch, error) {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := p.listRefs(owner, r, "heads/"+branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if ref.Name == "refs/heads/"+branch {
+			return &GitBranch{
+				Name: branch,
+				Commit: &GitCommit{
+					SHA:    ref.ObjectID,
+					Branch: branch,
+				},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetProjects returns nil as Azure Boards are not used as git projects
+func (p *AzureDevOpsProvider) GetProjects(owner string, repo string) ([]GitProject, error) {
+	return nil, nil
+}
+
+// IsWikiEnabled returns true if the project of owner/repo has a wiki
+func (p *AzureDevOpsProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
+	r, err := p.getRepository(owner, repo)
+	if err != nil {
+		return false, err
+	}
+	if r.Project == nil {
+		return false, nil
+	}
+	result := struct {
+		Value []interface{} `json:"value"`
+	}{}
+	err = p.get(p.apiURL(util.UrlJoin(owner, r.Project.Name), "wiki/wikis"), nil, &result)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list the wikis of project %s", r.Project.Name)
+	}
+	return len(result.Value) > 0, nil
+}
+
+// ConfigureFeatures does nothing as issues, projects and wikis are configured on the Azure DevOps project rather than
+// the repository
+func (p *AzureDevOpsProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
+	log.Logger().Infof("Configuring features is not supported on Azure DevOps repositories. Please configure the project of %s/%s instead.", owner, repo)
+	return p.GetRepository(owner, repo)
+}
@@ -0,0 +1,441 @@
+package gits_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	azureRepoPath = "/test-org/_apis/git/repositories/5febef5a-833d-4e14-b9c0-14cb638f91e6"
+	azureSHA      = "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c"
+)
+
+type AzureDevOpsProviderTestSuite struct {
+	suite.Suite
+	mux           *http.ServeMux
+	server        *httptest.Server
+	provider      *gits.AzureDevOpsProvider
+	subscriptions []map[string]interface{}
+	tags          []map[string]interface{}
+}
+
+var azureDevOpsRouter = util.Router{
+	"/test-org/_apis/projects": util.MethodMap{
+		"GET": "projects.json",
+	},
+	"/test-org/_apis/git/repositories": util.MethodMap{
+		"GET":  "repos.json",
+		"POST": "repo.json",
+	},
+	"/test-org/test-project/_apis/git/repositories": util.MethodMap{
+		"GET": "repos.json",
+	},
+	"/duplicate-org/_apis/git/repositories": util.MethodMap{
+		"GET": "repos-duplicate.json",
+	},
+	azureRepoPath: util.MethodMap{
+		"DELETE": "empty.json",
+	},
+	azureRepoPath + "/pullrequests": util.MethodMap{
+		"GET":  "prs.json",
+		"POST": "pr.json",
+	},
+	azureRepoPath + "/pullrequests/1": util.MethodMap{
+		"GET":   "pr.json",
+		"PATCH": "pr-completed.json",
+	},
+	azureRepoPath + "/pullRequests/1/threads": util.MethodMap{
+		"GET":  "threads.json",
+		"POST": "empty.json",
+	},
+	azureRepoPath + "/commits": util.MethodMap{
+		"GET": "commits.json",
+	},
+	azureRepoPath + "/commits/" + azureSHA + "/statuses": util.MethodMap{
+		"GET":  "statuses.json",
+		"POST": "status.json",
+	},
+	azureRepoPath + "/annotatedtags/8e4b1c9a0d7f6e5d4c3b2a1908f7e6d5c4b3a291": util.MethodMap{
+		"GET": "annotated-tag.json",
+	},
+	azureRepoPath + "/items": util.MethodMap{
+		"GET": "item.json",
+	},
+	"/test-org/_apis/wit/wiql": util.MethodMap{
+		"POST": "wiql.json",
+	},
+	"/test-org/_apis/wit/workitems": util.MethodMap{
+		"GET": "workitems.json",
+	},
+	"/test-org/_apis/wit/workitems/1": util.MethodMap{
+		"GET": "workitem.json",
+	},
+	"/test-org/test-project/_apis/wit/workitems/$Issue": util.MethodMap{
+		"POST": "workitem.json",
+	},
+}
+
+func (suite *AzureDevOpsProviderTestSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+
+	for path, methodMap := range azureDevOpsRouter {
+		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/azuredevops", methodMap))
+	}
+	subscriptions := util.GetMockAPIResponseFromFile("test_data/azuredevops", util.MethodMap{
+		"GET":  "subscriptions.json",
+		"POST": "empty.json",
+	})
+	suite.mux.HandleFunc("/test-org/_apis/hooks/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			data, err := ioutil.ReadAll(r.Body)
+			suite.Require().NoError(err)
+			subscription := map[string]interface{}{}
+			suite.Require().NoError(json.Unmarshal(data, &subscription))
+			suite.subscriptions = append(suite.subscriptions, subscription)
+		}
+		subscriptions(w, r)
+	})
+	refs := util.GetMockAPIResponseFromFile("test_data/azuredevops", util.MethodMap{
+		"GET": "refs.json",
+	})
+	refsPage2 := util.GetMockAPIResponseFromFile("test_data/azuredevops", util.MethodMap{
+		"GET": "refs-page2.json",
+	})
+	suite.mux.HandleFunc(azureRepoPath+"/refs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Header().Set("x-ms-continuationtoken", "page2")
+			refs(w, r)
+			return
+		}
+		refsPage2(w, r)
+	})
+	suite.mux.HandleFunc(azureRepoPath+"/annotatedtags", func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		suite.Require().NoError(err)
+		tag := map[string]interface{}{}
+		suite.Require().NoError(json.Unmarshal(data, &tag))
+		suite.tags = append(suite.tags, tag)
+		w.Write([]byte("{}"))
+	})
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	as := auth.AuthServer{
+		URL:         suite.server.URL,
+		Name:        "Test Azure DevOps",
+		Kind:        gits.KindAzureDevOps,
+		CurrentUser: "test-user",
+	}
+	ua := auth.UserAuth{
+		Username: "test-user",
+		ApiToken: "0123456789abdef",
+	}
+
+	git := gits.NewGitCLI()
+	p, err := gits.NewAzureDevOpsProvider(&as, &ua, git)
+	suite.Require().NotNil(p)
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = p.(*gits.AzureDevOpsProvider)
+	suite.Require().True(ok)
+	suite.provider.ProfileURL = suite.server.URL
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetRepository() {
+	repo, err := suite.provider.GetRepository("test-org", "test-repo")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Equal("test-project", repo.Project)
+	suite.Equal("https://test-org@dev.azure.com/test-org/test-project/_git/test-repo", repo.CloneURL)
+	suite.True(repo.Private)
+
+	repo, err = suite.provider.GetRepository("test-org", "test-project/test-repo")
+	suite.Require().Nil(err)
+	suite.Equal("test-repo", repo.Name)
+
+	_, err = suite.provider.GetRepository("test-org", "other-project/test-repo")
+	suite.Require().Error(err)
+
+	_, err = suite.provider.GetRepository("duplicate-org", "test-repo")
+	suite.Require().Error(err, "repositories with the same name in different projects")
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListRepositories() {
+	repos, err := suite.provider.ListRepositories("test-org")
+	suite.Require().Nil(err)
+	suite.Len(repos, 2)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreateRepository() {
+	repo, err := suite.provider.CreateRepository("test-org", "new-repo", true)
+	suite.Require().Nil(err)
+	suite.Equal("new-repo", repo.Name)
+
+	_, err = suite.provider.CreateRepository("test-org", "other-project/new-repo", true)
+	suite.Require().Error(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestValidateRepositoryName() {
+	err := suite.provider.ValidateRepositoryName("test-org", "test-repo")
+	suite.Require().Error(err)
+
+	err = suite.provider.ValidateRepositoryName("test-org", "new-repo")
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestDeleteRepository() {
+	err := suite.provider.DeleteRepository("test-org", "test-repo")
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreatePullRequest() {
+	pr, err := suite.provider.CreatePullRequest(&gits.GitPullRequestArguments{
+		GitRepository: &gits.GitRepository{Organisation: "test-org", Name: "test-repo"},
+		Title:         "Update dependencies",
+		Head:          "feature",
+		Base:          "master",
+	})
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Equal(1, *pr.Number)
+	suite.Equal("open", *pr.State)
+	suite.Equal(azureSHA, pr.LastCommitSha)
+	suite.Equal("feature", *pr.HeadRef)
+	suite.Equal("https://dev.azure.com/test-org/test-project/_git/test-repo/pullrequest/1", pr.URL)
+	suite.Require().Len(pr.Labels, 1)
+	suite.Equal("updatebot", *pr.Labels[0].Name)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListOpenPullRequests() {
+	prs, err := suite.provider.ListOpenPullRequests("test-org", "test-repo")
+	suite.Require().Nil(err)
+	suite.Require().Len(prs, 1)
+	suite.Equal("test-user@example.com", prs[0].Author.Login)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestMergePullRequest() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Owner:  "test-org",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.MergePullRequest(pr, "Merged")
+	suite.Require().Nil(err)
+
+	err = suite.provider.UpdatePullRequestStatus(pr)
+	suite.Require().Nil(err)
+	suite.False(*pr.Merged)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListPullRequestComments() {
+	comments, err := suite.provider.ListPullRequestComments("test-org", "test-repo", 1)
+	suite.Require().Nil(err)
+	suite.Require().Len(comments, 1)
+	suite.Equal("/preview wake", comments[0].Body)
+	suite.Equal(int64(2<<32|1), comments[0].ID)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestAddPRComment() {
+	number := 1
+	err := suite.provider.AddPRComment(&gits.GitPullRequest{
+		Owner:  "test-org",
+		Repo:   "test-repo",
+		Number: &number,
+	}, "This is a new comment.")
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCommitStatus() {
+	statuses, err := suite.provider.ListCommitStatus("test-org", "test-repo", azureSHA)
+	suite.Require().Nil(err)
+	suite.Require().Len(statuses, 2)
+	suite.Equal("jx/unit", statuses[0].Context)
+	suite.Equal("success", statuses[0].State)
+
+	number := 1
+	state, err := suite.provider.PullRequestLastCommitStatus(&gits.GitPullRequest{
+		Owner:         "test-org",
+		Repo:          "test-repo",
+		Number:        &number,
+		LastCommitSha: azureSHA,
+	})
+	suite.Require().Nil(err)
+	suite.Equal("success", state)
+
+	status, err := suite.provider.UpdateCommitStatus("test-org", "test-repo", azureSHA, &gits.GitRepoStatus{
+		Context: "jx/unit",
+		State:   "failure",
+	})
+	suite.Require().Nil(err)
+	suite.Equal("failure", status.State)
+	suite.Equal("jx/unit", status.Context)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListCommits() {
+	commits, err := suite.provider.ListCommits("test-org", "test-repo", &gits.ListCommitsArguments{
+		SHA:     "master",
+		Path:    "env/requirements.yaml",
+		PerPage: 10,
+	})
+	suite.Require().Nil(err)
+	suite.Require().Len(commits, 1)
+	suite.Equal(azureSHA, commits[0].SHA)
+	suite.Equal("test-user@example.com", commits[0].Author.Email)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetContent() {
+	content, err := suite.provider.GetContent("test-org", "test-repo", "env/requirements.yaml", "master")
+	suite.Require().Nil(err)
+	suite.Equal("env/requirements.yaml", content.Path)
+	suite.Equal("requirements.yaml", content.Name)
+	suite.Contains(content.Content, "name: myapp")
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestWebHooks() {
+	hooks, err := suite.provider.ListWebHooks("test-org", "test-repo")
+	suite.Require().Nil(err)
+	suite.Require().Len(hooks, 1)
+	suite.Equal("http://hook.jx.example.com/hook", hooks[0].URL)
+	suite.Empty(hooks[0].Secret, "masked secret")
+
+	suite.subscriptions = nil
+	err = suite.provider.CreateWebHook(&gits.GitWebHookArguments{
+		Repo: &gits.GitRepository{Organisation: "test-org", Name: "test-repo"},
+		URL:  "http://hook.jx.example.com/hook",
+	})
+	suite.Require().Nil(err)
+	suite.Empty(suite.subscriptions, "existing webhook")
+
+	err = suite.provider.CreateWebHook(&gits.GitWebHookArguments{
+		Repo:   &gits.GitRepository{Organisation: "test-org", Project: "test-project", Name: "test-repo"},
+		URL:    "http://lighthouse.jx.example.com/hook",
+		Secret: "secret",
+	})
+	suite.Require().Error(err, "unsigned webhook with a secret")
+	suite.Empty(suite.subscriptions)
+
+	err = suite.provider.CreateWebHook(&gits.GitWebHookArguments{
+		Repo: &gits.GitRepository{Organisation: "test-org", Project: "test-project", Name: "test-repo"},
+		URL:  "http://lighthouse.jx.example.com/hook",
+	})
+	suite.Require().Nil(err)
+	suite.Require().Len(suite.subscriptions, 5)
+	subscription := suite.subscriptions[0]
+	suite.Equal("git.push", subscription["eventType"])
+	suite.Equal(map[string]interface{}{
+		"projectId":  "eb6e4656-77fc-42a1-9181-4c6d8e9da5d1",
+		"repository": "5febef5a-833d-4e14-b9c0-14cb638f91e6",
+	}, subscription["publisherInputs"])
+	suite.Equal(map[string]interface{}{
+		"url": "http://lighthouse.jx.example.com/hook",
+	}, subscription["consumerInputs"])
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestIssues() {
+	issue, err := suite.provider.GetIssue("test-org", "test-repo", 1)
+	suite.Require().Nil(err)
+	suite.Equal("Broken build", issue.Title)
+	suite.Equal("open", *issue.State)
+	suite.Equal("https://dev.azure.com/test-org/test-project/_workitems/edit/1", issue.URL)
+	suite.Equal("test-user@example.com", issue.User.Login)
+	suite.Equal([]gits.GitLabel{{Name: "bug"}, {Name: "ci"}}, issue.Labels)
+
+	issues, err := suite.provider.SearchIssues("test-org", "test-repo", "open")
+	suite.Require().Nil(err)
+	suite.Len(issues, 1)
+
+	issues, err = suite.provider.SearchIssuesClosedSince("test-org", "test-repo", time.Now().Add(-time.Hour))
+	suite.Require().Nil(err)
+	suite.Len(issues, 1)
+
+	issue, err = suite.provider.CreateIssue("test-org", "test-repo", &gits.GitIssue{
+		Title:  "Broken build",
+		Labels: []gits.GitLabel{{Name: "bug"}},
+	})
+	suite.Require().Nil(err)
+	suite.Equal(1, *issue.Number)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestReleases() {
+	releases, err := suite.provider.ListReleases("test-org", "test-repo")
+	suite.Require().Nil(err)
+	suite.Require().Len(releases, 3, "tags on both pages")
+	suite.Equal("v1.0.0", releases[0].TagName)
+	suite.Equal("v0.9.0", releases[2].TagName)
+
+	release, err := suite.provider.GetRelease("test-org", "test-repo", "v1.0.0")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(release)
+	suite.Equal("Release 1.0.0", release.Body)
+
+	release, err = suite.provider.GetLatestRelease("test-org", "test-repo")
+	suite.Require().Nil(err)
+	suite.Equal("v1.1.0", release.TagName)
+	suite.Equal("", release.Body)
+
+	release, err = suite.provider.GetRelease("test-org", "test-repo", "v2.0.0")
+	suite.Require().Nil(err)
+	suite.Nil(release)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestUpdateRelease() {
+	suite.tags = nil
+	release := &gits.GitRelease{
+		Name:            "2.0.0",
+		TagName:         "v2.0.0",
+		Body:            "Release 2.0.0",
+		TargetCommitish: azureSHA,
+	}
+	err := suite.provider.UpdateRelease("test-org", "test-repo", "v2.0.0", release)
+	suite.Require().Nil(err)
+	suite.Equal("https://dev.azure.com/test-org/test-project/_git/test-repo?version=GTv2.0.0", release.HTMLURL)
+	suite.Require().Len(suite.tags, 1)
+	suite.Equal("v2.0.0", suite.tags[0]["name"])
+	suite.Equal(map[string]interface{}{"objectId": azureSHA}, suite.tags[0]["taggedObject"])
+
+	suite.tags = nil
+	err = suite.provider.UpdateRelease("test-org", "test-repo", "v2.0.0", &gits.GitRelease{TagName: "v2.0.0", TargetCommitish: "v1.0.0"})
+	suite.Require().Nil(err)
+	suite.Require().Len(suite.tags, 1)
+	suite.Equal(map[string]interface{}{"objectId": azureSHA}, suite.tags[0]["taggedObject"], "peeled commit of the tag")
+
+	suite.tags = nil
+	err = suite.provider.UpdateRelease("test-org", "test-repo", "v2.0.0", &gits.GitRelease{TagName: "v2.0.0"})
+	suite.Require().Error(err)
+	suite.Empty(suite.tags)
+
+	err = suite.provider.UpdateRelease("test-org", "test-repo", "v1.0.0", &gits.GitRelease{TagName: "v1.0.0"})
+	suite.Require().Nil(err, "existing tag")
+	suite.Empty(suite.tags)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetBranch() {
+	branch, err := suite.provider.GetBranch("test-org", "test-repo", "master")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(branch)
+	suite.Equal("f5c3e1bd2c6f0b7d3b8e3a1b1e6f4c7c1a8e9d2b", branch.Commit.SHA)
+}
+
+func TestAzureDevOpsProviderTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestAzureDevOpsProviderTestSuite in short mode")
+	} else {
+		suite.Run(t, new(AzureDevOpsProviderTestSuite))
+	}
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TearDownSuite() {
+	suite.server.Close()
+}
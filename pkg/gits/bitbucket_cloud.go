@@ -926,6 +926,11 @@ func (b *BitbucketCloudProvider) CreateIssueComment(owner string, repo string, n
 	return err
 }
 
+// ListPullRequestComments is not supported for this git provider
+func (b *BitbucketCloudProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	return nil, fmt.Errorf("Listing pull request comments not supported on bitbucket")
+}
+
 func (b *BitbucketCloudProvider) HasIssues() bool {
 	return true
 }
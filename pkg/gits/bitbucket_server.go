@@ -734,14 +734,55 @@ func (b *BitbucketServerProvider) ListCommitStatus(org, repo, sha string) ([]*Gi
 	return statuses, nil
 }
 
+// buildStatusStateMap maps the commit status states to the Bitbucket Server build status states
+var buildStatusStateMap = map[string]string{
+	"success": "SUCCESSFUL",
+	"failure": "FAILED",
+	"error":   "FAILED",
+	"pending": "INPROGRESS",
+}
+
 func (b *BitbucketServerProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+	state, ok := buildStatusStateMap[status.State]
+	if !ok {
+		return &GitRepoStatus{}, fmt.Errorf("unsupported commit status state %s", status.State)
+	}
+	// the build status API requires a URL so lets default to the server
+	targetURL := status.TargetURL
+	if targetURL == "" {
+		targetURL = b.Server.URL
+	}
+	var options = map[string]interface{}{
+		"state":       state,
+		"key":         status.Context,
+		"name":        status.Context,
+		"url":         targetURL,
+		"description": status.Description,
+	}
+	requestBody, err := json.Marshal(options)
+	if err != nil {
+		return &GitRepoStatus{}, errors.Wrap(err, "failed to JSON encode the build status request body")
+	}
+	statusURL := util.UrlJoin(b.Server.URL, "rest/build-status/1.0/commits", sha)
+	_, err = util.CallWithExponentialBackOff(statusURL, b.Username+":"+b.User.ApiToken, "POST", requestBody, nil)
+	if err != nil {
+		return &GitRepoStatus{}, errors.Wrapf(err, "failed to update the build status %s of commit %s", status.Context, sha)
+	}
+	return &GitRepoStatus{
+		ID:          status.Context,
+		Context:     status.Context,
+		URL:         targetURL,
+		State:       status.State,
+		TargetURL:   targetURL,
+		Description: status.Description,
+	}, nil
 }
 
 func convertBitBucketBuildStatusToGitStatus(buildStatus *bitbucket.BuildStatus) *GitRepoStatus {
 	return &GitRepoStatus{
-		ID:  buildStatus.Key,
-		URL: buildStatus.Url,
+		ID:      buildStatus.Key,
+		Context: buildStatus.Key,
+		URL:     buildStatus.Url,
 		// var from BitBucketCloudProvider
 		State:       stateMap[buildStatus.State],
 		TargetURL:   buildStatus.Url,
@@ -1015,6 +1056,11 @@ func (b *BitbucketServerProvider) CreateIssueComment(owner string, repo string,
 	return nil
 }
 
+// ListPullRequestComments is not supported for this git provider
+func (b *BitbucketServerProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	return nil, fmt.Errorf("Listing pull request comments not supported on bitbucket server")
+}
+
 func (b *BitbucketServerProvider) HasIssues() bool {
 	return true
 }
@@ -1,6 +1,8 @@
 package gits
 
 const (
+	// KindAzureDevOps git kind for Azure DevOps Repos
+	KindAzureDevOps = "azuredevops"
 	// KindBitBucketCloud git kind for BitBucket Cloud
 	KindBitBucketCloud = "bitbucketcloud"
 	// KindBitBucketServer git kind for BitBucket Server
@@ -16,6 +18,9 @@ const (
 	// KindUnknown git kind for unknown git
 	KindUnknown = "unknown"
 
+	// AzureDevOpsURL the default URL for Azure DevOps
+	AzureDevOpsURL = "https://dev.azure.com"
+
 	// BitbucketCloudURL the default URL for BitBucket Cloud
 	BitbucketCloudURL = "https://bitbucket.org"
 
@@ -27,5 +32,5 @@ const (
 )
 
 var (
-	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
+	KindGits = []string{KindAzureDevOps, KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
 )
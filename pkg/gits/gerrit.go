@@ -264,6 +264,11 @@ func (p *GerritProvider) CreateIssueComment(owner string, repo string, number in
 	return nil
 }
 
+// ListPullRequestComments is not supported for this git provider
+func (p *GerritProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	return nil, fmt.Errorf("Listing pull request comments not supported on gerrit")
+}
+
 func (p *GerritProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
 	return nil
 }
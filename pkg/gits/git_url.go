@@ -145,6 +145,15 @@ func ParseGitURL(text string) (*GitRepository, error) {
 		t = strings.TrimSuffix(t, ".git")
 
 		arr := util.RegexpSplit(t, ":|/")
+		// Azure DevOps SSH URLs are of the form git@ssh.dev.azure.com:v3/<org>/<project>/<repo>
+		if len(arr) == 5 && arr[1] == "v3" {
+			answer.Scheme = "git"
+			answer.Host = arr[0]
+			answer.Organisation = arr[2]
+			answer.Project = arr[3]
+			answer.Name = arr[4]
+			return &answer, nil
+		}
 		if len(arr) >= 3 {
 			answer.Scheme = "git"
 			answer.Host = arr[0]
@@ -220,6 +229,13 @@ func parsePath(path string, info *GitRepository, requireRepo bool) (*GitReposito
 		info.Project = arr[0]
 		info.Name = arr[len(arr)-1]
 
+		// Azure DevOps paths are of the form /<org>/<project>/_git/<repo> or /<org>/_git/<repo> when the repository
+		// has the same name as its project
+		if len(arr) == 4 && arr[2] == "_git" {
+			info.Project = arr[1]
+		} else if len(arr) == 3 && arr[1] == "_git" {
+			info.Project = arr[2]
+		}
 		return info, nil
 	} else if len(arr) == 1 && !requireRepo {
 		// We're assuming the beginning of the path is of the form /<org>/<repo>
@@ -245,6 +261,8 @@ func SaasGitKind(gitServiceUrl string) string {
 		return KindBitBucketCloud
 	case BitbucketCloudURL:
 		return KindBitBucketCloud
+	case AzureDevOpsURL:
+		return KindAzureDevOps
 	case "http://fake.git", FakeGitURL:
 		return KindGitFake
 	default:
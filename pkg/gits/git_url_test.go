@@ -122,6 +122,18 @@ func TestParseGitURL(t *testing.T) {
 		{
 			"https://bitbucketserver.com/projects/myproject/repos/foo/pull-requests/1", "bitbucketserver.com", "myproject", "foo",
 		},
+		{
+			"https://myorg@dev.azure.com/myorg/myproject/_git/foo", "dev.azure.com", "myorg", "foo",
+		},
+		{
+			"https://dev.azure.com/myorg/myproject/_git/foo/pullrequest/1", "dev.azure.com", "myorg", "foo",
+		},
+		{
+			"git@ssh.dev.azure.com:v3/myorg/myproject/foo", "ssh.dev.azure.com", "myorg", "foo",
+		},
+		{
+			"https://dev.azure.com/myorg/_git/foo", "dev.azure.com", "myorg", "foo",
+		},
 	}
 	for _, data := range testCases {
 		info, err := gits.ParseGitURL(data.url)
@@ -133,6 +145,20 @@ func TestParseGitURL(t *testing.T) {
 	}
 }
 
+func TestParseAzureDevOpsGitURLProject(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"https://myorg@dev.azure.com/myorg/myproject/_git/foo": "myproject",
+		"https://dev.azure.com/myorg/_git/foo":                 "foo",
+		"git@ssh.dev.azure.com:v3/myorg/myproject/foo":         "myproject",
+	}
+	for gitURL, project := range testCases {
+		info, err := gits.ParseGitURL(gitURL)
+		assert.Nil(t, err)
+		assert.Equal(t, project, info.Project, "Project does not match for input %s", gitURL)
+	}
+}
+
 func TestSaasKind(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
@@ -147,6 +173,10 @@ func TestSaasKind(t *testing.T) {
 			gitURL: "https://github.test.com",
 			kind:   gits.KindGitHub,
 		},
+		"Azure DevOps": {
+			gitURL: "https://dev.azure.com/",
+			kind:   gits.KindAzureDevOps,
+		},
 	}
 
 	for name, tc := range tests {
@@ -1,7 +1,6 @@
 package gits
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -569,6 +568,30 @@ func (p *GiteaProvider) CreateIssueComment(owner string, repo string, number int
 	return nil
 }
 
+// ListPullRequestComments lists the comments on a pull request
+func (p *GiteaProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	comments, err := p.Client.ListIssueComments(owner, repo, int64(number))
+	if err != nil {
+		return nil, err
+	}
+	var answer []*GitPullRequestComment
+	for _, comment := range comments {
+		created := comment.Created
+		c := &GitPullRequestComment{
+			ID:        comment.ID,
+			Body:      comment.Body,
+			CreatedAt: &created,
+		}
+		if comment.Poster != nil {
+			c.User = &GitUser{
+				Login: comment.Poster.UserName,
+			}
+		}
+		answer = append(answer, c)
+	}
+	return answer, nil
+}
+
 func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
 	answer := []*GitRepoStatus{}
 	results, err := p.Client.ListStatuses(org, repo, sha, gitea.ListStatusesOption{})
@@ -589,8 +612,24 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	return answer, nil
 }
 
-func (b *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+func (p *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	result, err := p.Client.CreateStatus(org, repo, sha, gitea.CreateStatusOption{
+		State:       gitea.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+	})
+	if err != nil {
+		return &GitRepoStatus{}, errors2.Wrapf(err, "failed to create the status %s on %s/%s with ref %s", status.Context, org, repo, sha)
+	}
+	return &GitRepoStatus{
+		ID:          strconv.FormatInt(result.ID, 10),
+		Context:     result.Context,
+		URL:         result.URL,
+		TargetURL:   result.TargetURL,
+		State:       string(result.State),
+		Description: result.Description,
+	}, nil
 }
 
 func (p *GiteaProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
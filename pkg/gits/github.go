@@ -890,6 +890,36 @@ func (p *GitHubProvider) CreateIssueComment(owner string, repo string, number in
 	return nil
 }
 
+// ListPullRequestComments lists the comments on the conversation of a pull request
+func (p *GitHubProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	var answer []*GitPullRequestComment
+	options := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			Page:    0,
+			PerPage: pageSize,
+		},
+	}
+	for {
+		comments, resp, err := p.Client.Issues.ListComments(p.Context, owner, repo, number, options)
+		if err != nil {
+			return answer, errors.Wrapf(err, "listing comments of pull request %d of %s/%s", number, owner, repo)
+		}
+		for _, comment := range comments {
+			answer = append(answer, &GitPullRequestComment{
+				ID:        comment.GetID(),
+				Body:      asText(comment.Body),
+				User:      toGitHubUser(comment.User),
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
 func (p *GitHubProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
 	ref := pr.LastCommitSha
 	if ref == "" {
@@ -483,8 +483,13 @@ func (g *GitlabProvider) UpdateCommitStatus(owner string, repo string, sha strin
 	if err != nil {
 		return nil, err
 	}
+	state := status.State
+	// GitLab has no failure or error states
+	if state == "failure" || state == "error" {
+		state = "failed"
+	}
 	statusOptions := &gitlab.SetCommitStatusOptions{
-		State:       gitlab.BuildStateValue(status.State),
+		State:       gitlab.BuildStateValue(state),
 		Name:        &status.Context,
 		Context:     &status.Context,
 		Description: &status.Description,
@@ -713,6 +718,42 @@ func (g *GitlabProvider) CreateIssueComment(owner string, repo string, number in
 	return err
 }
 
+// ListPullRequestComments lists the notes on a merge request
+func (g *GitlabProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	pid, err := g.projectId(owner, g.Username, repo)
+	if err != nil {
+		return nil, err
+	}
+	opt := &gitlab.ListMergeRequestNotesOptions{
+		Page:    1,
+		PerPage: pageSize,
+	}
+	var answer []*GitPullRequestComment
+	for {
+		notes, resp, err := g.Client.Notes.ListMergeRequestNotes(pid, number, opt)
+		if err != nil {
+			return answer, err
+		}
+		for _, note := range notes {
+			answer = append(answer, &GitPullRequestComment{
+				ID:   int64(note.ID),
+				Body: note.Body,
+				User: &GitUser{
+					Login: note.Author.Username,
+					Name:  note.Author.Name,
+					Email: note.Author.Email,
+				},
+				CreatedAt: note.CreatedAt,
+			})
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return answer, nil
+}
+
 func (g *GitlabProvider) HasIssues() bool {
 	return true
 }
@@ -100,6 +100,8 @@ type GitProvider interface {
 
 	CreateIssueComment(owner string, repo string, number int, comment string) error
 
+	ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error)
+
 	UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error
 
 	UpdateReleaseStatus(owner string, repo string, tag string, releaseInfo *GitRelease) error
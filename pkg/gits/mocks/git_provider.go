@@ -702,6 +702,25 @@ func (mock *MockGitProvider) ListOrganisations() ([]gits.GitOrganisation, error)
 	return ret0, ret1
 }
 
+func (mock *MockGitProvider) ListPullRequestComments(_param0 string, _param1 string, _param2 int) ([]*gits.GitPullRequestComment, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ListPullRequestComments", params, []reflect.Type{reflect.TypeOf((*[]*gits.GitPullRequestComment)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []*gits.GitPullRequestComment
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]*gits.GitPullRequestComment)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockGitProvider) ListReleases(_param0 string, _param1 string) ([]*gits.GitRelease, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
@@ -2214,6 +2233,41 @@ func (c *MockGitProvider_ListOrganisations_OngoingVerification) GetCapturedArgum
 func (c *MockGitProvider_ListOrganisations_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierMockGitProvider) ListPullRequestComments(_param0 string, _param1 string, _param2 int) *MockGitProvider_ListPullRequestComments_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListPullRequestComments", params, verifier.timeout)
+	return &MockGitProvider_ListPullRequestComments_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGitProvider_ListPullRequestComments_OngoingVerification struct {
+	mock              *MockGitProvider
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGitProvider_ListPullRequestComments_OngoingVerification) GetCapturedArguments() (string, string, int) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *MockGitProvider_ListPullRequestComments_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []int) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]int, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(int)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGitProvider) ListReleases(_param0 string, _param1 string) *MockGitProvider_ListReleases_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListReleases", params, verifier.timeout)
@@ -48,6 +48,11 @@ type PullRequestOperation struct {
 	AuthorName    string
 	AuthorEmail   string
 	SkipAutoMerge bool
+	// Labels are added to the pull request and used to find an existing pull request to update
+	Labels []string
+	// Title and Message replace the title and body of the pull request generated from the dependency update
+	Title   string
+	Message string
 }
 
 // ChangeFilesFn is the function called to create the pull request
@@ -85,11 +90,18 @@ func (o *PullRequestOperation) CreatePullRequest(kind string, update ChangeFiles
 		if !o.SkipAutoMerge {
 			labels = append(labels, "updatebot")
 		}
+		labels = append(labels, o.Labels...)
 		filter := &gits.PullRequestFilter{
 			Labels: labels,
 		}
 
 		details.Labels = labels
+		if o.Title != "" {
+			details.Title = o.Title
+		}
+		if o.Message != "" {
+			details.Message = o.Message
+		}
 		result, err = gits.PushRepoAndCreatePullRequest(dir, upstreamInfo, forkInfo, o.Base, details, filter, !o.SkipCommit, commitMessage, true, o.DryRun, o.Git(), provider)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create PR for base %s and head branch %s from temp dir %s", o.Base, details.BranchName, dir)
@@ -117,6 +117,14 @@ type GitIssue struct {
 	Assignees     []GitUser
 }
 
+// GitPullRequestComment a comment on a pull request
+type GitPullRequestComment struct {
+	ID        int64
+	Body      string
+	User      *GitUser
+	CreatedAt *time.Time
+}
+
 type GitUser struct {
 	URL       string
 	Login     string
@@ -135,6 +143,8 @@ type GitRelease struct {
 	HTMLURL       string
 	DownloadCount int
 	Assets        *[]GitReleaseAsset
+	// TargetCommitish the commit tagged by providers which create the tag of a release if it does not exist
+	TargetCommitish string
 }
 
 // GitReleaseAsset represents a release stored in Git
@@ -265,7 +275,9 @@ func CreateProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (G
 	if server.Kind == "" {
 		server.Kind = SaasGitKind(server.URL)
 	}
-	if server.Kind == KindBitBucketCloud {
+	if server.Kind == KindAzureDevOps {
+		return NewAzureDevOpsProvider(server, user, git)
+	} else if server.Kind == KindBitBucketCloud {
 		return NewBitbucketCloudProvider(server, user, git)
 	} else if server.Kind == KindBitBucketServer {
 		return NewBitbucketServerProvider(server, user, git)
@@ -298,6 +310,8 @@ func GetHost(gitProvider GitProvider) (string, error) {
 
 func ProviderAccessTokenURL(kind string, url string, username string) string {
 	switch kind {
+	case KindAzureDevOps:
+		return AzureDevOpsAccessTokenURL(url)
 	case KindBitBucketCloud:
 		// TODO pass in the username
 		return BitBucketCloudAccessTokenURL(url, username)
@@ -312,6 +326,12 @@ func ProviderAccessTokenURL(kind string, url string, username string) string {
 	}
 }
 
+// SignsWebHooks returns true if the git provider of the given kind signs the payloads of webhooks with their secret,
+// which Prow and Lighthouse require to validate the webhooks they receive
+func SignsWebHooks(kind string) bool {
+	return kind != KindAzureDevOps
+}
+
 // PickOwner allows to select a potential owner of a repository
 func PickOwner(orgLister OrganisationLister, userName string, handles util.IOFileHandles) (string, error) {
 	msg := "Who should be the owner of the repository?"
@@ -676,6 +676,31 @@ func (f *FakeProvider) CreateIssueComment(owner string, repoName string, number
 	return fmt.Errorf("repository with name '%s' not found", repoName)
 }
 
+func (f *FakeProvider) ListPullRequestComments(owner string, repoName string, number int) ([]*GitPullRequestComment, error) {
+	repos, ok := f.Repositories[owner]
+	if !ok {
+		return nil, fmt.Errorf("no repositories found for '%s'", owner)
+	}
+	for _, r := range repos {
+		if r.GitRepo.Name == repoName {
+			pr, ok := r.PullRequests[number]
+			if !ok {
+				return nil, fmt.Errorf("pull request with id '%d' not found", number)
+			}
+			if pr.Comment == "" {
+				return nil, nil
+			}
+			return []*GitPullRequestComment{
+				{
+					ID:   1,
+					Body: pr.Comment,
+				},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("repository with name '%s' not found", repoName)
+}
+
 func (f *FakeProvider) UpdateRelease(owner string, repoName string, tag string, releaseInfo *GitRelease) error {
 	repos, ok := f.Repositories[owner]
 	if !ok {
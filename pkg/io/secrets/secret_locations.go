@@ -20,6 +20,8 @@ const (
 	FileSystemLocationKind SecretsLocationKind = "local"
 	// VaultLocationKind indicates that secrets location is vault
 	VaultLocationKind SecretsLocationKind = "vault"
+	// SopsLocationKind indicates that secrets location is files encrypted with sops
+	SopsLocationKind SecretsLocationKind = "sops"
 	// KubeLocationKind inidcates that secrets location is in Kuberntes
 	KubeLocationKind SecretsLocationKind = "kube"
 	// AutoLocationKind indicates that secrets location needs to be dynamically determine
@@ -61,6 +63,9 @@ func (s *secretLocation) Location() SecretsLocationKind {
 	if ok && value == string(VaultLocationKind) {
 		return VaultLocationKind
 	}
+	if ok && value == string(SopsLocationKind) {
+		return SopsLocationKind
+	}
 	return s.location
 }
 
@@ -96,6 +101,8 @@ func ToSecretsLocation(location string) SecretsLocationKind {
 		return FileSystemLocationKind
 	case "vault":
 		return VaultLocationKind
+	case "sops":
+		return SopsLocationKind
 	case "kube":
 		return KubeLocationKind
 	default:
@@ -88,6 +88,21 @@ func TestSecretsLocation_UpdateFromJxInstallConfigMap(t *testing.T) {
 	assert.Equal(t, string(VaultLocationKind), configMap.Data[SecretsLocationKey])
 }
 
+func TestSecretsLocation_Sops(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := createMockCluster()
+	secretLocation := NewSecretLocation(kubeClient, ns)
+
+	err := secretLocation.SetLocation(ToSecretsLocation("sops"), true)
+	assert.NoError(t, err)
+
+	configMap, err := kubeClient.Core().ConfigMaps(ns).Get("jx-install-config", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, string(SopsLocationKind), configMap.Data[SecretsLocationKey])
+	assert.Equal(t, SopsLocationKind, NewSecretLocation(kubeClient, ns).Location())
+}
+
 func createMockCluster() *fake.Clientset {
 	namespace := &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -758,6 +758,25 @@ func (k *PromoteStepActivityKey) OnPromoteUpdate(kubeClient kubernetes.Interface
 	return err
 }
 
+// RecordFreezeOverride records on the activity that it released to an environment during one of its freezes
+func (k *PipelineActivityKey) RecordFreezeOverride(jxClient versioned.Interface, ns string, override v1.PromotionFreezeOverride) error {
+	if !k.IsValid() {
+		return nil
+	}
+	a, _, err := k.GetOrCreate(jxClient, ns)
+	if err != nil {
+		return err
+	}
+	if override.Timestamp == nil {
+		override.Timestamp = &metav1.Time{
+			Time: time.Now(),
+		}
+	}
+	a.Spec.FreezeOverrides = append(a.Spec.FreezeOverrides, override)
+	_, err = jxClient.JenkinsV1().PipelineActivities(ns).PatchUpdate(a)
+	return err
+}
+
 // ListSelectedPipelineActivities retrieves the PipelineActivities instances matching the specified label and field selectors. Selectors can be empty or nil.
 func ListSelectedPipelineActivities(activitiesClient typev1.PipelineActivityInterface, labelSelector fmt.Stringer, fieldSelector fields.Selector) (*v1.PipelineActivityList, error) {
 	log.Logger().Debugf("looking for PipelineActivities with label selector %v and field selector %v", labelSelector, fieldSelector)
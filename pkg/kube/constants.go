@@ -76,6 +76,9 @@ const (
 	// SecretKaniko the name of the secret containing the kaniko service account
 	SecretKaniko = "kaniko-secret"
 
+	// SecretSopsKeys the name of the secret containing the keys which decrypt the secrets encrypted with sops
+	SecretSopsKeys = "jx-sops-keys" // #nosec
+
 	// SecretVelero the name of the secret containing the velero service account
 	SecretVelero = "velero-secret" // #nosec
 
@@ -291,6 +294,10 @@ const (
 	// AnnotationReleaseName is the name of the annotation that stores the release name in the preview environment
 	AnnotationReleaseName = "jenkins.io/chart-release"
 
+	// AnnotationPreviewDependencyReleases is the name of the annotation that stores the comma separated release names
+	// of the dependencies deployed into the preview environment
+	AnnotationPreviewDependencyReleases = "jenkins.io/preview-dependency-releases"
+
 	// SecretDataUsername the username in a Secret/Credentials
 	SecretDataUsername = "username"
 
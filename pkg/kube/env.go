@@ -1070,6 +1070,18 @@ func GetPreviewEnvironmentReleaseName(env *v1.Environment) string {
 	return env.Annotations[AnnotationReleaseName]
 }
 
+// GetPreviewDependencyReleaseNames returns the release names of the dependencies deployed into a preview environment
+func GetPreviewDependencyReleaseNames(env *v1.Environment) []string {
+	if !IsPreviewEnvironment(env) {
+		return nil
+	}
+	value := env.Annotations[AnnotationPreviewDependencyReleases]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 // IsPermanentEnvironment indicates if an environment is permanent
 func IsPermanentEnvironment(env *v1.Environment) bool {
 	return env.Spec.Kind == v1.EnvironmentKindTypePermanent
@@ -0,0 +1,66 @@
+package kube
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxPendingLines is the most lines held back waiting for the end of a private key block. A 4096 bit RSA key is
+// around 50 lines so a block which is still open after this many lines is masked and written out
+const maxPendingLines = 200
+
+var (
+	privateKeyBeginPattern = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)
+	privateKeyEndPattern   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+)
+
+// LogLineMasker masks a log which is streamed one line at a time. Lines are masked as they arrive except for
+// private key blocks, which are held back from the BEGIN line until the END line so the whole block can be masked
+type LogLineMasker struct {
+	masker  *LogMasker
+	pending []string
+}
+
+// NewLogLineMasker creates a LogLineMasker which masks the lines with the given masker
+func NewLogLineMasker(masker *LogMasker) *LogLineMasker {
+	return &LogLineMasker{
+		masker: masker,
+	}
+}
+
+// MaskLine returns the masked lines which are ready to be written. No lines are returned while a private key block
+// is open, and all of the lines of the block are returned once it ends
+func (l *LogLineMasker) MaskLine(line string) []string {
+	if len(l.pending) > 0 {
+		l.pending = append(l.pending, line)
+		if privateKeyEndPattern.MatchString(line) || len(l.pending) >= maxPendingLines {
+			return l.Flush()
+		}
+		return nil
+	}
+	begin := privateKeyBeginPattern.FindStringIndex(line)
+	if begin != nil && !privateKeyEndPattern.MatchString(line[begin[1]:]) {
+		l.pending = append(l.pending, line)
+		return nil
+	}
+	return []string{l.masker.MaskLog(line)}
+}
+
+// Flush returns any lines which are held back, masked together. If the private key block has not ended everything
+// after the BEGIN marker is masked, as a partial key is still a secret
+func (l *LogLineMasker) Flush() []string {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	block := strings.Join(l.pending, "\n")
+	text := l.masker.MaskLog(block)
+	if !privateKeyEndPattern.MatchString(block) {
+		// masking keeps the length of the text so the marker is at the same position in the masked text
+		begin := privateKeyBeginPattern.FindStringIndex(block)
+		if begin != nil {
+			text = text[:begin[1]] + maskText(text[begin[1]:])
+		}
+	}
+	l.pending = nil
+	return strings.Split(text, "\n")
+}
@@ -1,21 +1,40 @@
 package kube
 
 import (
+	"encoding/base64"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-// LogMasker replaces words in a log from a set of secrets
+// minEncodedSecretLength is the length of the shortest secret whose encoded variants are masked. The encodings of
+// short values such as "1" or "true" are so short that they would mask parts of ordinary words in the logs
+const minEncodedSecretLength = 6
+
+// LogMasker replaces words in a log from a set of secrets and masks any text matched by its detectors. It can be
+// shared by goroutines masking different logs
 type LogMasker struct {
 	ReplaceWords map[string]string
+	Detectors    []SecretDetector
+
+	lock sync.Mutex
+	// replacements are the keys of ReplaceWords with the longest first, each followed by its replacement, sorted once
+	// rather than for every log line
+	replacements []string
 }
 
 // NewLogMasker creates a new LogMasker loading secrets from the given namespace
 func NewLogMasker(kubeClient kubernetes.Interface, ns string) (*LogMasker, error) {
-	masker := &LogMasker{}
+	masker := &LogMasker{
+		Detectors: DefaultSecretDetectors(),
+	}
 	resourceList, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
 	if err != nil {
 		return masker, err
@@ -30,11 +49,17 @@ func NewLogMasker(kubeClient kubernetes.Interface, ns string) (*LogMasker, error
 func NewLogMaskerFromMap(m map[string]interface{}) *LogMasker {
 	masker := &LogMasker{
 		ReplaceWords: map[string]string{},
+		Detectors:    DefaultSecretDetectors(),
 	}
 	masker.replaceMapValues(m)
 	return masker
 }
 
+// AddDetectors adds detectors which mask any matching text in addition to the known secrets
+func (m *LogMasker) AddDetectors(detectors ...SecretDetector) {
+	m.Detectors = append(m.Detectors, detectors...)
+}
+
 // LoadSecrets loads the secrets into the log masker
 func (m *LogMasker) LoadSecrets(kubeClient kubernetes.Interface, ns string) error {
 	resourceList, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
@@ -49,31 +74,68 @@ func (m *LogMasker) LoadSecrets(kubeClient kubernetes.Interface, ns string) erro
 
 // LoadSecret loads the secret data into the log masker
 func (m *LogMasker) LoadSecret(secret *corev1.Secret) {
-	if m.ReplaceWords == nil {
-		m.ReplaceWords = map[string]string{}
-	}
-
 	if secret.Data != nil {
 		for _, v := range secret.Data {
 			if v != nil && len(v) > 0 {
-				// key := string(k)
-				value := string(v)
-
-				m.ReplaceWords[value] = m.replaceValue(value)
+				m.addSecret(string(v))
 			}
 		}
 	}
 }
 
+// LoadSecretURLs loads the values of the named secrets from a secret URL backend such as vault or
+// the local file system into the log masker
+func (m *LogMasker) LoadSecretURLs(client secreturl.Client, secretNames ...string) error {
+	for _, name := range secretNames {
+		values, err := client.Read(name)
+		if err != nil {
+			return errors.Wrapf(err, "reading secret %s", name)
+		}
+		m.replaceMapValues(values)
+	}
+	return nil
+}
+
 // MaskLog returns the text with all of the secrets masked out
 func (m *LogMasker) MaskLog(text string) string {
 	answer := text
-	for k, v := range m.ReplaceWords {
-		answer = strings.Replace(answer, k, v, -1)
+	replacements := m.sortedReplacements()
+	for i := 0; i < len(replacements); i += 2 {
+		answer = strings.Replace(answer, replacements[i], replacements[i+1], -1)
+	}
+	for _, d := range m.Detectors {
+		answer = d.Mask(answer)
 	}
 	return answer
 }
 
+// sortedReplacements returns the words to replace, each followed by its replacement, with the longest word first so
+// that a secret which contains another secret is fully masked. The words are only sorted again if secrets have been
+// added since they were last sorted
+func (m *LogMasker) sortedReplacements() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.replacements) == 2*len(m.ReplaceWords) {
+		return m.replacements
+	}
+	words := make([]string, 0, len(m.ReplaceWords))
+	for k := range m.ReplaceWords {
+		words = append(words, k)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i]) != len(words[j]) {
+			return len(words[i]) > len(words[j])
+		}
+		return words[i] < words[j]
+	})
+	replacements := make([]string, 0, 2*len(words))
+	for _, w := range words {
+		replacements = append(replacements, w, m.ReplaceWords[w])
+	}
+	m.replacements = replacements
+	return replacements
+}
+
 // MaskLogData masks the log data
 func (m *LogMasker) MaskLogData(logData []byte) []byte {
 	text := m.MaskLog(string(logData))
@@ -89,12 +151,53 @@ func (m *LogMasker) replaceMapValues(values map[string]interface{}) {
 			continue
 		}
 		text, ok := value.(string)
-		if ok {
-			m.ReplaceWords[text] = m.replaceValue(text)
+		if ok && text != "" {
+			m.addSecret(text)
 		}
 	}
 }
 
+// addSecret adds the secret value along with the encoded variants of it which commonly end up in logs
+func (m *LogMasker) addSecret(value string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.ReplaceWords == nil {
+		m.ReplaceWords = map[string]string{}
+	}
+	for _, v := range encodedVariants(value) {
+		m.ReplaceWords[v] = m.replaceValue(v)
+	}
+	m.replacements = nil
+}
+
+// encodedVariants returns the value, its base64 encodings, including the encoding of the value followed by a
+// new line as produced by 'echo $SECRET | base64', and its URL encodings. Values shorter than minEncodedSecretLength
+// only have themselves as a variant
+func encodedVariants(value string) []string {
+	answer := []string{value}
+	if len(value) < minEncodedSecretLength {
+		return answer
+	}
+	add := func(v string) {
+		for _, a := range answer {
+			if a == v {
+				return
+			}
+		}
+		answer = append(answer, v)
+	}
+	for _, plain := range []string{value, value + "\n"} {
+		data := []byte(plain)
+		add(base64.StdEncoding.EncodeToString(data))
+		add(base64.RawStdEncoding.EncodeToString(data))
+		add(base64.URLEncoding.EncodeToString(data))
+		add(base64.RawURLEncoding.EncodeToString(data))
+	}
+	add(url.QueryEscape(value))
+	add(url.PathEscape(value))
+	return answer
+}
+
 func (m *LogMasker) replaceValue(value string) string {
 	return strings.Repeat("*", len(value))
 }
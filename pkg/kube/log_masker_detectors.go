@@ -0,0 +1,116 @@
+package kube
+
+import (
+	"regexp"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// SecretDetector masks secrets in a log which are recognised by their format rather than by a known value
+type SecretDetector interface {
+	// Name returns the name of the kind of secret detected
+	Name() string
+
+	// Mask returns the text with any detected secrets masked out
+	Mask(text string) string
+}
+
+// RegexSecretDetector masks all text matching a regular expression
+type RegexSecretDetector struct {
+	DetectorName string
+	Pattern      *regexp.Regexp
+}
+
+// NewRegexSecretDetector creates a detector masking the text matching the given regular expression
+func NewRegexSecretDetector(name string, pattern string) (*RegexSecretDetector, error) {
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexSecretDetector{
+		DetectorName: name,
+		Pattern:      r,
+	}, nil
+}
+
+// Name returns the name of the kind of secret detected
+func (d *RegexSecretDetector) Name() string {
+	return d.DetectorName
+}
+
+// Mask returns the text with all matches of the pattern masked out. If the pattern contains a capture group
+// only the text matched by the first group is masked so that any surrounding context remains readable.
+func (d *RegexSecretDetector) Mask(text string) string {
+	if d.Pattern.NumSubexp() == 0 {
+		return d.Pattern.ReplaceAllStringFunc(text, maskText)
+	}
+	matches := d.Pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2], match[3]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(maskText(text[start:end]))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// maskText replaces every character other than new lines with an asterisk so multi line secrets keep their shape
+func maskText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Repeat("*", len(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var defaultSecretDetectorPatterns = []struct {
+	name    string
+	pattern string
+}{
+	{"aws-access-key-id", `\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`},
+	{"aws-secret-access-key", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`},
+	{"github-token", `\bgh[pousr]_[A-Za-z0-9]{36,255}\b`},
+	{"github-fine-grained-token", `\bgithub_pat_[A-Za-z0-9_]{22,255}\b`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+	{"private-key", `-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----(?s:.*?)-----END [A-Z0-9 ]*PRIVATE KEY-----`},
+}
+
+// DefaultSecretDetectors returns the detectors for AWS keys, GitHub tokens, JWTs and private key blocks.
+// Private keys are only detected if the whole block is masked at once, which LogLineMasker does for streamed logs.
+func DefaultSecretDetectors() []SecretDetector {
+	var answer []SecretDetector
+	for _, p := range defaultSecretDetectorPatterns {
+		answer = append(answer, &RegexSecretDetector{
+			DetectorName: p.name,
+			Pattern:      regexp.MustCompile(p.pattern),
+		})
+	}
+	return answer
+}
+
+// LoadLogMaskPatterns adds a detector for each of the patterns in the team settings. Invalid patterns are skipped
+// and returned as an error so that the valid patterns are still used
+func (m *LogMasker) LoadLogMaskPatterns(patterns []v1.LogMaskPattern) error {
+	var errs []error
+	for _, p := range patterns {
+		detector, err := NewRegexSecretDetector(p.Name, p.Pattern)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid log mask pattern %s", p.Name))
+			continue
+		}
+		m.AddDetectors(detector)
+	}
+	return util.CombineErrors(errs...)
+}
@@ -14,12 +14,18 @@ const (
 
 	// ClassificationReports stores test results, coverage & quality reports
 	ClassificationReports = "reports"
+
+	// ClassificationCache stores cached directories restored between pipeline runs
+	ClassificationCache = "cache"
+
+	// ClassificationSBOM stores software bills of materials of releases
+	ClassificationSBOM = "sbom"
 )
 
 var (
 	// Classifications the common classification names
 	Classifications = []string{
-		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports,
+		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports, ClassificationCache, ClassificationSBOM,
 	}
 
 	// ClassificationValues the classification values as a string
@@ -0,0 +1,320 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/pkg/cmd/step"
+	"github.com/jenkins-x/jx/pkg/collector"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// ArchiveIndexDir is the directory in the log storage containing the index of the log archive. There is a directory
+// for each month containing an index file for each build, so that concurrent builds never update the same file
+const ArchiveIndexDir = "jenkins-x/logs/index"
+
+// archiveWriteAttempts is the number of times writing an index file is attempted. Git storage is cloned again for
+// each attempt, so a push rejected because another build pushed first is retried on top of the other build's commit
+const archiveWriteAttempts = 3
+
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// ArchiveEntry describes a build log stored in the log archive
+type ArchiveEntry struct {
+	Activity   string    `json:"activity"`
+	Owner      string    `json:"owner,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	Branch     string    `json:"branch,omitempty"`
+	Context    string    `json:"context,omitempty"`
+	Build      string    `json:"build,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Started    time.Time `json:"started"`
+	LogURL     string    `json:"logUrl"`
+	BuildURL   string    `json:"buildUrl,omitempty"`
+}
+
+// ArchiveIndex is the list of build logs archived in a month
+type ArchiveIndex struct {
+	Entries []ArchiveEntry `json:"entries"`
+}
+
+// ArchiveQuery the criteria used to search the log archive. The builds are filtered using the index before any of
+// their logs are read
+type ArchiveQuery struct {
+	Pattern    *regexp.Regexp
+	Since      time.Time
+	Owner      string
+	Repository string
+	Branch     string
+	Context    string
+	Build      string
+	MaxMatches int
+	// MaxLogs is the maximum number of logs to read, taking the builds which started most recently
+	MaxLogs int
+}
+
+// ArchiveMatch is a line of an archived build log which matched an ArchiveQuery
+type ArchiveMatch struct {
+	Entry      ArchiveEntry
+	LineNumber int
+	Line       string
+	Matches    [][]int
+}
+
+// LogArchive stores an index of the build logs written to the long term storage so that they can be searched
+// after the build pods have been garbage collected. The index only holds the metadata of each build, which is used to
+// pick the logs to read, so searching reads and scans the whole of each of those logs
+type LogArchive struct {
+	// Collector writes the index files. It is only required to archive logs
+	Collector collector.Collector
+	// ReadFiles reads the files in the given directory of the log storage keyed by their path relative to the
+	// directory, returning no files if the directory does not exist
+	ReadFiles func(dir string) (map[string][]byte, error)
+	// ReadURL reads the contents of a URL stored as the log URL of an entry
+	ReadURL func(u string) ([]byte, error)
+	// Now returns the current time, defaulting to time.Now
+	Now func() time.Time
+}
+
+// NewLogArchive creates a log archive for the given storage location. The index is read by cloning the git storage
+// or listing the bucket rather than through the URLs of the files, which may be cached and miss recent builds
+func NewLogArchive(location v1.StorageLocation, coll collector.Collector, gitter gits.Gitter, readURL func(u string) ([]byte, error)) *LogArchive {
+	return &LogArchive{
+		Collector: coll,
+		ReadFiles: func(dir string) (map[string][]byte, error) {
+			return collector.ReadFiles(location, gitter, dir, time.Second*20)
+		},
+		ReadURL: readURL,
+	}
+}
+
+// ArchiveURLReader returns a function which reads archive URLs from buckets or git providers, using the git tokens
+// from the auth service to access git providers
+func ArchiveURLReader(authSvc auth.ConfigService, timeout time.Duration) func(u string) ([]byte, error) {
+	httpFn := step.CreateBucketHTTPFn(authSvc)
+	return func(u string) ([]byte, error) {
+		return buckets.ReadURL(u, timeout, httpFn)
+	}
+}
+
+// NewArchiveEntry creates the archive entry for the log of the given activity stored at the log URL
+func NewArchiveEntry(activity *v1.PipelineActivity, logURL string) ArchiveEntry {
+	entry := ArchiveEntry{
+		Activity:   activity.Name,
+		Owner:      activity.RepositoryOwner(),
+		Repository: activity.RepositoryName(),
+		Branch:     activity.BranchName(),
+		Context:    activity.Spec.Context,
+		Build:      activity.Spec.Build,
+		Status:     string(activity.Spec.Status),
+		LogURL:     logURL,
+		BuildURL:   activity.Spec.BuildURL,
+	}
+	if activity.Spec.StartedTimestamp != nil {
+		entry.Started = activity.Spec.StartedTimestamp.Time
+	} else {
+		entry.Started = activity.CreationTimestamp.Time
+	}
+	return entry
+}
+
+// IndexDir returns the directory of the index files of the builds started in the month of the given time
+func IndexDir(t time.Time) string {
+	return filepath.Join(ArchiveIndexDir, t.UTC().Format("2006-01"))
+}
+
+// IndexPath returns the path of the index file of the build of the entry. The owner and repository are lower case
+// in the path as they are matched case insensitively when searching
+func IndexPath(entry ArchiveEntry) string {
+	return filepath.Join(IndexDir(entry.Started), strings.ToLower(entry.Owner), strings.ToLower(entry.Repository), entry.Activity+".yml")
+}
+
+// LoadIndex loads the index of the builds started in the month of the given time, oldest first. If no builds have
+// been archived for the month an empty index is returned
+func (a *LogArchive) LoadIndex(month time.Time) (*ArchiveIndex, error) {
+	return a.loadIndexDir(IndexDir(month))
+}
+
+// loadIndexDir loads the index files in the directory
+func (a *LogArchive) loadIndexDir(dir string) (*ArchiveIndex, error) {
+	files, err := a.ReadFiles(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the log archive index %s", dir)
+	}
+	index := &ArchiveIndex{}
+	for name, data := range files {
+		if filepath.Ext(name) != ".yml" {
+			continue
+		}
+		entry := ArchiveEntry{}
+		err = yaml.Unmarshal(data, &entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal the log archive index file %s", filepath.Join(dir, name))
+		}
+		index.Entries = append(index.Entries, entry)
+	}
+	sort.SliceStable(index.Entries, func(i, j int) bool {
+		if index.Entries[i].Started.Equal(index.Entries[j].Started) {
+			return index.Entries[i].Activity < index.Entries[j].Activity
+		}
+		return index.Entries[i].Started.Before(index.Entries[j].Started)
+	})
+	return index, nil
+}
+
+// Archive writes the index file of the entry in the directory for the month the build started in, replacing any
+// previous entry for the same activity
+func (a *LogArchive) Archive(entry ArchiveEntry) error {
+	if a.Collector == nil {
+		return errors.New("no collector configured to write the log archive index")
+	}
+	if entry.Started.IsZero() {
+		entry.Started = a.now()
+	}
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the log archive index")
+	}
+	path := IndexPath(entry)
+	for i := 1; ; i++ {
+		_, err = a.Collector.CollectData(data, path)
+		if err == nil {
+			return nil
+		}
+		if i >= archiveWriteAttempts {
+			return errors.Wrapf(err, "failed to write the log archive index %s", path)
+		}
+		log.Logger().Debugf("failed to write the log archive index %s, retrying: %s", path, err)
+	}
+}
+
+// Search returns the lines of the archived logs matching the query, oldest build first so the first match is
+// from the first build which printed it. Logs which can no longer be read are skipped
+func (a *LogArchive) Search(query ArchiveQuery) ([]ArchiveMatch, error) {
+	if query.Pattern == nil {
+		return nil, errors.New("no pattern to search for")
+	}
+	entries, err := a.findEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	if query.MaxLogs > 0 && len(entries) > query.MaxLogs {
+		log.Logger().Warnf("only the logs of the latest %d of the %d matching builds are searched so matches in the %d older builds are not shown",
+			query.MaxLogs, len(entries), len(entries)-query.MaxLogs)
+		entries = entries[len(entries)-query.MaxLogs:]
+	}
+	var answer []ArchiveMatch
+	for _, entry := range entries {
+		data, err := a.ReadURL(entry.LogURL)
+		if err != nil {
+			log.Logger().Warnf("failed to read the archived log %s: %s", entry.LogURL, err)
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		lineNumber := 0
+		for scanner.Scan() {
+			lineNumber++
+			line := ansiEscapeRegex.ReplaceAllString(scanner.Text(), "")
+			matches := query.Pattern.FindAllStringIndex(line, -1)
+			if len(matches) == 0 {
+				continue
+			}
+			answer = append(answer, ArchiveMatch{
+				Entry:      entry,
+				LineNumber: lineNumber,
+				Line:       line,
+				Matches:    matches,
+			})
+			if query.MaxMatches > 0 && len(answer) >= query.MaxMatches {
+				return answer, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Logger().Warnf("failed to scan the archived log %s: %s", entry.LogURL, err)
+		}
+	}
+	return answer, nil
+}
+
+// findEntries loads the index files for each month since the start of the query and returns the matching entries.
+// Only the index files of the repository are read if the query has an owner and repository
+func (a *LogArchive) findEntries(query ArchiveQuery) ([]ArchiveEntry, error) {
+	now := a.now().UTC()
+	since := query.Since.UTC()
+	if query.Since.IsZero() {
+		since = now
+	}
+	var answer []ArchiveEntry
+	month := time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(now) {
+		dir := IndexDir(month)
+		if query.Owner != "" && query.Repository != "" {
+			dir = filepath.Join(dir, strings.ToLower(query.Owner), strings.ToLower(query.Repository))
+		}
+		index, err := a.loadIndexDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range index.Entries {
+			if e.Started.Before(query.Since) {
+				continue
+			}
+			if !matchesFilter(e.Owner, query.Owner) || !matchesFilter(e.Repository, query.Repository) || !matchesFilter(e.Branch, query.Branch) {
+				continue
+			}
+			if !matchesFilter(e.Context, query.Context) || !matchesFilter(e.Build, query.Build) {
+				continue
+			}
+			answer = append(answer, e)
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Started.Before(answer[j].Started)
+	})
+	return answer, nil
+}
+
+func (a *LogArchive) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+// Highlight returns the line with each matching part of it passed through the given function
+func (m *ArchiveMatch) Highlight(fn func(a ...interface{}) string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range m.Matches {
+		b.WriteString(m.Line[last:match[0]])
+		b.WriteString(fn(m.Line[match[0]:match[1]]))
+		last = match[1]
+	}
+	b.WriteString(m.Line[last:])
+	return b.String()
+}
+
+// Link returns the URL of the build if it is known, otherwise the URL of the archived log
+func (m *ArchiveMatch) Link() string {
+	if m.Entry.BuildURL != "" {
+		return m.Entry.BuildURL
+	}
+	return m.Entry.LogURL
+}
+
+func matchesFilter(value string, filter string) bool {
+	return filter == "" || strings.EqualFold(value, filter)
+}
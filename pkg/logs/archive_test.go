@@ -0,0 +1,189 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage is a Collector and URL reader backed by a map for testing the log archive
+type memoryStorage struct {
+	files map[string][]byte
+	// failures is the number of writes which fail before writes succeed
+	failures int
+	// dirsRead are the directories read by readFiles
+	dirsRead []string
+}
+
+func (s *memoryStorage) CollectFiles(patterns []string, outputPath string, basedir string) ([]string, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (s *memoryStorage) CollectData(data []byte, outputPath string) (string, error) {
+	if s.failures > 0 {
+		s.failures--
+		return "", fmt.Errorf("failed to push some refs")
+	}
+	s.files[outputPath] = data
+	return "mem://" + outputPath, nil
+}
+
+func (s *memoryStorage) readFiles(dir string) (map[string][]byte, error) {
+	s.dirsRead = append(s.dirsRead, dir)
+	answer := map[string][]byte{}
+	for path, data := range s.files {
+		if strings.HasPrefix(path, dir+"/") {
+			answer[strings.TrimPrefix(path, dir+"/")] = data
+		}
+	}
+	return answer, nil
+}
+
+func (s *memoryStorage) read(u string) ([]byte, error) {
+	data, ok := s.files[u[len("mem://"):]]
+	if !ok {
+		return nil, fmt.Errorf("status 404 Not Found when performing GET on %s", u)
+	}
+	return data, nil
+}
+
+func newTestArchive(now time.Time) (*LogArchive, *memoryStorage) {
+	storage := &memoryStorage{files: map[string][]byte{}}
+	return &LogArchive{
+		Collector: storage,
+		ReadFiles: storage.readFiles,
+		ReadURL:   storage.read,
+		Now: func() time.Time {
+			return now
+		},
+	}, storage
+}
+
+func TestLogArchiveSearch(t *testing.T) {
+	now := time.Date(2019, time.November, 10, 12, 0, 0, 0, time.UTC)
+	archive, storage := newTestArchive(now)
+
+	builds := []struct {
+		entry ArchiveEntry
+		log   string
+	}{
+		{
+			entry: ArchiveEntry{Activity: "cheese-master-1", Owner: "jx", Repository: "cheese", Branch: "master", Build: "1", Started: now.AddDate(0, -1, 0)},
+			log:   "building\nall good\n",
+		},
+		{
+			entry: ArchiveEntry{Activity: "cheese-master-2", Owner: "jx", Repository: "cheese", Branch: "master", Build: "2", Started: now.Add(-48 * time.Hour), BuildURL: "https://dashboard/cheese/2"},
+			log:   "building\n\x1b[31merror: connection refused\x1b[0m\nretrying\nerror: connection refused again\n",
+		},
+		{
+			entry: ArchiveEntry{Activity: "wine-master-1", Owner: "jx", Repository: "wine", Branch: "master", Build: "1", Started: now.Add(-time.Hour)},
+			log:   "error: connection refused\n",
+		},
+	}
+	// archive out of order to check the matches are sorted by the time the build started
+	for i := len(builds) - 1; i >= 0; i-- {
+		b := builds[i]
+		logURL, err := storage.CollectData([]byte(b.log), b.entry.Activity+".log")
+		require.NoError(t, err)
+		b.entry.LogURL = logURL
+		err = archive.Archive(b.entry)
+		require.NoError(t, err)
+	}
+	assert.Contains(t, storage.files, "jenkins-x/logs/index/2019-10/jx/cheese/cheese-master-1.yml")
+	assert.Contains(t, storage.files, "jenkins-x/logs/index/2019-11/jx/cheese/cheese-master-2.yml")
+	assert.Contains(t, storage.files, "jenkins-x/logs/index/2019-11/jx/wine/wine-master-1.yml")
+
+	// archiving the same activity again replaces the entry
+	builds[2].entry.LogURL = "mem://wine-master-1.log"
+	builds[2].entry.Status = "Succeeded"
+	err := archive.Archive(builds[2].entry)
+	require.NoError(t, err)
+	index, err := archive.LoadIndex(now)
+	require.NoError(t, err)
+	require.Len(t, index.Entries, 2)
+	assert.Equal(t, "cheese-master-2", index.Entries[0].Activity)
+	assert.Equal(t, "Succeeded", index.Entries[1].Status)
+
+	query := ArchiveQuery{
+		Pattern: regexp.MustCompile(`connection refused`),
+		Since:   now.AddDate(0, -2, 0),
+	}
+	matches, err := archive.Search(query)
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "cheese-master-2", matches[0].Entry.Activity)
+	assert.Equal(t, 2, matches[0].LineNumber)
+	assert.Equal(t, "error: connection refused", matches[0].Line)
+	assert.Equal(t, "https://dashboard/cheese/2", matches[0].Link())
+	assert.Equal(t, "mem://wine-master-1.log", matches[2].Link())
+
+	highlight := func(a ...interface{}) string {
+		return fmt.Sprintf("[%s]", a...)
+	}
+	assert.Equal(t, "error: [connection refused] again", matches[1].Highlight(highlight))
+
+	query.Owner = "JX"
+	query.Repository = "wine"
+	storage.dirsRead = nil
+	matches, err = archive.Search(query)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "wine-master-1", matches[0].Entry.Activity)
+	assert.Contains(t, storage.dirsRead, "jenkins-x/logs/index/2019-11/jx/wine", "only the index of the repository should be read")
+
+	query.Owner = ""
+	query.Repository = ""
+	query.MaxLogs = 1
+	matches, err = archive.Search(query)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "wine-master-1", matches[0].Entry.Activity, "only the latest log should be read")
+	query.MaxLogs = 0
+
+	query.Repository = ""
+	query.Since = now.Add(-24 * time.Hour)
+	query.Pattern = regexp.MustCompile(`building`)
+	matches, err = archive.Search(query)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	query.Since = now.AddDate(0, -2, 0)
+	query.MaxMatches = 1
+	matches, err = archive.Search(query)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "cheese-master-1", matches[0].Entry.Activity)
+}
+
+func TestLogArchiveRetriesFailedWrites(t *testing.T) {
+	now := time.Date(2019, time.November, 10, 12, 0, 0, 0, time.UTC)
+	archive, storage := newTestArchive(now)
+
+	storage.failures = 1
+	err := archive.Archive(ArchiveEntry{Activity: "cheese-master-1", Owner: "jx", Repository: "cheese", Started: now})
+	require.NoError(t, err)
+	index, err := archive.LoadIndex(now)
+	require.NoError(t, err)
+	require.Len(t, index.Entries, 1)
+
+	storage.failures = archiveWriteAttempts
+	err = archive.Archive(ArchiveEntry{Activity: "cheese-master-2", Owner: "jx", Repository: "cheese", Started: now})
+	assert.Error(t, err)
+}
+
+func TestLogArchiveLoadIndexFailure(t *testing.T) {
+	archive, _ := newTestArchive(time.Now())
+	archive.ReadFiles = func(dir string) (map[string][]byte, error) {
+		return nil, fmt.Errorf("permission denied")
+	}
+	_, err := archive.LoadIndex(time.Now())
+	assert.Error(t, err)
+
+	_, err = archive.Search(ArchiveQuery{Pattern: regexp.MustCompile("error")})
+	assert.Error(t, err)
+}
@@ -232,6 +232,24 @@ func InstallKubectl(skipPathScan bool) error {
 	return InstallKubectlWithVersion(KubectlVersion, skipPathScan)
 }
 
+// InstallSopsWithVersion install a specific version of sops
+func InstallSopsWithVersion(version string, skipPathScan bool) error {
+	return InstallOrUpdateBinary(InstallOrUpdateBinaryOptions{
+		Binary:              "sops",
+		GitHubOrganization:  "getsops",
+		DownloadUrlTemplate: "https://github.com/getsops/sops/releases/download/v{{.version}}/sops-v{{.version}}.{{.os}}.{{.arch}}",
+		Version:             version,
+		SkipPathScan:        skipPathScan,
+		VersionExtractor:    nil,
+		Archived:            false,
+	})
+}
+
+// InstallSops installs sops which encrypts and decrypts the secrets when using sops for secret storage
+func InstallSops(skipPathScan bool) error {
+	return InstallSopsWithVersion(SopsVersion, skipPathScan)
+}
+
 // UninstallBinary uninstalls given binary
 func UninstallBinary(binDir string, name string) error {
 	fileName := name
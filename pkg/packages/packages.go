@@ -25,6 +25,9 @@ const IamAuthenticatorAwsVersion = "1.12.7"
 // KubectlVersion binary version to use
 const KubectlVersion = "1.13.2"
 
+// SopsVersion sops binary version to use
+const SopsVersion = "3.8.1"
+
 func BinaryWithExtension(binary string) string {
 	if runtime.GOOS == "windows" {
 		if binary == "gcloud" {
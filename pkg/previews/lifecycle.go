@@ -0,0 +1,211 @@
+package previews
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// WakeCommand the pull request comment which wakes up a hibernated Preview Environment
+	WakeCommand = "/preview wake"
+
+	// DefaultRequestsQuery the Prometheus query for the number of requests the ingress controller received for a
+	// namespace over a duration
+	DefaultRequestsQuery = `sum(increase(nginx_ingress_controller_requests{exported_namespace="$NAMESPACE"}[$DURATION]))`
+)
+
+// Defaults the time to live and idle timeout of Preview Environments which do not specify their own
+type Defaults struct {
+	TTL         time.Duration
+	IdleTimeout time.Duration
+}
+
+// Lifecycle returns the lifecycle of the Preview Environment lazily creating it
+func Lifecycle(env *v1.Environment) *v1.PreviewLifecycle {
+	if env.Spec.PreviewLifecycle == nil {
+		env.Spec.PreviewLifecycle = &v1.PreviewLifecycle{}
+	}
+	return env.Spec.PreviewLifecycle
+}
+
+// Expired returns true if the Preview Environment was last deployed longer ago than its time to live
+func Expired(env *v1.Environment, defaults Defaults, now time.Time) (bool, error) {
+	ttl := defaults.TTL
+	lifecycle := env.Spec.PreviewLifecycle
+	if lifecycle != nil && lifecycle.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(lifecycle.TTL)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid TTL %s of Preview Environment %s", lifecycle.TTL, env.Name)
+		}
+	}
+	if ttl <= 0 {
+		return false, nil
+	}
+	since := env.CreationTimestamp.Time
+	if lifecycle != nil && lifecycle.LastDeployedTimestamp != nil {
+		since = lifecycle.LastDeployedTimestamp.Time
+	}
+	return now.Sub(since) > ttl, nil
+}
+
+// IdleTimeout returns how long the Preview Environment can go without traffic before it is hibernated or zero if it
+// is never hibernated
+func IdleTimeout(env *v1.Environment, defaults Defaults) (time.Duration, error) {
+	lifecycle := env.Spec.PreviewLifecycle
+	if lifecycle != nil && lifecycle.IdleTimeout != "" {
+		idle, err := time.ParseDuration(lifecycle.IdleTimeout)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid idle timeout %s of Preview Environment %s", lifecycle.IdleTimeout, env.Name)
+		}
+		return idle, nil
+	}
+	return defaults.IdleTimeout, nil
+}
+
+// LastActive returns when the Preview Environment was last deployed, woken up or seen to receive traffic
+func LastActive(env *v1.Environment) time.Time {
+	answer := env.CreationTimestamp.Time
+	lifecycle := env.Spec.PreviewLifecycle
+	if lifecycle == nil {
+		return answer
+	}
+	for _, t := range []*metav1.Time{lifecycle.LastDeployedTimestamp, lifecycle.LastActiveTimestamp, lifecycle.WokenTimestamp} {
+		if t != nil && t.After(answer) {
+			answer = t.Time
+		}
+	}
+	return answer
+}
+
+// IsHibernated returns true if the Deployments of the Preview Environment are scaled to zero
+func IsHibernated(env *v1.Environment) bool {
+	return env.Spec.PreviewLifecycle != nil && env.Spec.PreviewLifecycle.Hibernated
+}
+
+// FindWakeComment returns the first comment after the given time which asks for the Preview Environment to be woken
+// up or nil if there is none
+func FindWakeComment(comments []*gits.GitPullRequestComment, since time.Time) *gits.GitPullRequestComment {
+	for _, comment := range comments {
+		if comment == nil || (comment.CreatedAt != nil && comment.CreatedAt.Before(since)) {
+			continue
+		}
+		for _, line := range strings.Split(comment.Body, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), WakeCommand) {
+				return comment
+			}
+		}
+	}
+	return nil
+}
+
+// CountRequests returns the number of requests the ingress controller received for the namespace over the duration
+// using a query which may refer to $NAMESPACE and $DURATION
+func CountRequests(client promotion.MetricsClient, query string, ns string, duration time.Duration) (float64, error) {
+	if query == "" {
+		query = DefaultRequestsQuery
+	}
+	expanded := os.Expand(query, func(name string) string {
+		switch name {
+		case "NAMESPACE":
+			return ns
+		case "DURATION":
+			return fmt.Sprintf("%ds", int64(duration.Seconds()))
+		default:
+			return "$" + name
+		}
+	})
+	return client.Query(expanded)
+}
+
+// Hibernate scales the Deployments of the Preview Environment to zero recording their replicas on the Environment so
+// that they can be restored when it is woken up
+func Hibernate(kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string, env *v1.Environment) error {
+	deployments, err := kubeClient.AppsV1().Deployments(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the Deployments in namespace %s", env.Spec.Namespace)
+	}
+	lifecycle := Lifecycle(env)
+	if lifecycle.Replicas == nil {
+		lifecycle.Replicas = map[string]int32{}
+	}
+	for _, d := range deployments.Items {
+		if d.Spec.Replicas != nil && *d.Spec.Replicas > 0 {
+			lifecycle.Replicas[d.Name] = *d.Spec.Replicas
+		}
+	}
+	lifecycle.Hibernated = true
+	lifecycle.HibernatedTimestamp = &metav1.Time{Time: time.Now()}
+
+	// lets record the replicas before scaling down so that they are never lost
+	_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(env)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Environment %s", env.Name)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if d.Spec.Replicas == nil || *d.Spec.Replicas == 0 {
+			continue
+		}
+		zero := int32(0)
+		d.Spec.Replicas = &zero
+		_, err = kubeClient.AppsV1().Deployments(env.Spec.Namespace).Update(d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scale down Deployment %s in namespace %s", d.Name, env.Spec.Namespace)
+		}
+		log.Logger().Debugf("Scaled down Deployment %s in namespace %s", d.Name, env.Spec.Namespace)
+	}
+	return nil
+}
+
+// Wake restores the replicas of the Deployments of a hibernated Preview Environment recording what woke it up
+func Wake(kubeClient kubernetes.Interface, jxClient versioned.Interface, ns string, env *v1.Environment, wokenBy string) error {
+	lifecycle := Lifecycle(env)
+	deployments, err := kubeClient.AppsV1().Deployments(env.Spec.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the Deployments in namespace %s", env.Spec.Namespace)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if d.Spec.Replicas != nil && *d.Spec.Replicas > 0 {
+			continue
+		}
+		// deployments which were already scaled to zero when hibernated stay that way
+		replicas, ok := lifecycle.Replicas[d.Name]
+		if !ok {
+			if len(lifecycle.Replicas) > 0 {
+				continue
+			}
+			replicas = 1
+		}
+		d.Spec.Replicas = &replicas
+		_, err = kubeClient.AppsV1().Deployments(env.Spec.Namespace).Update(d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scale up Deployment %s in namespace %s", d.Name, env.Spec.Namespace)
+		}
+		log.Logger().Debugf("Scaled up Deployment %s in namespace %s to %d replicas", d.Name, env.Spec.Namespace, replicas)
+	}
+	now := &metav1.Time{Time: time.Now()}
+	lifecycle.Hibernated = false
+	lifecycle.HibernatedTimestamp = nil
+	lifecycle.Replicas = nil
+	lifecycle.WokenBy = wokenBy
+	lifecycle.WokenTimestamp = now
+	lifecycle.LastActiveTimestamp = now
+	_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(env)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Environment %s", env.Name)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package previews_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/previews"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type requestsClient struct {
+	query    string
+	requests float64
+}
+
+func (c *requestsClient) Query(query string) (float64, error) {
+	c.query = query
+	return c.requests, nil
+}
+
+func previewEnvironment(created time.Time) *v1.Environment {
+	return &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "myorg-myapp-pr-1",
+			Namespace:         "jx",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1.EnvironmentSpec{
+			Kind:      v1.EnvironmentKindTypePreview,
+			Namespace: "jx-myorg-myapp-pr-1",
+		},
+	}
+}
+
+func TestExpired(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	env := previewEnvironment(now.Add(-100 * time.Hour))
+
+	expired, err := previews.Expired(env, previews.Defaults{}, now)
+	require.NoError(t, err)
+	assert.False(t, expired, "no TTL")
+
+	expired, err = previews.Expired(env, previews.Defaults{TTL: 72 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.True(t, expired, "default TTL since creation")
+
+	previews.Lifecycle(env).LastDeployedTimestamp = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+	expired, err = previews.Expired(env, previews.Defaults{TTL: 72 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.False(t, expired, "default TTL since last deployed")
+
+	env.Spec.PreviewLifecycle.TTL = "1h"
+	expired, err = previews.Expired(env, previews.Defaults{TTL: 72 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.True(t, expired, "TTL of the preview")
+
+	env.Spec.PreviewLifecycle.TTL = "a while"
+	_, err = previews.Expired(env, previews.Defaults{}, now)
+	assert.Error(t, err)
+}
+
+func TestFindWakeComment(t *testing.T) {
+	t.Parallel()
+	hibernated := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	before := hibernated.Add(-time.Hour)
+	after := hibernated.Add(time.Hour)
+	comments := []*gits.GitPullRequestComment{
+		{ID: 1, Body: "/preview wake", CreatedAt: &before},
+		{ID: 2, Body: ":zzz: preview environment has been hibernated. Comment `/preview wake` to wake it up", CreatedAt: &after},
+		{ID: 3, Body: "looks good\n  /preview wake please", CreatedAt: &after},
+	}
+	comment := previews.FindWakeComment(comments, hibernated)
+	require.NotNil(t, comment)
+	assert.Equal(t, int64(3), comment.ID)
+
+	assert.Nil(t, previews.FindWakeComment(comments[:2], hibernated))
+}
+
+func TestCountRequests(t *testing.T) {
+	t.Parallel()
+	client := &requestsClient{requests: 3}
+	requests, err := previews.CountRequests(client, "", "jx-myorg-myapp-pr-1", 8*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), requests)
+	assert.Equal(t, `sum(increase(nginx_ingress_controller_requests{exported_namespace="jx-myorg-myapp-pr-1"}[28800s]))`, client.query)
+}
+
+func TestHibernateAndWake(t *testing.T) {
+	t.Parallel()
+	env := previewEnvironment(time.Now())
+	two := int32(2)
+	zero := int32(0)
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: env.Spec.Namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &two},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: env.Spec.Namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+		},
+	)
+	jxClient := jxfake.NewSimpleClientset(env)
+
+	err := previews.Hibernate(kubeClient, jxClient, "jx", env)
+	require.NoError(t, err)
+	assert.True(t, previews.IsHibernated(env))
+	assert.Equal(t, map[string]int32{"myapp": 2}, env.Spec.PreviewLifecycle.Replicas)
+	d, err := kubeClient.AppsV1().Deployments(env.Spec.Namespace).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *d.Spec.Replicas)
+
+	stored, err := jxClient.JenkinsV1().Environments("jx").Get(env.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, previews.IsHibernated(stored))
+
+	err = previews.Wake(kubeClient, jxClient, "jx", stored, "comment by reviewer")
+	require.NoError(t, err)
+	d, err = kubeClient.AppsV1().Deployments(env.Spec.Namespace).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *d.Spec.Replicas)
+	d, err = kubeClient.AppsV1().Deployments(env.Spec.Namespace).Get("worker", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *d.Spec.Replicas)
+
+	stored, err = jxClient.JenkinsV1().Environments("jx").Get(env.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, previews.IsHibernated(stored))
+	assert.Equal(t, "comment by reviewer", stored.Spec.PreviewLifecycle.WokenBy)
+	assert.Nil(t, stored.Spec.PreviewLifecycle.Replicas)
+}
@@ -0,0 +1,133 @@
+package promotion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a standard 5 field cron expression of minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+	// anyDayOfMonth and anyDayOfWeek are true if the day field is unrestricted
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames   = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// ParseCronSchedule parses a cron expression such as '0 17 * * FRI'. Each field may be *, a value, a range such as
+// MON-FRI or a comma separated list of them with an optional step such as */15
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' should have 5 fields but has %d", expression, len(fields))
+	}
+	s := &CronSchedule{}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	if s.daysOfWeek, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, err
+	}
+	// both 0 and 7 are Sunday
+	if s.daysOfWeek[7] {
+		s.daysOfWeek[0] = true
+	}
+	s.anyDayOfMonth = fields[2] == "*" || fields[2] == "?"
+	s.anyDayOfWeek = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+// Matches returns true if the schedule fires at the minute of the given time
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	dom := s.daysOfMonth[t.Day()]
+	dow := s.daysOfWeek[int(t.Weekday())]
+	// like cron if both days are restricted then either may match
+	if !s.anyDayOfMonth && !s.anyDayOfWeek {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// LastBefore returns the last time the schedule fired at or before the given time and after the given limit
+func (s *CronSchedule) LastBefore(t time.Time, limit time.Time) (time.Time, bool) {
+	for m := t.Truncate(time.Minute); m.After(limit); m = m.Add(-time.Minute) {
+		if s.Matches(m) {
+			return m, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseCronField(field string, min int, max int, names map[string]int) ([]bool, error) {
+	answer := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in cron field '%s'", field)
+			}
+			part = part[:i]
+		}
+		from, to := min, max
+		if part != "*" && part != "?" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			from, err = parseCronValue(bounds[0], min, max, names)
+			if err != nil {
+				return nil, err
+			}
+			to = from
+			if len(bounds) > 1 {
+				to, err = parseCronValue(bounds[1], min, max, names)
+				if err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				to = max
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid range '%s' in cron field '%s'", part, field)
+			}
+		}
+		for v := from; v <= to; v += step {
+			answer[v] = true
+		}
+	}
+	return answer, nil
+}
+
+func parseCronValue(text string, min int, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid cron value '%s' which should be between %d and %d", text, min, max)
+	}
+	return v, nil
+}
@@ -0,0 +1,173 @@
+package promotion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+// maxFreezeDuration the longest a recurring freeze may last which bounds the search for the start of the freeze
+const maxFreezeDuration = time.Hour * 24 * 31
+
+// FreezeOverrideTrailer the git trailer of the commit message of a promotion which overrides a freeze so that the
+// environment pipeline applies the promotion during the freeze
+const FreezeOverrideTrailer = "Freeze-Override-Reason"
+
+// ActiveFreeze is a freeze of an environment which is in effect
+type ActiveFreeze struct {
+	Environment string
+	Name        string
+	Reason      string
+	Start       time.Time
+	End         time.Time
+}
+
+// String returns a description of the freeze
+func (f *ActiveFreeze) String() string {
+	text := fmt.Sprintf("environment %s is frozen by %s until %s", f.Environment, f.Name, f.End.Format(time.RFC1123))
+	if f.Reason != "" {
+		text += ": " + f.Reason
+	}
+	return text
+}
+
+// ValidateFreeze returns an error if the freeze is neither a valid recurring freeze nor a valid ad-hoc freeze
+func ValidateFreeze(freeze *v1.PromotionFreeze) error {
+	if freeze.Schedule != "" {
+		_, _, err := recurringFreeze(freeze)
+		return err
+	}
+	if freeze.End == nil {
+		return fmt.Errorf("freeze %s must have either a schedule or an end time", freeze.Name)
+	}
+	if freeze.Start != nil && !freeze.Start.Before(freeze.End) {
+		return fmt.Errorf("freeze %s must start before it ends", freeze.Name)
+	}
+	return nil
+}
+
+// FindActiveFreeze returns the freeze of the environment in effect at the given time which ends last or nil if the
+// environment is not frozen
+func FindActiveFreeze(env *v1.Environment, now time.Time) (*ActiveFreeze, error) {
+	var answer *ActiveFreeze
+	for i := range env.Spec.Freezes {
+		freeze := &env.Spec.Freezes[i]
+		name := freeze.Name
+		if name == "" {
+			name = fmt.Sprintf("freeze-%d", i+1)
+		}
+		var start, end time.Time
+		if freeze.Schedule != "" {
+			schedule, duration, err := recurringFreeze(freeze)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid freeze %s of environment %s", name, env.Name)
+			}
+			location, err := freezeLocation(freeze)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid freeze %s of environment %s", name, env.Name)
+			}
+			local := now.In(location)
+			last, ok := schedule.LastBefore(local, local.Add(-duration))
+			if !ok {
+				continue
+			}
+			start, end = last, last.Add(duration)
+		} else {
+			if freeze.End == nil {
+				continue
+			}
+			end = freeze.End.Time
+			if freeze.Start != nil {
+				start = freeze.Start.Time
+			}
+			if now.Before(start) || !now.Before(end) {
+				continue
+			}
+		}
+		if answer == nil || end.After(answer.End) {
+			answer = &ActiveFreeze{
+				Environment: env.Name,
+				Name:        name,
+				Reason:      freeze.Reason,
+				Start:       start,
+				End:         end,
+			}
+		}
+	}
+	return answer, nil
+}
+
+func recurringFreeze(freeze *v1.PromotionFreeze) (*CronSchedule, time.Duration, error) {
+	schedule, err := ParseCronSchedule(freeze.Schedule)
+	if err != nil {
+		return nil, 0, err
+	}
+	if freeze.Duration == "" {
+		return nil, 0, fmt.Errorf("freeze %s has a schedule but no duration", freeze.Name)
+	}
+	duration, err := time.ParseDuration(freeze.Duration)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid duration %s", freeze.Duration)
+	}
+	if duration <= 0 || duration > maxFreezeDuration {
+		return nil, 0, fmt.Errorf("the duration %s of freeze %s must be positive and no longer than %s", freeze.Duration, freeze.Name, maxFreezeDuration)
+	}
+	_, err = freezeLocation(freeze)
+	return schedule, duration, err
+}
+
+func freezeLocation(freeze *v1.PromotionFreeze) (*time.Location, error) {
+	if freeze.TimeZone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(freeze.TimeZone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid time zone %s", freeze.TimeZone)
+	}
+	return location, nil
+}
+
+// ParseFreezeTime parses either a duration from now such as 48h, an RFC 3339 time, a local time such as
+// '2019-12-27 09:00' or a local date such as 2019-12-27
+func ParseFreezeTime(text string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(text); err == nil {
+		return now.Add(duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, text, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' which should be a duration such as 48h, an RFC 3339 time or a time such as '2006-01-02 15:04'", text)
+}
+
+// AddFreezeOverrideTrailer returns the commit message with a trailer recording the reason for overriding a freeze
+func AddFreezeOverrideTrailer(message string, reason string) string {
+	reason = strings.Join(strings.Fields(reason), " ")
+	if reason == "" || FindFreezeOverrideReason(message) != "" {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + FreezeOverrideTrailer + ": " + reason + "\n"
+}
+
+// FindFreezeOverrideReason returns the reason of the freeze override trailer in the given commit messages or an
+// empty string if the freeze is not overridden
+func FindFreezeOverrideReason(messages string) string {
+	prefix := strings.ToLower(FreezeOverrideTrailer) + ":"
+	for _, line := range strings.Split(messages, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(line), prefix) {
+			reason := strings.TrimSpace(line[len(prefix):])
+			if reason != "" {
+				return reason
+			}
+		}
+	}
+	return ""
+}
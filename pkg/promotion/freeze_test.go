@@ -0,0 +1,106 @@
+package promotion_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCronSchedule(t *testing.T) {
+	t.Parallel()
+	schedule, err := promotion.ParseCronSchedule("0 17 * * FRI")
+	require.NoError(t, err)
+	// October 18 2019 is a Friday
+	assert.True(t, schedule.Matches(time.Date(2019, time.October, 18, 17, 0, 0, 0, time.UTC)))
+	assert.False(t, schedule.Matches(time.Date(2019, time.October, 18, 17, 1, 0, 0, time.UTC)))
+	assert.False(t, schedule.Matches(time.Date(2019, time.October, 17, 17, 0, 0, 0, time.UTC)))
+
+	schedule, err = promotion.ParseCronSchedule("*/15 9-17 1,15 * *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Matches(time.Date(2019, time.October, 15, 9, 45, 0, 0, time.UTC)))
+	assert.False(t, schedule.Matches(time.Date(2019, time.October, 15, 9, 50, 0, 0, time.UTC)))
+	assert.False(t, schedule.Matches(time.Date(2019, time.October, 16, 9, 45, 0, 0, time.UTC)))
+
+	for _, expression := range []string{"0 17 * *", "60 * * * *", "0 0 * * FUNDAY", "0 5-2 * * *"} {
+		_, err = promotion.ParseCronSchedule(expression)
+		assert.Error(t, err, "expression %s", expression)
+	}
+}
+
+func TestFindActiveFreeze(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, time.October, 19, 12, 0, 0, 0, time.UTC)
+	start := metav1.NewTime(now.Add(-time.Hour))
+	end := metav1.NewTime(now.Add(time.Hour))
+	past := metav1.NewTime(now.Add(-time.Minute))
+
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "production"},
+		Spec: v1.EnvironmentSpec{
+			Freezes: []v1.PromotionFreeze{
+				{Name: "expired", End: &past},
+				{Name: "weekends", Schedule: "0 17 * * FRI", Duration: "64h", Reason: "no weekend releases"},
+				{Name: "outage", Start: &start, End: &end},
+			},
+		},
+	}
+
+	freeze, err := promotion.FindActiveFreeze(env, now)
+	require.NoError(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "weekends", freeze.Name)
+	assert.Equal(t, time.Date(2019, time.October, 18, 17, 0, 0, 0, time.UTC), freeze.Start)
+	assert.Equal(t, time.Date(2019, time.October, 21, 9, 0, 0, 0, time.UTC), freeze.End)
+	assert.Contains(t, freeze.String(), "no weekend releases")
+
+	// on Monday afternoon only the ad-hoc freeze applies
+	monday := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	env.Spec.Freezes[2].End = &metav1.Time{Time: monday.Add(time.Hour)}
+	freeze, err = promotion.FindActiveFreeze(env, monday)
+	require.NoError(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "outage", freeze.Name)
+
+	freeze, err = promotion.FindActiveFreeze(env, monday.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, freeze)
+
+	env.Spec.Freezes[1].Duration = ""
+	_, err = promotion.FindActiveFreeze(env, now)
+	assert.Error(t, err)
+}
+
+func TestParseFreezeTime(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, time.October, 19, 12, 0, 0, 0, time.UTC)
+	expected := map[string]time.Time{
+		"48h":                  now.Add(48 * time.Hour),
+		"2019-12-27T09:00:00Z": time.Date(2019, time.December, 27, 9, 0, 0, 0, time.UTC),
+		"2019-12-27 09:00":     time.Date(2019, time.December, 27, 9, 0, 0, 0, time.UTC),
+		"2019-12-27":           time.Date(2019, time.December, 27, 0, 0, 0, 0, time.UTC),
+	}
+	for text, value := range expected {
+		actual, err := promotion.ParseFreezeTime(text, now)
+		require.NoError(t, err, "parsing %s", text)
+		assert.True(t, value.Equal(actual), "parsing %s expected %s but was %s", text, value, actual)
+	}
+	_, err := promotion.ParseFreezeTime("next tuesday", now)
+	assert.Error(t, err)
+}
+
+func TestFreezeOverrideTrailer(t *testing.T) {
+	t.Parallel()
+	message := promotion.AddFreezeOverrideTrailer("chore: Promote myapp to version 1.0.0\n", "fix the\ncheckout outage")
+	assert.Equal(t, "chore: Promote myapp to version 1.0.0\n\nFreeze-Override-Reason: fix the checkout outage\n", message)
+	assert.Equal(t, message, promotion.AddFreezeOverrideTrailer(message, "another reason"))
+	assert.Equal(t, "fix the checkout outage", promotion.FindFreezeOverrideReason("Merge pull request #1\n\n"+message))
+
+	assert.Equal(t, "chore: no override", promotion.AddFreezeOverrideTrailer("chore: no override", " "))
+	assert.Equal(t, "", promotion.FindFreezeOverrideReason("chore: no override"))
+	assert.Equal(t, "", promotion.FindFreezeOverrideReason("freeze-override-reason:"))
+}
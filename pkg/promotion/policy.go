@@ -0,0 +1,220 @@
+package promotion
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultGateTimeout how long to wait for the gates to pass after the soak time if the policy has no timeout
+const DefaultGateTimeout = time.Minute * 30
+
+// GateContext is the state of a promotion which the gates of the PromotionPolicy of an environment are evaluated against
+type GateContext struct {
+	App     string
+	Version string
+	// PreviousEnvironment the environment the version was promoted to before this one
+	PreviousEnvironment string
+	// PreviousNamespace the namespace of the previous environment
+	PreviousNamespace string
+	// PreviousPromoted when the version was promoted to the previous environment or zero if there was no previous environment
+	PreviousPromoted time.Time
+	// Released when the pipeline which released the version started. The tests required for the first environment must
+	// have run after this
+	Released time.Time
+	// Activities the pipelines which may include the tests required by the policy
+	Activities []v1.PipelineActivity
+	// Metrics evaluates the metric gates of the policy
+	Metrics MetricsClient
+	Now     time.Time
+}
+
+// EvaluatePolicy evaluates each gate of the policy returning the results in the order of soak time, tests then metrics
+func EvaluatePolicy(policy *v1.PromotionPolicy, ctx *GateContext) ([]v1.PromotionGateResult, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	now := ctx.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	evaluated := metav1.NewTime(now)
+	var answer []v1.PromotionGateResult
+	add := func(name string, kind v1.PromotionGateKind, status v1.ActivityStatusType, message string) {
+		answer = append(answer, v1.PromotionGateResult{
+			Name:               name,
+			Kind:               kind,
+			Status:             status,
+			Message:            message,
+			EvaluatedTimestamp: &evaluated,
+		})
+	}
+
+	if policy.SoakTime != "" {
+		soak, err := time.ParseDuration(policy.SoakTime)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid soak time %s", policy.SoakTime)
+		}
+		name := "soak-time"
+		if ctx.PreviousPromoted.IsZero() {
+			add(name, v1.PromotionGateKindSoak, v1.ActivityStatusTypeSucceeded, "there is no previous environment")
+		} else {
+			elapsed := now.Sub(ctx.PreviousPromoted).Round(time.Second)
+			if elapsed >= soak {
+				add(name, v1.PromotionGateKindSoak, v1.ActivityStatusTypeSucceeded, fmt.Sprintf("running in %s for %s", ctx.PreviousEnvironment, elapsed))
+			} else {
+				add(name, v1.PromotionGateKindSoak, v1.ActivityStatusTypePending, fmt.Sprintf("running in %s for %s of %s", ctx.PreviousEnvironment, elapsed, soak))
+			}
+		}
+	}
+
+	for _, test := range policy.Tests {
+		status, message := evaluateTest(test, ctx)
+		add(test, v1.PromotionGateKindTest, status, message)
+	}
+
+	for i, metric := range policy.Metrics {
+		name := metric.Name
+		if name == "" {
+			name = fmt.Sprintf("metric-%d", i+1)
+		}
+		status, message, err := evaluateMetric(&metric, ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid metric gate %s", name)
+		}
+		add(name, v1.PromotionGateKindMetric, status, message)
+	}
+	return answer, nil
+}
+
+// evaluateTest finds the latest build of the test pipeline started after the version was promoted to the previous
+// environment, or after the version was released for the first environment, so that only test runs against the
+// version being promoted are used
+func evaluateTest(pipeline string, ctx *GateContext) (v1.ActivityStatusType, string) {
+	since := ctx.PreviousPromoted
+	if since.IsZero() {
+		since = ctx.Released
+	}
+	if since.IsZero() {
+		return v1.ActivityStatusTypePending, fmt.Sprintf("cannot find when version %s was released", ctx.Version)
+	}
+	var latest *v1.PipelineActivity
+	var latestStarted time.Time
+	for i := range ctx.Activities {
+		a := &ctx.Activities[i]
+		if !strings.EqualFold(a.Spec.Pipeline, pipeline) {
+			continue
+		}
+		started := a.CreationTimestamp.Time
+		if a.Spec.StartedTimestamp != nil {
+			started = a.Spec.StartedTimestamp.Time
+		}
+		if started.Before(since) {
+			continue
+		}
+		if latest == nil || started.After(latestStarted) {
+			latest = a
+			latestStarted = started
+		}
+	}
+	if latest == nil {
+		return v1.ActivityStatusTypePending, fmt.Sprintf("waiting for the pipeline to run against version %s", ctx.Version)
+	}
+	status := latest.Spec.Status
+	switch {
+	case status == v1.ActivityStatusTypeSucceeded:
+		return v1.ActivityStatusTypeSucceeded, fmt.Sprintf("build #%s succeeded", latest.Spec.Build)
+	case status.IsTerminated():
+		return v1.ActivityStatusTypeFailed, fmt.Sprintf("build #%s is %s", latest.Spec.Build, status)
+	default:
+		return v1.ActivityStatusTypePending, fmt.Sprintf("waiting for build #%s to complete", latest.Spec.Build)
+	}
+}
+
+// evaluateMetric returns an error if the gate is invalid. Failing to query the metric fails the gate
+func evaluateMetric(metric *v1.PromotionMetricGate, ctx *GateContext) (v1.ActivityStatusType, string, error) {
+	comparison := metric.Comparison
+	if comparison == "" {
+		comparison = "<="
+	}
+	compare, ok := comparisons[comparison]
+	if !ok {
+		return "", "", fmt.Errorf("unknown comparison %s", comparison)
+	}
+	if ctx.Metrics == nil {
+		return v1.ActivityStatusTypeFailed, "no Prometheus server is configured for the promotion policy", nil
+	}
+	query := os.Expand(metric.Query, func(name string) string {
+		switch name {
+		case "APP":
+			return ctx.App
+		case "VERSION":
+			return ctx.Version
+		case "NAMESPACE":
+			return ctx.PreviousNamespace
+		default:
+			return "$" + name
+		}
+	})
+	value, err := ctx.Metrics.Query(query)
+	if err != nil {
+		return v1.ActivityStatusTypeFailed, err.Error(), nil
+	}
+	message := fmt.Sprintf("%g %s %g", value, comparison, metric.Threshold)
+	if !compare(value, metric.Threshold) {
+		return v1.ActivityStatusTypeFailed, "expected " + message, nil
+	}
+	return v1.ActivityStatusTypeSucceeded, message, nil
+}
+
+var comparisons = map[string]func(value float64, threshold float64) bool{
+	"<":  func(v float64, t float64) bool { return v < t },
+	"<=": func(v float64, t float64) bool { return v <= t },
+	">":  func(v float64, t float64) bool { return v > t },
+	">=": func(v float64, t float64) bool { return v >= t },
+	"==": func(v float64, t float64) bool { return v == t },
+}
+
+// GatesPassed returns true if all of the gates succeeded
+func GatesPassed(results []v1.PromotionGateResult) bool {
+	for _, r := range results {
+		if r.Status != v1.ActivityStatusTypeSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyTimeout returns how long to wait for the gates of the policy to pass
+func PolicyTimeout(policy *v1.PromotionPolicy) (time.Duration, error) {
+	if policy.Timeout != "" {
+		timeout, err := time.ParseDuration(policy.Timeout)
+		if err != nil {
+			return timeout, errors.Wrapf(err, "invalid timeout %s", policy.Timeout)
+		}
+		return timeout, nil
+	}
+	timeout := DefaultGateTimeout
+	if policy.SoakTime != "" {
+		soak, err := time.ParseDuration(policy.SoakTime)
+		if err != nil {
+			return timeout, errors.Wrapf(err, "invalid soak time %s", policy.SoakTime)
+		}
+		timeout += soak
+	}
+	return timeout, nil
+}
+
+// GatesSummary returns a one line description of the status of each gate
+func GatesSummary(results []v1.PromotionGateResult) string {
+	var parts []string
+	for _, r := range results {
+		parts = append(parts, fmt.Sprintf("%s %s: %s", r.Name, r.Status, r.Message))
+	}
+	return strings.Join(parts, ", ")
+}
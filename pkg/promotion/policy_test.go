@@ -0,0 +1,161 @@
+package promotion_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newPrometheusStub returns a server which answers the queries with the given values
+func newPrometheusStub(t *testing.T, values map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		query := r.URL.Query().Get("query")
+		value, ok := values[query]
+		if !ok {
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1571097600,"%s"]}]}}`, value)
+	}))
+}
+
+func TestPrometheusClient(t *testing.T) {
+	t.Parallel()
+	server := newPrometheusStub(t, map[string]string{"up": "1"})
+	defer server.Close()
+
+	client := promotion.NewPrometheusClient(server.URL)
+	value, err := client.Query("up")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+
+	_, err = client.Query("missing")
+	assert.Error(t, err)
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	t.Parallel()
+	server := newPrometheusStub(t, map[string]string{
+		`sum(rate(http_errors{namespace="jx-staging",app="cheese"}[5m]))`: "0.5",
+		`avg(latency{app="cheese"})`:                                      "250",
+	})
+	defer server.Close()
+
+	now := time.Now()
+	promoted := now.Add(-20 * time.Minute)
+	started := metav1.NewTime(promoted.Add(time.Minute))
+	before := metav1.NewTime(promoted.Add(-time.Minute))
+	policy := &v1.PromotionPolicy{
+		SoakTime: "30m",
+		Tests:    []string{"jstrachan/cheese-bdd/master"},
+		Metrics: []v1.PromotionMetricGate{
+			{Name: "errors", Query: `sum(rate(http_errors{namespace="$NAMESPACE",app="$APP"}[5m]))`, Threshold: 1},
+			{Query: `avg(latency{app="$APP"})`, Comparison: "<", Threshold: 200},
+		},
+	}
+	ctx := &promotion.GateContext{
+		App:                 "cheese",
+		Version:             "1.0.1",
+		PreviousEnvironment: "staging",
+		PreviousNamespace:   "jx-staging",
+		PreviousPromoted:    promoted,
+		Activities: []v1.PipelineActivity{
+			{Spec: v1.PipelineActivitySpec{Pipeline: "jstrachan/cheese-bdd/master", Build: "1", StartedTimestamp: &before, Status: v1.ActivityStatusTypeFailed}},
+			{Spec: v1.PipelineActivitySpec{Pipeline: "jstrachan/cheese-bdd/master", Build: "2", StartedTimestamp: &started, Status: v1.ActivityStatusTypeRunning}},
+		},
+		Metrics: promotion.NewPrometheusClient(server.URL),
+		Now:     now,
+	}
+
+	results, err := promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.False(t, promotion.GatesPassed(results))
+
+	assert.Equal(t, v1.PromotionGateKindSoak, results[0].Kind)
+	assert.Equal(t, v1.ActivityStatusTypePending, results[0].Status)
+	assert.Equal(t, v1.PromotionGateKindTest, results[1].Kind)
+	assert.Equal(t, v1.ActivityStatusTypePending, results[1].Status, results[1].Message)
+	assert.Equal(t, "errors", results[2].Name)
+	assert.Equal(t, v1.ActivityStatusTypeSucceeded, results[2].Status, results[2].Message)
+	assert.Equal(t, "metric-2", results[3].Name)
+	assert.Equal(t, v1.ActivityStatusTypeFailed, results[3].Status, results[3].Message)
+
+	// once soaked and tested with a lower latency the gates pass
+	ctx.Now = now.Add(15 * time.Minute)
+	ctx.Activities[1].Spec.Status = v1.ActivityStatusTypeSucceeded
+	policy.Metrics[1].Threshold = 300
+	results, err = promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	assert.True(t, promotion.GatesPassed(results), promotion.GatesSummary(results))
+
+	// a failed test fails the gate
+	ctx.Activities[1].Spec.Status = v1.ActivityStatusTypeFailed
+	results, err = promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, v1.ActivityStatusTypeFailed, results[1].Status)
+
+	policy.Metrics[0].Comparison = "~"
+	_, err = promotion.EvaluatePolicy(policy, ctx)
+	assert.Error(t, err)
+}
+
+func TestEvaluatePolicyTestsForFirstEnvironment(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	released := now.Add(-10 * time.Minute)
+	old := metav1.NewTime(released.Add(-time.Hour))
+	policy := &v1.PromotionPolicy{
+		Tests: []string{"jstrachan/cheese-bdd/master"},
+	}
+	ctx := &promotion.GateContext{
+		App:     "cheese",
+		Version: "1.0.1",
+		Activities: []v1.PipelineActivity{
+			{Spec: v1.PipelineActivitySpec{Pipeline: "jstrachan/cheese-bdd/master", Build: "1", StartedTimestamp: &old, Status: v1.ActivityStatusTypeSucceeded}},
+		},
+		Now: now,
+	}
+
+	// without knowing when the version was released an old test run cannot pass the gate
+	results, err := promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, v1.ActivityStatusTypePending, results[0].Status, results[0].Message)
+
+	// a test run from before the version was released is for an older version
+	ctx.Released = released
+	results, err = promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, v1.ActivityStatusTypePending, results[0].Status, results[0].Message)
+
+	started := metav1.NewTime(released.Add(time.Minute))
+	ctx.Activities = append(ctx.Activities, v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{Pipeline: "jstrachan/cheese-bdd/master", Build: "2", StartedTimestamp: &started, Status: v1.ActivityStatusTypeSucceeded},
+	})
+	results, err = promotion.EvaluatePolicy(policy, ctx)
+	require.NoError(t, err)
+	assert.True(t, promotion.GatesPassed(results), promotion.GatesSummary(results))
+}
+
+func TestPolicyTimeout(t *testing.T) {
+	t.Parallel()
+	timeout, err := promotion.PolicyTimeout(&v1.PromotionPolicy{SoakTime: "1h"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour+promotion.DefaultGateTimeout, timeout)
+
+	timeout, err = promotion.PolicyTimeout(&v1.PromotionPolicy{SoakTime: "1h", Timeout: "2h"})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, timeout)
+
+	_, err = promotion.PolicyTimeout(&v1.PromotionPolicy{SoakTime: "a while"})
+	assert.Error(t, err)
+}
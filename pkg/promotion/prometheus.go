@@ -0,0 +1,98 @@
+package promotion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// MetricsClient evaluates a query which returns a single value
+type MetricsClient interface {
+	Query(query string) (float64, error)
+}
+
+// PrometheusClient evaluates instant queries using the HTTP API of a Prometheus server
+type PrometheusClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// NewPrometheusClient creates a client for the Prometheus server at the given URL
+func NewPrometheusClient(serverURL string) *PrometheusClient {
+	return &PrometheusClient{
+		URL:        serverURL,
+		HTTPClient: util.GetClientWithTimeout(time.Second * 30),
+	}
+}
+
+// Query evaluates the query returning the value of the scalar or the first sample of the vector it results in
+func (c *PrometheusClient) Query(query string) (float64, error) {
+	u := strings.TrimSuffix(c.URL, "/") + "/api/v1/query?query=" + url.QueryEscape(query)
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to query %s", c.URL)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read the response from %s", c.URL)
+	}
+	result := &prometheusResponse{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the response from %s with status %d", c.URL, resp.StatusCode)
+	}
+	if result.Status != "success" {
+		return 0, fmt.Errorf("query %s failed: %s", query, result.Error)
+	}
+
+	var value []interface{}
+	switch result.Data.ResultType {
+	case "scalar":
+		err = json.Unmarshal(result.Data.Result, &value)
+	case "vector":
+		var samples []prometheusSample
+		err = json.Unmarshal(result.Data.Result, &samples)
+		if err == nil {
+			if len(samples) == 0 {
+				return 0, fmt.Errorf("query %s returned no data", query)
+			}
+			value = samples[0].Value
+		}
+	default:
+		return 0, fmt.Errorf("query %s returned an unsupported result type %s", query, result.Data.ResultType)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the result of query %s", query)
+	}
+	if len(value) != 2 {
+		return 0, fmt.Errorf("query %s returned an invalid value %v", query, value)
+	}
+	text, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("query %s returned an invalid value %v", query, value)
+	}
+	return strconv.ParseFloat(text, 64)
+}
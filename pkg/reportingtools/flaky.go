@@ -0,0 +1,122 @@
+package reportingtools
+
+import (
+	"sort"
+	"time"
+)
+
+// TestRun is the set of test results reported by a single build
+type TestRun struct {
+	Activity   string
+	Owner      string
+	Repository string
+	Build      string
+	Commit     string
+	Started    time.Time
+	Results    []TestResult
+}
+
+// TestHistoryEntry is the result of a test in one build
+type TestHistoryEntry struct {
+	Activity string    `json:"activity"`
+	Build    string    `json:"build,omitempty"`
+	Commit   string    `json:"commit,omitempty"`
+	Started  time.Time `json:"started"`
+	Status   string    `json:"status"`
+}
+
+// TestHistory is the aggregated results of a test across builds. A test is flaky if it both passed and failed
+// when testing the same commit
+type TestHistory struct {
+	Owner        string             `json:"owner,omitempty"`
+	Repository   string             `json:"repository,omitempty"`
+	Name         string             `json:"name"`
+	Runs         int                `json:"runs"`
+	Failures     int                `json:"failures"`
+	FailureRate  float64            `json:"failureRate"`
+	FlakyCommits []string           `json:"flakyCommits,omitempty"`
+	History      []TestHistoryEntry `json:"history"`
+}
+
+// IsFlaky returns true if the result of the test flipped on the same commit
+func (h *TestHistory) IsFlaky() bool {
+	return len(h.FlakyCommits) > 0
+}
+
+// AnalyzeTestRuns aggregates the results of each test across the runs. Tests are identified by the repository of
+// the run as well as their name, so that tests with the same name in different repositories have separate histories.
+// Skipped results are kept in the history but do not count as runs. The most flaky tests are returned first, followed by the tests with the highest
+// failure rate.
+func AnalyzeTestRuns(runs []TestRun) []*TestHistory {
+	sorted := append([]TestRun{}, runs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+
+	histories := map[string]*TestHistory{}
+	// the statuses seen for each test and commit
+	commitStatuses := map[string]map[string]map[string]bool{}
+	for _, run := range sorted {
+		for i := range run.Results {
+			result := &run.Results[i]
+			name := result.FullName()
+			key := run.Owner + "/" + run.Repository + "/" + name
+			h := histories[key]
+			if h == nil {
+				h = &TestHistory{Owner: run.Owner, Repository: run.Repository, Name: name}
+				histories[key] = h
+				commitStatuses[key] = map[string]map[string]bool{}
+			}
+			h.History = append(h.History, TestHistoryEntry{
+				Activity: run.Activity,
+				Build:    run.Build,
+				Commit:   run.Commit,
+				Started:  run.Started,
+				Status:   result.Status,
+			})
+			if result.Status == TestStatusSkipped {
+				continue
+			}
+			h.Runs++
+			if result.Status == TestStatusFailed {
+				h.Failures++
+			}
+			if run.Commit != "" {
+				statuses := commitStatuses[key][run.Commit]
+				if statuses == nil {
+					statuses = map[string]bool{}
+					commitStatuses[key][run.Commit] = statuses
+				}
+				statuses[result.Status] = true
+			}
+		}
+	}
+
+	answer := make([]*TestHistory, 0, len(histories))
+	for key, h := range histories {
+		if h.Runs > 0 {
+			h.FailureRate = float64(h.Failures) / float64(h.Runs)
+		}
+		for commit, statuses := range commitStatuses[key] {
+			if statuses[TestStatusPassed] && statuses[TestStatusFailed] {
+				h.FlakyCommits = append(h.FlakyCommits, commit)
+			}
+		}
+		sort.Strings(h.FlakyCommits)
+		answer = append(answer, h)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		a, b := answer[i], answer[j]
+		if len(a.FlakyCommits) != len(b.FlakyCommits) {
+			return len(a.FlakyCommits) > len(b.FlakyCommits)
+		}
+		if a.FailureRate != b.FailureRate {
+			return a.FailureRate > b.FailureRate
+		}
+		if a.Owner+"/"+a.Repository != b.Owner+"/"+b.Repository {
+			return a.Owner+"/"+a.Repository < b.Owner+"/"+b.Repository
+		}
+		return a.Name < b.Name
+	})
+	return answer
+}
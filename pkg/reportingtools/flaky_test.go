@@ -0,0 +1,109 @@
+package reportingtools_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/reportingtools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const passingReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="cheese" tests="3">
+    <testcase classname="cheese.Edam" name="TestSlice" time="0.5"/>
+    <testcase classname="cheese.Edam" name="TestMelt" time="1.25"/>
+    <testcase classname="cheese.Edam" name="TestAge"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+
+const failingReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="cheese" tests="3" failures="1">
+  <testcase classname="cheese.Edam" name="TestSlice"/>
+  <testcase classname="cheese.Edam" name="TestMelt"><failure type="assert">too cold</failure></testcase>
+  <testcase classname="cheese.Edam" name="TestAge"><skipped/></testcase>
+</testsuite>`
+
+func TestParseJUnitReport(t *testing.T) {
+	results, err := reportingtools.ParseJUnitReport([]byte(passingReport))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "cheese.Edam.TestSlice", results[0].FullName())
+	assert.Equal(t, reportingtools.TestStatusPassed, results[0].Status)
+	assert.Equal(t, 1.25, results[1].Time)
+	assert.Equal(t, reportingtools.TestStatusSkipped, results[2].Status)
+
+	results, err = reportingtools.ParseJUnitReport([]byte(failingReport))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, reportingtools.TestStatusFailed, results[1].Status)
+
+	_, err = reportingtools.ParseJUnitReport([]byte(`<html></html>`))
+	assert.Error(t, err)
+}
+
+func TestAnalyzeTestRuns(t *testing.T) {
+	passing, err := reportingtools.ParseJUnitReport([]byte(passingReport))
+	require.NoError(t, err)
+	failing, err := reportingtools.ParseJUnitReport([]byte(failingReport))
+	require.NoError(t, err)
+
+	now := time.Now()
+	runs := []reportingtools.TestRun{
+		// a failure followed by a passing retry of the same commit is flaky
+		{Activity: "cheese-master-2", Build: "2", Commit: "abc", Started: now.Add(-2 * time.Hour), Results: failing},
+		{Activity: "cheese-master-1", Build: "1", Commit: "123", Started: now.Add(-3 * time.Hour), Results: passing},
+		{Activity: "cheese-master-3", Build: "3", Commit: "abc", Started: now.Add(-1 * time.Hour), Results: passing},
+		// a failure on a different commit is not flaky
+		{Activity: "cheese-master-4", Build: "4", Commit: "def", Started: now, Results: failing},
+	}
+
+	histories := reportingtools.AnalyzeTestRuns(runs)
+	require.Len(t, histories, 3)
+
+	melt := histories[0]
+	assert.Equal(t, "cheese.Edam.TestMelt", melt.Name)
+	assert.True(t, melt.IsFlaky())
+	assert.Equal(t, []string{"abc"}, melt.FlakyCommits)
+	assert.Equal(t, 4, melt.Runs)
+	assert.Equal(t, 2, melt.Failures)
+	assert.Equal(t, 0.5, melt.FailureRate)
+	require.Len(t, melt.History, 4)
+	assert.Equal(t, "1", melt.History[0].Build)
+	assert.Equal(t, "4", melt.History[3].Build)
+
+	for _, h := range histories[1:] {
+		assert.False(t, h.IsFlaky(), "test %s should not be flaky", h.Name)
+		assert.Equal(t, 0, h.Failures)
+	}
+	age := histories[1]
+	assert.Equal(t, "cheese.Edam.TestAge", age.Name)
+	assert.Equal(t, 0, age.Runs)
+	assert.Len(t, age.History, 4)
+}
+
+func TestAnalyzeTestRunsSeparatesRepositories(t *testing.T) {
+	passing, err := reportingtools.ParseJUnitReport([]byte(passingReport))
+	require.NoError(t, err)
+	failing, err := reportingtools.ParseJUnitReport([]byte(failingReport))
+	require.NoError(t, err)
+
+	now := time.Now()
+	runs := []reportingtools.TestRun{
+		// the same commit of different repositories passing and failing is not flaky
+		{Activity: "cheese-master-1", Owner: "jx", Repository: "cheese", Commit: "abc", Started: now.Add(-time.Hour), Results: failing},
+		{Activity: "wine-master-1", Owner: "jx", Repository: "wine", Commit: "abc", Started: now, Results: passing},
+	}
+
+	histories := reportingtools.AnalyzeTestRuns(runs)
+	require.Len(t, histories, 6)
+	for _, h := range histories {
+		assert.False(t, h.IsFlaky(), "test %s of %s should not be flaky", h.Name, h.Repository)
+		assert.Len(t, h.History, 1)
+	}
+	melt := histories[0]
+	assert.Equal(t, "cheese.Edam.TestMelt", melt.Name)
+	assert.Equal(t, "cheese", melt.Repository)
+	assert.Equal(t, 1, melt.Failures)
+}
@@ -0,0 +1,137 @@
+package reportingtools
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TestStatusPassed the test passed
+	TestStatusPassed = "Passed"
+	// TestStatusFailed the test failed or errored
+	TestStatusFailed = "Failed"
+	// TestStatusSkipped the test was skipped
+	TestStatusSkipped = "Skipped"
+)
+
+// TestResult is the result of a single test case in a JUnit report
+type TestResult struct {
+	Suite     string  `json:"suite,omitempty"`
+	Classname string  `json:"classname,omitempty"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Time      float64 `json:"time,omitempty"`
+}
+
+// FullName returns the name of the test qualified by its class name, or suite name if there is no class name
+func (r *TestResult) FullName() string {
+	prefix := r.Classname
+	if prefix == "" {
+		prefix = r.Suite
+	}
+	if prefix == "" {
+		return r.Name
+	}
+	return prefix + "." + r.Name
+}
+
+// TestSuites is the representation of the root of a *.junit.xml xml file
+type TestSuites struct {
+	XMLName    xml.Name    `xml:"testsuites"`
+	Text       string      `xml:",chardata"`
+	TestSuites []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite is the representation of a <testsuite> of a *.junit.xml xml file
+type TestSuite struct {
+	XMLName    xml.Name    `xml:"testsuite"`
+	Text       string      `xml:",chardata"`
+	Name       string      `xml:"name,attr"`
+	Tests      string      `xml:"tests,attr"`
+	Failures   string      `xml:"failures,attr"`
+	Errors     string      `xml:"errors,attr"`
+	Time       string      `xml:"time,attr"`
+	TestCase   []TestCase  `xml:"testcase"`
+	TestSuites []TestSuite `xml:"testsuite,omitempty"`
+}
+
+// TestCase is the representation of an individual test case within a TestSuite in a *.junit.xml xml file
+type TestCase struct {
+	XMLName   xml.Name `xml:"testcase"`
+	Text      string   `xml:",chardata"`
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Error     *Failure `xml:"error,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out"`
+}
+
+// Failure is the representation of a Failure that can be present in a TestCase within a TestSuite in a *.junit.xml xml file
+type Failure struct {
+	Text string `xml:",chardata"`
+	Type string `xml:"type,attr"`
+}
+
+// Skipped is the representation of a skipped TestCase within a TestSuite in a *.junit.xml xml file
+type Skipped struct {
+	Text    string `xml:",chardata"`
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// UnmarshalJUnitReport parses a JUnit XML report whose root element is either <testsuites> or a single <testsuite>,
+// returning the test suites it contains
+func UnmarshalJUnitReport(data []byte) ([]TestSuite, error) {
+	// trying to parse <testsuites></testsuites>
+	var testSuites TestSuites
+	err := xml.Unmarshal(data, &testSuites)
+	if err == nil {
+		return testSuites.TestSuites, nil
+	}
+	// If no <testsuites></testsuites>, trying to parse <testsuite></testsuite>
+	var testSuite TestSuite
+	err = xml.Unmarshal(data, &testSuite)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the JUnit report")
+	}
+	return []TestSuite{testSuite}, nil
+}
+
+// ParseJUnitReport parses the test results from a JUnit XML report whose root element is either
+// <testsuites> or a single <testsuite>
+func ParseJUnitReport(data []byte) ([]TestResult, error) {
+	suites, err := UnmarshalJUnitReport(data)
+	if err != nil {
+		return nil, err
+	}
+	var answer []TestResult
+	for i := range suites {
+		collectTestResults(&suites[i], &answer)
+	}
+	return answer, nil
+}
+
+func collectTestResults(suite *TestSuite, results *[]TestResult) {
+	for _, tc := range suite.TestCase {
+		status := TestStatusPassed
+		if tc.Failure != nil || tc.Error != nil {
+			status = TestStatusFailed
+		} else if tc.Skipped != nil {
+			status = TestStatusSkipped
+		}
+		t, _ := strconv.ParseFloat(tc.Time, 64)
+		*results = append(*results, TestResult{
+			Suite:     suite.Name,
+			Classname: tc.Classname,
+			Name:      tc.Name,
+			Status:    status,
+			Time:      t,
+		})
+	}
+	for i := range suite.TestSuites {
+		collectTestResults(&suite.TestSuites[i], results)
+	}
+}
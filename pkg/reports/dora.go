@@ -0,0 +1,180 @@
+package reports
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+)
+
+// Deployment is the promotion of a version of an app to an environment
+type Deployment struct {
+	App         string
+	Environment string
+	Version     string
+	Activity    string
+	Finished    time.Time
+	Failed      bool
+	// CommitTimes are the times of the commits released in this version
+	CommitTimes []time.Time
+}
+
+// DORAMetrics are the DevOps Research and Assessment metrics for deploying an app to an environment
+type DORAMetrics struct {
+	App                 string        `json:"app"`
+	Environment         string        `json:"environment"`
+	Deployments         int           `json:"deployments"`
+	FailedDeployments   int           `json:"failedDeployments"`
+	DeploymentsPerWeek  float64       `json:"deploymentsPerWeek"`
+	LeadTime            time.Duration `json:"leadTime"`
+	ChangeFailureRate   float64       `json:"changeFailureRate"`
+	MeanTimeToRecovery  time.Duration `json:"meanTimeToRecovery"`
+	Recoveries          int           `json:"recoveries"`
+	CommitsWithLeadTime int           `json:"commitsWithLeadTime"`
+}
+
+// DeploymentsFromActivities returns the deployments of the completed promote steps of the activities. The commits
+// released in each version are found from the Release of the app with the same version.
+func DeploymentsFromActivities(activities []v1.PipelineActivity, releases []v1.Release) []Deployment {
+	commitTimes := map[string][]time.Time{}
+	for i := range releases {
+		spec := &releases[i].Spec
+		var times []time.Time
+		for _, c := range spec.Commits {
+			if c.Timestamp != nil {
+				times = append(times, c.Timestamp.Time)
+			}
+		}
+		commitTimes[releaseKey(spec.GitOwner, spec.GitRepository, spec.Version)] = times
+	}
+
+	var answer []Deployment
+	for i := range activities {
+		a := &activities[i]
+		owner := a.RepositoryOwner()
+		app := a.RepositoryName()
+		version := a.Spec.Version
+		for _, step := range a.Spec.Steps {
+			promote := step.Promote
+			if promote == nil || promote.Environment == "" {
+				continue
+			}
+			// aborted promotions are usually superseded by a newer version so are not counted as failures
+			status := promote.Status
+			if status != v1.ActivityStatusTypeSucceeded && status != v1.ActivityStatusTypeFailed && status != v1.ActivityStatusTypeError {
+				continue
+			}
+			finished := promote.CompletedTimestamp
+			if finished == nil {
+				finished = promote.StartedTimestamp
+			}
+			if finished == nil {
+				continue
+			}
+			answer = append(answer, Deployment{
+				App:         app,
+				Environment: promote.Environment,
+				Version:     version,
+				Activity:    a.Name,
+				Finished:    finished.Time,
+				Failed:      status != v1.ActivityStatusTypeSucceeded,
+				CommitTimes: commitTimes[releaseKey(owner, app, version)],
+			})
+		}
+	}
+	return answer
+}
+
+func releaseKey(owner string, repository string, version string) string {
+	return strings.ToLower(owner + "/" + repository + "/" + strings.TrimPrefix(version, "v"))
+}
+
+// CalculateDORAMetrics calculates the metrics of each app and environment from the deployments which finished
+// in the given period:
+//
+// * the deployment frequency is the number of successful deployments per week
+// * the lead time for changes is the median time from a commit to the successful deployment which released it
+// * the change failure rate is the fraction of deployments which failed
+// * the mean time to recovery is the mean time from a failed deployment to the next successful one
+func CalculateDORAMetrics(deployments []Deployment, from time.Time, to time.Time) []*DORAMetrics {
+	grouped := map[string][]Deployment{}
+	for _, d := range deployments {
+		if d.Finished.Before(from) || d.Finished.After(to) {
+			continue
+		}
+		key := d.App + "/" + d.Environment
+		grouped[key] = append(grouped[key], d)
+	}
+
+	weeks := to.Sub(from).Hours() / (24 * 7)
+	var answer []*DORAMetrics
+	for _, group := range grouped {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Finished.Before(group[j].Finished)
+		})
+		m := &DORAMetrics{
+			App:         group[0].App,
+			Environment: group[0].Environment,
+		}
+		var leadTimes []time.Duration
+		var recoveryTotal time.Duration
+		var failedSince *time.Time
+		for i := range group {
+			d := &group[i]
+			if d.Failed {
+				m.FailedDeployments++
+				if failedSince == nil {
+					failedSince = &d.Finished
+				}
+				continue
+			}
+			m.Deployments++
+			if failedSince != nil {
+				recoveryTotal += d.Finished.Sub(*failedSince)
+				m.Recoveries++
+				failedSince = nil
+			}
+			for _, t := range d.CommitTimes {
+				if !t.After(d.Finished) {
+					leadTimes = append(leadTimes, d.Finished.Sub(t))
+				}
+			}
+		}
+		if weeks > 0 {
+			m.DeploymentsPerWeek = float64(m.Deployments) / weeks
+		}
+		total := m.Deployments + m.FailedDeployments
+		if total > 0 {
+			m.ChangeFailureRate = float64(m.FailedDeployments) / float64(total)
+		}
+		if m.Recoveries > 0 {
+			m.MeanTimeToRecovery = recoveryTotal / time.Duration(m.Recoveries)
+		}
+		m.CommitsWithLeadTime = len(leadTimes)
+		m.LeadTime = medianDuration(leadTimes)
+		answer = append(answer, m)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].App != answer[j].App {
+			return answer[i].App < answer[j].App
+		}
+		return answer[i].Environment < answer[j].Environment
+	})
+	return answer
+}
+
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
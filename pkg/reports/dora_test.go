@@ -0,0 +1,105 @@
+package reports_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentsFromActivities(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	committed := metav1.NewTime(now.Add(-3 * time.Hour))
+	finished := metav1.NewTime(now)
+
+	promote := func(env string, status v1.ActivityStatusType) v1.PipelineActivityStep {
+		return v1.PipelineActivityStep{
+			Kind: v1.ActivityStepKindTypePromote,
+			Promote: &v1.PromoteActivityStep{
+				CoreActivityStep: v1.CoreActivityStep{Status: status, CompletedTimestamp: &finished},
+				Environment:      env,
+			},
+		}
+	}
+	activities := []v1.PipelineActivity{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "jstrachan-cheese-master-1"},
+			Spec: v1.PipelineActivitySpec{
+				Pipeline: "jstrachan/cheese/master",
+				Version:  "1.0.1",
+				Steps: []v1.PipelineActivityStep{
+					promote("staging", v1.ActivityStatusTypeSucceeded),
+					promote("production", v1.ActivityStatusTypeFailed),
+					promote("test", v1.ActivityStatusTypeAborted),
+				},
+			},
+		},
+	}
+	releases := []v1.Release{
+		{
+			Spec: v1.ReleaseSpec{
+				GitOwner:      "jstrachan",
+				GitRepository: "cheese",
+				Version:       "v1.0.1",
+				Commits: []v1.CommitSummary{
+					{SHA: "abc", Timestamp: &committed},
+					{SHA: "def"},
+				},
+			},
+		},
+	}
+
+	deployments := reports.DeploymentsFromActivities(activities, releases)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, "cheese", deployments[0].App)
+	assert.Equal(t, "staging", deployments[0].Environment)
+	assert.False(t, deployments[0].Failed)
+	require.Len(t, deployments[0].CommitTimes, 1)
+	assert.True(t, committed.Time.Equal(deployments[0].CommitTimes[0]))
+	assert.Equal(t, "production", deployments[1].Environment)
+	assert.True(t, deployments[1].Failed)
+}
+
+func TestCalculateDORAMetrics(t *testing.T) {
+	t.Parallel()
+	to := time.Date(2019, time.October, 15, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-14 * 24 * time.Hour)
+	at := func(hours int) time.Time {
+		return from.Add(time.Duration(hours) * time.Hour)
+	}
+
+	deployments := []reports.Deployment{
+		{App: "cheese", Environment: "production", Finished: at(10), CommitTimes: []time.Time{at(8), at(9)}},
+		{App: "cheese", Environment: "production", Finished: at(20), Failed: true},
+		{App: "cheese", Environment: "production", Finished: at(22), Failed: true},
+		{App: "cheese", Environment: "production", Finished: at(26), CommitTimes: []time.Time{at(23)}},
+		{App: "cheese", Environment: "staging", Finished: at(5)},
+		// outside of the period
+		{App: "cheese", Environment: "production", Finished: from.Add(-time.Hour), Failed: true},
+	}
+
+	metrics := reports.CalculateDORAMetrics(deployments, from, to)
+	require.Len(t, metrics, 2)
+
+	production := metrics[0]
+	assert.Equal(t, "production", production.Environment)
+	assert.Equal(t, 2, production.Deployments)
+	assert.Equal(t, 2, production.FailedDeployments)
+	assert.Equal(t, 1.0, production.DeploymentsPerWeek)
+	assert.Equal(t, 0.5, production.ChangeFailureRate)
+	assert.Equal(t, 1, production.Recoveries)
+	assert.Equal(t, 6*time.Hour, production.MeanTimeToRecovery)
+	assert.Equal(t, 3, production.CommitsWithLeadTime)
+	assert.Equal(t, 2*time.Hour, production.LeadTime)
+
+	staging := metrics[1]
+	assert.Equal(t, "staging", staging.Environment)
+	assert.Equal(t, 1, staging.Deployments)
+	assert.Equal(t, 0.0, staging.ChangeFailureRate)
+	assert.Equal(t, time.Duration(0), staging.LeadTime)
+}
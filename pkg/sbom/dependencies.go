@@ -0,0 +1,323 @@
+package sbom
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	npmLockFile   = "package-lock.json"
+	npmModulesDir = "node_modules/"
+)
+
+// skipDirs the directories which are not searched for dependencies
+var skipDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true, "target": true}
+
+type npmLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Dev     bool   `json:"dev"`
+	Link    bool   `json:"link"`
+}
+
+type npmLockDependency struct {
+	Version      string                        `json:"version"`
+	Dev          bool                          `json:"dev"`
+	Dependencies map[string]*npmLockDependency `json:"dependencies"`
+}
+
+type npmLock struct {
+	// Packages the packages of lockfile version 2 and above keyed by their path such as node_modules/a/node_modules/b
+	Packages map[string]*npmLockPackage `json:"packages"`
+	// Dependencies the tree of dependencies of lockfile version 1 which is only read when there are no packages
+	Dependencies map[string]*npmLockDependency `json:"dependencies"`
+}
+
+// FindComponents returns the resolved versions of the go modules, maven dependencies and npm packages of the
+// projects in the directory including their transitive dependencies.
+//
+// Go modules are listed with 'go list -m all' falling back to the highest version of each module in go.sum when the
+// go tool cannot list them, maven dependencies are listed with 'mvn dependency:list' and npm packages are read from
+// package-lock.json. Development and test dependencies are not included as they are not part of the release
+func FindComponents(dir string) ([]Component, error) {
+	var answer []Component
+	var mavenDirs []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		source, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		var components []Component
+		switch info.Name() {
+		case "go.mod":
+			components, err = goComponents(filepath.Dir(path), source)
+		case "pom.xml":
+			projectDir := filepath.Dir(path)
+			for _, d := range mavenDirs {
+				if strings.HasPrefix(projectDir, d+string(filepath.Separator)) {
+					// the modules of a maven project are listed with the project
+					return nil
+				}
+			}
+			mavenDirs = append(mavenDirs, projectDir)
+			components, err = mavenComponents(projectDir, source)
+		case npmLockFile:
+			components, err = npmComponents(path, source)
+		case "package.json":
+			exists, err := util.FileExists(filepath.Join(filepath.Dir(path), npmLockFile))
+			if err != nil {
+				return err
+			}
+			if !exists {
+				log.Logger().Warnf("skipping the npm packages of %s as it has no %s so their versions are not resolved", source, npmLockFile)
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the dependencies of %s", source)
+		}
+		answer = append(answer, components...)
+		return nil
+	})
+	return answer, err
+}
+
+// goComponents returns the modules of the go module in the directory using 'go list -m all' falling back to go.sum
+func goComponents(dir string, source string) ([]Component, error) {
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "go",
+		Args: []string{"list", "-mod=mod", "-m", "all"},
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err == nil {
+		return ParseGoModuleList(out, source), nil
+	}
+	sumFile := filepath.Join(dir, "go.sum")
+	data, readErr := ioutil.ReadFile(sumFile)
+	if os.IsNotExist(readErr) {
+		return nil, errors.Wrap(err, "failed to list the go modules")
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	log.Logger().Warnf("failed to list the go modules of %s so using the highest version of each module in go.sum: %s", source, err.Error())
+	return ParseGoSum(string(data), source), nil
+}
+
+// ParseGoModuleList parses the output of 'go list -m all' skipping the main module and modules replaced by a local
+// directory. Modules replaced by another module are listed as the replacement
+func ParseGoModuleList(text string, source string) []Component {
+	var answer []Component
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if i := util.StringArrayIndex(fields, "=>"); i >= 0 {
+			fields = fields[i+1:]
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		answer = append(answer, goComponent(fields[0], fields[1], source))
+	}
+	return answer
+}
+
+// ParseGoSum parses a go.sum file returning the highest version of each module which is the version selected by the
+// go tool as long as the file is tidy
+func ParseGoSum(text string, source string) []Component {
+	versions := map[string]*semver.Version{}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		v, err := semver.NewVersion(strings.TrimSuffix(fields[1], "/go.mod"))
+		if err != nil {
+			continue
+		}
+		current := versions[fields[0]]
+		if current == nil || v.GreaterThan(current) {
+			versions[fields[0]] = v
+		}
+	}
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var answer []Component
+	for _, name := range names {
+		answer = append(answer, goComponent(name, "v"+versions[name].String(), source))
+	}
+	return answer
+}
+
+// mavenComponents returns the compile and runtime dependencies of the maven project and its modules using
+// 'mvn dependency:list'
+func mavenComponents(dir string, source string) ([]Component, error) {
+	tmpDir, err := ioutil.TempDir("", "jx-sbom-maven-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	outputFile := filepath.Join(tmpDir, "dependencies.txt")
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "mvn",
+		Args: []string{"-B", "-q", "dependency:list", "-DincludeScope=runtime", "-DoutputFile=" + outputFile, "-DappendOutput=true"},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the maven dependencies")
+	}
+	data, err := ioutil.ReadFile(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMavenDependencyList(string(data), source), nil
+}
+
+// ParseMavenDependencyList parses the output of 'mvn dependency:list' whose dependencies are in the format
+// groupId:artifactId:type[:classifier]:version:scope
+func ParseMavenDependencyList(text string, source string) []Component {
+	var answer []Component
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// newer versions of the plugin append the java module name and mark optional dependencies
+		if i := strings.Index(line, " "); i > 0 {
+			line = line[:i]
+		}
+		parts := strings.Split(line, ":")
+		classifier := ""
+		switch len(parts) {
+		case 5:
+		case 6:
+			classifier = parts[3]
+			parts = append(parts[:3], parts[4:]...)
+		default:
+			continue
+		}
+		c := Component{
+			Type:    ComponentTypeLibrary,
+			Group:   parts[0],
+			Name:    parts[1],
+			Version: parts[3],
+			Source:  source,
+		}
+		c.PURL = fmt.Sprintf("pkg:maven/%s/%s@%s", escape(c.Group), escape(c.Name), escape(c.Version))
+		qualifiers := url.Values{}
+		if classifier != "" {
+			qualifiers.Set("classifier", classifier)
+		}
+		if parts[2] != "jar" {
+			qualifiers.Set("type", parts[2])
+		}
+		if len(qualifiers) > 0 {
+			c.PURL += "?" + qualifiers.Encode()
+		}
+		answer = append(answer, c)
+	}
+	return answer
+}
+
+// npmComponents returns the packages of a package-lock.json file
+func npmComponents(path string, source string) ([]Component, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNpmLockFile(data, source)
+}
+
+// ParseNpmLockFile parses a package-lock.json file returning the installed packages other than development
+// dependencies and linked local packages
+func ParseNpmLockFile(data []byte, source string) ([]Component, error) {
+	lock := &npmLock{}
+	err := json.Unmarshal(data, lock)
+	if err != nil {
+		return nil, err
+	}
+	var answer []Component
+	if len(lock.Packages) > 0 {
+		for path, p := range lock.Packages {
+			i := strings.LastIndex(path, npmModulesDir)
+			if i < 0 || p == nil || p.Dev || p.Link || p.Version == "" {
+				continue
+			}
+			name := p.Name
+			if name == "" {
+				name = path[i+len(npmModulesDir):]
+			}
+			answer = append(answer, npmComponent(name, p.Version, source))
+		}
+	} else {
+		answer = npmDependencyTree(lock.Dependencies, source)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].PURL < answer[j].PURL
+	})
+	return answer, nil
+}
+
+// npmDependencyTree returns the packages of the nested dependencies of a version 1 lockfile
+func npmDependencyTree(dependencies map[string]*npmLockDependency, source string) []Component {
+	var answer []Component
+	for name, d := range dependencies {
+		if d == nil || d.Dev || d.Version == "" || strings.Contains(d.Version, ":") {
+			continue
+		}
+		answer = append(answer, npmComponent(name, d.Version, source))
+		answer = append(answer, npmDependencyTree(d.Dependencies, source)...)
+	}
+	return answer
+}
+
+func goComponent(name string, version string, source string) Component {
+	return Component{
+		Type:    ComponentTypeLibrary,
+		Name:    name,
+		Version: version,
+		PURL:    fmt.Sprintf("pkg:golang/%s@%s", escapePath(name), escape(version)),
+		Source:  source,
+	}
+}
+
+func npmComponent(name string, version string, source string) Component {
+	c := Component{
+		Type:    ComponentTypeLibrary,
+		Name:    name,
+		Version: version,
+		Source:  source,
+	}
+	if strings.HasPrefix(name, "@") && strings.Contains(name, "/") {
+		parts := strings.SplitN(name, "/", 2)
+		c.Group, c.Name = parts[0], parts[1]
+		c.PURL = fmt.Sprintf("pkg:npm/%s/%s@%s", escape(c.Group), escape(c.Name), escape(version))
+	} else {
+		c.PURL = fmt.Sprintf("pkg:npm/%s@%s", escape(name), escape(version))
+	}
+	return c
+}
@@ -0,0 +1,318 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jenkins-x/jx/pkg/cve"
+)
+
+const (
+	// FormatCycloneDX the CycloneDX JSON format
+	FormatCycloneDX = "cyclonedx"
+
+	// FormatSPDX the SPDX JSON format
+	FormatSPDX = "spdx"
+
+	// ComponentTypeApplication the type of the component the bill of materials describes
+	ComponentTypeApplication = "application"
+
+	// ComponentTypeLibrary the type of a dependency or an operating system package
+	ComponentTypeLibrary = "library"
+
+	cycloneDXSpecVersion = "1.2"
+	spdxVersion          = "SPDX-2.2"
+	spdxNoAssertion      = "NOASSERTION"
+	spdxDocumentID       = "SPDXRef-DOCUMENT"
+	spdxApplicationID    = "SPDXRef-Application"
+	spdxNamespacePrefix  = "https://jenkins-x.io/spdx/"
+	toolVendor           = "jenkins-x"
+	toolName             = "jx"
+)
+
+// Formats the supported formats
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// Component a library, module or package included in a build
+type Component struct {
+	Type    string `json:"type"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// PURL the package URL which uniquely identifies the component
+	PURL string `json:"purl"`
+	// Source the manifest file or image the component was found in
+	Source string `json:"-"`
+}
+
+// BOM the software bill of materials of a version of an application
+type BOM struct {
+	Name         string
+	Version      string
+	Timestamp    time.Time
+	SerialNumber string
+	ToolVersion  string
+	Components   []Component
+}
+
+// NewBOM creates an empty bill of materials for the version of the application
+func NewBOM(name string, version string, toolVersion string) *BOM {
+	return &BOM{
+		Name:         name,
+		Version:      version,
+		Timestamp:    time.Now().UTC(),
+		SerialNumber: uuid.New().String(),
+		ToolVersion:  toolVersion,
+	}
+}
+
+// AddComponents adds the components to the bill of materials ignoring any it already contains
+func (b *BOM) AddComponents(components ...Component) {
+	existing := map[string]bool{}
+	for _, c := range b.Components {
+		existing[c.PURL] = true
+	}
+	for _, c := range components {
+		if existing[c.PURL] {
+			continue
+		}
+		existing[c.PURL] = true
+		b.Components = append(b.Components, c)
+	}
+	sort.Slice(b.Components, func(i, j int) bool {
+		return b.Components[i].PURL < b.Components[j].PURL
+	})
+}
+
+// FileName returns the file name of the document of the bill of materials in the format
+func (b *BOM) FileName(format string) string {
+	extension := "cdx.json"
+	if format == FormatSPDX {
+		extension = "spdx.json"
+	}
+	return fmt.Sprintf("%s-%s.%s", b.Name, b.Version, extension)
+}
+
+// Document returns the document of the bill of materials in the format
+func (b *BOM) Document(format string) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return b.CycloneDX()
+	case FormatSPDX:
+		return b.SPDX()
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %s. Supported formats are: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ImageComponents returns the operating system packages installed in the image
+func ImageComponents(contents *cve.ImageContents, image string) []Component {
+	purlType := "deb"
+	if contents.Distro == "alpine" {
+		purlType = "apk"
+	}
+	namespace := contents.Distro
+	if namespace == "" {
+		namespace = "unknown"
+	}
+	var answer []Component
+	for _, p := range contents.Packages {
+		qualifiers := url.Values{}
+		if contents.Distro != "" {
+			qualifiers.Set("distro", strings.TrimSuffix(contents.Distro+"-"+contents.DistroVersion, "-"))
+		}
+		if p.Source != "" && p.Source != p.Name {
+			qualifiers.Set("upstream", p.Source)
+		}
+		purl := fmt.Sprintf("pkg:%s/%s/%s@%s", purlType, escape(namespace), escape(p.Name), escape(p.Version))
+		if len(qualifiers) > 0 {
+			purl += "?" + qualifiers.Encode()
+		}
+		answer = append(answer, Component{
+			Type:    ComponentTypeLibrary,
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    purl,
+			Source:  image,
+		})
+	}
+	return answer
+}
+
+// escape percent encodes a segment of a package URL
+func escape(text string) string {
+	return strings.Replace(url.PathEscape(text), "@", "%40", -1)
+}
+
+// escapePath percent encodes each segment of a path in a package URL such as a go module path
+func escapePath(text string) string {
+	segments := strings.Split(text, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+type cycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Type    string `json:"type"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// CycloneDX returns the bill of materials as a CycloneDX JSON document
+func (b *BOM) CycloneDX() ([]byte, error) {
+	application := cycloneDXComponent{
+		Type:    ComponentTypeApplication,
+		Name:    b.Name,
+		Version: b.Version,
+	}
+	doc := cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + b.SerialNumber,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: b.Timestamp.UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Vendor: toolVendor, Name: toolName, Version: b.ToolVersion}},
+			Component: application,
+		},
+		Components: []cycloneDXComponent{},
+	}
+	for _, c := range b.Components {
+		doc.Components = append(doc.Components, cycloneDXComponent{
+			BOMRef:  c.PURL,
+			Type:    c.Type,
+			Group:   c.Group,
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX returns the bill of materials as an SPDX JSON document
+func (b *BOM) SPDX() ([]byte, error) {
+	creator := "Tool: " + toolName
+	if b.ToolVersion != "" {
+		creator += "-" + b.ToolVersion
+	}
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              fmt.Sprintf("%s-%s", b.Name, b.Version),
+		DocumentNamespace: fmt.Sprintf("%s%s-%s-%s", spdxNamespacePrefix, b.Name, b.Version, b.SerialNumber),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Timestamp.UTC().Format(time.RFC3339),
+			Creators: []string{"Organization: " + toolVendor, creator},
+		},
+		DocumentDescribes: []string{spdxApplicationID},
+		Packages:          []spdxPackage{newSPDXPackage(spdxApplicationID, b.Name, b.Version, "")},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxApplicationID},
+		},
+	}
+	for i, c := range b.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		name := c.Name
+		if c.Group != "" {
+			name = c.Group + "/" + c.Name
+		}
+		doc.Packages = append(doc.Packages, newSPDXPackage(id, name, c.Version, c.PURL))
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxApplicationID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func newSPDXPackage(id string, name string, version string, purl string) spdxPackage {
+	answer := spdxPackage{
+		SPDXID:           id,
+		Name:             name,
+		VersionInfo:      version,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+	}
+	if purl != "" {
+		answer.ExternalRefs = []spdxExternalRef{
+			{ReferenceCategory: "PACKAGE_MANAGER", ReferenceType: "purl", ReferenceLocator: purl},
+		}
+	}
+	return answer
+}
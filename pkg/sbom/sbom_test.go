@@ -0,0 +1,163 @@
+package sbom_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindComponents(t *testing.T) {
+	t.Parallel()
+	components, err := sbom.FindComponents(filepath.Join("test_data", "project"))
+	require.NoError(t, err)
+
+	actual := map[string]string{}
+	for _, c := range components {
+		actual[c.PURL] = c.Source
+	}
+	frontend := filepath.Join("frontend", "package-lock.json")
+	legacy := filepath.Join("legacy", "package-lock.json")
+	assert.Equal(t, map[string]string{
+		"pkg:npm/%40angular/core@8.2.14": frontend,
+		"pkg:npm/tslib@1.10.0":           frontend,
+		"pkg:npm/lodash@4.17.15":         frontend,
+		"pkg:npm/string-width@4.2.3":     frontend,
+		"pkg:npm/express@4.17.1":         legacy,
+		"pkg:npm/debug@2.6.9":            legacy,
+	}, actual)
+}
+
+func TestParseGoModules(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "go-list.txt"))
+	require.NoError(t, err)
+	components := sbom.ParseGoModuleList(string(data), "go.mod")
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/pkg/errors@v0.8.1",
+		"pkg:golang/github.com/stretchr/testify@v1.4.0",
+		"pkg:golang/golang.org/x/net@v0.0.0-20190620200207-3b0461eec859",
+	}, purls(components))
+
+	data, err = ioutil.ReadFile(filepath.Join("test_data", "go.sum"))
+	require.NoError(t, err)
+	components = sbom.ParseGoSum(string(data), "go.mod")
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/davecgh/go-spew@v1.1.1",
+		"pkg:golang/github.com/pkg/errors@v0.8.1",
+	}, purls(components))
+}
+
+func TestParseMavenDependencyList(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "maven-dependency-list.txt"))
+	require.NoError(t, err)
+	components := sbom.ParseMavenDependencyList(string(data), "pom.xml")
+	assert.Equal(t, []string{
+		"pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.9.9.3",
+		"pkg:maven/com.fasterxml.jackson.core/jackson-annotations@2.9.0",
+		"pkg:maven/io.netty/netty-transport-native-epoll@4.1.42.Final?classifier=linux-x86_64",
+		"pkg:maven/org.postgresql/postgresql@42.2.8",
+	}, purls(components))
+	assert.Equal(t, "io.netty", components[2].Group)
+	assert.Equal(t, "netty-transport-native-epoll", components[2].Name)
+	assert.Equal(t, "4.1.42.Final", components[2].Version)
+}
+
+func purls(components []sbom.Component) []string {
+	var answer []string
+	for _, c := range components {
+		answer = append(answer, c.PURL)
+	}
+	return answer
+}
+
+func TestImageComponents(t *testing.T) {
+	t.Parallel()
+	contents := &cve.ImageContents{
+		Distro:        "debian",
+		DistroVersion: "9",
+		Packages: []cve.Package{
+			{Name: "libssl1.1", Version: "1.1.0j-1~deb9u1", Source: "openssl"},
+			{Name: "curl", Version: "7.52.1-5+deb9u9"},
+		},
+	}
+	components := sbom.ImageComponents(contents, "gcr.io/myorg/myapp:0.0.1")
+	require.Len(t, components, 2)
+	assert.Equal(t, "pkg:deb/debian/libssl1.1@1.1.0j-1~deb9u1?distro=debian-9&upstream=openssl", components[0].PURL)
+	assert.Equal(t, "pkg:deb/debian/curl@7.52.1-5+deb9u9?distro=debian-9", components[1].PURL)
+}
+
+func TestDocuments(t *testing.T) {
+	t.Parallel()
+	bom := sbom.NewBOM("myapp", "0.0.1", "2.0.1")
+	bom.Timestamp = time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	bom.SerialNumber = "3e671687-395b-41f5-a30f-a58921a69b79"
+	lodash := sbom.Component{Type: sbom.ComponentTypeLibrary, Name: "lodash", Version: "4.17.15", PURL: "pkg:npm/lodash@4.17.15"}
+	errs := sbom.Component{Type: sbom.ComponentTypeLibrary, Name: "github.com/pkg/errors", Version: "v0.8.1", PURL: "pkg:golang/github.com/pkg/errors@v0.8.1"}
+	bom.AddComponents(lodash, errs)
+	bom.AddComponents(lodash)
+	assert.Equal(t, []sbom.Component{errs, lodash}, bom.Components)
+
+	assert.Equal(t, "myapp-0.0.1.cdx.json", bom.FileName(sbom.FormatCycloneDX))
+	assert.Equal(t, "myapp-0.0.1.spdx.json", bom.FileName(sbom.FormatSPDX))
+
+	data, err := bom.Document(sbom.FormatCycloneDX)
+	require.NoError(t, err)
+	cdx := struct {
+		BOMFormat    string `json:"bomFormat"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Timestamp string `json:"timestamp"`
+			Component struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			PURL string `json:"purl"`
+		} `json:"components"`
+	}{}
+	require.NoError(t, json.Unmarshal(data, &cdx))
+	assert.Equal(t, "CycloneDX", cdx.BOMFormat)
+	assert.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", cdx.SerialNumber)
+	assert.Equal(t, "2019-10-01T12:00:00Z", cdx.Metadata.Timestamp)
+	assert.Equal(t, "application", cdx.Metadata.Component.Type)
+	assert.Equal(t, "myapp", cdx.Metadata.Component.Name)
+	require.Len(t, cdx.Components, 2)
+	assert.Equal(t, errs.PURL, cdx.Components[0].PURL)
+
+	data, err = bom.Document(sbom.FormatSPDX)
+	require.NoError(t, err)
+	spdx := struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			ExternalRefs []struct {
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			RelationshipType string `json:"relationshipType"`
+		} `json:"relationships"`
+	}{}
+	require.NoError(t, json.Unmarshal(data, &spdx))
+	assert.Equal(t, "SPDX-2.2", spdx.SPDXVersion)
+	require.Len(t, spdx.Packages, 3)
+	assert.Equal(t, "SPDXRef-Application", spdx.Packages[0].SPDXID)
+	assert.Equal(t, "lodash", spdx.Packages[2].Name)
+	assert.Equal(t, lodash.PURL, spdx.Packages[2].ExternalRefs[0].ReferenceLocator)
+	require.Len(t, spdx.Relationships, 3)
+	assert.Equal(t, "DESCRIBES", spdx.Relationships[0].RelationshipType)
+	assert.Equal(t, "CONTAINS", spdx.Relationships[1].RelationshipType)
+
+	_, err = bom.Document("xml")
+	assert.Error(t, err)
+}
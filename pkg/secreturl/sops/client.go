@@ -0,0 +1,256 @@
+package sops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/secreturl"
+	"github.com/jenkins-x/jx/pkg/secreturl/localvault"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Scheme the scheme of the URIs of secrets encrypted with sops such as sops:path:key
+	Scheme = "sops:"
+
+	// DefaultDir the default directory of the encrypted secrets relative to the requirements file
+	DefaultDir = "secrets"
+
+	binaryName = "sops"
+)
+
+var (
+	sopsURIRegex  = regexp.MustCompile(`:[\s"]*sops:[-_\w\/:]*`)
+	localURIRegex = regexp.MustCompile(`:[\s"]*local:[-_\w\/:]*`)
+)
+
+// Runner runs the sops binary
+type Runner interface {
+	// Run runs sops in the directory with the arguments returning its standard output
+	Run(dir string, args ...string) (string, error)
+}
+
+// FileSystemClient a client which stores each secret as a YAML file encrypted with sops so that the secrets can be
+// kept in a git repository. The files can be edited with 'sops <file>' using the same keys
+type FileSystemClient struct {
+	Dir string
+
+	// AgeRecipients the age public keys to encrypt new secrets with
+	AgeRecipients []string
+
+	// PGPFingerprints the fingerprints of the PGP keys to encrypt new secrets with
+	PGPFingerprints []string
+
+	// ConfigFile the sops configuration file. If there are no recipients the creation rules of the configuration are
+	// used which defaults to the .sops.yaml file in the directory or its parents
+	ConfigFile string
+
+	Runner Runner
+
+	// decrypted the decrypted secrets by file name so that each file is only decrypted once by the client
+	decrypted map[string]map[string]interface{}
+	// keysImported is true once the PGP keys mounted in a pipeline have been imported
+	keysImported bool
+}
+
+// NewFileSystemClient creates a new client for the secrets encrypted with sops in the directory
+func NewFileSystemClient(dir string, ageRecipients []string, pgpFingerprints []string) secreturl.Client {
+	return &FileSystemClient{
+		Dir:             dir,
+		AgeRecipients:   ageRecipients,
+		PGPFingerprints: pgpFingerprints,
+		Runner:          binaryRunner{},
+	}
+}
+
+// NewClientForRequirements creates a new client for the secrets configured in the requirements which are in a
+// directory relative to the requirements file
+func NewClientForRequirements(requirements *config.RequirementsConfig, requirementsFileName string) secreturl.Client {
+	sopsConfig := requirements.Sops
+	dir := sopsConfig.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(requirementsFileName), dir)
+	}
+	return NewFileSystemClient(dir, sopsConfig.AgeRecipients, sopsConfig.PGPFingerprints)
+}
+
+// Read reads and decrypts a named secret
+func (c *FileSystemClient) Read(secretName string) (map[string]interface{}, error) {
+	name := c.fileName(secretName)
+	exists, err := util.FileExists(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", name)
+	}
+	if !exists {
+		parts := strings.Split(secretName, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("sops secret file does not exist: %s", name)
+		}
+		// secrets will be stored in this path when we have no way to get the cluster name
+		name = c.fileName(filepath.Join(localvault.CanonicalClusterPath, parts[1]))
+		exists, err = util.FileExists(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check if file exists %s", name)
+		}
+		if !exists {
+			return nil, fmt.Errorf("sops secret file does not exist: %s", c.fileName(secretName))
+		}
+	}
+	answer := c.decrypted[name]
+	if answer == nil {
+		answer, err = c.decrypt(name)
+		if err != nil {
+			return nil, err
+		}
+		if c.decrypted == nil {
+			c.decrypted = map[string]map[string]interface{}{}
+		}
+		c.decrypted[name] = answer
+	}
+	// return a copy so that callers cannot change the cached secret
+	secret := make(map[string]interface{}, len(answer))
+	for k, v := range answer {
+		secret[k] = v
+	}
+	return secret, nil
+}
+
+func (c *FileSystemClient) decrypt(name string) (map[string]interface{}, error) {
+	if !c.keysImported {
+		err := importPGPKeys()
+		if err != nil {
+			return nil, err
+		}
+		c.keysImported = true
+	}
+	out, err := c.Runner.Run(c.Dir, "--decrypt", "--output-type", "json", name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt %s", name)
+	}
+	answer := map[string]interface{}{}
+	err = json.Unmarshal([]byte(out), &answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the decrypted secret %s", name)
+	}
+	return answer, nil
+}
+
+// ReadObject reads a generic named object from the encrypted secrets.
+// The secret _must_ be serializable to JSON.
+func (c *FileSystemClient) ReadObject(secretName string, secret interface{}) error {
+	m, err := c.Read(secretName)
+	if err != nil {
+		return errors.Wrapf(err, "reading the secret %q from sops", secretName)
+	}
+	err = util.ToStructFromMapStringInterface(m, &secret)
+	if err != nil {
+		return errors.Wrapf(err, "deserializing the secret %q from sops", secretName)
+	}
+	return nil
+}
+
+// Write encrypts a named secret with the data provided. The plain text is written to a temporary file which is
+// encrypted in place then renamed so that an existing secret is only replaced once the new version is encrypted
+func (c *FileSystemClient) Write(secretName string, data map[string]interface{}) (map[string]interface{}, error) {
+	path := c.fileName(secretName)
+	dir, base := filepath.Split(path)
+	err := os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ensure that parent directory exists %s", dir)
+	}
+	plainText, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal secret %s", secretName)
+	}
+	// keep the .yaml extension so that the file matches the same creation rules and is parsed as YAML
+	f, err := ioutil.TempFile(dir, "."+strings.TrimSuffix(base, ".yaml")+"-*.yaml")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a temporary file in %s", dir)
+	}
+	tmpFile := f.Name()
+	defer os.Remove(tmpFile) // nolint: errcheck
+	_, err = f.Write(plainText)
+	f.Close() // nolint: errcheck
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to write %s", tmpFile)
+	}
+
+	args := []string{"--encrypt", "--in-place"}
+	if c.ConfigFile != "" {
+		args = append(args, "--config", c.ConfigFile)
+	}
+	if len(c.AgeRecipients) > 0 {
+		args = append(args, "--age", strings.Join(c.AgeRecipients, ","))
+	}
+	if len(c.PGPFingerprints) > 0 {
+		args = append(args, "--pgp", strings.Join(c.PGPFingerprints, ","))
+	}
+	args = append(args, tmpFile)
+	_, err = c.Runner.Run(c.Dir, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encrypt secret %s. Configure the keys to encrypt secrets with in %s or a .sops.yaml file", secretName, config.RequirementsConfigFileName)
+	}
+	err = os.Rename(tmpFile, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to rename %s to %s", tmpFile, path)
+	}
+	delete(c.decrypted, path)
+	return c.Read(secretName)
+}
+
+// WriteObject writes a generic named object to the encrypted secrets.
+// The secret _must_ be serializable to JSON.
+func (c *FileSystemClient) WriteObject(secretName string, secret interface{}) (map[string]interface{}, error) {
+	m, err := util.ToMapStringInterfaceFromStruct(secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "serializing secret %q object for saving to sops", secretName)
+	}
+	return c.Write(secretName, m)
+}
+
+// ReplaceURIs will replace any sops: URIs in a string along with any local: URIs so that values files created for
+// the local file system secrets can be used with the encrypted secrets
+func (c *FileSystemClient) ReplaceURIs(s string) (string, error) {
+	s, err := secreturl.ReplaceURIs(s, c, sopsURIRegex, Scheme)
+	if err != nil {
+		return "", err
+	}
+	return secreturl.ReplaceURIs(s, c, localURIRegex, "local:")
+}
+
+func (c *FileSystemClient) fileName(secretName string) string {
+	return filepath.Join(c.Dir, secretName+".yaml")
+}
+
+// binaryRunner runs the sops binary keeping its standard error out of the output so that warnings do not stop the
+// decrypted secrets being parsed
+type binaryRunner struct{}
+
+// Run runs sops
+func (r binaryRunner) Run(dir string, args ...string) (string, error) {
+	var out, errOut bytes.Buffer
+	cmd := util.Command{
+		Name: binaryName,
+		Args: args,
+		Dir:  dir,
+		Out:  &out,
+		Err:  &errOut,
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running sops: %s", strings.TrimSpace(errOut.String()))
+	}
+	return out.String(), nil
+}
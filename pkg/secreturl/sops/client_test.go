@@ -0,0 +1,156 @@
+package sops_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/secreturl/sops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptedPrefix = "encrypted:"
+
+// fakeRunner pretends to be sops by prefixing the files it encrypts
+type fakeRunner struct {
+	calls [][]string
+	fail  bool
+}
+
+func (r *fakeRunner) Run(dir string, args ...string) (string, error) {
+	r.calls = append(r.calls, args)
+	fileName := args[len(args)-1]
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	switch args[0] {
+	case "--encrypt":
+		if r.fail {
+			return "", errors.New("no matching creation rules found")
+		}
+		return "", ioutil.WriteFile(fileName, append([]byte(encryptedPrefix), data...), 0600)
+	case "--decrypt":
+		if !strings.HasPrefix(string(data), encryptedPrefix) {
+			return "", errors.New("sops metadata not found")
+		}
+		json, err := yaml.YAMLToJSON(data[len(encryptedPrefix):])
+		return string(json), err
+	}
+	return "", errors.New("unsupported command")
+}
+
+func TestWriteAndRead(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-sops-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	runner := &fakeRunner{}
+	client := &sops.FileSystemClient{
+		Dir:           dir,
+		AgeRecipients: []string{"age1abc", "age1def"},
+		Runner:        runner,
+	}
+	data, err := client.Write("mycluster/adminUser", map[string]interface{}{"username": "admin", "password": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", data["password"])
+
+	encryptArgs := runner.calls[0]
+	assert.Equal(t, []string{"--encrypt", "--in-place", "--age", "age1abc,age1def"}, encryptArgs[:4])
+
+	fileName := filepath.Join(dir, "mycluster", "adminUser.yaml")
+	encrypted, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(encrypted), encryptedPrefix), "the secret is stored encrypted")
+	assertNoTemporaryFiles(t, filepath.Join(dir, "mycluster"))
+
+	user := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+	err = client.ReadObject("mycluster/adminUser", &user)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Username)
+
+	// secrets stored before the cluster name is known are found in the canonical cluster path
+	_, err = client.Write("currentCluster/pipelineUser", map[string]interface{}{"token": "abc"})
+	require.NoError(t, err)
+	data, err = client.Read("mycluster/pipelineUser")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", data["token"])
+
+	_, err = client.Read("mycluster/missing")
+	assert.Error(t, err)
+}
+
+func TestWriteFailureKeepsExistingSecret(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-sops-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	runner := &fakeRunner{}
+	client := &sops.FileSystemClient{Dir: dir, Runner: runner}
+	_, err = client.Write("hmac", map[string]interface{}{"token": "old"})
+	require.NoError(t, err)
+
+	runner.fail = true
+	_, err = client.Write("hmac", map[string]interface{}{"token": "new"})
+	require.Error(t, err)
+	assertNoTemporaryFiles(t, dir)
+
+	data, err := client.Read("hmac")
+	require.NoError(t, err)
+	assert.Equal(t, "old", data["token"])
+}
+
+func TestReplaceURIs(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-sops-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	requirements := config.NewRequirementsConfig()
+	requirements.SecretStorage = config.SecretStorageTypeSops
+	client := sops.NewClientForRequirements(requirements, filepath.Join(dir, config.RequirementsConfigFileName)).(*sops.FileSystemClient)
+	assert.Equal(t, filepath.Join(dir, sops.DefaultDir), client.Dir)
+	runner := &fakeRunner{}
+	client.Runner = runner
+
+	_, err = client.Write("mycluster/adminUser", map[string]interface{}{"username": "admin", "password": "secret"})
+	require.NoError(t, err)
+
+	values := `
+username: sops:mycluster/adminUser:username
+password: "local:mycluster/adminUser:password"
+`
+	result, err := client.ReplaceURIs(values)
+	require.NoError(t, err)
+	assert.Equal(t, `
+username: admin
+password: "secret"
+`, result)
+
+	decrypts := 0
+	for _, args := range runner.calls {
+		if args[0] == "--decrypt" {
+			decrypts++
+		}
+	}
+	assert.Equal(t, 1, decrypts, "the secret is only decrypted once for all of its references")
+}
+
+func assertNoTemporaryFiles(t *testing.T, dir string) {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	for _, f := range files {
+		assert.False(t, strings.HasPrefix(f.Name(), "."), "temporary file %s should be removed", f.Name())
+	}
+}
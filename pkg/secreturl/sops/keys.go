@@ -0,0 +1,97 @@
+package sops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AgeKeyFileEnvVar the environment variable of the file of age keys which sops decrypts secrets with
+	AgeKeyFileEnvVar = "SOPS_AGE_KEY_FILE"
+
+	// PGPKeyFileEnvVar the environment variable of a file of armored PGP secret keys which are imported into the gpg
+	// keyring before the first secret is decrypted
+	PGPKeyFileEnvVar = "JX_SOPS_PGP_KEY_FILE"
+
+	// KeysMountPath the directory the keys secret is mounted in the pipeline steps
+	KeysMountPath = "/secrets/sops"
+
+	// AgeKeyFile the key of the age keys in the keys secret
+	AgeKeyFile = "keys.txt"
+
+	// PGPKeyFile the key of the armored PGP secret keys in the keys secret
+	PGPKeyFile = "pgp.asc"
+)
+
+// LocalKeys returns the data of the keys secret which lets the pipelines in the cluster decrypt the secrets. The data
+// has the age keys of sops on this machine and the PGP secret keys of the fingerprints exported from the gpg keyring.
+// PGP keys must not have a passphrase as the pipelines cannot enter it
+func LocalKeys(pgpFingerprints []string) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	ageKeyFile := LocalAgeKeyFile()
+	exists, err := util.FileExists(ageKeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", ageKeyFile)
+	}
+	if exists {
+		data[AgeKeyFile], err = ioutil.ReadFile(ageKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the age keys %s", ageKeyFile)
+		}
+	}
+	if len(pgpFingerprints) > 0 {
+		cmd := util.Command{
+			Name: "gpg",
+			Args: append([]string{"--batch", "--armor", "--export-secret-keys"}, pgpFingerprints...),
+		}
+		out, err := cmd.RunWithoutRetry()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to export the PGP secret keys")
+		}
+		data[PGPKeyFile] = []byte(out)
+	}
+	return data, nil
+}
+
+// LocalAgeKeyFile returns the file of the age keys of sops which is $SOPS_AGE_KEY_FILE or sops/age/keys.txt in the
+// user configuration directory
+func LocalAgeKeyFile() string {
+	if fileName := os.Getenv(AgeKeyFileEnvVar); fileName != "" {
+		return fileName
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(util.HomeDir(), ".config")
+		if runtime.GOOS == "darwin" {
+			configDir = filepath.Join(util.HomeDir(), "Library", "Application Support")
+		}
+	}
+	return filepath.Join(configDir, "sops", "age", "keys.txt")
+}
+
+// importPGPKeys imports the PGP secret keys of the file in $JX_SOPS_PGP_KEY_FILE into the gpg keyring so that sops can
+// decrypt the secrets with them
+func importPGPKeys() error {
+	fileName := os.Getenv(PGPKeyFileEnvVar)
+	if fileName == "" {
+		return nil
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return err
+	}
+	cmd := util.Command{
+		Name: "gpg",
+		Args: []string{"--batch", "--import", fileName},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "failed to import the PGP secret keys %s", fileName)
+	}
+	return nil
+}
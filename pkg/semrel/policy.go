@@ -0,0 +1,427 @@
+package semrel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Bump is the part of a version which is incremented by a commit
+type Bump string
+
+const (
+	// BumpNone means no new version is released
+	BumpNone Bump = "none"
+	// BumpPatch increments the patch version
+	BumpPatch Bump = "patch"
+	// BumpMinor increments the minor version
+	BumpMinor Bump = "minor"
+	// BumpMajor increments the major version
+	BumpMajor Bump = "major"
+
+	// PolicyFileName is the default name of the release policy file in a repository
+	PolicyFileName = "release-policy.yml"
+)
+
+var policyCommitPattern = regexp.MustCompile(`^(\w*)(?:\((.*)\))?(!)?: (.*)$`)
+
+// defaultBreakingChangePattern marks a commit as a breaking change if the policy has no breaking change patterns
+var defaultBreakingChangePattern = regexp.MustCompile(`BREAKING CHANGES?`)
+
+func (b Bump) rank() int {
+	switch b {
+	case BumpMajor:
+		return 3
+	case BumpMinor:
+		return 2
+	case BumpPatch:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Policy configures how conventional commits are turned into the next version of a project
+type Policy struct {
+	// Types maps conventional commit types to the bump they cause. Types which are not listed don't cause a release.
+	Types map[string]Bump `json:"types,omitempty"`
+	// Scopes maps commit scopes to the bump they cause, overriding the bump of the commit type
+	Scopes map[string]Bump `json:"scopes,omitempty"`
+	// BreakingChangePatterns are regular expressions which mark a commit as a breaking change if they match its message
+	BreakingChangePatterns []string `json:"breakingChangePatterns,omitempty"`
+	// InitialDevelopment treats versions below 1.0.0 as initial development, so breaking changes only bump the
+	// minor version. Otherwise any release of a 0.x version releases 1.0.0.
+	InitialDevelopment bool `json:"initialDevelopment,omitempty"`
+	// Channels are the pre-release channels used for branches
+	Channels []Channel `json:"channels,omitempty"`
+	// Modules maps commit scopes to the modules of a monorepo which are released separately
+	Modules []Module `json:"modules,omitempty"`
+
+	breakingPatterns []*regexp.Regexp
+}
+
+// Channel releases pre-release versions such as 1.2.0-rc.1 from the branches matching the branch pattern
+type Channel struct {
+	// Branch is a glob pattern for the branch names using the channel
+	Branch string `json:"branch"`
+	// Prerelease is the pre-release identifier, such as rc or beta
+	Prerelease string `json:"prerelease"`
+}
+
+// Module is a separately released part of a monorepo
+type Module struct {
+	Name string `json:"name"`
+	// Scopes are the commit scopes which belong to the module
+	Scopes []string `json:"scopes"`
+	// TagPrefix is the prefix of the git tags of the module. Defaults to the module name followed by "-v"
+	TagPrefix string `json:"tagPrefix,omitempty"`
+}
+
+// Decision explains the bump caused by a single commit
+type Decision struct {
+	SHA     string
+	Subject string
+	Bump    Bump
+	Reason  string
+}
+
+// Report explains how the next version was chosen
+type Report struct {
+	Current   *semver.Version
+	Next      *semver.Version
+	Bump      Bump
+	Branch    string
+	Channel   *Channel
+	Module    string
+	Decisions []Decision
+	Notes     []string
+}
+
+// DefaultPolicy returns the policy used when a project doesn't configure one: feat commits bump the minor version,
+// fix commits bump the patch version and a BREAKING CHANGE bumps the major version
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Types:                  defaultTypes(),
+		BreakingChangePatterns: []string{defaultBreakingChangePattern.String()},
+		breakingPatterns:       []*regexp.Regexp{defaultBreakingChangePattern},
+	}
+}
+
+// defaultTypes returns the bumps of the commit types used when a policy doesn't configure any
+func defaultTypes() map[string]Bump {
+	return map[string]Bump{
+		"feat": BumpMinor,
+		"fix":  BumpPatch,
+	}
+}
+
+// LoadPolicy loads the release policy from the given file. If the file doesn't exist the default policy is returned.
+func LoadPolicy(fileName string) (*Policy, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", fileName)
+	}
+	if !exists {
+		return DefaultPolicy(), nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	p := &Policy{}
+	err = yaml.Unmarshal(data, p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	err = p.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid release policy %s", fileName)
+	}
+	return p, nil
+}
+
+// Validate validates the policy and fills in the default commit types and breaking change patterns
+func (p *Policy) Validate() error {
+	if len(p.Types) == 0 {
+		p.Types = defaultTypes()
+	}
+	if len(p.BreakingChangePatterns) == 0 {
+		p.BreakingChangePatterns = []string{defaultBreakingChangePattern.String()}
+	}
+	for t, b := range p.Types {
+		if b.rank() == 0 && b != BumpNone {
+			return fmt.Errorf("invalid bump %s for commit type %s", b, t)
+		}
+	}
+	for s, b := range p.Scopes {
+		if b.rank() == 0 && b != BumpNone {
+			return fmt.Errorf("invalid bump %s for commit scope %s", b, s)
+		}
+	}
+	p.breakingPatterns = nil
+	for _, pattern := range p.BreakingChangePatterns {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid breaking change pattern %s", pattern)
+		}
+		p.breakingPatterns = append(p.breakingPatterns, r)
+	}
+	for _, c := range p.Channels {
+		if c.Branch == "" || c.Prerelease == "" {
+			return fmt.Errorf("channels must have a branch and a prerelease")
+		}
+		if _, err := filepath.Match(c.Branch, ""); err != nil {
+			return errors.Wrapf(err, "invalid branch pattern %s", c.Branch)
+		}
+	}
+	names := map[string]bool{}
+	for _, m := range p.Modules {
+		if m.Name == "" || len(m.Scopes) == 0 {
+			return fmt.Errorf("modules must have a name and at least one scope")
+		}
+		if names[m.Name] {
+			return fmt.Errorf("duplicate module %s", m.Name)
+		}
+		names[m.Name] = true
+	}
+	return nil
+}
+
+// ChannelForBranch returns the first pre-release channel matching the branch or nil if the branch releases
+// normal versions
+func (p *Policy) ChannelForBranch(branch string) *Channel {
+	for i := range p.Channels {
+		if matched, _ := filepath.Match(p.Channels[i].Branch, branch); matched {
+			return &p.Channels[i]
+		}
+	}
+	return nil
+}
+
+// Module returns the module with the given name
+func (p *Policy) Module(name string) (*Module, error) {
+	var names []string
+	for i := range p.Modules {
+		if p.Modules[i].Name == name {
+			return &p.Modules[i], nil
+		}
+		names = append(names, p.Modules[i].Name)
+	}
+	return nil, util.InvalidOption("module", name, names)
+}
+
+// GetTagPrefix returns the prefix of the git tags of the module
+func (m *Module) GetTagPrefix() string {
+	if m.TagPrefix != "" {
+		return m.TagPrefix
+	}
+	return m.Name + "-v"
+}
+
+// NextVersion works out the next version after the current version from the commits made since it was released.
+// The module may be blank if the repository is released as a whole. The returned report has a nil Next version if
+// none of the commits cause a release.
+func (p *Policy) NextVersion(current *semver.Version, commits []gits.GitCommit, branch string, module string) (*Report, error) {
+	report := &Report{
+		Current: current,
+		Bump:    BumpNone,
+		Branch:  branch,
+		Module:  module,
+		Channel: p.ChannelForBranch(branch),
+	}
+	var m *Module
+	if module != "" {
+		var err error
+		m, err = p.Module(module)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range commits {
+		d := p.evaluate(&commits[i], m)
+		report.Decisions = append(report.Decisions, d)
+		if d.Bump.rank() > report.Bump.rank() {
+			report.Bump = d.Bump
+		}
+	}
+
+	bump := report.Bump
+	if current.Major() == 0 {
+		if !p.InitialDevelopment {
+			bump = BumpMajor
+			report.Notes = append(report.Notes, "the current version is below 1.0.0 so the next release is a major version")
+		} else if bump == BumpMajor {
+			bump = BumpMinor
+			report.Notes = append(report.Notes, "breaking changes bump the minor version during initial development")
+		}
+	}
+	report.Bump = bump
+	if bump == BumpNone {
+		report.Notes = append(report.Notes, "none of the commits cause a release")
+		return report, nil
+	}
+	report.Next = p.applyBump(current, bump, report.Channel)
+	return report, nil
+}
+
+// evaluate decides the bump caused by a commit
+func (p *Policy) evaluate(commit *gits.GitCommit, module *Module) Decision {
+	lines := strings.Split(commit.Message, "\n")
+	d := Decision{
+		SHA:     commit.SHA,
+		Subject: lines[0],
+		Bump:    BumpNone,
+	}
+	found := policyCommitPattern.FindStringSubmatch(lines[0])
+	if found == nil {
+		d.Reason = "not a conventional commit"
+		return d
+	}
+	commitType := strings.ToLower(found[1])
+	scope := found[2]
+
+	if module != nil && util.StringArrayIndex(module.Scopes, scope) < 0 {
+		d.Reason = fmt.Sprintf("scope '%s' does not belong to module %s", scope, module.Name)
+		return d
+	}
+	if found[3] == "!" {
+		d.Bump = BumpMajor
+		d.Reason = "marked as a breaking change with '!'"
+		return d
+	}
+	for _, r := range p.breakingPatterns {
+		if r.MatchString(commit.Message) {
+			d.Bump = BumpMajor
+			d.Reason = fmt.Sprintf("message matches breaking change pattern '%s'", r.String())
+			return d
+		}
+	}
+	if b, ok := p.Scopes[scope]; ok && scope != "" {
+		d.Bump = b
+		d.Reason = fmt.Sprintf("scope '%s' is configured as %s", scope, b)
+		return d
+	}
+	if b, ok := p.Types[commitType]; ok {
+		d.Bump = b
+		d.Reason = fmt.Sprintf("type '%s' is configured as %s", commitType, b)
+		return d
+	}
+	d.Reason = fmt.Sprintf("type '%s' does not cause a release", commitType)
+	return d
+}
+
+// applyBump increments the version. Without a channel a pre-release version has its pre-release number incremented.
+// With a channel the version is bumped unless the current pre-release already includes the bump, and the pre-release
+// number of the channel is incremented.
+func (p *Policy) applyBump(version *semver.Version, bump Bump, channel *Channel) *semver.Version {
+	preRel := version.Prerelease()
+	if channel == nil {
+		if preRel != "" {
+			newVersion, _ := version.SetPrerelease(incrementPrerelease(preRel, ""))
+			return &newVersion
+		}
+		newVersion := increment(version, bump)
+		return &newVersion
+	}
+
+	base := *version
+	if preRel != "" {
+		base, _ = version.SetPrerelease("")
+		if !includesBump(&base, bump) {
+			base = increment(&base, bump)
+			preRel = ""
+		}
+	} else {
+		base = increment(version, bump)
+	}
+	newVersion, _ := base.SetPrerelease(incrementPrerelease(preRel, channel.Prerelease))
+	return &newVersion
+}
+
+func increment(version *semver.Version, bump Bump) semver.Version {
+	switch bump {
+	case BumpMajor:
+		return version.IncMajor()
+	case BumpMinor:
+		return version.IncMinor()
+	default:
+		return version.IncPatch()
+	}
+}
+
+// includesBump returns true if a pre-release of the base version already contains a bump of the given size,
+// e.g. 1.3.0 is already a minor bump so a feat commit doesn't need to bump it again
+func includesBump(base *semver.Version, bump Bump) bool {
+	switch bump {
+	case BumpMajor:
+		return base.Minor() == 0 && base.Patch() == 0
+	case BumpMinor:
+		return base.Patch() == 0
+	default:
+		return true
+	}
+}
+
+// incrementPrerelease increments the number of the pre-release. If an identifier is given and it differs from the
+// current one the numbering restarts at 1.
+func incrementPrerelease(preRel string, identifier string) string {
+	parts := strings.Split(preRel, ".")
+	if identifier != "" && parts[0] != identifier {
+		return identifier + ".1"
+	}
+	if len(parts) > 1 {
+		idx, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			idx = 0
+		}
+		return fmt.Sprintf("%s.%d", parts[0], idx+1)
+	}
+	return preRel + ".1"
+}
+
+// String returns a description of how the version was chosen
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "current version: %s\n", r.Current.String())
+	if r.Module != "" {
+		fmt.Fprintf(&b, "module: %s\n", r.Module)
+	}
+	if r.Branch != "" {
+		if r.Channel != nil {
+			fmt.Fprintf(&b, "branch: %s (pre-release channel '%s' from branch pattern '%s')\n", r.Branch, r.Channel.Prerelease, r.Channel.Branch)
+		} else {
+			fmt.Fprintf(&b, "branch: %s\n", r.Branch)
+		}
+	}
+	b.WriteString("commits:\n")
+	if len(r.Decisions) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, d := range r.Decisions {
+		sha := d.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(&b, "  %s %s => %s (%s)\n", sha, d.Subject, d.Bump, d.Reason)
+	}
+	for _, n := range r.Notes {
+		fmt.Fprintf(&b, "note: %s\n", n)
+	}
+	fmt.Fprintf(&b, "bump: %s\n", r.Bump)
+	if r.Next != nil {
+		fmt.Fprintf(&b, "next version: %s\n", r.Next.String())
+	} else {
+		b.WriteString("next version: no release\n")
+	}
+	return b.String()
+}
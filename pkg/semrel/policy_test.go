@@ -0,0 +1,143 @@
+package semrel_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/semrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commits(messages ...string) []gits.GitCommit {
+	var answer []gits.GitCommit
+	for i, m := range messages {
+		answer = append(answer, gits.GitCommit{SHA: string(rune('a'+i)) + "123456789", Message: m})
+	}
+	return answer
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		current  string
+		messages []string
+		expected string
+		bump     semrel.Bump
+	}{
+		{"1.2.3", []string{"fix: a bug"}, "1.2.4", semrel.BumpPatch},
+		{"1.2.3", []string{"fix: a bug", "feat(api): a feature"}, "1.3.0", semrel.BumpMinor},
+		{"1.2.3", []string{"feat: a feature\n\nBREAKING CHANGE: removed the old api"}, "2.0.0", semrel.BumpMajor},
+		{"1.2.3", []string{"feat!: a feature"}, "2.0.0", semrel.BumpMajor},
+		{"1.2.3", []string{"docs: readme", "not conventional"}, "", semrel.BumpNone},
+		{"1.2.3-alpha.1", []string{"fix: a bug"}, "1.2.3-alpha.2", semrel.BumpPatch},
+		{"0.1.0", []string{"fix: a bug"}, "1.0.0", semrel.BumpMajor},
+	}
+	policy := semrel.DefaultPolicy()
+	for _, tt := range tests {
+		report, err := policy.NextVersion(semver.MustParse(tt.current), commits(tt.messages...), "master", "")
+		require.NoError(t, err)
+		assert.Equal(t, tt.bump, report.Bump, "bump for %s %v", tt.current, tt.messages)
+		if tt.expected == "" {
+			assert.Nil(t, report.Next, "next version for %s %v", tt.current, tt.messages)
+		} else if assert.NotNil(t, report.Next) {
+			assert.Equal(t, tt.expected, report.Next.String(), "next version for %s %v", tt.current, tt.messages)
+		}
+	}
+}
+
+func TestPolicyChannelsAndModules(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-release-policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, semrel.PolicyFileName)
+	err = ioutil.WriteFile(fileName, []byte(`
+types:
+  feat: minor
+  fix: patch
+  perf: patch
+scopes:
+  deps: none
+initialDevelopment: true
+channels:
+- branch: release/*
+  prerelease: rc
+- branch: beta
+  prerelease: beta
+modules:
+- name: api
+  scopes: [api, proto]
+- name: ui
+  scopes: [ui]
+  tagPrefix: ui/v
+`), 0600)
+	require.NoError(t, err)
+
+	policy, err := semrel.LoadPolicy(fileName)
+	require.NoError(t, err)
+
+	tests := []struct {
+		current  string
+		branch   string
+		module   string
+		messages []string
+		expected string
+	}{
+		{"1.2.3", "release/1.3", "", []string{"perf: faster"}, "1.2.4-rc.1"},
+		{"1.2.3", "release/1.3", "", []string{"feat: a feature"}, "1.3.0-rc.1"},
+		{"1.3.0-rc.1", "release/1.3", "", []string{"fix: a bug"}, "1.3.0-rc.2"},
+		{"1.3.0-rc.2", "release/1.3", "", []string{"feat!: breaking"}, "2.0.0-rc.1"},
+		{"1.3.0-rc.2", "beta", "", []string{"fix: a bug"}, "1.3.0-beta.1"},
+		{"1.2.4-rc.1", "release/1.3", "", []string{"feat: a feature"}, "1.3.0-rc.1"},
+		{"1.2.3", "master", "", []string{"fix(deps): bump"}, ""},
+		{"0.3.1", "master", "", []string{"feat!: breaking"}, "0.4.0"},
+		{"1.2.3", "master", "api", []string{"feat(ui): a feature", "fix(proto): a bug"}, "1.2.4"},
+		{"1.2.3", "master", "ui", []string{"fix(proto): a bug"}, ""},
+	}
+	for _, tt := range tests {
+		report, err := policy.NextVersion(semver.MustParse(tt.current), commits(tt.messages...), tt.branch, tt.module)
+		require.NoError(t, err)
+		if tt.expected == "" {
+			assert.Nil(t, report.Next, "next version for %s on %s %v", tt.current, tt.branch, tt.messages)
+		} else if assert.NotNil(t, report.Next, "next version for %s on %s %v", tt.current, tt.branch, tt.messages) {
+			assert.Equal(t, tt.expected, report.Next.String(), "next version for %s on %s %v", tt.current, tt.branch, tt.messages)
+		}
+	}
+
+	ui, err := policy.Module("ui")
+	require.NoError(t, err)
+	assert.Equal(t, "ui/v", ui.GetTagPrefix())
+	api, err := policy.Module("api")
+	require.NoError(t, err)
+	assert.Equal(t, "api-v", api.GetTagPrefix())
+	_, err = policy.Module("missing")
+	assert.Error(t, err)
+}
+
+func TestReportExplainsDecisions(t *testing.T) {
+	t.Parallel()
+	policy := semrel.DefaultPolicy()
+	report, err := policy.NextVersion(semver.MustParse("1.2.3"), commits("docs: readme", "feat(api): a feature"), "master", "")
+	require.NoError(t, err)
+
+	text := report.String()
+	assert.Contains(t, text, "current version: 1.2.3")
+	assert.Contains(t, text, "a123456 docs: readme => none (type 'docs' does not cause a release)")
+	assert.Contains(t, text, "b123456 feat(api): a feature => minor (type 'feat' is configured as minor)")
+	assert.Contains(t, text, "next version: 1.3.0")
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	t.Parallel()
+	policy := &semrel.Policy{Types: map[string]semrel.Bump{"feat": "huge"}}
+	assert.Error(t, policy.Validate())
+
+	policy = &semrel.Policy{Channels: []semrel.Channel{{Branch: "release/*"}}}
+	assert.Error(t, policy.Validate())
+}
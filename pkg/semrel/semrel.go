@@ -1,9 +1,6 @@
 package semrel
 
 import (
-	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -13,118 +10,64 @@ import (
 	"github.com/jenkins-x/jx/pkg/gits"
 )
 
-var commitPattern = regexp.MustCompile("^(\\w*)(?:\\((.*)\\))?\\: (.*)$")
-var breakingPattern = regexp.MustCompile("BREAKING CHANGES?")
-
-type change struct {
-	Major, Minor, Patch bool
-}
-
-type conventionalCommit struct {
-	*gits.GitCommit
-	MessageLines []string
-	Type         string
-	Scope        string
-	MessageBody  string
-	Change       change
-}
-
-type release struct {
-	SHA     string
-	Version *semver.Version
-}
-
-func calculateChange(commits []*conventionalCommit, latestRelease *release) change {
-	var change change
-	for _, commit := range commits {
-		if latestRelease.SHA == commit.SHA {
-			break
-		}
-		change.Major = change.Major || commit.Change.Major
-		change.Minor = change.Minor || commit.Change.Minor
-		change.Patch = change.Patch || commit.Change.Patch
+// GetNewVersion uses the conventional commits in the range of latestTagRev..endSha to increment the version from latestTag
+func GetNewVersion(dir string, endSha string, gitter gits.Gitter, latestTag string, latestTagRev string) (*semver.Version, error) {
+	report, err := GetNextVersionReport(dir, endSha, gitter, latestTag, latestTagRev, DefaultPolicy(), "", "")
+	if err != nil {
+		return nil, err
 	}
-	return change
+	return report.Next, nil
 }
 
-func applyChange(version *semver.Version, change change) *semver.Version {
-	if version.Major() == 0 {
-		change.Major = true
-	}
-	if !change.Major && !change.Minor && !change.Patch {
-		return nil
-	}
-	var newVersion semver.Version
-	preRel := version.Prerelease()
-	if preRel == "" {
-		switch {
-		case change.Major:
-			newVersion = version.IncMajor()
-			break
-		case change.Minor:
-			newVersion = version.IncMinor()
-			break
-		case change.Patch:
-			newVersion = version.IncPatch()
-			break
-		}
-		return &newVersion
-	}
-	preRelVer := strings.Split(preRel, ".")
-	if len(preRelVer) > 1 {
-		idx, err := strconv.ParseInt(preRelVer[1], 10, 32)
+// GetNextVersionReport uses the policy to work out the next version from the conventional commits in the range of
+// latestTagRev..endSha, returning a report which explains the decision. The tag prefix of the module, or 'v' if
+// there is no module, is removed from the latestTag before it is parsed.
+func GetNextVersionReport(dir string, endSha string, gitter gits.Gitter, latestTag string, latestTagRev string, policy *Policy, branch string, module string) (*Report, error) {
+	prefix := "v"
+	if module != "" {
+		m, err := policy.Module(module)
 		if err != nil {
-			idx = 0
+			return nil, err
 		}
-		preRel = fmt.Sprintf("%s.%d", preRelVer[0], idx+1)
-	} else {
-		preRel += ".1"
+		prefix = m.GetTagPrefix()
 	}
-	newVersion, _ = version.SetPrerelease(preRel)
-	return &newVersion
-}
-
-// GetNewVersion uses the conventional commits in the range of latestTagRev..endSha to increment the version from latestTag
-func GetNewVersion(dir string, endSha string, gitter gits.Gitter, latestTag string, latestTagRev string) (*semver.Version, error) {
-	version, err := semver.NewVersion(strings.TrimPrefix(latestTag, "v"))
+	version, err := semver.NewVersion(strings.TrimPrefix(latestTag, prefix))
 	if err != nil {
 		return nil, errors.Wrapf(err, "parsing %s as semantic version", latestTag)
 	}
-	release := release{
-		SHA:     latestTagRev,
-		Version: version,
-	}
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	rawCommits, err := gitter.GetCommits(dir, release.SHA, endSha)
+	commits, err := gitter.GetCommits(dir, latestTagRev, endSha)
 	if err != nil {
-		return nil, errors.Wrapf(err, "getting commits in range %s..%s", release.SHA, endSha)
-	}
-	commits := make([]*conventionalCommit, 0)
-	for _, c := range rawCommits {
-		commits = append(commits, parseCommit(&c))
+		return nil, errors.Wrapf(err, "getting commits in range %s..%s", latestTagRev, endSha)
 	}
-
-	return applyChange(release.Version, calculateChange(commits, &release)), nil
+	return policy.NextVersion(version, commits, branch, module)
 }
 
-func parseCommit(commit *gits.GitCommit) *conventionalCommit {
-	c := &conventionalCommit{
-		GitCommit: commit,
+// GetLatestModuleTag returns the tag of the highest released version of the module along with the SHA of the commit
+// it points to
+func GetLatestModuleTag(dir string, gitter gits.Gitter, module *Module) (string, string, error) {
+	prefix := module.GetTagPrefix()
+	tags, err := gitter.FilterTags(dir, prefix+"*")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "listing the tags of module %s", module.Name)
 	}
-	c.MessageLines = strings.Split(commit.Message, "\n")
-	found := commitPattern.FindAllStringSubmatch(c.MessageLines[0], -1)
-	if len(found) < 1 {
-		return c
+	var latest *semver.Version
+	latestTag := ""
+	for _, tag := range tags {
+		v, err := semver.NewVersion(strings.TrimPrefix(tag, prefix))
+		if err != nil {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+			latestTag = tag
+		}
+	}
+	if latestTag == "" {
+		return "", "", errors.Errorf("no tags found for module %s, tag the first release as %s0.0.0", module.Name, prefix)
 	}
-	c.Type = strings.ToLower(found[0][1])
-	c.Scope = found[0][2]
-	c.MessageBody = found[0][3]
-	c.Change = change{
-		Major: breakingPattern.MatchString(commit.Message),
-		Minor: c.Type == "feat",
-		Patch: c.Type == "fix",
+	sha, err := gitter.GetCommitPointedToByTag(dir, latestTag)
+	if err != nil {
+		return "", "", err
 	}
-	return c
+	return latestTag, sha, nil
 }
@@ -0,0 +1,131 @@
+package signing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// ImageReference an image deployed by a chart which is configured by a map of the chart values with repository and
+// tag entries such as the image values of the charts created by jx
+type ImageReference struct {
+	// Path the keys of the map of the image in the chart values such as image
+	Path       []string
+	Repository string
+	Tag        string
+	// Digest the digest the image is deployed by which is resolved from the signature of the tag if the values only
+	// specify a tag
+	Digest string
+}
+
+// String returns the image reference such as gcr.io/myorg/myapp:1.0.0@sha256:abc123
+func (i *ImageReference) String() string {
+	answer := i.Repository
+	if i.Tag != "" {
+		answer += ":" + i.Tag
+	}
+	if i.Digest != "" {
+		answer += "@" + i.Digest
+	}
+	return answer
+}
+
+// ChartImages returns the images configured in the values of the chart archive or directory after merging the
+// override values such as the values of the chart in an environment
+func ChartImages(fileName string, overrides map[string]interface{}) ([]ImageReference, error) {
+	chart, err := chartutil.Load(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load chart %s", fileName)
+	}
+	values := map[string]interface{}{}
+	if chart.Values != nil {
+		values, err = chartutil.ReadValues([]byte(chart.Values.Raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the values of chart %s", fileName)
+		}
+	}
+	util.CombineMapTrees(values, overrides)
+	return FindImages(values), nil
+}
+
+// FindImages returns the images configured in the values sorted by their path. The tag of an image may include the
+// digest as tag@digest
+func FindImages(values map[string]interface{}) []ImageReference {
+	answer := findImages(values, nil)
+	sort.Slice(answer, func(i, j int) bool {
+		return strings.Join(answer[i].Path, ".") < strings.Join(answer[j].Path, ".")
+	})
+	return answer
+}
+
+func findImages(values map[string]interface{}, path []string) []ImageReference {
+	repository, _ := values["repository"].(string)
+	if repository != "" {
+		image := ImageReference{
+			Path:       path,
+			Repository: repository,
+		}
+		if values["tag"] != nil {
+			image.Tag = fmt.Sprint(values["tag"])
+		}
+		if idx := strings.Index(image.Tag, "@"); idx >= 0 {
+			image.Tag, image.Digest = image.Tag[:idx], image.Tag[idx+1:]
+		}
+		return []ImageReference{image}
+	}
+	var answer []ImageReference
+	for key, value := range values {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		childPath := append(append([]string{}, path...), key)
+		answer = append(answer, findImages(m, childPath)...)
+	}
+	return answer
+}
+
+// PinnedValues returns the chart values which deploy each image by its digest rather than by its tag, which may have
+// been pushed again since the image was signed, by setting the tag to tag@digest
+func PinnedValues(images []ImageReference) map[string]interface{} {
+	answer := map[string]interface{}{}
+	for _, image := range images {
+		if image.Digest == "" {
+			continue
+		}
+		m := answer
+		for _, key := range image.Path {
+			child, ok := m[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[key] = child
+			}
+			m = child
+		}
+		m["tag"] = pinnedTag(image)
+	}
+	return answer
+}
+
+// PinnedSetValues returns the values of PinnedValues as the arguments of 'helm --set'
+func PinnedSetValues(images []ImageReference) []string {
+	var answer []string
+	for _, image := range images {
+		if image.Digest == "" {
+			continue
+		}
+		key := strings.Join(append(append([]string{}, image.Path...), "tag"), ".")
+		answer = append(answer, key+"="+pinnedTag(image))
+	}
+	return answer
+}
+
+// pinnedTag returns the tag of the image with its digest as the container runtime pulls an image by the digest when
+// both are specified
+func pinnedTag(image ImageReference) string {
+	return image.Tag + "@" + image.Digest
+}
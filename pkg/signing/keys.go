@@ -0,0 +1,144 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// pemTypeEncryptedCosign the PEM type of the private keys created by 'cosign generate-key-pair'
+	pemTypeEncryptedCosign   = "ENCRYPTED COSIGN PRIVATE KEY"
+	pemTypeEncryptedSigstore = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	pemTypeECPrivateKey      = "EC PRIVATE KEY"
+	pemTypePrivateKey        = "PRIVATE KEY"
+	pemTypePublicKey         = "PUBLIC KEY"
+)
+
+// encryptedKey the JSON contents of an encrypted cosign private key
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey parses a PEM encoded ECDSA private key which is either a cosign private key encrypted with the
+// password or an unencrypted PKCS8 or EC private key
+func LoadPrivateKey(data []byte, password []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the private key is not PEM encoded")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case pemTypeEncryptedCosign, pemTypeEncryptedSigstore:
+		der, err := decryptKey(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the decrypted private key")
+		}
+	case pemTypeECPrivateKey:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case pemTypePrivateKey:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %s", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the private key")
+	}
+	answer, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key is a %T rather than an ECDSA key", key)
+	}
+	return answer, nil
+}
+
+func decryptKey(data []byte, password []byte) ([]byte, error) {
+	k := &encryptedKey{}
+	err := json.Unmarshal(data, k)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the encrypted private key")
+	}
+	if k.KDF.Name != "scrypt" || k.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported private key encryption %s with %s", k.KDF.Name, k.Cipher.Name)
+	}
+	if len(k.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("invalid nonce length %d of the encrypted private key", len(k.Cipher.Nonce))
+	}
+	secret, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive the key of the encrypted private key")
+	}
+	var nonce [24]byte
+	var key [32]byte
+	copy(nonce[:], k.Cipher.Nonce)
+	copy(key[:], secret)
+	answer, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, errors.New("failed to decrypt the private key as the password is incorrect")
+	}
+	return answer, nil
+}
+
+// LoadPublicKey parses a PEM encoded ECDSA public key such as the cosign.pub file of 'cosign generate-key-pair'
+func LoadPublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the public key is not PEM encoded")
+	}
+	if block.Type != pemTypePublicKey {
+		return nil, fmt.Errorf("unsupported public key type %s", block.Type)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the public key")
+	}
+	answer, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the public key is a %T rather than an ECDSA key", key)
+	}
+	return answer, nil
+}
+
+// MarshalPublicKey returns the PEM encoding of the public key
+func MarshalPublicKey(key *ecdsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// KeyID returns the fingerprint of the public key
+func KeyID(key *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
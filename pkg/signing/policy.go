@@ -0,0 +1,133 @@
+package signing
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+// IsExcluded returns true if the trust policy does not require the chart to be signed
+func (v *Verifier) IsExcluded(chart string) bool {
+	for _, pattern := range v.policy.Exclude {
+		if pattern == chart {
+			return true
+		}
+		if matched, err := filepath.Match(pattern, chart); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyRelease returns an error unless the chart version of the release is signed by a trusted key along with each
+// image the chart deploys, unless the policy allows unsigned images. If the chart digest is not empty it must be the
+// signed digest. The images are returned with the digests which were signed so that they are deployed by digest, as a
+// tag which was pushed again after it was signed would otherwise deploy an unsigned image
+func (v *Verifier) VerifyRelease(release *v1.Release, chart string, version string, chartDigest string, images []ImageReference) ([]ImageReference, error) {
+	if release == nil {
+		return nil, fmt.Errorf("chart %s version %s is not signed as there is no Release for it", chart, version)
+	}
+	chartSigned := false
+	var chartErr error
+	for i := range release.Spec.Signatures {
+		sig := &release.Spec.Signatures[i]
+		if sig.Kind != v1.ArtifactKindChart || sig.Name != chart || !sameVersion(sig.Version, version) {
+			continue
+		}
+		err := v.Verify(sig)
+		if err == nil && chartDigest != "" && sig.Digest != chartDigest {
+			err = fmt.Errorf("the signed digest %s of chart %s does not match its digest %s", sig.Digest, chart, chartDigest)
+		}
+		if err != nil {
+			chartErr = err
+			continue
+		}
+		chartSigned = true
+	}
+	if !chartSigned {
+		if chartErr != nil {
+			return nil, chartErr
+		}
+		return nil, fmt.Errorf("chart %s version %s is not signed", chart, version)
+	}
+	if v.policy.AllowUnsignedImages {
+		var answer []ImageReference
+		for _, image := range images {
+			digest, err := v.verifyImage(release, &image)
+			if err == nil {
+				image.Digest = digest
+			}
+			answer = append(answer, image)
+		}
+		return answer, nil
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images were found in the values of chart %s version %s so their signatures cannot be verified", chart, version)
+	}
+	var answer []ImageReference
+	for _, image := range images {
+		digest, err := v.verifyImage(release, &image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the images of chart %s version %s are not signed", chart, version)
+		}
+		image.Digest = digest
+		answer = append(answer, image)
+	}
+	return answer, nil
+}
+
+// verifyImage returns the digest of the image which was signed by a trusted key. An image with a digest must have a
+// signature of that digest while an image with only a tag must have a signature of the tag
+func (v *Verifier) verifyImage(release *v1.Release, image *ImageReference) (string, error) {
+	if image.Tag == "" && image.Digest == "" {
+		return "", fmt.Errorf("image %s at %s of the chart values has no tag or digest", image.Repository, strings.Join(image.Path, "."))
+	}
+	var lastErr error
+	for i := range release.Spec.Signatures {
+		sig := &release.Spec.Signatures[i]
+		if sig.Kind != v1.ArtifactKindImage || sig.Name != image.Repository {
+			continue
+		}
+		if image.Digest != "" {
+			if sig.Digest != image.Digest {
+				continue
+			}
+		} else if sig.Version != image.Tag {
+			continue
+		}
+		err := v.Verify(sig)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return sig.Digest, nil
+	}
+	if lastErr != nil {
+		return "", lastErr
+	}
+	return "", fmt.Errorf("image %s is not signed", image.String())
+}
+
+// FindSignedRelease returns the release which has signatures for the chart version or nil if there is none
+func FindSignedRelease(releases []v1.Release, chart string, version string) *v1.Release {
+	var answer *v1.Release
+	for i := range releases {
+		release := &releases[i]
+		for _, sig := range release.Spec.Signatures {
+			if sig.Kind == v1.ArtifactKindChart && sig.Name == chart && sameVersion(sig.Version, version) {
+				return release
+			}
+		}
+		if answer == nil && release.Spec.Name == chart && sameVersion(release.Spec.Version, version) {
+			answer = release
+		}
+	}
+	return answer
+}
+
+func sameVersion(v1 string, v2 string) bool {
+	return strings.TrimPrefix(v1, "v") == strings.TrimPrefix(v2, "v")
+}
@@ -0,0 +1,198 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PayloadTypeImage the type of the simple signing payload of a cosign image signature
+	PayloadTypeImage = "cosign container image signature"
+
+	// PayloadTypeChart the type of the simple signing payload of a chart signature
+	PayloadTypeChart = "jenkins-x helm chart signature"
+)
+
+// Payload the simple signing payload which is signed in the same format as cosign so that signatures can be
+// checked with 'cosign verify-blob'
+type Payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// Signer signs artifacts with a private key
+type Signer struct {
+	key   *ecdsa.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer for the private key
+func NewSigner(key *ecdsa.PrivateKey) (*Signer, error) {
+	keyID, err := KeyID(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: key, keyID: keyID}, nil
+}
+
+// PublicKey returns the public key which verifies the signatures of the signer
+func (s *Signer) PublicKey() *ecdsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+// Sign signs the digest of the image or chart
+func (s *Signer) Sign(kind v1.ArtifactKind, name string, version string, digest string) (*v1.ArtifactSignature, error) {
+	payload, err := json.Marshal(newPayload(kind, name, version, digest))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(payload)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, hash[:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign %s %s", kind, name)
+	}
+	signature, err := asn1.Marshal(ecdsaSignature{R: r, S: ss})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ArtifactSignature{
+		Kind:      kind,
+		Name:      name,
+		Version:   version,
+		Digest:    digest,
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		KeyID:     s.keyID,
+	}, nil
+}
+
+func newPayload(kind v1.ArtifactKind, name string, version string, digest string) *Payload {
+	p := &Payload{}
+	p.Critical.Identity.DockerReference = name
+	p.Critical.Image.DockerManifestDigest = digest
+	p.Critical.Type = PayloadTypeImage
+	if kind == v1.ArtifactKindChart {
+		p.Critical.Type = PayloadTypeChart
+	}
+	if version != "" {
+		p.Optional = map[string]string{"version": version}
+	}
+	return p
+}
+
+// Verifier verifies signatures with the public keys of a trust policy
+type Verifier struct {
+	policy *v1.TrustPolicy
+	keys   []*ecdsa.PublicKey
+}
+
+// NewVerifier creates a verifier for the public keys of the trust policy
+func NewVerifier(policy *v1.TrustPolicy) (*Verifier, error) {
+	v := &Verifier{policy: policy}
+	for i, data := range policy.PublicKeys {
+		key, err := LoadPublicKey([]byte(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load public key %d of the trust policy", i+1)
+		}
+		v.keys = append(v.keys, key)
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("the trust policy has no public keys")
+	}
+	return v, nil
+}
+
+// Verify returns an error unless the signature was made by one of the keys and the signed payload is for the
+// name, version and digest of the artifact
+func (v *Verifier) Verify(sig *v1.ArtifactSignature) error {
+	payload, err := base64.StdEncoding.DecodeString(sig.Payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode the payload of the signature of %s %s", sig.Kind, sig.Name)
+	}
+	data, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode the signature of %s %s", sig.Kind, sig.Name)
+	}
+	signature := ecdsaSignature{}
+	_, err = asn1.Unmarshal(data, &signature)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the signature of %s %s", sig.Kind, sig.Name)
+	}
+	hash := sha256.Sum256(payload)
+	verified := false
+	for _, key := range v.keys {
+		if ecdsa.Verify(key, hash[:], signature.R, signature.S) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return fmt.Errorf("the signature of %s %s was not made by a trusted key", sig.Kind, sig.Name)
+	}
+
+	// lets make sure the signature is for this artifact and has not been copied from another one
+	signed := &Payload{}
+	err = json.Unmarshal(payload, signed)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the payload of the signature of %s %s", sig.Kind, sig.Name)
+	}
+	expected := newPayload(sig.Kind, sig.Name, sig.Version, sig.Digest)
+	if signed.Critical != expected.Critical || signed.Optional["version"] != sig.Version {
+		return fmt.Errorf("the signature of %s %s is for %s %s version %s", sig.Kind, sig.Name,
+			signed.Critical.Identity.DockerReference, signed.Critical.Image.DockerManifestDigest, signed.Optional["version"])
+	}
+	return nil
+}
+
+// FileDigest returns the sha256 digest of the file such as a chart archive
+func FileDigest(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ParseImage splits an image reference such as gcr.io/myorg/myapp:1.0.0@sha256:abc123 into its repository, tag
+// and digest
+func ParseImage(image string) (string, string, string) {
+	repository, tag, digest := image, "", ""
+	if idx := strings.Index(repository, "@"); idx >= 0 {
+		repository, digest = repository[:idx], repository[idx+1:]
+	}
+	if idx := strings.LastIndex(repository, ":"); idx > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:idx], repository[idx+1:]
+	}
+	return repository, tag, digest
+}
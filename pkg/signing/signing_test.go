@@ -0,0 +1,213 @@
+package signing_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestLoadPrivateKey(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	loaded, err := signing.LoadPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, key.D, loaded.D)
+
+	encrypted := encryptCosignKey(t, key, "secret")
+	loaded, err = signing.LoadPrivateKey(encrypted, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, key.D, loaded.D)
+
+	_, err = signing.LoadPrivateKey(encrypted, []byte("wrong"))
+	assert.Error(t, err)
+
+	publicKey, err := signing.MarshalPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	loadedPublicKey, err := signing.LoadPublicKey(publicKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey.X, loadedPublicKey.X)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+	signer, policy := createSigner(t)
+	verifier, err := signing.NewVerifier(policy)
+	require.NoError(t, err)
+
+	sig, err := signer.Sign(v1.ArtifactKindImage, "gcr.io/myorg/myapp", "0.0.1", "sha256:abc123")
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify(sig))
+
+	copied := *sig
+	copied.Name = "gcr.io/myorg/other"
+	assert.Error(t, verifier.Verify(&copied), "the signature should not verify a different image")
+
+	copied = *sig
+	copied.Digest = "sha256:def456"
+	assert.Error(t, verifier.Verify(&copied), "the signature should not verify a different digest")
+
+	otherSigner, _ := createSigner(t)
+	other, err := otherSigner.Sign(v1.ArtifactKindImage, "gcr.io/myorg/myapp", "0.0.1", "sha256:abc123")
+	require.NoError(t, err)
+	assert.Error(t, verifier.Verify(other), "the signature should not verify with an untrusted key")
+}
+
+func TestVerifyRelease(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-signing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "myapp-0.0.1.tgz")
+	require.NoError(t, ioutil.WriteFile(archive, []byte("chart"), 0600))
+	digest, err := signing.FileDigest(archive)
+	require.NoError(t, err)
+
+	signer, policy := createSigner(t)
+	chartSig, err := signer.Sign(v1.ArtifactKindChart, "myapp", "0.0.1", digest)
+	require.NoError(t, err)
+	imageSig, err := signer.Sign(v1.ArtifactKindImage, "gcr.io/myorg/myapp", "0.0.1", "sha256:abc123")
+	require.NoError(t, err)
+	previousSig, err := signer.Sign(v1.ArtifactKindChart, "myapp", "0.0.2", digest)
+	require.NoError(t, err)
+
+	releases := []v1.Release{
+		{Spec: v1.ReleaseSpec{Name: "myapp", Version: "0.0.1"}},
+		{Spec: v1.ReleaseSpec{Name: "myapp", Version: "0.0.2", Signatures: []v1.ArtifactSignature{*previousSig}}},
+		{Spec: v1.ReleaseSpec{Name: "myapp", Version: "v0.0.1", Signatures: []v1.ArtifactSignature{*chartSig, *imageSig}}},
+	}
+	verifier, err := signing.NewVerifier(policy)
+	require.NoError(t, err)
+
+	release := signing.FindSignedRelease(releases, "myapp", "0.0.1")
+	require.NotNil(t, release)
+	images := []signing.ImageReference{{Path: []string{"image"}, Repository: "gcr.io/myorg/myapp", Tag: "0.0.1"}}
+	pinned, err := verifier.VerifyRelease(release, "myapp", "0.0.1", digest, images)
+	require.NoError(t, err)
+	require.Len(t, pinned, 1)
+	assert.Equal(t, "gcr.io/myorg/myapp:0.0.1@sha256:abc123", pinned[0].String(), "the image should be resolved to the signed digest")
+
+	_, err = verifier.VerifyRelease(release, "myapp", "0.0.1", "sha256:def456", images)
+	assert.Error(t, err, "the chart digest should match")
+	_, err = verifier.VerifyRelease(&releases[0], "myapp", "0.0.1", "", images)
+	assert.Error(t, err, "the chart is not signed")
+	_, err = verifier.VerifyRelease(nil, "myapp", "0.0.1", "", images)
+	assert.Error(t, err, "there is no release")
+
+	repushed := []signing.ImageReference{{Path: []string{"image"}, Repository: "gcr.io/myorg/myapp", Tag: "0.0.1", Digest: "sha256:def456"}}
+	_, err = verifier.VerifyRelease(release, "myapp", "0.0.1", digest, repushed)
+	assert.Error(t, err, "an image deployed by a digest which was not signed should fail")
+	otherImage := []signing.ImageReference{{Path: []string{"image"}, Repository: "gcr.io/myorg/other", Tag: "0.0.1"}}
+	_, err = verifier.VerifyRelease(release, "myapp", "0.0.1", digest, otherImage)
+	assert.Error(t, err, "the signature of another image should not verify the image")
+	_, err = verifier.VerifyRelease(release, "myapp", "0.0.1", digest, nil)
+	assert.Error(t, err, "a chart without images cannot have its images verified")
+
+	imagesUnsigned := &v1.Release{Spec: v1.ReleaseSpec{Signatures: []v1.ArtifactSignature{*chartSig}}}
+	_, err = verifier.VerifyRelease(imagesUnsigned, "myapp", "0.0.1", "", images)
+	assert.Error(t, err, "the images are not signed")
+	policy.AllowUnsignedImages = true
+	pinned, err = verifier.VerifyRelease(imagesUnsigned, "myapp", "0.0.1", "", images)
+	require.NoError(t, err)
+	assert.Equal(t, images, pinned, "unsigned images should be deployed by tag")
+
+	policy.Exclude = []string{"nginx-*"}
+	assert.True(t, verifier.IsExcluded("nginx-ingress"))
+	assert.False(t, verifier.IsExcluded("myapp"))
+}
+
+func TestChartImages(t *testing.T) {
+	t.Parallel()
+	overrides := map[string]interface{}{
+		"worker": map[string]interface{}{
+			"image": map[string]interface{}{"tag": "0.0.2"},
+		},
+	}
+	images, err := signing.ChartImages(filepath.Join("test_data", "myapp"), overrides)
+	require.NoError(t, err)
+	assert.Equal(t, []signing.ImageReference{
+		{Path: []string{"image"}, Repository: "gcr.io/myorg/myapp", Tag: "0.0.1"},
+		{Path: []string{"worker", "image"}, Repository: "gcr.io/myorg/myapp-worker", Tag: "0.0.2"},
+	}, images)
+
+	images[0].Digest = "sha256:abc123"
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "0.0.1@sha256:abc123"},
+	}, signing.PinnedValues(images))
+	assert.Equal(t, []string{"image.tag=0.0.1@sha256:abc123"}, signing.PinnedSetValues(images))
+
+	images, err = signing.ChartImages(filepath.Join("test_data", "myapp"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/myorg/myapp-worker:0.0.1@sha256:def456", images[1].String())
+}
+
+func TestParseImage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		image, repository, tag, digest string
+	}{
+		{"gcr.io/myorg/myapp:0.0.1", "gcr.io/myorg/myapp", "0.0.1", ""},
+		{"localhost:5000/myapp", "localhost:5000/myapp", "", ""},
+		{"myapp:0.0.1@sha256:abc123", "myapp", "0.0.1", "sha256:abc123"},
+	}
+	for _, test := range tests {
+		repository, tag, digest := signing.ParseImage(test.image)
+		assert.Equal(t, test.repository, repository, test.image)
+		assert.Equal(t, test.tag, tag, test.image)
+		assert.Equal(t, test.digest, digest, test.image)
+	}
+}
+
+func createSigner(t *testing.T) (*signing.Signer, *v1.TrustPolicy) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signing.NewSigner(key)
+	require.NoError(t, err)
+	publicKey, err := signing.MarshalPublicKey(signer.PublicKey())
+	require.NoError(t, err)
+	return signer, &v1.TrustPolicy{PublicKeys: []string{string(publicKey)}}
+}
+
+// encryptCosignKey encrypts the key in the format of 'cosign generate-key-pair' using cheap scrypt parameters
+func encryptCosignKey(t *testing.T, key *ecdsa.PrivateKey, password string) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	salt := []byte("0123456789abcdef0123456789abcdef")
+	secret, err := scrypt.Key([]byte(password), salt, 1024, 8, 1, 32)
+	require.NoError(t, err)
+	var nonce [24]byte
+	var secretKey [32]byte
+	copy(secretKey[:], secret)
+	encrypted := map[string]interface{}{
+		"kdf": map[string]interface{}{
+			"name":   "scrypt",
+			"params": map[string]int{"N": 1024, "r": 8, "p": 1},
+			"salt":   salt,
+		},
+		"cipher": map[string]interface{}{
+			"name":  "nacl/secretbox",
+			"nonce": nonce[:],
+		},
+		"ciphertext": secretbox.Seal(nil, der, &nonce, &secretKey),
+	}
+	data, err := json.Marshal(encrypted)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED COSIGN PRIVATE KEY", Bytes: data})
+}
@@ -0,0 +1,319 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Runner runs the LocalStages of a pipeline against a local Runtime.
+//
+// Each workspace used by the pipeline starts off as a copy of SourceDir so that the source directory itself is
+// never modified. As on a cluster, parallel branches each get their own copy of the workspaces they use, and
+// changes they make to them are not seen by the stages after the parallel stage.
+type Runner struct {
+	Runtime Runtime
+	// BuildName is the name shown in the log headers
+	BuildName string
+	// SourceDir is the directory containing the source code to build
+	SourceDir string
+	// WorkDir is where the workspaces and stashes are created
+	WorkDir string
+	// WorkspaceRoot is the directory inside the steps that the workspace is mounted at
+	WorkspaceRoot string
+	Out           io.Writer
+
+	lock     sync.Mutex
+	counter  int
+	stashDir string
+}
+
+// workspaces holds the directories of the workspaces visible to a stage
+type workspaces struct {
+	runner *Runner
+	parent *workspaces
+	lock   sync.Mutex
+	dirs   map[string]string
+}
+
+// Run runs the stages in order
+func (r *Runner) Run(stages []*syntax.LocalStage) error {
+	if r.WorkspaceRoot == "" {
+		r.WorkspaceRoot = filepath.Join(syntax.WorkingDirRoot, "source")
+	}
+	if r.Out == nil {
+		r.Out = os.Stdout
+	}
+	r.stashDir = filepath.Join(r.WorkDir, "stashes")
+	ws := r.newWorkspaces(nil)
+	for _, s := range stages {
+		err := r.runStage(s, ws, r.Out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) runStage(stage *syntax.LocalStage, ws *workspaces, out io.Writer) error {
+	if len(stage.Steps) > 0 {
+		return r.runSteps(stage, ws, out)
+	}
+	for _, s := range stage.Stages {
+		err := r.runStage(s, ws, out)
+		if err != nil {
+			return err
+		}
+	}
+	if len(stage.Parallel) > 0 {
+		return r.runParallel(stage.Parallel, ws, out)
+	}
+	return nil
+}
+
+// runParallel runs the branches concurrently. The output of each branch is streamed a line at a time with the name of
+// the branch as a prefix so that the interleaved logs of different branches can be told apart.
+func (r *Runner) runParallel(branches []*syntax.LocalStage, ws *workspaces, out io.Writer) error {
+	outLock := &sync.Mutex{}
+	prefix := ""
+	if pw, ok := out.(*prefixWriter); ok {
+		// nested parallel stages write straight to the real output with the names of all their parent branches
+		out = pw.out
+		outLock = pw.lock
+		prefix = pw.prefix
+	}
+	prefixColor := color.New(color.FgCyan)
+	prefixColor.EnableColor()
+	var wg sync.WaitGroup
+	errs := make([]error, len(branches))
+	for i, branch := range branches {
+		wg.Add(1)
+		go func(i int, branch *syntax.LocalStage) {
+			defer wg.Done()
+			pw := &prefixWriter{out: out, lock: outLock, prefix: prefix + prefixColor.Sprintf("[%s] ", branch.Name)}
+			errs[i] = r.runStage(branch, r.newWorkspaces(ws), pw)
+			pw.flush()
+		}(i, branch)
+	}
+	wg.Wait()
+
+	var messages []string
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "\n"))
+	}
+	return nil
+}
+
+func (r *Runner) runSteps(stage *syntax.LocalStage, ws *workspaces, out io.Writer) error {
+	infoColor := color.New(color.FgGreen)
+	infoColor.EnableColor()
+	errorColor := color.New(color.FgRed)
+	errorColor.EnableColor()
+
+	dir, err := ws.get(stage.Workspace)
+	if err != nil {
+		return err
+	}
+	if stage.Unstash != nil {
+		err = r.unstash(stage.Unstash, dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unstash %s for stage %s", stage.Unstash.Name, stage.Name)
+		}
+	}
+
+	for i := range stage.Steps {
+		step := &stage.Steps[i]
+		_, err = fmt.Fprintf(out, "\nShowing logs for build %v stage %s and container %s\n",
+			infoColor.Sprintf(r.BuildName), infoColor.Sprintf(stage.Name), infoColor.Sprintf(step.Name))
+		if err != nil {
+			return err
+		}
+		err = r.Runtime.RunStep(step, r.WorkspaceRoot, dir, out)
+		if f, ok := out.(*prefixWriter); ok {
+			// end any partial last line of the step so the next step starts on its own line
+			f.flush()
+		}
+		if err != nil {
+			message := fmt.Sprintf("Pipeline failed on stage '%s' : container '%s'. The execution of the pipeline has stopped.", stage.Name, step.Name)
+			fmt.Fprintf(out, "\n%s\n", errorColor.Sprintf(message)) // #nosec
+			return errors.New(message)
+		}
+	}
+
+	if stage.Stash != nil {
+		err = r.stash(stage.Stash, dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to stash %s for stage %s", stage.Stash.Name, stage.Name)
+		}
+	}
+	return nil
+}
+
+// stash copies the files matching the stash pattern in the workspace dir into the stash directory
+func (r *Runner) stash(stash *syntax.Stash, dir string) error {
+	stashDir := filepath.Join(r.stashDir, util.ToValidFileSystemName(stash.Name))
+	err := os.RemoveAll(stashDir)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(stashDir, util.DefaultWritePermissions)
+	if err != nil {
+		return err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, stash.Files))
+	if err != nil {
+		return errors.Wrapf(err, "invalid stash files pattern %s", stash.Files)
+	}
+	if len(matches) == 0 {
+		return errors.Errorf("no files match %s", stash.Files)
+	}
+	for _, match := range matches {
+		rel, err := filepath.Rel(dir, match)
+		if err != nil {
+			return err
+		}
+		err = copyFileOrDir(match, filepath.Join(stashDir, rel))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unstash copies a previously stashed set of files into the workspace dir
+func (r *Runner) unstash(unstash *syntax.Unstash, dir string) error {
+	stashDir := filepath.Join(r.stashDir, util.ToValidFileSystemName(unstash.Name))
+	exists, err := util.DirExists(stashDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.Errorf("no stash called %s has been created by an earlier stage", unstash.Name)
+	}
+	targetDir := dir
+	if filepath.IsAbs(unstash.Dir) {
+		targetDir = localPath(unstash.Dir, r.WorkspaceRoot, dir)
+	} else if unstash.Dir != "" {
+		targetDir = filepath.Join(dir, unstash.Dir)
+	}
+	return util.CopyDirOverwrite(stashDir, targetDir)
+}
+
+func (r *Runner) newWorkspaces(parent *workspaces) *workspaces {
+	return &workspaces{
+		runner: r,
+		parent: parent,
+		dirs:   map[string]string{},
+	}
+}
+
+func (r *Runner) nextDir(name string) (string, error) {
+	r.lock.Lock()
+	r.counter++
+	dir := filepath.Join(r.WorkDir, "workspaces", fmt.Sprintf("%s-%d", util.ToValidFileSystemName(name), r.counter))
+	r.lock.Unlock()
+	return dir, os.MkdirAll(dir, util.DefaultWritePermissions)
+}
+
+// get returns the directory of the named workspace, creating it if it has not been used yet. The "empty" workspace
+// is always a new empty directory.
+func (w *workspaces) get(name string) (string, error) {
+	if name == "empty" {
+		return w.runner.nextDir(name)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if dir, ok := w.dirs[name]; ok {
+		return dir, nil
+	}
+	from := w.runner.SourceDir
+	if w.parent != nil {
+		parentDir, err := w.parent.get(name)
+		if err != nil {
+			return "", err
+		}
+		from = parentDir
+	}
+	dir, err := w.runner.nextDir(name)
+	if err != nil {
+		return "", err
+	}
+	err = util.CopyDirOverwrite(from, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create workspace %s", name)
+	}
+	w.dirs[name] = dir
+	return dir, nil
+}
+
+func copyFileOrDir(src string, dst string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return util.CopyDirOverwrite(src, dst)
+	}
+	err = os.MkdirAll(filepath.Dir(dst), util.DefaultWritePermissions)
+	if err != nil {
+		return err
+	}
+	return util.CopyFile(src, dst)
+}
+
+// prefixWriter writes each complete line to the underlying writer as soon as it is written, starting with the prefix.
+// The lock is shared by all the writers of a parallel stage so that their lines are never mixed up.
+type prefixWriter struct {
+	out    io.Writer
+	lock   *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		idx := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+		err := w.writeLine(w.buf.Next(idx + 1))
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// flush writes out any partial line which has not yet been ended with a newline
+func (w *prefixWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.writeLine(append(w.buf.Next(w.buf.Len()), '\n')) // #nosec
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err := io.WriteString(w.out, w.prefix+string(line))
+	return err
+}
+
+// CreateWorkDir creates a temporary directory for the workspaces and stashes of a run
+func CreateWorkDir() (string, error) {
+	return ioutil.TempDir("", "jx-syntax-run-")
+}
@@ -0,0 +1,26 @@
+package local
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriterStreamsLines(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &prefixWriter{out: out, lock: &sync.Mutex{}, prefix: "[unit] "}
+
+	_, err := w.Write([]byte("first\nsec"))
+	assert.NoError(t, err)
+	// complete lines are written straight away rather than when the step completes
+	assert.Equal(t, "[unit] first\n", out.String())
+
+	_, err = w.Write([]byte("ond\nthird"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[unit] first\n[unit] second\n", out.String())
+
+	w.flush()
+	assert.Equal(t, "[unit] first\n[unit] second\n[unit] third\n", out.String())
+}
@@ -0,0 +1,125 @@
+package local_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/tekton/local"
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func shellStep(name string, command string) corev1.Container {
+	return corev1.Container{
+		Name:       name,
+		Image:      "some-image",
+		Command:    []string{"/bin/sh", "-c"},
+		Args:       []string{command},
+		WorkingDir: "/workspace/source",
+		Env:        []corev1.EnvVar{{Name: "GREETING", Value: "hello"}},
+	}
+}
+
+func TestRunnerWorkspacesAndStashes(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "test-local-runner-source")
+	require.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+	workDir, err := ioutil.TempDir("", "test-local-runner-work")
+	require.NoError(t, err)
+	defer os.RemoveAll(workDir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("readme"), 0644))
+
+	stages := []*syntax.LocalStage{
+		{
+			Name:      "build",
+			Workspace: "default",
+			Stash:     &syntax.Stash{Name: "binaries", Files: "bin/*"},
+			Steps: []corev1.Container{
+				shellStep("compile", "mkdir bin && echo $GREETING > bin/app && cat README.md"),
+			},
+		},
+		{
+			Name:      "tests",
+			Workspace: "default",
+			Parallel: []*syntax.LocalStage{
+				{
+					Name:      "unit",
+					Workspace: "default",
+					Steps:     []corev1.Container{shellStep("unit", "touch unit-ran && cat bin/app")},
+				},
+				{
+					Name:      "integration",
+					Workspace: "empty",
+					Unstash:   &syntax.Unstash{Name: "binaries", Dir: "target"},
+					Steps:     []corev1.Container{shellStep("integration", "test ! -f README.md && cat target/bin/app")},
+				},
+			},
+		},
+		{
+			Name:      "release",
+			Workspace: "default",
+			Steps:     []corev1.Container{shellStep("release", "test ! -f unit-ran && echo released")},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	runner := &local.Runner{
+		Runtime:   &local.ProcessRuntime{},
+		BuildName: "local",
+		SourceDir: sourceDir,
+		WorkDir:   workDir,
+		Out:       out,
+	}
+	err = runner.Run(stages)
+	require.NoError(t, err, out.String())
+
+	output := out.String()
+	assert.Contains(t, output, "container")
+	assert.Contains(t, output, "readme")
+	assert.Contains(t, output, "released")
+	// the output of the parallel branches is prefixed with the branch name
+	assert.Regexp(t, `\[unit\] \S*hello`, output)
+	assert.Regexp(t, `\[integration\] \S*hello`, output)
+
+	// the source directory itself is never changed
+	_, err = os.Stat(filepath.Join(sourceDir, "bin"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunnerStopsOnFailure(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "test-local-runner-source")
+	require.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+	workDir, err := ioutil.TempDir("", "test-local-runner-work")
+	require.NoError(t, err)
+	defer os.RemoveAll(workDir)
+
+	stages := []*syntax.LocalStage{
+		{
+			Name:      "build",
+			Workspace: "default",
+			Steps: []corev1.Container{
+				shellStep("fail", "exit 1"),
+				shellStep("never", "echo should not run"),
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	runner := &local.Runner{
+		Runtime:   &local.ProcessRuntime{},
+		SourceDir: sourceDir,
+		WorkDir:   workDir,
+		Out:       out,
+	}
+	err = runner.Run(stages)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Pipeline failed on stage 'build' : container 'fail'")
+	assert.NotContains(t, out.String(), "should not run")
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RuntimeDocker runs steps in containers using docker
+	RuntimeDocker = "docker"
+	// RuntimePodman runs steps in containers using podman
+	RuntimePodman = "podman"
+	// RuntimeProcess runs steps as processes on the local machine, ignoring their images
+	RuntimeProcess = "process"
+)
+
+// Runtimes the names of the supported runtimes
+var Runtimes = []string{RuntimeDocker, RuntimePodman, RuntimeProcess}
+
+// Runtime runs a single step of a pipeline
+type Runtime interface {
+	// RunStep runs the step with the given workspace directory mounted at the step's workspace root, writing
+	// the output of the step to out
+	RunStep(step *corev1.Container, workspaceRoot string, workspaceDir string, out io.Writer) error
+}
+
+// NewRuntime creates the runtime for the given name
+func NewRuntime(name string) (Runtime, error) {
+	switch name {
+	case RuntimeDocker, RuntimePodman:
+		return &ContainerRuntime{Binary: name}, nil
+	case RuntimeProcess:
+		return &ProcessRuntime{}, nil
+	default:
+		return nil, util.InvalidOption("runtime", name, Runtimes)
+	}
+}
+
+// ContainerRuntime runs each step in its image using a docker compatible command line
+type ContainerRuntime struct {
+	Binary string
+}
+
+// RunStep runs the step in a container
+func (r *ContainerRuntime) RunStep(step *corev1.Container, workspaceRoot string, workspaceDir string, out io.Writer) error {
+	if step.Image == "" {
+		return errors.Errorf("no image specified for step %s", step.Name)
+	}
+	cmd := util.Command{
+		Name: r.Binary,
+		Args: containerArgs(step, workspaceRoot, workspaceDir),
+		Out:  out,
+		Err:  out,
+	}
+	_, err := cmd.RunWithoutRetry()
+	return err
+}
+
+// containerArgs returns the arguments used to run the step in a container
+func containerArgs(step *corev1.Container, workspaceRoot string, workspaceDir string) []string {
+	args := []string{"run", "--rm", "-v", fmt.Sprintf("%s:%s", workspaceDir, workspaceRoot)}
+	if step.WorkingDir != "" {
+		args = append(args, "-w", step.WorkingDir)
+	}
+	env := envMap(step.Env)
+	for _, k := range util.SortedMapKeys(env) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	if len(step.Command) > 0 {
+		args = append(args, "--entrypoint", step.Command[0], step.Image)
+		args = append(args, step.Command[1:]...)
+	} else {
+		args = append(args, step.Image)
+	}
+	return append(args, step.Args...)
+}
+
+// ProcessRuntime runs each step as a process on the local machine
+type ProcessRuntime struct {
+}
+
+// RunStep runs the step as a local process
+func (r *ProcessRuntime) RunStep(step *corev1.Container, workspaceRoot string, workspaceDir string, out io.Writer) error {
+	commandAndArgs := append(append([]string{}, step.Command...), step.Args...)
+	if len(commandAndArgs) == 0 {
+		return nil
+	}
+	env := envMap(step.Env)
+	for k, v := range env {
+		env[k] = strings.Replace(v, workspaceRoot, workspaceDir, -1)
+	}
+	cmd := util.Command{
+		Name: commandAndArgs[0],
+		Args: commandAndArgs[1:],
+		Dir:  localPath(step.WorkingDir, workspaceRoot, workspaceDir),
+		Out:  out,
+		Err:  out,
+		Env:  env,
+	}
+	_, err := cmd.RunWithoutRetry()
+	return err
+}
+
+// localPath maps a path inside the workspace root onto the local workspace directory
+func localPath(path string, workspaceRoot string, workspaceDir string) string {
+	if path == "" {
+		return workspaceDir
+	}
+	rel, err := filepath.Rel(workspaceRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.Join(workspaceDir, rel)
+}
+
+func envMap(envVars []corev1.EnvVar) map[string]string {
+	m := map[string]string{}
+	for _, e := range envVars {
+		m[e.Name] = e.Value
+	}
+	return m
+}
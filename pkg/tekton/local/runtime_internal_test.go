@@ -0,0 +1,35 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestContainerArgs(t *testing.T) {
+	step := &corev1.Container{
+		Name:       "build",
+		Image:      "golang:1.12",
+		Command:    []string{"/bin/sh", "-c"},
+		Args:       []string{"make build"},
+		WorkingDir: "/workspace/source/cmd",
+		Env: []corev1.EnvVar{
+			{Name: "GOPROXY", Value: "https://proxy.golang.org"},
+			{Name: "CGO_ENABLED", Value: "0"},
+		},
+	}
+
+	args := containerArgs(step, "/workspace/source", "/tmp/ws")
+	assert.Equal(t, []string{
+		"run", "--rm", "-v", "/tmp/ws:/workspace/source", "-w", "/workspace/source/cmd",
+		"-e", "CGO_ENABLED=0", "-e", "GOPROXY=https://proxy.golang.org",
+		"--entrypoint", "/bin/sh", "golang:1.12", "-c", "make build",
+	}, args)
+}
+
+func TestLocalPath(t *testing.T) {
+	assert.Equal(t, "/tmp/ws", localPath("", "/workspace/source", "/tmp/ws"))
+	assert.Equal(t, "/tmp/ws/cmd", localPath("/workspace/source/cmd", "/workspace/source", "/tmp/ws"))
+	assert.Equal(t, "/home/jenkins", localPath("/home/jenkins", "/workspace/source", "/tmp/ws"))
+}
@@ -62,6 +62,9 @@ type StageInfo struct {
 	Parallel []*StageInfo
 	Stages   []*StageInfo
 
+	// Skipped is true if this stage will not be run because its when conditions were not met
+	Skipped bool
+
 	// This field will be non-empty if this is a nested stage, containing a list of  the names of all its parent stages with the top-level parent first
 	Parents []string
 }
@@ -435,6 +438,7 @@ func stageAndChildrenToStageInfo(psc *v1.PipelineStageAndChildren, parents []str
 	si := &StageInfo{
 		Name:    psc.Stage.Name,
 		Parents: parents,
+		Skipped: psc.Stage.Skipped,
 	}
 	if psc.Stage.TaskRef != nil {
 		si.Task = *psc.Stage.TaskRef
@@ -0,0 +1,296 @@
+package syntax
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+const (
+	// GraphFormatDot is the Graphviz DOT output format
+	GraphFormatDot = "dot"
+	// GraphFormatMermaid is the Mermaid output format
+	GraphFormatMermaid = "mermaid"
+)
+
+// GraphFormats the supported output formats of a PipelineGraph
+var GraphFormats = []string{GraphFormatDot, GraphFormatMermaid}
+
+// PipelineGraph is the structure of a pipeline as generated by GenerateCRDs, for rendering as a diagram
+type PipelineGraph struct {
+	Stages []*GraphStage
+	Edges  []GraphEdge
+}
+
+// GraphStage is a stage in a PipelineGraph. Stages with steps have a TaskName, other stages contain either
+// sequential or parallel child stages.
+type GraphStage struct {
+	ID        string
+	Name      string
+	TaskName  string
+	Workspace string
+	Parallel  bool
+	Skipped   bool
+	// Conditional is true if the stage has when conditions which could not be decided, so it may or may not run
+	Conditional bool
+	Overrides   []string
+	Children    []*GraphStage
+}
+
+// GraphEdge is a dependency between two stages with steps. Workspace edges point from the stage providing
+// a workspace to the stage which uses it, other edges point from a stage to the stages which run after it.
+type GraphEdge struct {
+	From      string
+	To        string
+	Workspace bool
+}
+
+// CreatePipelineGraph creates the graph of the pipeline from the CRDs generated for it. The parsed pipeline is used to
+// find the workspace names and when conditions of the stages and the overrides are the ones which were applied to the
+// pipeline. The when context is the one the CRDs were generated with, and its unknown values make stages conditional.
+func CreatePipelineGraph(parsed *ParsedPipeline, pipeline *tektonv1alpha1.Pipeline, structure *v1.PipelineStructure, overrides []*PipelineOverride, whenContext *WhenContext) *PipelineGraph {
+	workspaces := make(map[string]string)
+	collectWorkspaces(parsed.Stages, workspaces)
+	conditional := make(map[string]bool)
+	collectConditional(parsed.Stages, whenContext, conditional)
+
+	graph := &PipelineGraph{}
+	counter := 0
+	byTask := make(map[string]*GraphStage)
+
+	var add func(s *v1.PipelineStructureStage, parentWorkspace string) *GraphStage
+	add = func(s *v1.PipelineStructureStage, parentWorkspace string) *GraphStage {
+		counter++
+		gs := &GraphStage{
+			ID:        fmt.Sprintf("stage%d", counter),
+			Name:      s.Name,
+			Workspace: parentWorkspace,
+			Parallel:  len(s.Parallel) > 0,
+			Skipped:   s.Skipped,
+		}
+		gs.Conditional = !gs.Skipped && conditional[s.Name]
+		if ws, ok := workspaces[s.Name]; ok {
+			gs.Workspace = ws
+		}
+		if s.TaskRef != nil {
+			gs.TaskName = *s.TaskRef
+			byTask[gs.TaskName] = gs
+		}
+		if len(s.Stages) == 0 && len(s.Parallel) == 0 {
+			gs.Overrides = describeOverrides(s.Name, overrides)
+		}
+		for _, name := range append(append([]string{}, s.Stages...), s.Parallel...) {
+			if child := structure.GetStage(name); child != nil {
+				gs.Children = append(gs.Children, add(child, gs.Workspace))
+			}
+		}
+		return gs
+	}
+	// use the order of the parsed pipeline as skipped stages are added to the end of the structure
+	for _, ps := range parsed.Stages {
+		if s := structure.GetStage(ps.Name); s != nil {
+			graph.Stages = append(graph.Stages, add(s, "default"))
+		}
+	}
+
+	if pipeline == nil {
+		return graph
+	}
+	pipelineTaskStages := make(map[string]*GraphStage)
+	for _, pt := range pipeline.Spec.Tasks {
+		if gs, ok := byTask[pt.TaskRef.Name]; ok {
+			pipelineTaskStages[pt.Name] = gs
+		}
+	}
+	for _, pt := range pipeline.Spec.Tasks {
+		to, ok := pipelineTaskStages[pt.Name]
+		if !ok {
+			continue
+		}
+		for _, after := range pt.RunAfter {
+			if from, ok := pipelineTaskStages[after]; ok {
+				graph.Edges = append(graph.Edges, GraphEdge{From: from.ID, To: to.ID})
+			}
+		}
+		if pt.Resources != nil {
+			for _, input := range pt.Resources.Inputs {
+				for _, provider := range input.From {
+					if from, ok := pipelineTaskStages[provider]; ok {
+						graph.Edges = append(graph.Edges, GraphEdge{From: from.ID, To: to.ID, Workspace: true})
+					}
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// collectWorkspaces records the explicitly configured workspace of each stage by name
+func collectWorkspaces(stages []Stage, workspaces map[string]string) {
+	for _, s := range stages {
+		if s.Options != nil && s.Options.Workspace != nil {
+			workspaces[s.Name] = *s.Options.Workspace
+		}
+		collectWorkspaces(s.Stages, workspaces)
+		collectWorkspaces(s.Parallel, workspaces)
+	}
+}
+
+// collectConditional records the stages whose when conditions can't be decided with the context by name
+func collectConditional(stages []Stage, ctx *WhenContext, conditional map[string]bool) {
+	for _, s := range stages {
+		if s.When.IsConditional(ctx) {
+			conditional[s.Name] = true
+		}
+		collectConditional(s.Stages, ctx, conditional)
+		collectConditional(s.Parallel, ctx, conditional)
+	}
+}
+
+// describeOverrides returns a short description of each override which applies to the stage
+func describeOverrides(stageName string, overrides []*PipelineOverride) []string {
+	var answer []string
+	for _, o := range overrides {
+		if o == nil || !o.MatchesStage(stageName) {
+			continue
+		}
+		if o.Step != nil || len(o.Steps) > 0 {
+			overrideType := StepOverrideReplace
+			if o.Type != nil {
+				overrideType = *o.Type
+			}
+			target := "all steps"
+			if o.Name != "" {
+				target = "step " + o.Name
+			}
+			answer = append(answer, fmt.Sprintf("%s %s", overrideType, target))
+		}
+		if o.Agent != nil {
+			answer = append(answer, "agent")
+		}
+		if o.ContainerOptions != nil {
+			answer = append(answer, "containerOptions")
+		}
+		if len(o.Volumes) > 0 {
+			answer = append(answer, "volumes")
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+func (s *GraphStage) labelLines() []string {
+	lines := []string{s.Name}
+	if s.Parallel {
+		lines[0] += " (parallel)"
+	}
+	if s.Skipped {
+		lines[0] += " (skipped)"
+	}
+	if s.Conditional {
+		lines[0] += " (conditional)"
+	}
+	if s.TaskName != "" || len(s.Children) == 0 {
+		lines = append(lines, "workspace: "+s.Workspace)
+	}
+	for _, o := range s.Overrides {
+		lines = append(lines, "override: "+o)
+	}
+	return lines
+}
+
+// Dot renders the graph in the Graphviz DOT format
+func (g *PipelineGraph) Dot() string {
+	var b strings.Builder
+	b.WriteString("digraph pipeline {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	var write func(s *GraphStage, indent string)
+	write = func(s *GraphStage, indent string) {
+		if len(s.Children) == 0 {
+			style := ""
+			if s.Skipped {
+				style = ", style=dashed, color=grey"
+			}
+			fmt.Fprintf(&b, "%s%s [label=%s%s];\n", indent, s.ID, dotQuote(strings.Join(s.labelLines(), "\n")), style)
+			return
+		}
+		fmt.Fprintf(&b, "%ssubgraph cluster_%s {\n", indent, s.ID)
+		fmt.Fprintf(&b, "%s  label=%s;\n", indent, dotQuote(strings.Join(s.labelLines(), "\n")))
+		if s.Parallel {
+			fmt.Fprintf(&b, "%s  style=dashed;\n", indent)
+		}
+		for _, c := range s.Children {
+			write(c, indent+"  ")
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	for _, s := range g.Stages {
+		write(s, "  ")
+	}
+	for _, e := range g.Edges {
+		if e.Workspace {
+			fmt.Fprintf(&b, "  %s -> %s [style=dotted, label=\"workspace\"];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph in the Mermaid flowchart format
+func (g *PipelineGraph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	var write func(s *GraphStage, indent string)
+	write = func(s *GraphStage, indent string) {
+		label := mermaidQuote(strings.Join(s.labelLines(), "<br/>"))
+		if len(s.Children) == 0 {
+			fmt.Fprintf(&b, "%s%s[%s]\n", indent, s.ID, label)
+			if s.Skipped {
+				fmt.Fprintf(&b, "%sstyle %s stroke-dasharray: 5 5,color:grey\n", indent, s.ID)
+			}
+			return
+		}
+		fmt.Fprintf(&b, "%ssubgraph %s[%s]\n", indent, s.ID, label)
+		for _, c := range s.Children {
+			write(c, indent+"  ")
+		}
+		fmt.Fprintf(&b, "%send\n", indent)
+	}
+	for _, s := range g.Stages {
+		write(s, "  ")
+	}
+	for _, e := range g.Edges {
+		if e.Workspace {
+			fmt.Fprintf(&b, "  %s -. workspace .-> %s\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+		}
+	}
+	return b.String()
+}
+
+// Render renders the graph in the given format
+func (g *PipelineGraph) Render(format string) (string, error) {
+	switch format {
+	case GraphFormatDot:
+		return g.Dot(), nil
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %s. Supported formats: %s", format, strings.Join(GraphFormats, ", "))
+	}
+}
+
+func dotQuote(text string) string {
+	return `"` + strings.Replace(strings.Replace(text, `"`, `\"`, -1), "\n", `\n`, -1) + `"`
+}
+
+func mermaidQuote(text string) string {
+	return `"` + strings.Replace(text, `"`, "#quot;", -1) + `"`
+}
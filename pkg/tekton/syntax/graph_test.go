@@ -0,0 +1,107 @@
+package syntax_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/tekton/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePipelineGraph(t *testing.T) {
+	workspace := "custom"
+	replace := syntax.StepOverrideReplace
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{Image: "some-image"},
+		Stages: []syntax.Stage{
+			{
+				Name:  "Build",
+				Steps: []syntax.Step{{Name: "compile", Command: "make"}},
+			},
+			{
+				Name: "Tests",
+				Parallel: []syntax.Stage{
+					{
+						Name:  "Unit",
+						Steps: []syntax.Step{{Command: "make test"}},
+					},
+					{
+						Name:    "Lint",
+						Options: &syntax.StageOptions{Workspace: &workspace},
+						Steps:   []syntax.Step{{Command: "make lint"}},
+					},
+				},
+			},
+			{
+				Name:  "Release",
+				When:  &syntax.When{Branch: []string{"master"}},
+				Steps: []syntax.Step{{Command: "make release"}},
+			},
+			{
+				Name:  "Docs",
+				When:  &syntax.When{ChangedFiles: []string{"docs/**"}, Labels: []string{"docs"}},
+				Steps: []syntax.Step{{Command: "make docs"}},
+			},
+		},
+	}
+	overrides := []*syntax.PipelineOverride{
+		{Stage: "Build", Name: "compile", Type: &replace, Step: &syntax.Step{Command: "make all"}},
+	}
+
+	whenContext := &syntax.WhenContext{Branch: "PR-1", ChangedFilesUnknown: true, LabelsUnknown: true}
+	pipeline, _, structure, err := parsed.DeepCopy().GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "graph",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "graph",
+		SourceDir:          "source",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		WhenContext:        whenContext,
+	})
+	require.NoError(t, err)
+
+	graph := syntax.CreatePipelineGraph(parsed, pipeline, structure, overrides, whenContext)
+	require.Len(t, graph.Stages, 4)
+
+	build := graph.Stages[0]
+	assert.Equal(t, "default", build.Workspace)
+	assert.Equal(t, []string{"replace step compile"}, build.Overrides)
+
+	tests := graph.Stages[1]
+	assert.True(t, tests.Parallel)
+	if assert.Len(t, tests.Children, 2) {
+		unit := tests.Children[0]
+		lint := tests.Children[1]
+		assert.Equal(t, "default", unit.Workspace)
+		assert.Equal(t, "custom", lint.Workspace)
+		assert.Contains(t, graph.Edges, syntax.GraphEdge{From: build.ID, To: unit.ID})
+		assert.Contains(t, graph.Edges, syntax.GraphEdge{From: build.ID, To: lint.ID})
+		assert.Contains(t, graph.Edges, syntax.GraphEdge{From: build.ID, To: unit.ID, Workspace: true})
+		assert.NotContains(t, graph.Edges, syntax.GraphEdge{From: build.ID, To: lint.ID, Workspace: true})
+	}
+
+	release := graph.Stages[2]
+	assert.True(t, release.Skipped)
+	assert.False(t, release.Conditional)
+
+	// the changed files and labels aren't known so the stage may or may not run
+	docs := graph.Stages[3]
+	assert.False(t, docs.Skipped)
+	assert.True(t, docs.Conditional)
+
+	dot := graph.Dot()
+	assert.Contains(t, dot, "digraph pipeline {")
+	assert.Contains(t, dot, "subgraph cluster_"+tests.ID+" {")
+	assert.Contains(t, dot, `label="Build\nworkspace: default\noverride: replace step compile"`)
+	assert.Contains(t, dot, build.ID+" -> "+tests.Children[0].ID+" [style=dotted, label=\"workspace\"];")
+
+	mermaid := graph.Mermaid()
+	assert.Contains(t, mermaid, "graph LR")
+	assert.Contains(t, mermaid, "subgraph "+tests.ID+`["Tests (parallel)"]`)
+	assert.Contains(t, mermaid, build.ID+" --> "+tests.Children[0].ID)
+	assert.Contains(t, mermaid, release.ID+`["Release (skipped)<br/>workspace: default"]`)
+	assert.Contains(t, mermaid, docs.ID+`["Docs (conditional)<br/>workspace: default"]`)
+
+	_, err = graph.Render("svg")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package syntax
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LocalStage is a stage of a pipeline prepared for running outside of a cluster. Its steps are resolved in the same way
+// as they are by GenerateCRDs, with the exception that the step working directories are left absolute rather than
+// being changed to using a "cd" prefix on the command.
+type LocalStage struct {
+	Name      string
+	Depth     int8
+	Workspace string
+	Stash     *Stash
+	Unstash   *Unstash
+	Steps     []corev1.Container
+	Stages    []*LocalStage
+	Parallel  []*LocalStage
+}
+
+// LocalStages translates the pipeline into a tree of LocalStages which can be run without creating any CRDs.
+// Stages whose when conditions don't match the WhenContext of the params are left out.
+func (j *ParsedPipeline) LocalStages(params CRDsFromPipelineParams) ([]*LocalStage, error) {
+	if len(j.Post) != 0 {
+		return nil, errors.New("Post at top level not yet supported")
+	}
+	params.InterpretMode = true
+
+	var parentContainer *corev1.Container
+	if j.Options != nil {
+		parentContainer = j.Options.ContainerOptions
+	}
+
+	filter := newWhenFilter(params.WhenContext, j.GetEnv())
+	stages := filter.filterStages(j.Stages, 0, nil, true)
+	if len(stages) == 0 {
+		return nil, ErrAllStagesSkipped
+	}
+
+	var answer []*LocalStage
+	for _, s := range stages {
+		ls, err := stageToLocalStage(stageToTaskParams{
+			parentParams:    params,
+			stage:           s,
+			baseWorkingDir:  j.WorkingDir,
+			parentEnv:       j.GetEnv(),
+			parentAgent:     j.Agent,
+			parentWorkspace: "default",
+			parentContainer: parentContainer,
+			depth:           0,
+		})
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, ls)
+	}
+	return answer, nil
+}
+
+func stageToLocalStage(params stageToTaskParams) (*LocalStage, error) {
+	if params.stage.Matrix != nil {
+		params.stage = params.stage.expandMatrix()
+	}
+	if len(params.stage.Post) != 0 {
+		return nil, errors.New("post on stages not yet supported")
+	}
+
+	ls := &LocalStage{
+		Name:      params.stage.Name,
+		Depth:     params.depth,
+		Workspace: params.parentWorkspace,
+	}
+
+	stageContainer := &corev1.Container{}
+	if o := params.stage.Options; o != nil {
+		if o.RootOptions != nil && o.ContainerOptions != nil {
+			stageContainer = o.ContainerOptions
+		}
+		if o.Workspace != nil {
+			ls.Workspace = *o.Workspace
+		}
+		ls.Stash = o.Stash
+		ls.Unstash = o.Unstash
+	}
+
+	if params.stage.WorkingDir != nil {
+		params.baseWorkingDir = params.stage.WorkingDir
+	}
+	if params.parentContainer != nil {
+		merged, err := MergeContainers(params.parentContainer, stageContainer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error merging stage and parent container overrides: %s", err)
+		}
+		stageContainer = merged
+	}
+
+	env := scopedEnv(params.stage.GetEnv(), params.parentEnv)
+	agent := params.stage.Agent.DeepCopy()
+	if agent == nil {
+		agent = params.parentAgent.DeepCopy()
+	}
+	image := ""
+	if agent != nil {
+		image = agent.Image
+	}
+
+	stepCounter := 0
+	for _, step := range params.stage.Steps {
+		actualSteps, _, newCounter, err := generateSteps(generateStepsParams{
+			stageParams:     params,
+			step:            step,
+			inheritedAgent:  image,
+			env:             env,
+			parentContainer: stageContainer,
+			stepCounter:     stepCounter,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate the steps for stage %s", params.stage.Name)
+		}
+		stepCounter = newCounter
+		ls.Steps = append(ls.Steps, actualSteps...)
+	}
+
+	nested := func(stages []Stage) ([]*LocalStage, error) {
+		var answer []*LocalStage
+		for _, s := range stages {
+			child, err := stageToLocalStage(stageToTaskParams{
+				parentParams:    params.parentParams,
+				stage:           s,
+				baseWorkingDir:  params.baseWorkingDir,
+				parentEnv:       env,
+				parentAgent:     agent,
+				parentWorkspace: ls.Workspace,
+				parentContainer: stageContainer,
+				depth:           params.depth + 1,
+			})
+			if err != nil {
+				return nil, err
+			}
+			answer = append(answer, child)
+		}
+		return answer, nil
+	}
+
+	var err error
+	ls.Stages, err = nested(params.stage.Stages)
+	if err != nil {
+		return nil, err
+	}
+	ls.Parallel, err = nested(params.stage.Parallel)
+	if err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
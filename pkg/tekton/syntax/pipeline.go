@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -146,6 +147,21 @@ type StageOptions struct {
 	Unstash *Unstash `json:"unstash,omitempty"`
 
 	Workspace *string `json:"workspace,omitempty"`
+
+	Cache *Cache `json:"cache,omitempty"`
+}
+
+// Cache defines directories which are restored before a stage's steps run and saved after they complete, keyed on a
+// hash of the contents of the stage's input files.
+type Cache struct {
+	// The name of the cache, which is used as a prefix for the cache key
+	Name string `json:"name"`
+	// Patterns for the files whose contents make up the cache key, such as pom.xml or **/package-lock.json
+	Inputs []string `json:"inputs,omitempty"`
+	// The directories to restore and save, such as ~/.m2/repository or node_modules. The cache steps run in their own
+	// containers so only directories in the workspace or the home directory, which every step of the stage shares, can
+	// be cached. Paths must be relative to the working directory of the stage or start with ~/
+	Paths []string `json:"paths"`
 }
 
 // Step defines a single step, from the author's perspective, to be executed within a stage.
@@ -198,6 +214,23 @@ type Loop struct {
 	Steps []Step `json:"steps"`
 }
 
+// Matrix defines a set of axes for a stage. The stage is run once for every combination of values across the axes,
+// with those combinations run in parallel and each axis value set in the environment under the axis name.
+type Matrix struct {
+	// The axes to combine
+	Axes []MatrixAxis `json:"axes"`
+	// Combinations to skip, each one keyed by axis name. A combination is skipped if it matches every entry.
+	Exclude []map[string]string `json:"exclude,omitempty"`
+}
+
+// MatrixAxis is a single named dimension of a Matrix along with the values it can take.
+type MatrixAxis struct {
+	// The axis name, which is also used as the environment variable name.
+	Name string `json:"name"`
+	// The list of values for the axis
+	Values []string `json:"values"`
+}
+
 // Stage is a unit of work in a pipeline, corresponding either to a Task or a set of Tasks to be run sequentially or in
 // parallel with common configuration.
 type Stage struct {
@@ -208,6 +241,8 @@ type Stage struct {
 	Steps      []Step          `json:"steps,omitempty"`
 	Stages     []Stage         `json:"stages,omitempty"`
 	Parallel   []Stage         `json:"parallel,omitempty"`
+	Matrix     *Matrix         `json:"matrix,omitempty"`
+	When       *When           `json:"when,omitempty"`
 	Post       []Post          `json:"post,omitempty"`
 	WorkingDir *string         `json:"dir,omitempty"`
 
@@ -653,6 +688,29 @@ func validateStage(s Stage, parentAgent *Agent) *apis.FieldError {
 		}
 	}
 
+	if err := validateWhen(s.When).ViaField("when"); err != nil {
+		return err
+	}
+
+	if s.Options != nil && s.Options.Cache != nil && len(s.Steps) == 0 {
+		return &apis.FieldError{
+			Message: "cache can only be used on a stage with steps",
+			Paths:   []string{"options.cache"},
+		}
+	}
+
+	if s.Matrix != nil {
+		if len(s.Steps) == 0 {
+			return &apis.FieldError{
+				Message: "matrix can only be used on a stage with steps",
+				Paths:   []string{"matrix"},
+			}
+		}
+		if err := validateMatrix(s.Matrix); err != nil {
+			return err.ViaField("matrix")
+		}
+	}
+
 	return validateStageOptions(s.Options).ViaField("options")
 }
 
@@ -757,6 +815,49 @@ func validateLoop(l *Loop) *apis.FieldError {
 	return nil
 }
 
+func validateMatrix(m *Matrix) *apis.FieldError {
+	if len(m.Axes) == 0 {
+		return apis.ErrMissingField("axes")
+	}
+
+	axisNames := make(map[string]bool)
+	for i, axis := range m.Axes {
+		if axis.Name == "" {
+			return apis.ErrMissingField("name").ViaFieldIndex("axes", i)
+		}
+		if len(axis.Values) == 0 {
+			return apis.ErrMissingField("values").ViaFieldIndex("axes", i)
+		}
+		if axisNames[axis.Name] {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("matrix axis %s is defined more than once", axis.Name),
+				Paths:   []string{fmt.Sprintf("axes[%d].name", i)},
+			}
+		}
+		axisNames[axis.Name] = true
+	}
+
+	for i, exclude := range m.Exclude {
+		for k := range exclude {
+			if !axisNames[k] {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("%s is not a matrix axis", k),
+					Paths:   []string{fmt.Sprintf("exclude[%d]", i)},
+				}
+			}
+		}
+	}
+
+	if len(m.combinations()) == 0 {
+		return &apis.FieldError{
+			Message: "every matrix combination is excluded",
+			Paths:   []string{"exclude"},
+		}
+	}
+
+	return nil
+}
+
 func validateStages(stages []Stage, parentAgent *Agent) *apis.FieldError {
 	if len(stages) == 0 {
 		return apis.ErrMissingField("stages")
@@ -876,6 +977,10 @@ func validateStageOptions(o *StageOptions) *apis.FieldError {
 			}
 		}
 
+		if err := validateCache(o.Cache); err != nil {
+			return err.ViaField("cache")
+		}
+
 		if o.RootOptions != nil && o.RootOptions.DistributeParallelAcrossNodes {
 			return &apis.FieldError{
 				Message: "distributeParallelAcrossNodes cannot be used in a stage",
@@ -950,6 +1055,53 @@ func validateStash(s *Stash) *apis.FieldError {
 	return nil
 }
 
+func validateCache(c *Cache) *apis.FieldError {
+	if c != nil {
+		if c.Name == "" {
+			return &apis.FieldError{
+				Message: "The cache name must be provided",
+				Paths:   []string{"name"},
+			}
+		}
+		// the name is part of the names of the restore and save steps so it must make a valid step name
+		if errs := validation.IsDNS1123Label("restore-cache-" + c.Name); len(errs) > 0 {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("the cache name %s must be a lowercase DNS-1123 label of at most %d characters", c.Name, validation.DNS1123LabelMaxLength-len("restore-cache-")),
+				Paths:   []string{"name"},
+			}
+		}
+		// without inputs the cache key never changes so the cache would never be refreshed
+		if len(c.Inputs) == 0 {
+			return &apis.FieldError{
+				Message: "inputs to compute the cache key from must be provided",
+				Paths:   []string{"inputs"},
+			}
+		}
+		if len(c.Paths) == 0 {
+			return &apis.FieldError{
+				Message: "paths to cache must be provided",
+				Paths:   []string{"paths"},
+			}
+		}
+		for _, p := range c.Paths {
+			// paths in the home directory are allowed as every step of the stage shares the same home directory
+			rel := p
+			if strings.HasPrefix(p, "~/") {
+				rel = strings.TrimPrefix(p, "~/")
+			}
+			clean := filepath.ToSlash(filepath.Clean(rel))
+			if filepath.IsAbs(rel) || strings.HasPrefix(rel, "~") || clean == ".." || strings.HasPrefix(clean, "../") {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("the cache path %s must be a directory in the workspace or the home directory", p),
+					Paths:   []string{"paths"},
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func validateWorkspace(w string) *apis.FieldError {
 	if w == "" {
 		return &apis.FieldError{
@@ -1209,7 +1361,102 @@ type stageToTaskParams struct {
 	previousSiblingStage *transformedStage
 }
 
+// combinations returns every combination of axis values, in axis order, skipping any that match an exclusion.
+func (m *Matrix) combinations() [][]string {
+	combos := [][]string{{}}
+	for _, axis := range m.Axes {
+		var next [][]string
+		for _, combo := range combos {
+			for _, value := range axis.Values {
+				c := make([]string, len(combo), len(combo)+1)
+				copy(c, combo)
+				next = append(next, append(c, value))
+			}
+		}
+		combos = next
+	}
+
+	var result [][]string
+	for _, combo := range combos {
+		if !m.isExcluded(combo) {
+			result = append(result, combo)
+		}
+	}
+	return result
+}
+
+func (m *Matrix) isExcluded(combo []string) bool {
+	for _, exclude := range m.Exclude {
+		if len(exclude) == 0 {
+			continue
+		}
+		matches := true
+		for i, axis := range m.Axes {
+			if v, ok := exclude[axis.Name]; ok && v != combo[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMatrix turns a stage with a matrix into a parallel stage containing one copy of the original stage per
+// combination of axis values. Each copy has the axis values added to its environment, and any ${AXIS} references in
+// its agent and step images replaced with the corresponding value.
+func (s *Stage) expandMatrix() Stage {
+	expanded := Stage{
+		Name:       s.Name,
+		Agent:      s.Agent.DeepCopy(),
+		WorkingDir: s.WorkingDir,
+	}
+	if s.Options != nil && s.Options.Workspace != nil {
+		workspace := *s.Options.Workspace
+		expanded.Options = &StageOptions{
+			RootOptions: &RootOptions{},
+			Workspace:   &workspace,
+		}
+	}
+
+	for _, combo := range s.Matrix.combinations() {
+		cell := s.DeepCopy()
+		cell.Matrix = nil
+		cell.Name = fmt.Sprintf("%s %s", s.Name, strings.Join(combo, " "))
+
+		var axisEnv []corev1.EnvVar
+		replacements := make([]string, 0, len(combo)*2)
+		for i, axis := range s.Matrix.Axes {
+			axisEnv = append(axisEnv, corev1.EnvVar{Name: axis.Name, Value: combo[i]})
+			replacements = append(replacements, "${"+axis.Name+"}", combo[i])
+		}
+		cell.Env = scopedEnv(axisEnv, s.GetEnv())
+		cell.Environment = nil
+
+		replacer := strings.NewReplacer(replacements...)
+		if cell.Agent != nil {
+			cell.Agent.Image = replacer.Replace(cell.Agent.Image)
+		}
+		for i := range cell.Steps {
+			cell.Steps[i].Image = replacer.Replace(cell.Steps[i].Image)
+			if cell.Steps[i].Agent != nil {
+				cell.Steps[i].Agent.Image = replacer.Replace(cell.Steps[i].Agent.Image)
+			}
+		}
+
+		expanded.Parallel = append(expanded.Parallel, *cell)
+	}
+
+	return expanded
+}
+
 func stageToTask(params stageToTaskParams) (*transformedStage, error) {
+	if params.stage.Matrix != nil {
+		params.stage = params.stage.expandMatrix()
+	}
+
 	if len(params.stage.Post) != 0 {
 		return nil, errors.New("post on stages not yet supported")
 	}
@@ -1312,7 +1559,12 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 			volumes[v.Name] = *v
 		}
 
-		for _, step := range params.stage.Steps {
+		stageSteps := params.stage.Steps
+		if params.stage.Options != nil && params.stage.Options.Cache != nil {
+			stageSteps = params.stage.Options.Cache.wrapSteps(stageSteps)
+		}
+
+		for _, step := range stageSteps {
 			actualSteps, stepVolumes, newCounter, err := generateSteps(generateStepsParams{
 				stageParams:     params,
 				step:            step,
@@ -1411,6 +1663,33 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 	return nil, errors.New("no steps, sequential stages, or parallel stages")
 }
 
+// wrapSteps surrounds the given steps with steps to restore the cache before they run and save it afterwards.
+func (c *Cache) wrapSteps(steps []Step) []Step {
+	args := []string{"--name", c.Name}
+	for _, input := range c.Inputs {
+		args = append(args, "--input", "'"+input+"'")
+	}
+	for _, path := range c.Paths {
+		args = append(args, "--path", "'"+path+"'")
+	}
+
+	restore := Step{
+		Name:      "restore-cache-" + c.Name,
+		Image:     GitMergeImage,
+		Command:   "jx",
+		Arguments: append([]string{"step", "cache", "restore"}, args...),
+	}
+	save := Step{
+		Name:      "save-cache-" + c.Name,
+		Image:     GitMergeImage,
+		Command:   "jx",
+		Arguments: append([]string{"step", "cache", "save"}, args...),
+	}
+
+	wrapped := append([]Step{restore}, steps...)
+	return append(wrapped, save)
+}
+
 // MergeContainers combines parent and child container structs, with the child overriding the parent.
 func MergeContainers(parentContainer, childContainer *corev1.Container) (*corev1.Container, error) {
 	if parentContainer == nil {
@@ -1641,6 +1920,8 @@ type CRDsFromPipelineParams struct {
 	Labels             map[string]string
 	DefaultImage       string
 	InterpretMode      bool
+	// WhenContext is used to decide which stages to skip. If it is nil, stage When conditions are ignored.
+	WhenContext *WhenContext
 }
 
 // GenerateCRDs translates the Pipeline structure into the corresponding Pipeline and Task CRDs
@@ -1701,8 +1982,16 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 
 	baseEnv := j.GetEnv()
 
-	for i, s := range j.Stages {
-		isLastStage := i == len(j.Stages)-1
+	filter := newWhenFilter(params.WhenContext, j.GetEnv())
+	stages := filter.filterStages(j.Stages, 0, nil, true)
+	if len(stages) == 0 {
+		// the structure of the skipped stages is still returned so that they can be shown
+		filter.addSkippedToStructure(structure)
+		return nil, nil, structure, ErrAllStagesSkipped
+	}
+
+	for i, s := range stages {
+		isLastStage := i == len(stages)-1
 
 		stage, err := stageToTask(stageToTaskParams{
 			parentParams:         params,
@@ -1747,6 +2036,7 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 		p.Spec.Tasks = append(p.Spec.Tasks, pipelineTasks...)
 		structure.Stages = append(structure.Stages, stage.getAllAsPipelineStructureStages()...)
 	}
+	filter.addSkippedToStructure(structure)
 
 	return p, tasks, structure, nil
 }
@@ -1,8 +1,12 @@
 package syntax
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestFindDuplicates(t *testing.T) {
@@ -57,3 +61,346 @@ func TestFindDuplicates(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandMatrix(t *testing.T) {
+	stage := Stage{
+		Name:  "Build",
+		Agent: &Agent{Image: "golang:${GO_VERSION}"},
+		Env:   []corev1.EnvVar{{Name: "GOOS", Value: "darwin"}, {Name: "CGO_ENABLED", Value: "0"}},
+		Matrix: &Matrix{
+			Axes: []MatrixAxis{
+				{Name: "GO_VERSION", Values: []string{"1.11", "1.12"}},
+				{Name: "GOOS", Values: []string{"linux", "windows"}},
+			},
+			Exclude: []map[string]string{{"GO_VERSION": "1.11", "GOOS": "windows"}},
+		},
+		Steps: []Step{{Command: "make", Arguments: []string{"build"}}},
+	}
+
+	expanded := stage.expandMatrix()
+
+	assert.Equal(t, "Build", expanded.Name)
+	assert.Nil(t, expanded.Matrix)
+	assert.Empty(t, expanded.Steps)
+	if assert.Len(t, expanded.Parallel, 3) {
+		assert.Equal(t, "Build 1.11 linux", expanded.Parallel[0].Name)
+		assert.Equal(t, "Build 1.12 linux", expanded.Parallel[1].Name)
+		assert.Equal(t, "Build 1.12 windows", expanded.Parallel[2].Name)
+
+		cell := expanded.Parallel[2]
+		assert.Nil(t, cell.Matrix)
+		assert.Equal(t, "golang:1.12", cell.Agent.Image)
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "CGO_ENABLED", Value: "0"},
+			{Name: "GOOS", Value: "windows"},
+			{Name: "GO_VERSION", Value: "1.12"},
+		}, cell.Env)
+		assert.Equal(t, stage.Steps, cell.Steps)
+	}
+
+	// The original stage must not be modified by the expansion
+	assert.Equal(t, "golang:${GO_VERSION}", stage.Agent.Image)
+	assert.NotNil(t, stage.Matrix)
+}
+
+func TestGenerateCRDsWithMatrix(t *testing.T) {
+	pipeline := &ParsedPipeline{
+		Agent: &Agent{Image: "some-image"},
+		Stages: []Stage{{
+			Name: "Build",
+			Matrix: &Matrix{
+				Axes: []MatrixAxis{{Name: "OS", Values: []string{"linux", "windows"}}},
+			},
+			Steps: []Step{{Command: "echo", Arguments: []string{"${OS}"}}},
+		}},
+	}
+
+	p, tasks, structure, err := pipeline.GenerateCRDs(CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "somepipeline",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, tasks, 2)
+	if assert.Len(t, p.Spec.Tasks, 2) {
+		assert.Equal(t, "build-linux", p.Spec.Tasks[0].Name)
+		assert.Equal(t, "build-windows", p.Spec.Tasks[1].Name)
+		assert.Empty(t, p.Spec.Tasks[0].RunAfter)
+		assert.Empty(t, p.Spec.Tasks[1].RunAfter)
+	}
+
+	var stageNames []string
+	for _, s := range structure.Stages {
+		stageNames = append(stageNames, s.Name)
+	}
+	assert.Equal(t, []string{"Build", "Build linux", "Build windows"}, stageNames)
+	assert.Equal(t, []string{"Build linux", "Build windows"}, structure.Stages[0].Parallel)
+}
+
+func TestWhenMatches(t *testing.T) {
+	ctx := &WhenContext{
+		Branch:       "PR-12",
+		ChangedFiles: []string{"services/foo/main.go", "README.md"},
+		Env:          map[string]string{"JOB_TYPE": "presubmit"},
+		Labels:       []string{"needs-e2e"},
+	}
+
+	tests := []struct {
+		name     string
+		when     *When
+		ctx      *WhenContext
+		expected bool
+	}{
+		{name: "nil when", when: nil, ctx: ctx, expected: true},
+		{name: "nil context", when: &When{Branch: []string{"master"}}, ctx: nil, expected: true},
+		{name: "branch pattern", when: &When{Branch: []string{"master", "PR-*"}}, ctx: ctx, expected: true},
+		{name: "branch mismatch", when: &When{Branch: []string{"master"}}, ctx: ctx, expected: false},
+		{name: "changed directory", when: &When{ChangedFiles: []string{"services/foo/**"}}, ctx: ctx, expected: true},
+		{name: "changed glob", when: &When{ChangedFiles: []string{"*.md"}}, ctx: ctx, expected: true},
+		{name: "unchanged directory", when: &When{ChangedFiles: []string{"services/bar/**"}}, ctx: ctx, expected: false},
+		{
+			name:     "unknown changed files",
+			when:     &When{ChangedFiles: []string{"services/bar/**"}},
+			ctx:      &WhenContext{ChangedFilesUnknown: true},
+			expected: true,
+		},
+		{name: "env", when: &When{Env: map[string]string{"JOB_TYPE": "pre*"}}, ctx: ctx, expected: true},
+		{name: "missing env", when: &When{Env: map[string]string{"OTHER": "x"}}, ctx: ctx, expected: false},
+		{name: "label", when: &When{Labels: []string{"skip-e2e", "needs-e2e"}}, ctx: ctx, expected: true},
+		{name: "missing label", when: &When{Labels: []string{"skip-e2e"}}, ctx: ctx, expected: false},
+		{
+			name:     "all conditions must match",
+			when:     &When{Branch: []string{"PR-*"}, ChangedFiles: []string{"services/bar/**"}},
+			ctx:      ctx,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.when.Matches(tt.ctx))
+		})
+	}
+}
+
+func TestGenerateCRDsWithWhen(t *testing.T) {
+	pipeline := &ParsedPipeline{
+		Agent: &Agent{Image: "some-image"},
+		Stages: []Stage{
+			{
+				Name:  "Build",
+				Steps: []Step{{Command: "make"}},
+			},
+			{
+				Name: "Services",
+				Parallel: []Stage{
+					{
+						Name:  "Foo",
+						When:  &When{ChangedFiles: []string{"services/foo/**"}},
+						Steps: []Step{{Command: "make", Arguments: []string{"foo"}}},
+					},
+					{
+						Name:  "Bar",
+						When:  &When{ChangedFiles: []string{"services/bar/**"}},
+						Steps: []Step{{Command: "make", Arguments: []string{"bar"}}},
+					},
+				},
+			},
+			{
+				Name:  "Release",
+				When:  &When{Branch: []string{"master"}},
+				Steps: []Step{{Command: "make", Arguments: []string{"release"}}},
+			},
+		},
+	}
+
+	p, tasks, structure, err := pipeline.GenerateCRDs(CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "somepipeline",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+		WhenContext: &WhenContext{
+			Branch:       "PR-1",
+			ChangedFiles: []string{"services/foo/main.go"},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, tasks, 2)
+	if assert.Len(t, p.Spec.Tasks, 2) {
+		assert.Equal(t, "build", p.Spec.Tasks[0].Name)
+		assert.Equal(t, "foo", p.Spec.Tasks[1].Name)
+	}
+
+	skipped := make(map[string]bool)
+	for _, s := range structure.Stages {
+		skipped[s.Name] = s.Skipped
+	}
+	assert.Equal(t, map[string]bool{
+		"Build":    false,
+		"Services": false,
+		"Foo":      false,
+		"Bar":      true,
+		"Release":  true,
+	}, skipped)
+
+	services := structure.GetStage("Services")
+	if assert.NotNil(t, services) {
+		assert.Equal(t, []string{"Foo", "Bar"}, services.Parallel)
+	}
+	assert.Len(t, structure.GetAllStagesWithSteps(), 2)
+	release := structure.GetStage("Release")
+	if assert.NotNil(t, release) && assert.NotNil(t, release.Previous) {
+		assert.Equal(t, "Services", *release.Previous)
+		assert.Nil(t, release.Next)
+	}
+}
+
+func TestGenerateCRDsWithWhenPipelineEnv(t *testing.T) {
+	pipeline := &ParsedPipeline{
+		Agent:       &Agent{Image: "some-image"},
+		Environment: []corev1.EnvVar{{Name: "DEPLOY", Value: "true"}},
+		Stages: []Stage{
+			{
+				Name:  "Build",
+				Steps: []Step{{Command: "make"}},
+			},
+			{
+				Name:  "Deploy",
+				When:  &When{Env: map[string]string{"DEPLOY": "true"}},
+				Steps: []Step{{Command: "make", Arguments: []string{"deploy"}}},
+			},
+		},
+	}
+
+	_, tasks, _, err := pipeline.GenerateCRDs(CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "somepipeline",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+		WhenContext:        &WhenContext{Branch: "master"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestGenerateCRDsAllStagesSkipped(t *testing.T) {
+	pipeline := &ParsedPipeline{
+		Agent: &Agent{Image: "some-image"},
+		Stages: []Stage{
+			{
+				Name:  "Docs",
+				When:  &When{ChangedFiles: []string{"docs/**"}},
+				Steps: []Step{{Command: "make", Arguments: []string{"docs"}}},
+			},
+		},
+	}
+	assert.True(t, pipeline.HasWhenConditions())
+	assert.False(t, (&ParsedPipeline{Stages: []Stage{{Name: "Build"}}}).HasWhenConditions())
+
+	_, _, structure, err := pipeline.GenerateCRDs(CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		ResourceIdentifier: "somepipeline",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+		WhenContext:        &WhenContext{ChangedFiles: []string{"main.go"}},
+	})
+	assert.Equal(t, ErrAllStagesSkipped, err)
+	if assert.NotNil(t, structure) && assert.Len(t, structure.Stages, 1) {
+		assert.True(t, structure.Stages[0].Skipped)
+	}
+}
+
+func TestCacheWrapSteps(t *testing.T) {
+	c := &Cache{
+		Name:   "maven",
+		Inputs: []string{"**/pom.xml"},
+		Paths:  []string{".m2/repository"},
+	}
+
+	steps := c.wrapSteps([]Step{{Command: "mvn", Arguments: []string{"install"}}})
+	if assert.Len(t, steps, 3) {
+		assert.Equal(t, "restore-cache-maven", steps[0].Name)
+		assert.Equal(t, []string{"step", "cache", "restore", "--name", "maven", "--input", "'**/pom.xml'", "--path", "'.m2/repository'"}, steps[0].Arguments)
+		assert.Equal(t, "mvn", steps[1].Command)
+		assert.Equal(t, "save-cache-maven", steps[2].Name)
+		assert.Equal(t, []string{"step", "cache", "save", "--name", "maven", "--input", "'**/pom.xml'", "--path", "'.m2/repository'"}, steps[2].Arguments)
+	}
+}
+
+func TestLocalStages(t *testing.T) {
+	workspace := "custom"
+	pipeline := &ParsedPipeline{
+		Agent: &Agent{Image: "some-image"},
+		Env:   []corev1.EnvVar{{Name: "ROOT", Value: "root"}},
+		Stages: []Stage{
+			{
+				Name:    "Build",
+				Options: &StageOptions{Stash: &Stash{Name: "bin", Files: "bin/*"}},
+				Steps:   []Step{{Name: "compile", Command: "make", Arguments: []string{"build"}, Dir: "cmd"}},
+			},
+			{
+				Name: "Tests",
+				Parallel: []Stage{
+					{
+						Name:  "Unit",
+						Agent: &Agent{Image: "other-image"},
+						Env:   []corev1.EnvVar{{Name: "STAGE", Value: "unit"}},
+						Steps: []Step{{Command: "make test"}},
+					},
+					{
+						Name:    "Lint",
+						Options: &StageOptions{Workspace: &workspace, Unstash: &Unstash{Name: "bin"}},
+						When:    &When{Branch: []string{"master"}},
+						Steps:   []Step{{Command: "make lint"}},
+					},
+				},
+			},
+		},
+	}
+
+	stages, err := pipeline.LocalStages(CRDsFromPipelineParams{
+		SourceDir:   "source",
+		VersionsDir: filepath.Join("test_data", "stable_versions"),
+		WhenContext: &WhenContext{Branch: "PR-1"},
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, stages, 2) {
+		return
+	}
+
+	build := stages[0]
+	assert.Equal(t, "default", build.Workspace)
+	assert.Equal(t, "bin", build.Stash.Name)
+	if assert.Len(t, build.Steps, 1) {
+		step := build.Steps[0]
+		assert.Equal(t, "compile", step.Name)
+		assert.Equal(t, []string{"make build"}, step.Args)
+		assert.Equal(t, "/workspace/source/cmd", step.WorkingDir)
+		assert.Contains(t, step.Env, corev1.EnvVar{Name: "ROOT", Value: "root"})
+	}
+
+	tests := stages[1]
+	assert.Empty(t, tests.Steps)
+	if assert.Len(t, tests.Parallel, 1) {
+		unit := tests.Parallel[0]
+		assert.Equal(t, "Unit", unit.Name)
+		assert.Equal(t, int8(1), unit.Depth)
+		if assert.Len(t, unit.Steps, 1) {
+			assert.Equal(t, "/workspace/source", unit.Steps[0].WorkingDir)
+			assert.Contains(t, unit.Steps[0].Image, "other-image")
+			assert.Contains(t, unit.Steps[0].Env, corev1.EnvVar{Name: "STAGE", Value: "unit"})
+			assert.Contains(t, unit.Steps[0].Env, corev1.EnvVar{Name: "ROOT", Value: "root"})
+		}
+	}
+}
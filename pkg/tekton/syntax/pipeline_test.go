@@ -1671,6 +1671,49 @@ func TestFailedValidation(t *testing.T) {
 			name:          "loop_without_values",
 			expectedError: apis.ErrMissingField("values").ViaField("loop").ViaFieldIndex("steps", 0).ViaFieldIndex("stages", 0),
 		},
+		{
+			name:          "stage_when_without_conditions",
+			expectedError: apis.ErrMissingOneOf("branch", "changedFiles", "env", "labels").ViaField("when").ViaFieldIndex("stages", 0),
+		},
+		{
+			name: "cache_without_paths",
+			expectedError: (&apis.FieldError{
+				Message: "paths to cache must be provided",
+				Paths:   []string{"paths"},
+			}).ViaField("cache").ViaField("options").ViaFieldIndex("stages", 0),
+		},
+		{
+			name: "cache_without_inputs",
+			expectedError: (&apis.FieldError{
+				Message: "inputs to compute the cache key from must be provided",
+				Paths:   []string{"inputs"},
+			}).ViaField("cache").ViaField("options").ViaFieldIndex("stages", 0),
+		},
+		{
+			name: "cache_outside_workspace",
+			expectedError: (&apis.FieldError{
+				Message: "the cache path ~/../.m2/repository must be a directory in the workspace or the home directory",
+				Paths:   []string{"paths"},
+			}).ViaField("cache").ViaField("options").ViaFieldIndex("stages", 0),
+		},
+		{
+			name: "cache_invalid_name",
+			expectedError: (&apis.FieldError{
+				Message: "the cache name Maven Repo must be a lowercase DNS-1123 label of at most 49 characters",
+				Paths:   []string{"name"},
+			}).ViaField("cache").ViaField("options").ViaFieldIndex("stages", 0),
+		},
+		{
+			name:          "matrix_without_values",
+			expectedError: apis.ErrMissingField("values").ViaFieldIndex("axes", 0).ViaField("matrix").ViaFieldIndex("stages", 0),
+		},
+		{
+			name: "matrix_on_parallel_stage",
+			expectedError: (&apis.FieldError{
+				Message: "matrix can only be used on a stage with steps",
+				Paths:   []string{"matrix"},
+			}).ViaFieldIndex("stages", 0),
+		},
 		{
 			name: "top_level_container_options_with_command",
 			expectedError: (&apis.FieldError{
@@ -0,0 +1,316 @@
+package syntax
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/knative/pkg/apis"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrAllStagesSkipped is returned when generating a pipeline whose stages were all skipped by their when conditions
+// so that there is nothing to run
+var ErrAllStagesSkipped = errors.New("every stage in the pipeline was skipped by its when conditions")
+
+// When defines the conditions under which a stage is run. Every condition which is specified must be met for the stage
+// to run, and a condition with a list of patterns is met if any of its patterns match.
+type When struct {
+	// Branch patterns, such as master or release-*
+	Branch []string `json:"branch,omitempty"`
+	// ChangedFiles patterns, such as docs/* or services/foo/**, matched against the files changed by the pull request, or
+	// by the last commit for release builds
+	ChangedFiles []string `json:"changedFiles,omitempty"`
+	// Env maps environment variable names to the pattern their value must match
+	Env map[string]string `json:"env,omitempty"`
+	// Labels on the pull request, any one of which must be present
+	Labels []string `json:"labels,omitempty"`
+}
+
+// WhenContext contains the details of the build that When conditions are evaluated against.
+type WhenContext struct {
+	Branch       string
+	ChangedFiles []string
+	// ChangedFilesUnknown is true if the changed files could not be found, in which case changedFiles conditions are
+	// treated as met so that stages are run rather than silently skipped
+	ChangedFilesUnknown bool
+	Env                 map[string]string
+	// EnvUnknown is true if the environment variables are not known, in which case env conditions are treated as met
+	EnvUnknown bool
+	Labels     []string
+	// LabelsUnknown is true if the pull request labels are not known, in which case labels conditions are treated as met
+	LabelsUnknown bool
+}
+
+// HasWhenConditions returns true if any stage of the pipeline, including nested stages, has when conditions
+func (j *ParsedPipeline) HasWhenConditions() bool {
+	return stagesHaveWhen(j.Stages)
+}
+
+func stagesHaveWhen(stages []Stage) bool {
+	for _, s := range stages {
+		if s.When != nil || stagesHaveWhen(s.Stages) || stagesHaveWhen(s.Parallel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if all of the conditions on this When are met for the given context.
+func (w *When) Matches(ctx *WhenContext) bool {
+	if w == nil || ctx == nil {
+		return true
+	}
+
+	if len(w.Branch) > 0 && !matchesAnyPattern(ctx.Branch, w.Branch) {
+		return false
+	}
+
+	if len(w.ChangedFiles) > 0 && !ctx.ChangedFilesUnknown {
+		changed := false
+		for _, f := range ctx.ChangedFiles {
+			if matchesAnyPattern(f, w.ChangedFiles) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+
+	for name, pattern := range w.Env {
+		if !ctx.EnvUnknown && !matchesAnyPattern(ctx.Env[name], []string{pattern}) {
+			return false
+		}
+	}
+
+	if len(w.Labels) > 0 && !ctx.LabelsUnknown {
+		labelled := false
+		for _, label := range ctx.Labels {
+			for _, l := range w.Labels {
+				if label == l {
+					labelled = true
+				}
+			}
+		}
+		if !labelled {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsConditional returns true if whether the conditions are met can't be decided from the context, because the context
+// is missing or doesn't know the values of some of the conditions.
+func (w *When) IsConditional(ctx *WhenContext) bool {
+	if w == nil {
+		return false
+	}
+	if ctx == nil {
+		return len(w.Branch) > 0 || len(w.ChangedFiles) > 0 || len(w.Env) > 0 || len(w.Labels) > 0
+	}
+	return (len(w.ChangedFiles) > 0 && ctx.ChangedFilesUnknown) || (len(w.Env) > 0 && ctx.EnvUnknown) ||
+		(len(w.Labels) > 0 && ctx.LabelsUnknown)
+}
+
+// matchesAnyPattern returns true if the text matches any of the patterns. A pattern ending in /** matches everything
+// underneath that directory, otherwise patterns are matched with filepath.Match.
+func matchesAnyPattern(text string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/**") {
+			if strings.HasPrefix(text, strings.TrimSuffix(pattern, "**")) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, text); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWhen(w *When) *apis.FieldError {
+	if w == nil {
+		return nil
+	}
+
+	if len(w.Branch) == 0 && len(w.ChangedFiles) == 0 && len(w.Env) == 0 && len(w.Labels) == 0 {
+		return apis.ErrMissingOneOf("branch", "changedFiles", "env", "labels")
+	}
+
+	for i, p := range w.Branch {
+		if err := validateWhenPattern(p); err != nil {
+			return err.ViaFieldIndex("branch", i)
+		}
+	}
+	for i, p := range w.ChangedFiles {
+		if err := validateWhenPattern(strings.TrimSuffix(p, "/**")); err != nil {
+			return err.ViaFieldIndex("changedFiles", i)
+		}
+	}
+	for k, p := range w.Env {
+		if err := validateWhenPattern(p); err != nil {
+			return err.ViaFieldKey("env", k)
+		}
+	}
+
+	return nil
+}
+
+func validateWhenPattern(pattern string) *apis.FieldError {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s is not a valid pattern", pattern),
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// whenFilter removes the stages whose When conditions are not met, keeping track of what was removed so that the
+// skipped stages can still be reported in the PipelineStructure.
+type whenFilter struct {
+	ctx *WhenContext
+	// skipped contains the structure for every stage which will not be run
+	skipped []v1.PipelineStructureStage
+	// original contains the unfiltered version of every stage which had nested stages removed
+	original map[string]Stage
+}
+
+// newWhenFilter creates a filter for the context, with the environment variables of the pipeline used for any
+// variables which are not in the context
+func newWhenFilter(ctx *WhenContext, pipelineEnv []corev1.EnvVar) *whenFilter {
+	if ctx != nil && len(pipelineEnv) > 0 {
+		withEnv := *ctx
+		withEnv.Env = map[string]string{}
+		for _, e := range pipelineEnv {
+			if e.ValueFrom == nil {
+				withEnv.Env[e.Name] = e.Value
+			}
+		}
+		for k, v := range ctx.Env {
+			withEnv.Env[k] = v
+		}
+		ctx = &withEnv
+	}
+	return &whenFilter{
+		ctx:      ctx,
+		original: make(map[string]Stage),
+	}
+}
+
+func (f *whenFilter) filterStages(stages []Stage, depth int8, parent *string, sequential bool) []Stage {
+	var kept []Stage
+	for i, s := range stages {
+		var previous, next *string
+		if sequential {
+			previous, next = siblingNames(stages, i)
+		}
+		if !s.When.Matches(f.ctx) {
+			f.skip(s, depth, parent, previous, next)
+			continue
+		}
+
+		filtered := s
+		if len(s.Stages) > 0 {
+			filtered.Stages = f.filterStages(s.Stages, depth+1, &filtered.Name, true)
+			if len(filtered.Stages) == 0 {
+				f.skipParentOnly(s, depth, parent, previous, next)
+				continue
+			}
+		}
+		if len(s.Parallel) > 0 {
+			filtered.Parallel = f.filterStages(s.Parallel, depth+1, &filtered.Name, false)
+			if len(filtered.Parallel) == 0 {
+				f.skipParentOnly(s, depth, parent, previous, next)
+				continue
+			}
+		}
+		if len(filtered.Stages) != len(s.Stages) || len(filtered.Parallel) != len(s.Parallel) {
+			f.original[s.Name] = s
+		}
+		kept = append(kept, filtered)
+	}
+	return kept
+}
+
+// skip records the stage and all of its nested stages as skipped
+func (f *whenFilter) skip(s Stage, depth int8, parent *string, previous *string, next *string) {
+	f.skipParentOnly(s, depth, parent, previous, next)
+	for i, nested := range s.Stages {
+		nestedPrevious, nestedNext := siblingNames(s.Stages, i)
+		f.skip(nested, depth+1, &s.Name, nestedPrevious, nestedNext)
+	}
+	for _, nested := range s.Parallel {
+		f.skip(nested, depth+1, &s.Name, nil, nil)
+	}
+}
+
+// skipParentOnly records the stage as skipped, without recording its nested stages. The previous and next stages are
+// the sequential siblings of the stage in the pipeline whether or not they are skipped too
+func (f *whenFilter) skipParentOnly(s Stage, depth int8, parent *string, previous *string, next *string) {
+	ps := v1.PipelineStructureStage{
+		Name:     s.Name,
+		Depth:    depth,
+		Parent:   parent,
+		Previous: previous,
+		Next:     next,
+		Skipped:  true,
+	}
+	for _, nested := range s.Stages {
+		ps.Stages = append(ps.Stages, nested.Name)
+	}
+	for _, nested := range s.Parallel {
+		ps.Parallel = append(ps.Parallel, nested.Name)
+	}
+	f.skipped = append(f.skipped, ps)
+}
+
+// siblingNames returns the names of the stages before and after the stage at the index
+func siblingNames(stages []Stage, i int) (*string, *string) {
+	var previous, next *string
+	if i > 0 {
+		previous = &stages[i-1].Name
+	}
+	if i < len(stages)-1 {
+		next = &stages[i+1].Name
+	}
+	return previous, next
+}
+
+// addSkippedToStructure adds the skipped stages to the structure, and restores their names in the nested stages of
+// any stage they were removed from.
+func (f *whenFilter) addSkippedToStructure(structure *v1.PipelineStructure) {
+	for i := range structure.Stages {
+		s := &structure.Stages[i]
+		if orig, ok := f.original[s.Name]; ok {
+			s.Stages = nil
+			for _, nested := range orig.Stages {
+				s.Stages = append(s.Stages, nested.Name)
+			}
+			s.Parallel = nil
+			for _, nested := range orig.Parallel {
+				s.Parallel = append(s.Parallel, nested.Name)
+			}
+		}
+	}
+	structure.Stages = append(structure.Stages, f.skipped...)
+}
+
+// ParseChangedFiles converts the output of git diff --name-status into a list of file names, including both the old
+// and new names of renamed files.
+func ParseChangedFiles(changes string) []string {
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(changes), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) > 1 {
+			files = append(files, fields[1:]...)
+		}
+	}
+	return files
+}
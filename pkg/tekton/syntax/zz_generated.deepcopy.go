@@ -25,6 +25,32 @@ func (in *Agent) DeepCopy() *Agent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cache) DeepCopyInto(out *Cache) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cache.
+func (in *Cache) DeepCopy() *Cache {
+	if in == nil {
+		return nil
+	}
+	out := new(Cache)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CRDsFromPipelineParams) DeepCopyInto(out *CRDsFromPipelineParams) {
 	*out = *in
@@ -52,6 +78,15 @@ func (in *CRDsFromPipelineParams) DeepCopyInto(out *CRDsFromPipelineParams) {
 			(*out)[key] = val
 		}
 	}
+	if in.WhenContext != nil {
+		in, out := &in.WhenContext, &out.WhenContext
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(WhenContext)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -93,6 +128,63 @@ func (in *Loop) DeepCopy() *Loop {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Matrix) DeepCopyInto(out *Matrix) {
+	*out = *in
+	if in.Axes != nil {
+		in, out := &in.Axes, &out.Axes
+		*out = make([]MatrixAxis, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Matrix.
+func (in *Matrix) DeepCopy() *Matrix {
+	if in == nil {
+		return nil
+	}
+	out := new(Matrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixAxis) DeepCopyInto(out *MatrixAxis) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixAxis.
+func (in *MatrixAxis) DeepCopy() *MatrixAxis {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixAxis)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ParsedPipeline) DeepCopyInto(out *ParsedPipeline) {
 	*out = *in
@@ -128,6 +220,24 @@ func (in *ParsedPipeline) DeepCopyInto(out *ParsedPipeline) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Matrix)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(When)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.Post != nil {
 		in, out := &in.Post, &out.Post
 		*out = make([]Post, len(*in))
@@ -470,6 +580,15 @@ func (in *StageOptions) DeepCopyInto(out *StageOptions) {
 			**out = **in
 		}
 	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Cache)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -595,3 +714,74 @@ func (in *Unstash) DeepCopy() *Unstash {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *When) DeepCopyInto(out *When) {
+	*out = *in
+	if in.Branch != nil {
+		in, out := &in.Branch, &out.Branch
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChangedFiles != nil {
+		in, out := &in.ChangedFiles, &out.ChangedFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new When.
+func (in *When) DeepCopy() *When {
+	if in == nil {
+		return nil
+	}
+	out := new(When)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WhenContext) DeepCopyInto(out *WhenContext) {
+	*out = *in
+	if in.ChangedFiles != nil {
+		in, out := &in.ChangedFiles, &out.ChangedFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhenContext.
+func (in *WhenContext) DeepCopy() *WhenContext {
+	if in == nil {
+		return nil
+	}
+	out := new(WhenContext)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,166 @@
+package rotation
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AuditConfigMapName the name of the ConfigMap which stores the audit records of the rotations
+	AuditConfigMapName = "jx-secret-rotations"
+
+	// DefaultMaxRecords the default number of audit records which are kept for each secret
+	DefaultMaxRecords = 20
+
+	// updateAttempts the number of times the ConfigMap is updated when there are conflicting updates
+	updateAttempts = 5
+)
+
+// Record the audit record of a rotation of a secret
+type Record struct {
+	Name              string    `json:"name"`
+	Kind              string    `json:"kind"`
+	Timestamp         time.Time `json:"timestamp"`
+	User              string    `json:"user,omitempty"`
+	VaultPath         string    `json:"vaultPath"`
+	VaultVersion      string    `json:"vaultVersion,omitempty"`
+	KubernetesSecrets []string  `json:"kubernetesSecrets,omitempty"`
+	Workloads         []string  `json:"workloads,omitempty"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// AuditStore stores the audit records of the rotations in a ConfigMap with one entry per secret
+type AuditStore struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	MaxRecords int
+}
+
+// NewAuditStore creates a store of audit records in the namespace
+func NewAuditStore(kubeClient kubernetes.Interface, ns string) *AuditStore {
+	return &AuditStore{
+		KubeClient: kubeClient,
+		Namespace:  ns,
+		MaxRecords: DefaultMaxRecords,
+	}
+}
+
+// Record stores the audit record removing the oldest records of the secret beyond the maximum number of records
+func (s *AuditStore) Record(record *Record) error {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	configMaps := s.KubeClient.CoreV1().ConfigMaps(s.Namespace)
+	for i := 0; ; i++ {
+		cm, err := configMaps.Get(AuditConfigMapName, metav1.GetOptions{})
+		create := false
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to get ConfigMap %s in namespace %s", AuditConfigMapName, s.Namespace)
+			}
+			create = true
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: AuditConfigMapName,
+				},
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		records, err := parseRecords(record.Name, cm.Data[record.Name])
+		if err != nil {
+			return err
+		}
+		records = append([]*Record{record}, records...)
+		max := s.MaxRecords
+		if max <= 0 {
+			max = DefaultMaxRecords
+		}
+		if len(records) > max {
+			records = records[:max]
+		}
+		data, err := json.Marshal(records)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the audit records of secret %s", record.Name)
+		}
+		cm.Data[record.Name] = string(data)
+
+		if create {
+			_, err = configMaps.Create(cm)
+		} else {
+			_, err = configMaps.Update(cm)
+		}
+		if err == nil {
+			return nil
+		}
+		if (!apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err)) || i >= updateAttempts {
+			return errors.Wrapf(err, "failed to save the audit record of secret %s in ConfigMap %s", record.Name, AuditConfigMapName)
+		}
+	}
+}
+
+// List returns the audit records of all the secrets, newest first
+func (s *AuditStore) List() ([]*Record, error) {
+	cm, err := s.KubeClient.CoreV1().ConfigMaps(s.Namespace).Get(AuditConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get ConfigMap %s in namespace %s", AuditConfigMapName, s.Namespace)
+	}
+	var answer []*Record
+	for name, value := range cm.Data {
+		records, err := parseRecords(name, value)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, records...)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Timestamp.After(answer[j].Timestamp)
+	})
+	return answer, nil
+}
+
+// LastRotated returns the time the secret was last rotated successfully or the zero time if it has never been
+func (s *AuditStore) LastRotated(name string) (time.Time, error) {
+	records, err := s.List()
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, r := range records {
+		if r.Name == name && r.Error == "" {
+			return r.Timestamp, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// IsDue returns true if the secret has not been rotated successfully within the maximum age
+func (s *AuditStore) IsDue(name string, maxAge time.Duration, now time.Time) (bool, error) {
+	last, err := s.LastRotated(name)
+	if err != nil {
+		return false, err
+	}
+	return last.IsZero() || !last.Add(maxAge).After(now), nil
+}
+
+func parseRecords(name string, value string) ([]*Record, error) {
+	var records []*Record
+	if value == "" {
+		return records, nil
+	}
+	err := json.Unmarshal([]byte(value), &records)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the audit records of secret %s", name)
+	}
+	return records, nil
+}
@@ -0,0 +1,164 @@
+package rotation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConfigFileName the default name of the file which configures the secrets to rotate
+	ConfigFileName = "secret-rotations.yml"
+
+	// KindGitToken a git provider API token
+	KindGitToken = "gitToken"
+
+	// KindDocker the password of a docker registry
+	KindDocker = "docker"
+
+	// KindWebhook the HMAC token which signs the webhooks of the git repositories
+	KindWebhook = "webhook"
+
+	// KindPassword a generated password such as those used in charts
+	KindPassword = "password"
+)
+
+var (
+	// Kinds the kinds of secret which can be rotated
+	Kinds = []string{KindGitToken, KindDocker, KindWebhook, KindPassword}
+
+	// the names are used as ConfigMap keys of the audit records
+	nameRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+	defaultKeys = map[string]string{
+		KindGitToken: "token",
+		KindDocker:   "password",
+		KindWebhook:  "hmac",
+		KindPassword: "password",
+	}
+)
+
+// Config the secrets in Vault which are rotated
+type Config struct {
+	Secrets []Secret `json:"secrets"`
+}
+
+// Secret a secret in Vault which is rotated along with the Kubernetes secrets which contain it
+type Secret struct {
+	// Name the name of the rotation used in the audit records
+	Name string `json:"name"`
+	// Kind the kind of secret which decides how the new value is created
+	Kind string `json:"kind"`
+	// VaultPath the path of the secret in Vault
+	VaultPath string `json:"vaultPath"`
+	// Key the key in the Vault secret of the value which is rotated. Defaults to a key for the kind
+	Key string `json:"key,omitempty"`
+	// Length the length of generated values
+	Length int `json:"length,omitempty"`
+	// GitServer the URL of the git server of a git API token
+	GitServer string `json:"gitServer,omitempty"`
+	// GitKind the kind of the git server of a git API token
+	GitKind string `json:"gitKind,omitempty"`
+	// Username the user of a git API token or docker registry
+	Username string `json:"username,omitempty"`
+	// Registry the host of a docker registry
+	Registry string `json:"registry,omitempty"`
+	// ApplyCommand the command which changes a password in the system which stores it such as a database. The command
+	// is run before the new password is stored with the new and previous passwords in the ROTATED_VALUE and
+	// PREVIOUS_VALUE environment variables, and again with the passwords swapped if storing the new password fails
+	ApplyCommand []string `json:"applyCommand,omitempty"`
+	// Stateless is true for a password which is only read from the Kubernetes secrets, such as the basic auth password
+	// of chartmuseum, so rolling the workloads which use it is enough to change it. Passwords which are also stored by
+	// the system which checks them, such as the passwords of Postgres or Nexus which are kept in their data volumes,
+	// are not stateless and need an applyCommand otherwise their clients are locked out
+	Stateless bool `json:"stateless,omitempty"`
+	// KubernetesSecrets the Kubernetes secrets which contain the value
+	KubernetesSecrets []SecretRef `json:"kubernetesSecrets,omitempty"`
+}
+
+// SecretRef a key of a Kubernetes secret which contains the rotated value
+type SecretRef struct {
+	Name string `json:"name"`
+	// Namespace defaults to the development namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Key defaults to the key of the value in Vault
+	Key string `json:"key,omitempty"`
+}
+
+// LoadConfig loads the rotation configuration from the file
+func LoadConfig(fileName string) (*Config, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	config := &Config{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid secret rotations in %s", fileName)
+	}
+	return config, nil
+}
+
+// Validate returns an error if a secret is not configured correctly
+func (c *Config) Validate() error {
+	names := map[string]bool{}
+	for i := range c.Secrets {
+		s := &c.Secrets[i]
+		if !nameRegex.MatchString(s.Name) {
+			return fmt.Errorf("secret %d has an invalid name %q which must only contain letters, digits, '-', '_' and '.'", i+1, s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("secret %s is configured more than once", s.Name)
+		}
+		names[s.Name] = true
+		if defaultKeys[s.Kind] == "" {
+			return fmt.Errorf("secret %s has an unknown kind %q. Supported kinds are %v", s.Name, s.Kind, Kinds)
+		}
+		if s.VaultPath == "" {
+			return fmt.Errorf("secret %s has no vaultPath", s.Name)
+		}
+		if s.Kind == KindGitToken && s.GitServer == "" {
+			return fmt.Errorf("secret %s has no gitServer", s.Name)
+		}
+		if s.Kind == KindDocker && s.Registry == "" {
+			return fmt.Errorf("secret %s has no registry", s.Name)
+		}
+		if s.Kind == KindPassword && len(s.ApplyCommand) == 0 && !s.Stateless {
+			return fmt.Errorf("secret %s needs an applyCommand which changes the password in the system which stores it or stateless: true if the password is only read from the Kubernetes secrets", s.Name)
+		}
+		if len(s.ApplyCommand) > 0 && s.Kind != KindPassword {
+			return fmt.Errorf("secret %s of kind %s cannot have an applyCommand", s.Name, s.Kind)
+		}
+		for _, ref := range s.KubernetesSecrets {
+			if ref.Name == "" {
+				return fmt.Errorf("secret %s has a Kubernetes secret with no name", s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the secret with the name or nil if there is none
+func (c *Config) Find(name string) *Secret {
+	for i := range c.Secrets {
+		if c.Secrets[i].Name == name {
+			return &c.Secrets[i]
+		}
+	}
+	return nil
+}
+
+// ValueKey returns the key in Vault of the value which is rotated
+func (s *Secret) ValueKey() string {
+	if s.Key != "" {
+		return s.Key
+	}
+	return defaultKeys[s.Kind]
+}
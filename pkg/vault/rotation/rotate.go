@@ -0,0 +1,300 @@
+package rotation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/jenkins-x/jx/pkg/vault"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RotatedAnnotation the annotation added to the pod template of a workload to roll it when a secret it uses
+	// is rotated
+	RotatedAnnotation = "jenkins.io/secrets-rotated-at"
+)
+
+// Rotation rotates secrets in Vault and updates the Kubernetes secrets and workloads which use them
+type Rotation struct {
+	KubeClient  kubernetes.Interface
+	VaultClient vault.Client
+	Namespace   string
+	Rotators    map[string]Rotator
+	Audit       *AuditStore
+	User        string
+}
+
+// Rotate updates the external systems which use the secret with its new value before writing the new version to
+// Vault and updating the Kubernetes secrets, then rolls the workloads which consume the Kubernetes secrets. If Vault
+// or a Kubernetes secret cannot be updated the previous value is restored everywhere so that the systems and their
+// clients keep agreeing on the value. An audit record is stored whether or not the rotation succeeds once the new
+// value has been created
+func (r *Rotation) Rotate(secret *Secret) (*Record, error) {
+	rotator := r.Rotators[secret.Kind]
+	if rotator == nil {
+		return nil, fmt.Errorf("no rotator for secret %s of kind %s", secret.Name, secret.Kind)
+	}
+	current, err := r.VaultClient.Read(secret.VaultPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read secret %s from Vault", secret.Name)
+	}
+	data, err := rotator.Rotate(secret, current)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Name:      secret.Name,
+		Kind:      secret.Kind,
+		Timestamp: time.Now(),
+		User:      r.User,
+		VaultPath: secret.VaultPath,
+	}
+	err = r.apply(secret, rotator, current, data, record)
+	if err != nil {
+		record.Error = err.Error()
+	}
+	auditErr := r.Audit.Record(record)
+	if err != nil {
+		return record, err
+	}
+	return record, auditErr
+}
+
+func (r *Rotation) apply(secret *Secret, rotator Rotator, current map[string]interface{}, data map[string]interface{}, record *Record) error {
+	err := rotator.Apply(secret, data, current)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the systems which use secret %s so it has not been rotated", secret.Name)
+	}
+	written, err := r.VaultClient.Write(secret.VaultPath, data)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write secret %s to Vault path %s", secret.Name, secret.VaultPath)
+		return r.restore(secret, rotator, current, data, false, nil, err)
+	}
+	if version, ok := written["version"]; ok {
+		record.VaultVersion = fmt.Sprint(version)
+	}
+
+	var originals []*corev1.Secret
+	updated := map[string][]string{}
+	for i := range secret.KubernetesSecrets {
+		ref := &secret.KubernetesSecrets[i]
+		ns := ref.Namespace
+		if ns == "" {
+			ns = r.Namespace
+		}
+		original, err := r.updateKubeSecret(secret, rotator, ref, ns, data)
+		if err != nil {
+			return r.restore(secret, rotator, current, data, true, originals, err)
+		}
+		originals = append(originals, original)
+		if util.StringArrayIndex(updated[ns], ref.Name) < 0 {
+			updated[ns] = append(updated[ns], ref.Name)
+			record.KubernetesSecrets = append(record.KubernetesSecrets, ns+"/"+ref.Name)
+		}
+	}
+
+	namespaces := make([]string, 0, len(updated))
+	for ns := range updated {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		names, err := r.rollWorkloads(ns, updated[ns], record.Timestamp)
+		record.Workloads = append(record.Workloads, names...)
+		if err != nil {
+			return errors.Wrapf(err, "secret %s has been rotated but not all of the workloads which use it have been rolled", secret.Name)
+		}
+	}
+	return nil
+}
+
+// restore puts back the previous value of the secret in the Kubernetes secrets which have been updated, in Vault if
+// the new version was written and in the external systems after the rotation failed with the cause
+func (r *Rotation) restore(secret *Secret, rotator Rotator, current map[string]interface{}, data map[string]interface{}, vaultWritten bool, originals []*corev1.Secret, cause error) error {
+	var failures []string
+	// the secrets are restored in reverse order so that a secret updated more than once ends up with its original data
+	for i := len(originals) - 1; i >= 0; i-- {
+		err := r.restoreKubeSecret(originals[i])
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if vaultWritten {
+		_, err := r.VaultClient.Write(secret.VaultPath, current)
+		if err != nil {
+			failures = append(failures, errors.Wrapf(err, "failed to write the previous version of secret %s to Vault path %s", secret.Name, secret.VaultPath).Error())
+		}
+	}
+	err := rotator.Apply(secret, current, data)
+	if err != nil {
+		failures = append(failures, errors.Wrapf(err, "failed to update the systems which use secret %s", secret.Name).Error())
+	}
+	if len(failures) > 0 {
+		return errors.Wrapf(cause, "failed to restore the previous value of secret %s (%s)", secret.Name, strings.Join(failures, ", "))
+	}
+	return errors.Wrapf(cause, "restored the previous value of secret %s", secret.Name)
+}
+
+// updateKubeSecret updates the Kubernetes secret with the new data returning its original state
+func (r *Rotation) updateKubeSecret(secret *Secret, rotator Rotator, ref *SecretRef, ns string, data map[string]interface{}) (*corev1.Secret, error) {
+	secrets := r.KubeClient.CoreV1().Secrets(ns)
+	kubeSecret, err := secrets.Get(ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get Secret %s in namespace %s", ref.Name, ns)
+	}
+	original := kubeSecret.DeepCopy()
+	if kubeSecret.Data == nil {
+		kubeSecret.Data = map[string][]byte{}
+	}
+	if updater, ok := rotator.(KubeSecretUpdater); ok {
+		err = updater.UpdateKubeSecret(secret, ref, data, kubeSecret)
+	} else {
+		err = copyValue(secret, ref, data, kubeSecret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = secrets.Update(kubeSecret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update Secret %s in namespace %s", ref.Name, ns)
+	}
+	log.Logger().Infof("updated Secret %s in namespace %s", util.ColorInfo(ref.Name), util.ColorInfo(ns))
+	return original, nil
+}
+
+// restoreKubeSecret puts back the original data of a Kubernetes secret. The secret is read again as the original has
+// the resource version from before it was updated
+func (r *Rotation) restoreKubeSecret(original *corev1.Secret) error {
+	secrets := r.KubeClient.CoreV1().Secrets(original.Namespace)
+	kubeSecret, err := secrets.Get(original.Name, metav1.GetOptions{})
+	if err == nil {
+		kubeSecret.Data = original.Data
+		_, err = secrets.Update(kubeSecret)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to restore Secret %s in namespace %s", original.Name, original.Namespace)
+	}
+	log.Logger().Warnf("restored the previous data of Secret %s in namespace %s", util.ColorInfo(original.Name), util.ColorInfo(original.Namespace))
+	return nil
+}
+
+// rollWorkloads annotates the pod template of the Deployments, StatefulSets and DaemonSets which use the secrets so
+// that their pods are replaced with pods using the new values. The workloads are returned as 'Kind namespace/name'
+func (r *Rotation) rollWorkloads(ns string, secretNames []string, timestamp time.Time) ([]string, error) {
+	apps := r.KubeClient.AppsV1()
+	var answer []string
+	rolled := func(kind string, name string) {
+		log.Logger().Infof("rolling %s %s in namespace %s", kind, util.ColorInfo(name), util.ColorInfo(ns))
+		answer = append(answer, fmt.Sprintf("%s %s/%s", kind, ns, name))
+	}
+
+	deployments, err := apps.Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list Deployments in namespace %s", ns)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if !annotatePodTemplate(&d.Spec.Template, secretNames, timestamp) {
+			continue
+		}
+		_, err = apps.Deployments(ns).Update(d)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to roll Deployment %s in namespace %s", d.Name, ns)
+		}
+		rolled("Deployment", d.Name)
+	}
+
+	statefulSets, err := apps.StatefulSets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list StatefulSets in namespace %s", ns)
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if !annotatePodTemplate(&s.Spec.Template, secretNames, timestamp) {
+			continue
+		}
+		_, err = apps.StatefulSets(ns).Update(s)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to roll StatefulSet %s in namespace %s", s.Name, ns)
+		}
+		if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			log.Logger().Warnf("StatefulSet %s in namespace %s uses the OnDelete update strategy so its pods must be deleted to use the new value", s.Name, ns)
+		}
+		rolled("StatefulSet", s.Name)
+	}
+
+	daemonSets, err := apps.DaemonSets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list DaemonSets in namespace %s", ns)
+	}
+	for i := range daemonSets.Items {
+		d := &daemonSets.Items[i]
+		if !annotatePodTemplate(&d.Spec.Template, secretNames, timestamp) {
+			continue
+		}
+		_, err = apps.DaemonSets(ns).Update(d)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to roll DaemonSet %s in namespace %s", d.Name, ns)
+		}
+		if d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			log.Logger().Warnf("DaemonSet %s in namespace %s uses the OnDelete update strategy so its pods must be deleted to use the new value", d.Name, ns)
+		}
+		rolled("DaemonSet", d.Name)
+	}
+	return answer, nil
+}
+
+// annotatePodTemplate adds the rotated annotation to the pod template if its pods use any of the secrets returning
+// true if it was added
+func annotatePodTemplate(template *corev1.PodTemplateSpec, secretNames []string, timestamp time.Time) bool {
+	if !UsesSecrets(&template.Spec, secretNames) {
+		return false
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[RotatedAnnotation] = timestamp.UTC().Format(time.RFC3339)
+	return true
+}
+
+// UsesSecrets returns true if the pod uses any of the secrets in its environment variables or volumes
+func UsesSecrets(pod *corev1.PodSpec, secretNames []string) bool {
+	uses := func(name string) bool {
+		return util.StringArrayIndex(secretNames, name) >= 0
+	}
+	containers := append(append([]corev1.Container{}, pod.InitContainers...), pod.Containers...)
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && uses(env.ValueFrom.SecretKeyRef.Name) {
+				return true
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.SecretRef != nil && uses(envFrom.SecretRef.Name) {
+				return true
+			}
+		}
+	}
+	for _, v := range pod.Volumes {
+		if v.Secret != nil && uses(v.Secret.SecretName) {
+			return true
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.Secret != nil && uses(source.Secret.Name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,370 @@
+package rotation_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/vault/fake"
+	"github.com/jenkins-x/jx/pkg/vault/rotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const ns = "jx"
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	config, err := rotation.LoadConfig(filepath.Join("test_data", rotation.ConfigFileName))
+	require.NoError(t, err)
+	require.Len(t, config.Secrets, 4)
+
+	secret := config.Find("pipeline-git-token")
+	require.NotNil(t, secret)
+	assert.Equal(t, rotation.KindGitToken, secret.Kind)
+	assert.Equal(t, "token", secret.ValueKey())
+	assert.Equal(t, "password", secret.KubernetesSecrets[0].Key)
+	assert.Nil(t, config.Find("missing"))
+
+	invalid := &rotation.Config{Secrets: []rotation.Secret{{Name: "docker", Kind: rotation.KindDocker, VaultPath: "jx/docker"}}}
+	assert.Error(t, invalid.Validate(), "a docker secret needs a registry")
+	invalid = &rotation.Config{Secrets: []rotation.Secret{{Name: "my secret", Kind: rotation.KindPassword, VaultPath: "jx/secret"}}}
+	assert.Error(t, invalid.Validate(), "the name is not a valid ConfigMap key")
+	invalid = &rotation.Config{Secrets: []rotation.Secret{{Name: "secret", Kind: "ssh", VaultPath: "jx/secret"}}}
+	assert.Error(t, invalid.Validate(), "the kind is not supported")
+	invalid = &rotation.Config{Secrets: []rotation.Secret{{Name: "postgres", Kind: rotation.KindPassword, VaultPath: "jx/postgres"}}}
+	assert.Error(t, invalid.Validate(), "a password needs an apply command unless it is stateless")
+	valid := &rotation.Config{Secrets: []rotation.Secret{{Name: "postgres", Kind: rotation.KindPassword, VaultPath: "jx/postgres", ApplyCommand: []string{"./change-password.sh"}}}}
+	assert.NoError(t, valid.Validate())
+}
+
+func TestRotatePassword(t *testing.T) {
+	t.Parallel()
+	kubeClient := kubefake.NewSimpleClientset(
+		secret("jenkins-x-chartmuseum", map[string][]byte{"BASIC_AUTH_PASS": []byte("old")}),
+		deployment("jenkins-x-chartmuseum", corev1.EnvVar{
+			Name: "BASIC_AUTH_PASS",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "jenkins-x-chartmuseum"},
+					Key:                  "BASIC_AUTH_PASS",
+				},
+			},
+		}),
+		deployment("jenkins-x-nexus", corev1.EnvVar{Name: "USER", Value: "admin"}),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "chartmuseum-cache", Namespace: ns},
+			Spec:       appsv1.StatefulSetSpec{Template: podTemplate("cache", "jenkins-x-chartmuseum")},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "chartmuseum-agent", Namespace: ns},
+			Spec:       appsv1.DaemonSetSpec{Template: podTemplate("agent", "jenkins-x-chartmuseum")},
+		},
+	)
+	vaultClient := fake.NewFakeVaultClient()
+	vaultClient.Data["jx/chartmuseum"] = map[string]interface{}{"username": "admin", "password": "old"}
+
+	r := createRotation(kubeClient, vaultClient, &rotation.PasswordRotator{})
+	s := &rotation.Secret{
+		Name:              "chartmuseum",
+		Kind:              rotation.KindPassword,
+		VaultPath:         "jx/chartmuseum",
+		Length:            32,
+		Stateless:         true,
+		KubernetesSecrets: []rotation.SecretRef{{Name: "jenkins-x-chartmuseum", Key: "BASIC_AUTH_PASS"}},
+	}
+	record, err := r.Rotate(s)
+	require.NoError(t, err)
+
+	password := vaultClient.Data["jx/chartmuseum"]["password"]
+	assert.Len(t, password, 32)
+	assert.NotEqual(t, "old", password)
+	assert.Equal(t, "admin", vaultClient.Data["jx/chartmuseum"]["username"])
+
+	kubeSecret, err := kubeClient.CoreV1().Secrets(ns).Get("jenkins-x-chartmuseum", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, password, string(kubeSecret.Data["BASIC_AUTH_PASS"]))
+
+	d, err := kubeClient.AppsV1().Deployments(ns).Get("jenkins-x-chartmuseum", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, d.Spec.Template.Annotations[rotation.RotatedAnnotation])
+	d, err = kubeClient.AppsV1().Deployments(ns).Get("jenkins-x-nexus", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, d.Spec.Template.Annotations[rotation.RotatedAnnotation])
+	statefulSet, err := kubeClient.AppsV1().StatefulSets(ns).Get("chartmuseum-cache", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, statefulSet.Spec.Template.Annotations[rotation.RotatedAnnotation])
+	ds, err := kubeClient.AppsV1().DaemonSets(ns).Get("chartmuseum-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ds.Spec.Template.Annotations[rotation.RotatedAnnotation])
+
+	assert.Equal(t, []string{"jx/jenkins-x-chartmuseum"}, record.KubernetesSecrets)
+	assert.Equal(t, []string{
+		"Deployment jx/jenkins-x-chartmuseum",
+		"StatefulSet jx/chartmuseum-cache",
+		"DaemonSet jx/chartmuseum-agent",
+	}, record.Workloads)
+
+	due, err := r.Audit.IsDue("chartmuseum", 90*24*time.Hour, time.Now())
+	require.NoError(t, err)
+	assert.False(t, due)
+	due, err = r.Audit.IsDue("chartmuseum", 90*24*time.Hour, time.Now().Add(91*24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, due)
+	due, err = r.Audit.IsDue("hmac-token", 90*24*time.Hour, time.Now())
+	require.NoError(t, err)
+	assert.True(t, due, "a secret which has never been rotated is due")
+}
+
+func TestRotatePasswordRunsApplyCommand(t *testing.T) {
+	t.Parallel()
+	kubeClient := kubefake.NewSimpleClientset(secret("postgres", map[string][]byte{"password": []byte("old")}))
+	vaultClient := fake.NewFakeVaultClient()
+	vaultClient.Data["jx/postgres"] = map[string]interface{}{"password": "old"}
+
+	r := createRotation(kubeClient, vaultClient, &rotation.PasswordRotator{})
+	s := &rotation.Secret{
+		Name:              "postgres",
+		Kind:              rotation.KindPassword,
+		VaultPath:         "jx/postgres",
+		ApplyCommand:      []string{"sh", "-c", `test "$PREVIOUS_VALUE" = old && test -n "$ROTATED_VALUE"`},
+		KubernetesSecrets: []rotation.SecretRef{{Name: "postgres"}},
+	}
+	_, err := r.Rotate(s)
+	require.NoError(t, err)
+	assert.NotEqual(t, "old", vaultClient.Data["jx/postgres"]["password"])
+
+	vaultClient.Data["jx/postgres"] = map[string]interface{}{"password": "old"}
+	s.ApplyCommand = []string{"sh", "-c", "exit 1"}
+	record, err := r.Rotate(s)
+	assert.Error(t, err, "the password could not be changed in the database")
+	assert.NotEmpty(t, record.Error)
+	assert.Equal(t, "old", vaultClient.Data["jx/postgres"]["password"])
+}
+
+func TestRotateDockerConfig(t *testing.T) {
+	t.Parallel()
+	config := `{"auths":{"docker.example.com":{"auth":"b2xk","email":"admin@example.com"}}}`
+	kubeClient := kubefake.NewSimpleClientset(secret("jenkins-docker-cfg", map[string][]byte{"config.json": []byte(config)}))
+	vaultClient := fake.NewFakeVaultClient()
+	vaultClient.Data["jx/docker"] = map[string]interface{}{"username": "admin", "password": "old"}
+
+	r := createRotation(kubeClient, vaultClient, &rotation.DockerRotator{})
+	s := &rotation.Secret{
+		Name:              "docker-registry",
+		Kind:              rotation.KindDocker,
+		VaultPath:         "jx/docker",
+		Registry:          "docker.example.com",
+		KubernetesSecrets: []rotation.SecretRef{{Name: "jenkins-docker-cfg"}},
+	}
+	_, err := r.Rotate(s)
+	assert.Error(t, err, "the new password is required")
+
+	r.Rotators[rotation.KindDocker] = &rotation.DockerRotator{Password: "new"}
+	_, err = r.Rotate(s)
+	require.NoError(t, err)
+	assert.Equal(t, "new", vaultClient.Data["jx/docker"]["password"])
+
+	kubeSecret, err := kubeClient.CoreV1().Secrets(ns).Get("jenkins-docker-cfg", metav1.GetOptions{})
+	require.NoError(t, err)
+	updated := struct {
+		Auths map[string]map[string]string `json:"auths"`
+	}{}
+	require.NoError(t, json.Unmarshal(kubeSecret.Data["config.json"], &updated))
+	auth := updated.Auths["docker.example.com"]
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("admin:new")), auth["auth"])
+	assert.Equal(t, "admin@example.com", auth["email"])
+}
+
+func TestRotateWebhookAndGitToken(t *testing.T) {
+	t.Parallel()
+	kubeClient := kubefake.NewSimpleClientset(secret("hmac-token", map[string][]byte{"hmac": []byte("old")}))
+	vaultClient := fake.NewFakeVaultClient()
+	vaultClient.Data["jx/hmac"] = map[string]interface{}{"hmac": "old"}
+	vaultClient.Data["jx/pipelineUser"] = map[string]interface{}{"username": "jenkins-x-bot", "token": "old"}
+
+	hmacToken := ""
+	webhooks := &rotation.WebhookRotator{
+		UpdateWebhooks: func(token string) error {
+			hmacToken = token
+			return nil
+		},
+	}
+	gitTokens := &rotation.GitTokenRotator{
+		ValidateToken: func(secret *rotation.Secret, token string) error {
+			if token != "valid" {
+				return errors.New("bad credentials")
+			}
+			return nil
+		},
+	}
+	r := createRotation(kubeClient, vaultClient, webhooks)
+	r.Rotators[rotation.KindGitToken] = gitTokens
+
+	_, err := r.Rotate(&rotation.Secret{
+		Name:              "hmac-token",
+		Kind:              rotation.KindWebhook,
+		VaultPath:         "jx/hmac",
+		KubernetesSecrets: []rotation.SecretRef{{Name: "hmac-token"}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, hmacToken, 41)
+	assert.Equal(t, hmacToken, vaultClient.Data["jx/hmac"]["hmac"])
+	kubeSecret, err := kubeClient.CoreV1().Secrets(ns).Get("hmac-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, hmacToken, string(kubeSecret.Data["hmac"]))
+
+	gitSecret := &rotation.Secret{
+		Name:      "pipeline-git-token",
+		Kind:      rotation.KindGitToken,
+		VaultPath: "jx/pipelineUser",
+		GitServer: "https://github.com",
+		GitKind:   "github",
+		Username:  "jenkins-x-bot",
+	}
+	_, err = r.Rotate(gitSecret)
+	assert.Error(t, err, "a new token is required")
+	gitTokens.Token = "old"
+	_, err = r.Rotate(gitSecret)
+	assert.Error(t, err, "the token has not changed")
+	gitTokens.Token = "invalid"
+	_, err = r.Rotate(gitSecret)
+	assert.Error(t, err, "the token does not work")
+	assert.Equal(t, "old", vaultClient.Data["jx/pipelineUser"]["token"])
+	gitTokens.Token = "valid"
+	_, err = r.Rotate(gitSecret)
+	require.NoError(t, err)
+	assert.Equal(t, "valid", vaultClient.Data["jx/pipelineUser"]["token"])
+
+	records, err := r.Audit.List()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "pipeline-git-token", records[0].Name)
+	assert.Equal(t, "hmac-token", records[1].Name)
+}
+
+func TestFailedRotationRestoresPreviousValue(t *testing.T) {
+	t.Parallel()
+	kubeClient := kubefake.NewSimpleClientset(secret("hmac-token", map[string][]byte{"hmac": []byte("old")}))
+	vaultClient := fake.NewFakeVaultClient()
+	vaultClient.Data["jx/hmac"] = map[string]interface{}{"hmac": "old"}
+
+	var hmacTokens []string
+	webhooks := &rotation.WebhookRotator{
+		UpdateWebhooks: func(token string) error {
+			hmacTokens = append(hmacTokens, token)
+			return errors.New("failed to update webhook")
+		},
+	}
+	r := createRotation(kubeClient, vaultClient, webhooks)
+	s := &rotation.Secret{
+		Name:              "hmac-token",
+		Kind:              rotation.KindWebhook,
+		VaultPath:         "jx/hmac",
+		KubernetesSecrets: []rotation.SecretRef{{Name: "hmac-token"}, {Name: "missing"}},
+	}
+	record, err := r.Rotate(s)
+	require.Error(t, err)
+	assert.NotEmpty(t, record.Error)
+	assert.Len(t, hmacTokens, 1, "nothing else is updated when the webhooks cannot be")
+	assert.Equal(t, "old", vaultClient.Data["jx/hmac"]["hmac"])
+	kubeSecret, err := kubeClient.CoreV1().Secrets(ns).Get("hmac-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(kubeSecret.Data["hmac"]))
+
+	// the missing Kubernetes secret fails the rotation after the webhooks, Vault and the first secret are updated
+	hmacTokens = nil
+	webhooks.UpdateWebhooks = func(token string) error {
+		hmacTokens = append(hmacTokens, token)
+		return nil
+	}
+	_, err = r.Rotate(s)
+	require.Error(t, err)
+	require.Len(t, hmacTokens, 2)
+	assert.NotEqual(t, "old", hmacTokens[0])
+	assert.Equal(t, "old", hmacTokens[1], "the webhooks are updated back to the previous value")
+	assert.Equal(t, "old", vaultClient.Data["jx/hmac"]["hmac"])
+	kubeSecret, err = kubeClient.CoreV1().Secrets(ns).Get("hmac-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(kubeSecret.Data["hmac"]))
+}
+
+func TestAuditRecordsArePruned(t *testing.T) {
+	t.Parallel()
+	store := rotation.NewAuditStore(kubefake.NewSimpleClientset(), ns)
+	store.MaxRecords = 2
+	start := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := store.Record(&rotation.Record{Name: "hmac-token", Timestamp: start.Add(time.Duration(i) * time.Hour)})
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Record(&rotation.Record{Name: "chartmuseum", Timestamp: start, Error: "failed"}))
+
+	records, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, start.Add(2*time.Hour), records[0].Timestamp.UTC())
+
+	last, err := store.LastRotated("chartmuseum")
+	require.NoError(t, err)
+	assert.True(t, last.IsZero(), "failed rotations are ignored")
+}
+
+func createRotation(kubeClient *kubefake.Clientset, vaultClient fake.FakeVaultClient, rotator rotation.Rotator) *rotation.Rotation {
+	rotators := map[string]rotation.Rotator{}
+	switch rotator.(type) {
+	case *rotation.PasswordRotator:
+		rotators[rotation.KindPassword] = rotator
+	case *rotation.DockerRotator:
+		rotators[rotation.KindDocker] = rotator
+	case *rotation.WebhookRotator:
+		rotators[rotation.KindWebhook] = rotator
+	}
+	return &rotation.Rotation{
+		KubeClient:  kubeClient,
+		VaultClient: vaultClient,
+		Namespace:   ns,
+		Rotators:    rotators,
+		Audit:       rotation.NewAuditStore(kubeClient, ns),
+	}
+}
+
+func secret(name string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Data:       data,
+	}
+}
+
+func deployment(name string, env corev1.EnvVar) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: name, Env: []corev1.EnvVar{env}}},
+				},
+			},
+		},
+	}
+}
+
+// podTemplate returns a pod template which mounts the secret
+func podTemplate(name string, secretName string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: name}},
+			Volumes: []corev1.Volume{{
+				Name:         secretName,
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,240 @@
+package rotation
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultPasswordLength = 20
+
+	// why 41? the HMAC tokens created when installing are 41 characters
+	defaultHMACLength = 41
+
+	dockerConfigKey = "config.json"
+	usernameKey     = "username"
+
+	// RotatedValueEnvVar the environment variable of the apply command of a password with the new password
+	RotatedValueEnvVar = "ROTATED_VALUE"
+	// PreviousValueEnvVar the environment variable of the apply command of a password with the password it replaces
+	PreviousValueEnvVar = "PREVIOUS_VALUE"
+)
+
+// Rotator creates the new value of a kind of secret and updates the systems outside of Kubernetes which use it
+type Rotator interface {
+	// Rotate returns the new data of the Vault secret from its current data
+	Rotate(secret *Secret, current map[string]interface{}) (map[string]interface{}, error)
+
+	// Apply updates the systems which use the secret such as webhooks from the previous data to the new data. It is
+	// called before the new data is stored and again with the data swapped if storing the new data fails
+	Apply(secret *Secret, data map[string]interface{}, previous map[string]interface{}) error
+}
+
+// KubeSecretUpdater is implemented by a Rotator whose value is stored in Kubernetes secrets in its own format
+type KubeSecretUpdater interface {
+	// UpdateKubeSecret updates the Kubernetes secret with the new data of the Vault secret
+	UpdateKubeSecret(secret *Secret, ref *SecretRef, data map[string]interface{}, kubeSecret *corev1.Secret) error
+}
+
+// PasswordRotator generates random passwords such as those used in charts. A password which is also stored by the
+// system which checks it, such as a database password stored in its data volume, is changed by the apply command of
+// the secret
+type PasswordRotator struct{}
+
+// Rotate generates a new password
+func (r *PasswordRotator) Rotate(secret *Secret, current map[string]interface{}) (map[string]interface{}, error) {
+	length := secret.Length
+	if length <= 0 {
+		length = defaultPasswordLength
+	}
+	return generate(secret, current, length)
+}
+
+// Apply runs the apply command of the secret with the new and previous passwords in the ROTATED_VALUE and
+// PREVIOUS_VALUE environment variables. Stateless passwords have no apply command as they are only read from the
+// Kubernetes secrets
+func (r *PasswordRotator) Apply(secret *Secret, data map[string]interface{}, previous map[string]interface{}) error {
+	if len(secret.ApplyCommand) == 0 {
+		return nil
+	}
+	value, _ := data[secret.ValueKey()].(string)
+	previousValue, _ := previous[secret.ValueKey()].(string)
+	cmd := util.Command{
+		Name: secret.ApplyCommand[0],
+		Args: secret.ApplyCommand[1:],
+		Env: map[string]string{
+			RotatedValueEnvVar:  value,
+			PreviousValueEnvVar: previousValue,
+		},
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "failed to run the apply command of secret %s", secret.Name)
+	}
+	return nil
+}
+
+// WebhookRotator generates the HMAC token of the webhooks and updates the webhooks of the git repositories
+type WebhookRotator struct {
+	UpdateWebhooks func(hmacToken string) error
+}
+
+// Rotate generates a new HMAC token
+func (r *WebhookRotator) Rotate(secret *Secret, current map[string]interface{}) (map[string]interface{}, error) {
+	length := secret.Length
+	if length <= 0 {
+		length = defaultHMACLength
+	}
+	return generate(secret, current, length)
+}
+
+// Apply updates the webhooks with the new HMAC token
+func (r *WebhookRotator) Apply(secret *Secret, data map[string]interface{}, previous map[string]interface{}) error {
+	if r.UpdateWebhooks == nil {
+		return nil
+	}
+	token, _ := data[secret.ValueKey()].(string)
+	return r.UpdateWebhooks(token)
+}
+
+// GitTokenRotator stores a new API token of a git provider. Git providers cannot create API tokens so the new
+// token is created by a user and checked using the git provider before it is stored
+type GitTokenRotator struct {
+	Token         string
+	ValidateToken func(secret *Secret, token string) error
+}
+
+// Rotate returns the data with the new token once it has been checked
+func (r *GitTokenRotator) Rotate(secret *Secret, current map[string]interface{}) (map[string]interface{}, error) {
+	if r.Token == "" {
+		return nil, fmt.Errorf("secret %s needs a new API token which you can create at %s", secret.Name,
+			gits.ProviderAccessTokenURL(secret.GitKind, secret.GitServer, secret.Username))
+	}
+	if current[secret.ValueKey()] == r.Token {
+		return nil, fmt.Errorf("the new API token of secret %s is the same as the current token", secret.Name)
+	}
+	if r.ValidateToken != nil {
+		err := r.ValidateToken(secret, r.Token)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the new API token of secret %s does not work with %s", secret.Name, secret.GitServer)
+		}
+	}
+	return withValue(current, secret.ValueKey(), r.Token), nil
+}
+
+// Apply does nothing as the old token is revoked by the user once the new token is in use
+func (r *GitTokenRotator) Apply(secret *Secret, data map[string]interface{}, previous map[string]interface{}) error {
+	return nil
+}
+
+// DockerRotator stores a new password of a docker registry which has been changed in the registry
+type DockerRotator struct {
+	Password string
+}
+
+// Rotate returns the data with the new password
+func (r *DockerRotator) Rotate(secret *Secret, current map[string]interface{}) (map[string]interface{}, error) {
+	if r.Password == "" {
+		return nil, fmt.Errorf("secret %s needs the new password of the docker registry %s", secret.Name, secret.Registry)
+	}
+	return withValue(current, secret.ValueKey(), r.Password), nil
+}
+
+// Apply does nothing as the password has already been changed in the registry
+func (r *DockerRotator) Apply(secret *Secret, data map[string]interface{}, previous map[string]interface{}) error {
+	return nil
+}
+
+// UpdateKubeSecret updates the auth of the registry in a docker config secret or the password in other secrets
+func (r *DockerRotator) UpdateKubeSecret(secret *Secret, ref *SecretRef, data map[string]interface{}, kubeSecret *corev1.Secret) error {
+	key := ref.Key
+	if key == "" {
+		if kubeSecret.Type == corev1.SecretTypeDockerConfigJson {
+			key = corev1.DockerConfigJsonKey
+		} else if _, ok := kubeSecret.Data[dockerConfigKey]; ok {
+			key = dockerConfigKey
+		}
+	}
+	if key != corev1.DockerConfigJsonKey && key != dockerConfigKey {
+		return copyValue(secret, ref, data, kubeSecret)
+	}
+
+	username := secret.Username
+	if username == "" {
+		username, _ = data[usernameKey].(string)
+	}
+	if username == "" {
+		return fmt.Errorf("secret %s has no username for the docker registry %s", secret.Name, secret.Registry)
+	}
+	password, _ := data[secret.ValueKey()].(string)
+
+	config := map[string]interface{}{}
+	if len(kubeSecret.Data[key]) > 0 {
+		err := json.Unmarshal(kubeSecret.Data[key], &config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the docker config in key %s of secret %s", key, kubeSecret.Name)
+		}
+	}
+	auths, _ := config["auths"].(map[string]interface{})
+	if auths == nil {
+		auths = map[string]interface{}{}
+		config["auths"] = auths
+	}
+	auth, _ := auths[secret.Registry].(map[string]interface{})
+	if auth == nil {
+		auth = map[string]interface{}{}
+		auths[secret.Registry] = auth
+	}
+	auth["auth"] = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	if _, ok := auth["password"]; ok {
+		auth["username"] = username
+		auth["password"] = password
+	}
+	value, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	kubeSecret.Data[key] = value
+	return nil
+}
+
+// copyValue stores the rotated value in the key of the Kubernetes secret
+func copyValue(secret *Secret, ref *SecretRef, data map[string]interface{}, kubeSecret *corev1.Secret) error {
+	key := ref.Key
+	if key == "" {
+		key = secret.ValueKey()
+	}
+	value, ok := data[secret.ValueKey()].(string)
+	if !ok {
+		return fmt.Errorf("secret %s has no value for key %s", secret.Name, secret.ValueKey())
+	}
+	kubeSecret.Data[key] = []byte(value)
+	return nil
+}
+
+func generate(secret *Secret, current map[string]interface{}, length int) (map[string]interface{}, error) {
+	b := make([]byte, (length+1)/2)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate a new value for secret %s", secret.Name)
+	}
+	return withValue(current, secret.ValueKey(), hex.EncodeToString(b)[:length]), nil
+}
+
+// withValue returns a copy of the data with the new value so that the other values of the secret are kept
+func withValue(current map[string]interface{}, key string, value string) map[string]interface{} {
+	answer := map[string]interface{}{}
+	for k, v := range current {
+		answer[k] = v
+	}
+	answer[key] = value
+	return answer
+}
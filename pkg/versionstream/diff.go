@@ -0,0 +1,247 @@
+package versionstream
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// KindQuickStart represents a quickstart in the quickstarts.yml file when comparing version streams
+	KindQuickStart VersionKind = "quickstarts"
+)
+
+// VersionChange a chart, image, package, git repository or quickstart whose version differs between two version streams.
+// The from version is empty if it was added and the to version is empty if it was removed
+type VersionChange struct {
+	Kind         VersionKind `json:"kind"`
+	Name         string      `json:"name"`
+	FromVersion  string      `json:"fromVersion,omitempty"`
+	ToVersion    string      `json:"toVersion,omitempty"`
+	GitURL       string      `json:"gitUrl,omitempty"`
+	ChangelogURL string      `json:"changelogUrl,omitempty"`
+}
+
+// Workload a running deployment or app whose chart and images are compared to the changes of a version stream
+type Workload struct {
+	Environment string `json:"environment"`
+	Namespace   string `json:"namespace,omitempty"`
+	// Name the kind and name of the resource such as deployment/jenkins-x-chartmuseum
+	Name string `json:"name"`
+	// Chart the helm chart label of the workload which is the chart name and version such as jenkins-x-platform-2.0.330
+	Chart string `json:"chart,omitempty"`
+	// ChartName the name of the chart with its repository prefix if it is known when the version is separate
+	ChartName    string   `json:"chartName,omitempty"`
+	ChartVersion string   `json:"chartVersion,omitempty"`
+	Images       []string `json:"images,omitempty"`
+}
+
+// Impact a workload which runs a version of a chart or image which changes in the version stream
+type Impact struct {
+	Workload       *Workload      `json:"workload"`
+	Change         *VersionChange `json:"change"`
+	CurrentVersion string         `json:"currentVersion,omitempty"`
+}
+
+// LoadStableVersions returns the stable versions of the kind in the version stream keyed by name
+func LoadStableVersions(dir string, kind VersionKind) (map[string]*StableVersion, error) {
+	answer := map[string]*StableVersion{}
+	kindDir := filepath.Join(dir, string(kind))
+	err := filepath.Walk(kindDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == kindDir {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yml" {
+			return nil
+		}
+		name, err := NameFromPath(kindDir, path)
+		if err != nil {
+			return err
+		}
+		if kind == KindChart && name == "repositories" {
+			return nil
+		}
+		version, err := LoadStableVersionFile(path)
+		if err != nil {
+			return err
+		}
+		answer[name] = version
+		return nil
+	})
+	return answer, err
+}
+
+// DiffVersionStreams returns the charts, images, packages, git repositories and quickstarts whose versions differ
+// between the version streams in the directories in order of kind and name
+func DiffVersionStreams(fromDir string, toDir string) ([]*VersionChange, error) {
+	var answer []*VersionChange
+	for _, kind := range Kinds {
+		from, err := LoadStableVersions(fromDir, kind)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the %s versions from %s", string(kind), fromDir)
+		}
+		to, err := LoadStableVersions(toDir, kind)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the %s versions from %s", string(kind), toDir)
+		}
+		var changes []*VersionChange
+		for name, t := range to {
+			f := from[name]
+			if f != nil && f.Version == t.Version {
+				continue
+			}
+			change := &VersionChange{Kind: kind, Name: name, ToVersion: t.Version, GitURL: t.GitURL}
+			if f != nil {
+				change.FromVersion = f.Version
+			}
+			changes = append(changes, change)
+		}
+		for name, f := range from {
+			if to[name] == nil {
+				changes = append(changes, &VersionChange{Kind: kind, Name: name, FromVersion: f.Version, GitURL: f.GitURL})
+			}
+		}
+		answer = append(answer, sortChanges(changes)...)
+	}
+
+	quickstarts, err := diffQuickStarts(fromDir, toDir)
+	if err != nil {
+		return nil, err
+	}
+	answer = append(answer, quickstarts...)
+	for _, c := range answer {
+		c.ChangelogURL = ChangelogURL(c.GitURL, c.FromVersion, c.ToVersion)
+	}
+	return answer, nil
+}
+
+func diffQuickStarts(fromDir string, toDir string) ([]*VersionChange, error) {
+	load := func(dir string) (map[string]*QuickStart, error) {
+		qs, err := GetQuickStarts(dir)
+		if err != nil {
+			return nil, err
+		}
+		qs.DefaultMissingValues()
+		answer := map[string]*QuickStart{}
+		for _, q := range qs.QuickStarts {
+			answer[q.ID] = q
+		}
+		return answer, nil
+	}
+	from, err := load(fromDir)
+	if err != nil {
+		return nil, err
+	}
+	to, err := load(toDir)
+	if err != nil {
+		return nil, err
+	}
+	gitURL := func(q *QuickStart) string {
+		return fmt.Sprintf("https://github.com/%s/%s", q.Owner, q.Name)
+	}
+	var changes []*VersionChange
+	for id, t := range to {
+		f := from[id]
+		if f != nil && f.Version == t.Version {
+			continue
+		}
+		change := &VersionChange{Kind: KindQuickStart, Name: id, ToVersion: t.Version, GitURL: gitURL(t)}
+		if f != nil {
+			change.FromVersion = f.Version
+		}
+		changes = append(changes, change)
+	}
+	for id, f := range from {
+		if to[id] == nil {
+			changes = append(changes, &VersionChange{Kind: KindQuickStart, Name: id, FromVersion: f.Version, GitURL: gitURL(f)})
+		}
+	}
+	return sortChanges(changes), nil
+}
+
+func sortChanges(changes []*VersionChange) []*VersionChange {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// ChangelogURL returns the URL comparing the versions of a git repository or the URL of the release of the new version
+// if it was added. Versions are assumed to be tagged with a v prefix
+func ChangelogURL(gitURL string, fromVersion string, toVersion string) string {
+	if toVersion == "" || !strings.HasPrefix(gitURL, "http") {
+		return ""
+	}
+	u := strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git")
+	tag := func(version string) string {
+		return "v" + strings.TrimPrefix(version, "v")
+	}
+	if fromVersion == "" {
+		return u + "/releases/tag/" + tag(toVersion)
+	}
+	return u + "/compare/" + tag(fromVersion) + "..." + tag(toVersion)
+}
+
+// FindImpacts returns the workloads which run a different version of a chart or image than the version it changes to
+func FindImpacts(changes []*VersionChange, workloads []*Workload) []*Impact {
+	var answer []*Impact
+	for _, w := range workloads {
+		for _, c := range changes {
+			current, ok := "", false
+			switch c.Kind {
+			case KindChart:
+				current, ok = matchChart(c.Name, w)
+			case KindDocker:
+				current, ok = matchImage(c.Name, w)
+			}
+			if ok && current != c.ToVersion {
+				answer = append(answer, &Impact{Workload: w, Change: c, CurrentVersion: current})
+			}
+		}
+	}
+	return answer
+}
+
+// matchChart returns the version of the chart the workload runs if it runs the chart
+func matchChart(name string, w *Workload) (string, bool) {
+	chartName := name[strings.LastIndex(name, "/")+1:]
+	if w.ChartName != "" {
+		if w.ChartName == name || (!strings.Contains(w.ChartName, "/") && w.ChartName == chartName) {
+			return w.ChartVersion, true
+		}
+		return "", false
+	}
+	if !strings.HasPrefix(w.Chart, chartName+"-") {
+		return "", false
+	}
+	version := strings.TrimPrefix(w.Chart, chartName+"-")
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		return "", false
+	}
+	return version, true
+}
+
+// matchImage returns the tag of the image the workload runs if it runs the image
+func matchImage(name string, w *Workload) (string, bool) {
+	for _, image := range w.Images {
+		imageName := image
+		tag := ""
+		idx := strings.LastIndex(image, ":")
+		if idx > strings.LastIndex(image, "/") {
+			imageName = image[:idx]
+			tag = image[idx+1:]
+		}
+		imageName = strings.TrimPrefix(strings.TrimPrefix(imageName, "docker.io/"), "library/")
+		if imageName == name {
+			return tag, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package versionstream_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffVersionStreams(t *testing.T) {
+	t.Parallel()
+	changes, err := versionstream.DiffVersionStreams(filepath.Join("test_data", "diff", "from"), filepath.Join("test_data", "diff", "to"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*versionstream.VersionChange{
+		{
+			Kind:         versionstream.KindChart,
+			Name:         "jenkins-x/jenkins-x-platform",
+			FromVersion:  "2.0.100",
+			ToVersion:    "2.0.150",
+			GitURL:       "https://github.com/jenkins-x/jenkins-x-platform.git",
+			ChangelogURL: "https://github.com/jenkins-x/jenkins-x-platform/compare/v2.0.100...v2.0.150",
+		},
+		{
+			Kind:         versionstream.KindChart,
+			Name:         "jenkins-x/lighthouse",
+			ToVersion:    "0.0.100",
+			GitURL:       "https://github.com/jenkins-x/lighthouse",
+			ChangelogURL: "https://github.com/jenkins-x/lighthouse/releases/tag/v0.0.100",
+		},
+		{
+			Kind:        versionstream.KindChart,
+			Name:        "stable/nginx-ingress",
+			FromVersion: "1.0.0",
+		},
+		{
+			Kind:        versionstream.KindDocker,
+			Name:        "gcr.io/jenkinsxio/builder-go",
+			FromVersion: "0.1.700",
+			ToVersion:   "0.1.750",
+		},
+		{
+			Kind:         versionstream.KindQuickStart,
+			Name:         "jenkins-x-quickstarts/node-http",
+			FromVersion:  "1.0.10",
+			ToVersion:    "1.0.11",
+			GitURL:       "https://github.com/jenkins-x-quickstarts/node-http",
+			ChangelogURL: "https://github.com/jenkins-x-quickstarts/node-http/compare/v1.0.10...v1.0.11",
+		},
+	}, changes)
+}
+
+func TestFindImpacts(t *testing.T) {
+	t.Parallel()
+	platform := &versionstream.VersionChange{Kind: versionstream.KindChart, Name: "jenkins-x/jenkins-x-platform", FromVersion: "2.0.100", ToVersion: "2.0.150"}
+	lighthouse := &versionstream.VersionChange{Kind: versionstream.KindChart, Name: "jenkins-x/lighthouse", ToVersion: "0.0.100"}
+	nginx := &versionstream.VersionChange{Kind: versionstream.KindDocker, Name: "nginx", FromVersion: "1.16", ToVersion: "1.17"}
+	builder := &versionstream.VersionChange{Kind: versionstream.KindDocker, Name: "gcr.io/jenkinsxio/builder-go", FromVersion: "0.1.700", ToVersion: "0.1.750"}
+	changes := []*versionstream.VersionChange{platform, lighthouse, nginx, builder}
+
+	controller := &versionstream.Workload{
+		Environment: "dev",
+		Name:        "deployment/jenkins-x-controllerbuild",
+		Chart:       "jenkins-x-platform-2.0.100",
+		Images:      []string{"gcr.io/jenkinsxio/jx:2.0.100"},
+	}
+	web := &versionstream.Workload{
+		Environment: "production",
+		Name:        "deployment/web",
+		Chart:       "web-0.0.1",
+		Images:      []string{"docker.io/library/nginx:1.16", "gcr.io/jenkinsxio/builder-go:0.1.750"},
+	}
+	app := &versionstream.Workload{
+		Environment:  "dev",
+		Name:         "app/lighthouse",
+		ChartName:    "jenkins-x/lighthouse",
+		ChartVersion: "0.0.90",
+	}
+	other := &versionstream.Workload{
+		Environment: "staging",
+		Name:        "deployment/jenkins-x-platform-docs",
+		Chart:       "jenkins-x-platform-docs-1.0.0",
+	}
+
+	impacts := versionstream.FindImpacts(changes, []*versionstream.Workload{controller, web, app, other})
+	actual := []string{}
+	for _, i := range impacts {
+		actual = append(actual, i.Workload.Name+" "+i.Change.Name+" "+i.CurrentVersion)
+	}
+	assert.Equal(t, []string{
+		"deployment/jenkins-x-controllerbuild jenkins-x/jenkins-x-platform 2.0.100",
+		"deployment/web nginx 1.16",
+		"app/lighthouse jenkins-x/lighthouse 0.0.90",
+	}, actual)
+}
@@ -0,0 +1,327 @@
+package webhooks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DeliveriesConfigMapName the name of the ConfigMap which stores the recent webhook deliveries
+	DeliveriesConfigMapName = "jx-webhook-deliveries"
+
+	// DefaultMaxDeliveries the default number of webhook deliveries which are kept
+	DefaultMaxDeliveries = 100
+
+	// DefaultMaxBytes the default total size of the stored webhook deliveries which keeps the ConfigMap well below the
+	// 1 MiB limit of Kubernetes objects
+	DefaultMaxBytes = 768 * 1024
+
+	// ReplayOfHeader the HTTP header with the ID of the delivery which a request replays
+	ReplayOfHeader = "X-Jx-Replay-Of"
+
+	// idFormat formats the time of a delivery into an ID which is a valid ConfigMap key and sorts by time
+	idFormat = "20060102-150405.000000000"
+
+	// updateAttempts the number of times the ConfigMap is updated when there are conflicting updates
+	updateAttempts = 5
+
+	// activityClockSkew how long before a delivery was recorded a matching PipelineActivity can be created
+	activityClockSkew = 10 * time.Second
+)
+
+// Delivery is a webhook payload received by the pipeline runner and the pipeline it triggered
+type Delivery struct {
+	ID               string    `json:"id"`
+	Timestamp        time.Time `json:"timestamp"`
+	Owner            string    `json:"owner,omitempty"`
+	Repository       string    `json:"repository,omitempty"`
+	Branch           string    `json:"branch,omitempty"`
+	Context          string    `json:"context,omitempty"`
+	Revision         string    `json:"revision,omitempty"`
+	PipelineActivity string    `json:"pipelineActivity,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	ReplayOf         string    `json:"replayOf,omitempty"`
+	Payload          string    `json:"payload,omitempty"`
+	// PayloadOmitted is true if the payload was not stored as it was too large even when compressed
+	PayloadOmitted bool `json:"payloadOmitted,omitempty"`
+}
+
+// storedDelivery is a delivery as it is stored in the ConfigMap with its payload compressed
+type storedDelivery struct {
+	Delivery
+	CompressedPayload []byte `json:"compressedPayload,omitempty"`
+}
+
+// DeliveryStore stores the most recent webhook deliveries in a ConfigMap with one entry per delivery. The oldest
+// deliveries are removed once there are more than MaxDeliveries or they take more than MaxBytes
+type DeliveryStore struct {
+	KubeClient    kubernetes.Interface
+	Namespace     string
+	MaxDeliveries int
+	MaxBytes      int
+
+	lock sync.Mutex
+}
+
+// NewDeliveryStore creates a store of webhook deliveries in the namespace
+func NewDeliveryStore(kubeClient kubernetes.Interface, ns string) *DeliveryStore {
+	return &DeliveryStore{
+		KubeClient:    kubeClient,
+		Namespace:     ns,
+		MaxDeliveries: DefaultMaxDeliveries,
+		MaxBytes:      DefaultMaxBytes,
+	}
+}
+
+// Record stores the delivery assigning its ID and timestamp if they are not set and removing the oldest deliveries
+// beyond the maximum number or size of deliveries. The payload is omitted if the delivery is too large on its own
+func (s *DeliveryStore) Record(delivery *Delivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if delivery.Timestamp.IsZero() {
+		delivery.Timestamp = time.Now()
+	}
+	if delivery.ID == "" {
+		delivery.ID = delivery.Timestamp.UTC().Format(idFormat)
+	}
+	data, err := encodeDelivery(delivery)
+	if err != nil {
+		return err
+	}
+	if len(delivery.ID)+len(data) > s.maxBytes() {
+		omitted := *delivery
+		omitted.Payload = ""
+		omitted.PayloadOmitted = true
+		data, err = encodeDelivery(&omitted)
+		if err != nil {
+			return err
+		}
+	}
+
+	configMaps := s.KubeClient.CoreV1().ConfigMaps(s.Namespace)
+	for i := 0; ; i++ {
+		cm, err := configMaps.Get(DeliveriesConfigMapName, metav1.GetOptions{})
+		create := false
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to get ConfigMap %s in namespace %s", DeliveriesConfigMapName, s.Namespace)
+			}
+			create = true
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: DeliveriesConfigMapName,
+				},
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[delivery.ID] = data
+		s.prune(cm.Data)
+
+		if create {
+			_, err = configMaps.Create(cm)
+		} else {
+			_, err = configMaps.Update(cm)
+		}
+		if err == nil {
+			return nil
+		}
+		if (!apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err)) || i >= updateAttempts {
+			return errors.Wrapf(err, "failed to save webhook delivery %s in ConfigMap %s", delivery.ID, DeliveriesConfigMapName)
+		}
+	}
+}
+
+func (s *DeliveryStore) maxBytes() int {
+	if s.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return s.MaxBytes
+}
+
+// prune removes the oldest deliveries beyond the maximum number of deliveries or the maximum size
+func (s *DeliveryStore) prune(data map[string]string) {
+	max := s.MaxDeliveries
+	if max <= 0 {
+		max = DefaultMaxDeliveries
+	}
+	size := 0
+	keys := make([]string, 0, len(data))
+	for k, v := range data {
+		keys = append(keys, k)
+		size += len(k) + len(v)
+	}
+	sort.Strings(keys)
+	maxBytes := s.maxBytes()
+	for _, k := range keys {
+		if len(data) <= max && size <= maxBytes {
+			return
+		}
+		size -= len(k) + len(data[k])
+		delete(data, k)
+	}
+}
+
+// encodeDelivery returns the JSON of the delivery with its payload compressed
+func encodeDelivery(delivery *Delivery) (string, error) {
+	stored := storedDelivery{
+		Delivery: *delivery,
+	}
+	if delivery.Payload != "" {
+		buf := bytes.Buffer{}
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write([]byte(delivery.Payload))
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to compress the payload of webhook delivery %s", delivery.ID)
+		}
+		stored.Payload = ""
+		stored.CompressedPayload = buf.Bytes()
+	}
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal webhook delivery %s", delivery.ID)
+	}
+	return string(data), nil
+}
+
+// decodeDelivery parses a delivery stored by encodeDelivery or a delivery stored with an uncompressed payload
+func decodeDelivery(id string, value string) (*Delivery, error) {
+	stored := &storedDelivery{}
+	err := json.Unmarshal([]byte(value), stored)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal webhook delivery %s", id)
+	}
+	delivery := &stored.Delivery
+	delivery.ID = id
+	if len(stored.CompressedPayload) > 0 {
+		reader, err := gzip.NewReader(bytes.NewReader(stored.CompressedPayload))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decompress the payload of webhook delivery %s", id)
+		}
+		payload, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decompress the payload of webhook delivery %s", id)
+		}
+		delivery.Payload = string(payload)
+	}
+	return delivery, nil
+}
+
+// List returns the stored deliveries, newest first
+func (s *DeliveryStore) List() ([]*Delivery, error) {
+	cm, err := s.KubeClient.CoreV1().ConfigMaps(s.Namespace).Get(DeliveriesConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get ConfigMap %s in namespace %s", DeliveriesConfigMapName, s.Namespace)
+	}
+	var answer []*Delivery
+	for id, value := range cm.Data {
+		delivery, err := decodeDelivery(id, value)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, delivery)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].ID > answer[j].ID
+	})
+	return answer, nil
+}
+
+// Get returns the delivery with the ID
+func (s *DeliveryStore) Get(id string) (*Delivery, error) {
+	deliveries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("no webhook delivery %s found in ConfigMap %s. It may have been removed as only the most recent deliveries are kept", id, DeliveriesConfigMapName)
+}
+
+// FilterByRepository returns the deliveries for the repository which is either owner/name or just the name
+func FilterByRepository(deliveries []*Delivery, repository string) []*Delivery {
+	if repository == "" {
+		return deliveries
+	}
+	owner := ""
+	name := repository
+	idx := strings.Index(repository, "/")
+	if idx >= 0 {
+		owner = repository[:idx]
+		name = repository[idx+1:]
+	}
+	var answer []*Delivery
+	for _, d := range deliveries {
+		if !strings.EqualFold(d.Repository, name) {
+			continue
+		}
+		if owner != "" && !strings.EqualFold(d.Owner, owner) {
+			continue
+		}
+		answer = append(answer, d)
+	}
+	return answer
+}
+
+// MatchActivity returns the PipelineActivity the delivery triggered or nil if there is none. Deliveries which do not
+// record the name of their PipelineActivity are matched to the first PipelineActivity of the same repository, branch
+// and context created after the delivery
+func MatchActivity(delivery *Delivery, activities []v1.PipelineActivity) *v1.PipelineActivity {
+	if delivery.PipelineActivity != "" {
+		for i := range activities {
+			if activities[i].Name == delivery.PipelineActivity {
+				return &activities[i]
+			}
+		}
+		return nil
+	}
+	if delivery.Error != "" {
+		return nil
+	}
+	since := delivery.Timestamp.Add(-activityClockSkew)
+	var answer *v1.PipelineActivity
+	for i := range activities {
+		a := &activities[i]
+		spec := &a.Spec
+		if !strings.EqualFold(spec.GitOwner, delivery.Owner) || !strings.EqualFold(spec.GitRepository, delivery.Repository) ||
+			!strings.EqualFold(spec.GitBranch, delivery.Branch) {
+			continue
+		}
+		if delivery.Context != "" && spec.Context != "" && spec.Context != delivery.Context {
+			continue
+		}
+		created := a.CreationTimestamp.Time
+		if created.Before(since) {
+			continue
+		}
+		if answer == nil || created.Before(answer.CreationTimestamp.Time) {
+			answer = a
+		}
+	}
+	return answer
+}
@@ -0,0 +1,155 @@
+package webhooks_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRecordAndList(t *testing.T) {
+	t.Parallel()
+	store := webhooks.NewDeliveryStore(fake.NewSimpleClientset(), "jx")
+	store.MaxDeliveries = 2
+
+	deliveries, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	start := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	for i, repo := range []string{"app1", "app2", "app3"} {
+		err = store.Record(&webhooks.Delivery{
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+			Owner:      "myorg",
+			Repository: repo,
+			Payload:    `{"labels":{}}`,
+		})
+		require.NoError(t, err)
+	}
+
+	deliveries, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2, "the oldest delivery is removed")
+	assert.Equal(t, "20191021-120200.000000000", deliveries[0].ID)
+	assert.Equal(t, "app3", deliveries[0].Repository)
+	assert.Equal(t, "app2", deliveries[1].Repository)
+
+	delivery, err := store.Get("20191021-120100.000000000")
+	require.NoError(t, err)
+	assert.Equal(t, `{"labels":{}}`, delivery.Payload)
+
+	_, err = store.Get("20191021-120000.000000000")
+	assert.Error(t, err)
+}
+
+func TestRecordLimitsSize(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	store := webhooks.NewDeliveryStore(kubeClient, "jx")
+	store.MaxBytes = 4096
+
+	// random payloads do not compress
+	payload := func(size int) string {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+		return hex.EncodeToString(data)
+	}
+	start := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err := store.Record(&webhooks.Delivery{
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+			Repository: "myapp",
+			Payload:    payload(500),
+		})
+		require.NoError(t, err)
+	}
+	deliveries, err := store.List()
+	require.NoError(t, err)
+	assert.True(t, len(deliveries) > 0 && len(deliveries) < 5, "the oldest deliveries are removed to limit the size but %d are kept", len(deliveries))
+	assert.Equal(t, "20191021-120400.000000000", deliveries[0].ID)
+	assert.Len(t, deliveries[0].Payload, 1000)
+
+	large := &webhooks.Delivery{
+		Timestamp:  start.Add(time.Hour),
+		Repository: "myapp",
+		Payload:    payload(5000),
+	}
+	err = store.Record(large)
+	require.NoError(t, err)
+	assert.NotEmpty(t, large.Payload, "the recorded delivery is not changed")
+
+	delivery, err := store.Get("20191021-130000.000000000")
+	require.NoError(t, err)
+	assert.True(t, delivery.PayloadOmitted)
+	assert.Empty(t, delivery.Payload)
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("jx").Get(webhooks.DeliveriesConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	size := 0
+	for k, v := range cm.Data {
+		size += len(k) + len(v)
+	}
+	assert.True(t, size <= store.MaxBytes, "the ConfigMap has %d bytes", size)
+}
+
+func TestFilterByRepository(t *testing.T) {
+	t.Parallel()
+	deliveries := []*webhooks.Delivery{
+		{ID: "1", Owner: "myorg", Repository: "myapp"},
+		{ID: "2", Owner: "other", Repository: "myapp"},
+		{ID: "3", Owner: "myorg", Repository: "other"},
+	}
+	assert.Len(t, webhooks.FilterByRepository(deliveries, ""), 3)
+	assert.Len(t, webhooks.FilterByRepository(deliveries, "myapp"), 2)
+	filtered := webhooks.FilterByRepository(deliveries, "myorg/myapp")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "1", filtered[0].ID)
+}
+
+func TestMatchActivity(t *testing.T) {
+	t.Parallel()
+	received := time.Date(2019, time.October, 21, 12, 0, 0, 0, time.UTC)
+	activity := func(name string, created time.Time, branch string) v1.PipelineActivity {
+		return v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: v1.PipelineActivitySpec{
+				GitOwner:      "myorg",
+				GitRepository: "myapp",
+				GitBranch:     branch,
+			},
+		}
+	}
+	activities := []v1.PipelineActivity{
+		activity("myorg-myapp-pr-1-1", received.Add(-time.Hour), "PR-1"),
+		activity("myorg-myapp-pr-1-3", received.Add(time.Minute), "PR-1"),
+		activity("myorg-myapp-pr-1-2", received.Add(time.Second), "PR-1"),
+		activity("myorg-myapp-master-4", received.Add(time.Second), "master"),
+	}
+
+	delivery := &webhooks.Delivery{Timestamp: received, Owner: "myorg", Repository: "myapp", Branch: "PR-1"}
+	match := webhooks.MatchActivity(delivery, activities)
+	require.NotNil(t, match)
+	assert.Equal(t, "myorg-myapp-pr-1-2", match.Name)
+
+	delivery.PipelineActivity = "myorg-myapp-pr-1-3"
+	match = webhooks.MatchActivity(delivery, activities)
+	require.NotNil(t, match)
+	assert.Equal(t, "myorg-myapp-pr-1-3", match.Name)
+
+	delivery = &webhooks.Delivery{Timestamp: received, Owner: "myorg", Repository: "myapp", Branch: "PR-1", Error: "no prowJobSpec.refs passed"}
+	assert.Nil(t, webhooks.MatchActivity(delivery, activities))
+
+	delivery = &webhooks.Delivery{Timestamp: received.Add(time.Hour), Owner: "myorg", Repository: "myapp", Branch: "PR-1"}
+	assert.Nil(t, webhooks.MatchActivity(delivery, activities))
+}